@@ -0,0 +1,67 @@
+package sqlb
+
+import (
+	"context"
+	"hash/fnv"
+
+	"github.com/pkg/errors"
+)
+
+// AdvisoryLockKey derives the key of the advisory lock from the name, using FNV-1a hash.
+func AdvisoryLockKey(name string) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(name))
+	return int64(h.Sum64())
+}
+
+// AdvisoryLock obtains the session-level advisory lock of the key (pg_advisory_lock), waits until available.
+//
+// The lock is held until released by AdvisoryUnlock or the session ends,
+// the executor must be bound to a single connection (eg: sqlb.WrapExecutor(conn) of *sql.Conn), not a pool.
+func AdvisoryLock(ctx context.Context, exec Executor, key int64) error {
+	if _, err := exec.ExecContext(ctx, "SELECT pg_advisory_lock($1)", key); err != nil {
+		return errors.Wrapf(err, "failed to obtain advisory lock %d", key)
+	}
+	return nil
+}
+
+// TryAdvisoryLock obtains the session-level advisory lock of the key (pg_try_advisory_lock) if available,
+// returns false without waiting if not.
+func TryAdvisoryLock(ctx context.Context, exec Executor, key int64) (bool, error) {
+	var obtained bool
+	if err := queryScalar(ctx, exec, "SELECT pg_try_advisory_lock($1)", []any{key}, &obtained); err != nil {
+		return false, errors.Wrapf(err, "failed to try advisory lock %d", key)
+	}
+	return obtained, nil
+}
+
+// AdvisoryUnlock releases the session-level advisory lock of the key (pg_advisory_unlock),
+// returns false if the lock was not held.
+func AdvisoryUnlock(ctx context.Context, exec Executor, key int64) (bool, error) {
+	var released bool
+	if err := queryScalar(ctx, exec, "SELECT pg_advisory_unlock($1)", []any{key}, &released); err != nil {
+		return false, errors.Wrapf(err, "failed to release advisory lock %d", key)
+	}
+	return released, nil
+}
+
+// AdvisoryXactLock obtains the transaction-level advisory lock of the key (pg_advisory_xact_lock), waits until available.
+//
+// The lock is released automatically when the transaction ends,
+// the executor must be bound to a transaction (eg: sqlb.WrapExecutor(tx)).
+func AdvisoryXactLock(ctx context.Context, exec Executor, key int64) error {
+	if _, err := exec.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", key); err != nil {
+		return errors.Wrapf(err, "failed to obtain advisory lock %d", key)
+	}
+	return nil
+}
+
+// TryAdvisoryXactLock obtains the transaction-level advisory lock of the key (pg_try_advisory_xact_lock) if available,
+// returns false without waiting if not.
+func TryAdvisoryXactLock(ctx context.Context, exec Executor, key int64) (bool, error) {
+	var obtained bool
+	if err := queryScalar(ctx, exec, "SELECT pg_try_advisory_xact_lock($1)", []any{key}, &obtained); err != nil {
+		return false, errors.Wrapf(err, "failed to try advisory lock %d", key)
+	}
+	return obtained, nil
+}
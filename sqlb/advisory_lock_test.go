@@ -0,0 +1,93 @@
+package sqlb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// boolRows is a fake SqlRows returns a single row of a single bool value, for the sake of the test.
+type boolRows struct {
+	value bool
+	read  bool
+}
+
+func (r *boolRows) Next() bool {
+	next := !r.read
+	r.read = true
+	return next
+}
+
+func (r *boolRows) Scan(dest ...any) error {
+	*dest[0].(*bool) = r.value
+	return nil
+}
+
+func (r *boolRows) Close() error {
+	return nil
+}
+
+func (r *boolRows) Err() error {
+	return nil
+}
+
+// lockExecutor records the queries and returns the bool value, for the sake of the test.
+type lockExecutor struct {
+	recordingExecutor
+	value bool
+}
+
+func (e *lockExecutor) QueryContext(_ context.Context, query string, _ ...any) (SqlRows, error) {
+	e.statements = append(e.statements, query)
+	if e.err != nil {
+		return nil, e.err
+	}
+	return &boolRows{value: e.value}, nil
+}
+
+func TestAdvisoryLockKey(t *testing.T) {
+	require.Equal(t, AdvisoryLockKey("job:cleanup"), AdvisoryLockKey("job:cleanup"))
+	require.NotEqual(t, AdvisoryLockKey("job:cleanup"), AdvisoryLockKey("job:report"))
+}
+
+func TestAdvisoryLock(t *testing.T) {
+	ctx := context.Background()
+	exec := &lockExecutor{value: true}
+
+	require.NoError(t, AdvisoryLock(ctx, exec, 1))
+	require.NoError(t, AdvisoryXactLock(ctx, exec, 1))
+
+	obtained, err := TryAdvisoryLock(ctx, exec, 1)
+	require.NoError(t, err)
+	require.True(t, obtained)
+
+	obtained, err = TryAdvisoryXactLock(ctx, exec, 1)
+	require.NoError(t, err)
+	require.True(t, obtained)
+
+	released, err := AdvisoryUnlock(ctx, exec, 1)
+	require.NoError(t, err)
+	require.True(t, released)
+
+	require.Equal(t, []string{
+		"SELECT pg_advisory_lock($1)",
+		"SELECT pg_advisory_xact_lock($1)",
+		"SELECT pg_try_advisory_lock($1)",
+		"SELECT pg_try_advisory_xact_lock($1)",
+		"SELECT pg_advisory_unlock($1)",
+	}, exec.statements)
+
+	exec = &lockExecutor{value: false}
+	obtained, err = TryAdvisoryLock(ctx, exec, 1)
+	require.NoError(t, err)
+	require.False(t, obtained)
+
+	exec = &lockExecutor{recordingExecutor: recordingExecutor{err: errors.New("conn closed")}}
+	require.ErrorContains(t, AdvisoryLock(ctx, exec, 1), "failed to obtain advisory lock 1")
+	_, err = TryAdvisoryLock(ctx, exec, 1)
+	require.ErrorContains(t, err, "failed to try advisory lock 1")
+	_, err = AdvisoryUnlock(ctx, exec, 1)
+	require.ErrorContains(t, err, "failed to release advisory lock 1")
+}
@@ -0,0 +1,106 @@
+package sqlb
+
+import (
+	"fmt"
+)
+
+// Aggregate is an aggregate function, selected via SelectAggregates or used as a token in HAVING.
+type Aggregate struct {
+	expr   Expr
+	filter []any // filter is the tokens of FILTER (WHERE ...)
+	alias  string
+}
+
+// CountAll generates 'COUNT(*)'.
+func CountAll() Aggregate {
+	return Aggregate{expr: concatExpr("COUNT(*)")}
+}
+
+// Count generates 'COUNT([expr])'.
+func Count(expr any) Aggregate {
+	return newAggregate("COUNT", expr)
+}
+
+// CountDistinct generates 'COUNT(DISTINCT [expr])'.
+func CountDistinct(expr any) Aggregate {
+	return Aggregate{expr: concatExpr("COUNT(DISTINCT ", expr, ")")}
+}
+
+// Sum generates 'SUM([expr])'.
+func Sum(expr any) Aggregate {
+	return newAggregate("SUM", expr)
+}
+
+// Avg generates 'AVG([expr])'.
+func Avg(expr any) Aggregate {
+	return newAggregate("AVG", expr)
+}
+
+// Min generates 'MIN([expr])'.
+func Min(expr any) Aggregate {
+	return newAggregate("MIN", expr)
+}
+
+// Max generates 'MAX([expr])'.
+func Max(expr any) Aggregate {
+	return newAggregate("MAX", expr)
+}
+
+func newAggregate(function string, expr any) Aggregate {
+	return Aggregate{expr: functionExpr(function, expr)}
+}
+
+// As sets the alias of the selected aggregate, the scanned value is read by ScannedRows.GetAggregate.
+func (a Aggregate) As(alias string) Aggregate {
+	if alias == "" {
+		panic("alias cannot be empty")
+	}
+	a.alias = alias
+	return a
+}
+
+// Filter adds 'FILTER (WHERE [tokens])' to the aggregate, only the matching rows are aggregated,
+// eg: CountAll().Filter(Eq(col, "active")) generates 'COUNT(*) FILTER (WHERE [col] = $n)'.
+//
+// Tokens are the same as WHERE tokens, values bound via predicates or Arg are collected into the statement's args.
+func (a Aggregate) Filter(tokens ...any) Aggregate {
+	if len(tokens) == 0 {
+		panic("FILTER must have at least one token")
+	}
+	a.filter = tokens
+	return a
+}
+
+// SelectAggregates adds the aggregates to the SELECT statement, after the columns.
+func (b *SqlBuilder) SelectAggregates(aggregates ...Aggregate) *SqlBuilder {
+	b.mustTypeSelect()
+	b.mustBasicSelect()
+	b.mustPreviousAction(previousIsSelect)
+
+	for _, aggregate := range aggregates {
+		if aggregate.alias == "" {
+			panic("selected aggregate must have alias")
+		}
+		for _, selected := range b.selectAggregates {
+			if selected.alias == aggregate.alias {
+				panic(fmt.Sprintf("duplicated aggregate alias %s", aggregate.alias))
+			}
+		}
+		b.selectAggregates = append(b.selectAggregates, aggregate)
+	}
+	return b
+}
+
+// writeAggregate writes the aggregate without the alias.
+func (c *buildContext) writeAggregate(aggregate Aggregate, clause string) {
+	c.writeToken(aggregate.expr, clause)
+
+	if len(aggregate.filter) > 0 {
+		if c.dialect.isMySQL() || c.dialect == DialectSQLServer {
+			panic(ErrUnsupportedByDialect{Feature: "FILTER", Dialect: c.dialect})
+		}
+		c.writeString(" FILTER (WHERE")
+		c.writeTokens(aggregate.filter, "FILTER")
+		c.writeString(")")
+	}
+}
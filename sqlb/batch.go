@@ -0,0 +1,95 @@
+package sqlb
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+)
+
+// BatchStatement is a statement of the batch, with its own arguments.
+type BatchStatement struct {
+	Sql  string
+	Args []any
+}
+
+// Batch accumulates multiple builders, to be executed in a single round trip,
+// either as a multi-statement script (Build) or via the batch API of the driver (Statements), eg: pgx.Batch.
+type Batch struct {
+	builders   []*SqlBuilder
+	terminator bool
+}
+
+// NewBatch creates a batch of the builders.
+func NewBatch(builders ...*SqlBuilder) *Batch {
+	return (&Batch{}).Add(builders...)
+}
+
+// Add appends the builders to the batch, all builders must use the same dialect.
+func (b *Batch) Add(builders ...*SqlBuilder) *Batch {
+	for _, builder := range builders {
+		if builder == nil {
+			panic("builder is nil")
+		}
+		if len(b.builders) > 0 && builder.dialect != b.builders[0].dialect {
+			panic("all builders must use the same dialect")
+		}
+		b.builders = append(b.builders, builder)
+	}
+	return b
+}
+
+// WithTerminator makes the script rendered by Build terminated by a semicolon.
+func (b *Batch) WithTerminator() *Batch {
+	b.terminator = true
+	return b
+}
+
+// Len returns number of statements in the batch.
+func (b *Batch) Len() int {
+	return len(b.builders)
+}
+
+// Statements builds each statement of the batch with its own arguments, for drivers supporting batches.
+func (b *Batch) Statements() []BatchStatement {
+	statements := make([]BatchStatement, len(b.builders))
+	for i, builder := range b.builders {
+		statements[i].Sql, statements[i].Args = builder.Build()
+	}
+	return statements
+}
+
+// Build renders the batch as a single multi-statement script separated by semicolons.
+// The placeholders are renumbered so the arguments of all statements are concatenated, in order.
+//
+// Note: the driver must support multi-statement queries with arguments,
+// PostgreSQL does not support that via the extended protocol, use Statements instead.
+func (b *Batch) Build() (sql string, args []any) {
+	if len(b.builders) == 0 {
+		panic("batch is empty")
+	}
+
+	sb := strings.Builder{}
+	for i, statement := range b.Statements() {
+		if i > 0 {
+			sb.WriteString(";\n")
+		}
+
+		offset := len(args)
+		dialect := b.builders[i].dialect
+		sb.WriteString(renamePlaceholders(statement.Sql, dialect, len(statement.Args), func(n int) string {
+			return dialect.placeholder(offset + n)
+		}))
+		args = append(args, statement.Args...)
+	}
+	if b.terminator {
+		sb.WriteString(";")
+	}
+
+	return sb.String(), args
+}
+
+// ExecWithExecutor executes the batch as a single multi-statement script, see Build.
+func (b *Batch) ExecWithExecutor(ctx context.Context, exec Executor) (sql.Result, error) {
+	stmt, args := b.Build()
+	return exec.ExecContext(ctx, stmt, args...)
+}
@@ -0,0 +1,72 @@
+package sqlb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatch(t *testing.T) {
+	newBatch := func(dialect Dialect) *Batch {
+		table1 := UseTable[testStruct1]().Seal()
+		table2 := UseTable[testStruct2]().Seal()
+		return NewBatch(
+			InsertInto(table1, table1.Col("pk1"), table1.Col("pk2")).
+				Values(testStruct1{Pk1: "a", Pk2: 1}).
+				WithDialect(dialect),
+		).Add(
+			InsertInto(table2, table2.Col("pk1"), table2.Col("pk2"), table2.Col("pk3")).
+				Values(testStruct2{Pk1: "b", Pk2: 2, Pk3: 3}).
+				WithDialect(dialect),
+		)
+	}
+
+	t.Run("statements", func(t *testing.T) {
+		batch := newBatch(DialectPostgres)
+		require.Equal(t, 2, batch.Len())
+		require.Equal(t, []BatchStatement{
+			{
+				Sql:  "INSERT INTO table1 (pk1, pk2)\nVALUES ($1,$2)",
+				Args: []any{"a", 1},
+			},
+			{
+				Sql:  "INSERT INTO table2 (pk1, pk2, pk3)\nVALUES ($1,$2,$3)",
+				Args: []any{"b", 2, int64(3)},
+			},
+		}, batch.Statements())
+	})
+
+	t.Run("script", func(t *testing.T) {
+		gotSql, gotArgs := newBatch(DialectPostgres).WithTerminator().Build()
+		require.Equal(t, `INSERT INTO table1 (pk1, pk2)
+VALUES ($1,$2);
+INSERT INTO table2 (pk1, pk2, pk3)
+VALUES ($3,$4,$5);`, gotSql)
+		require.Equal(t, []any{"a", 1, "b", 2, int64(3)}, gotArgs)
+
+		gotSql, _ = newBatch(DialectMySQL).Build()
+		require.Equal(t, `INSERT INTO table1 (pk1, pk2)
+VALUES (?,?);
+INSERT INTO table2 (pk1, pk2, pk3)
+VALUES (?,?,?)`, gotSql)
+	})
+
+	t.Run("exec", func(t *testing.T) {
+		exec := &recordingExecutor{}
+		_, err := newBatch(DialectPostgres).ExecWithExecutor(context.Background(), exec)
+		require.NoError(t, err)
+		require.Len(t, exec.statements, 1)
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		require.Panics(t, func() {
+			NewBatch().Build()
+		}, "empty batch")
+
+		table1 := UseTable[testStruct1]().Seal()
+		require.Panics(t, func() {
+			newBatch(DialectPostgres).Add(InsertInto(table1).Values(testStruct1{}).WithDialect(DialectMySQL))
+		}, "mixed dialects")
+	})
+}
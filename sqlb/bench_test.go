@@ -0,0 +1,165 @@
+package sqlb
+
+import (
+	"context"
+	"io"
+	"testing"
+)
+
+func BenchmarkBuild_selectWhere(b *testing.B) {
+	table1 := UseTable[testStruct1]().Alias("t1").Seal()
+	table2 := UseTable[testStruct2]().Alias("t2").Seal()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = Select(table1.Col("pk1"), table1.Col("amount"), table2.Col("pk3")).
+			From(table1).
+			Join(InnerJoin, table2, table1.Col("pk1"), table2.Col("pk1")).
+			Where(table1.Col("pk2"), "= $1").
+			And(table1.Col("amount"), ">", 10).
+			And(Eq(table2.Col("pk3"), "c")).
+			Or(table1.Col("cost"), "IS NULL").
+			Args("b").
+			OrderBy(table1.Col("amount"), DESC).
+			Limit(20).
+			Build()
+	}
+}
+
+func BenchmarkBuild_insertOnConflict(b *testing.B) {
+	table1 := UseTable[testStruct1]().Seal()
+	row := testStruct1{Pk1: "a", Pk2: 2, Amount: 1}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = InsertInto(table1, table1.Col("pk1"), table1.Col("pk2"), table1.Col("amount")).
+			Values(row).
+			OnConflict(table1.Col("pk1"), table1.Col("pk2")).
+			DoUpdate(table1.Col("amount"), "=", table1.Col("amount"), "+", 1).
+			Where(table1.Col("amount"), "<", 100).
+			Build()
+	}
+}
+
+func BenchmarkBuild_selectManyConditions(b *testing.B) {
+	table1 := UseTable[testStruct1]().Alias("t1").Seal()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		builder := Select(table1.Columns()...).
+			From(table1).
+			Where(table1.Col("pk1"), "IS NOT NULL")
+		for j := 0; j < 8; j++ {
+			builder.And(Gt(table1.Col("amount"), j))
+		}
+		_, _ = builder.Build()
+	}
+}
+
+func BenchmarkCompiledQuery_bind(b *testing.B) {
+	table1 := UseTable[testStruct1]().Alias("t1").Seal()
+	compiled, err := Select(table1.Col("pk1"), table1.Col("amount")).
+		From(table1).
+		Where(Eq(table1.Col("pk1"), Slot("pk1"))).
+		And(Gt(table1.Col("amount"), Slot("min"))).
+		Compile()
+	if err != nil {
+		b.Fatal(err)
+	}
+	args := map[string]any{"pk1": "a", "min": 10}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = compiled.Bind(args)
+	}
+}
+
+func BenchmarkBuild_insertManyRows(b *testing.B) {
+	table1 := UseTable[testStruct1]().Seal()
+	rows := make([]any, 5000)
+	for i := range rows {
+		rows[i] = testStruct1{Pk1: "a", Pk2: i, Amount: i * 10}
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = InsertInto(table1).Values(rows...).Build()
+	}
+}
+
+func BenchmarkBuildTo_insertManyRows(b *testing.B) {
+	table1 := UseTable[testStruct1]().Seal()
+	rows := make([]any, 5000)
+	for i := range rows {
+		rows[i] = testStruct1{Pk1: "a", Pk2: i, Amount: i * 10}
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = InsertInto(table1).Values(rows...).BuildTo(io.Discard)
+	}
+}
+
+func BenchmarkSqlBuilder_ScanRows(b *testing.B) {
+	table1 := UseTable[testStruct1]().Alias("t1").Seal()
+	table2 := UseTable[testStruct2]().Alias("t2").Seal()
+	builder := Select(table1.Col("pk1"), table1.Col("pk2"), table1.Col("amount"), table2.Col("pk3")).
+		From(table1).
+		Join(InnerJoin, table2, table1.Col("pk1"), table2.Col("pk1"))
+
+	values := make([][]any, 100_000)
+	for i := range values {
+		values[i] = []any{"a", i, i * 10, int64(i)}
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := builder.ScanRows(&valuesRows{rows: values}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSqlBuilder_ScanAll(b *testing.B) {
+	table1 := UseTable[testStruct1]().Alias("t1").Seal()
+	builder := Select(table1.Col("pk1"), table1.Col("pk2"), table1.Col("amount")).From(table1)
+
+	values := make([][]any, 100_000)
+	for i := range values {
+		values[i] = []any{"a", i, i * 10}
+	}
+	exec := &fixedRowsExecutor{rows: values}
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var dest []testStruct1
+		if err := builder.ScanAll(ctx, exec, &dest); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSqlBuilder_ScanRowsSingleTable(b *testing.B) {
+	table1 := UseTable[testStruct1]().Alias("t1").Seal()
+	builder := Select(table1.Col("pk1"), table1.Col("pk2"), table1.Col("amount")).From(table1)
+
+	values := make([][]any, 100_000)
+	for i := range values {
+		values[i] = []any{"a", i, i * 10}
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rows, err := builder.ScanRows(&valuesRows{rows: values})
+		if err != nil {
+			b.Fatal(err)
+		}
+		_ = table1.ReadAllFromRows(rows)
+	}
+}
@@ -0,0 +1,174 @@
+package sqlb
+
+import (
+	"fmt"
+	"strings"
+)
+
+// columnStyle controls how a column is rendered in the statement.
+type columnStyle uint8
+
+const (
+	columnStyleWithAlias     columnStyle = iota // [alias].[column]
+	columnStyleNameOnly                         // [column]
+	columnStyleWithTableName                    // [table].[column]
+)
+
+// stringWriter is the output of the statement, implemented by *strings.Builder and *bufio.Writer.
+type stringWriter interface {
+	WriteString(s string) (int, error)
+}
+
+// buildContext holds the state while rendering a statement: the output and the collected arguments.
+type buildContext struct {
+	sb          stringWriter
+	args        []any
+	columnStyle columnStyle
+	dialect     Dialect
+	// omitRedundantAlias omits 'AS [alias]' of the table references when the alias equals the table name
+	omitRedundantAlias bool
+	// markBound renders the placeholders of the bound args as boundArgMarker, see orderPositionalArgs
+	markBound bool
+	// autoAliases are the aliases chosen by the builder for the tables of UseTableAutoAlias, see SqlBuilder.aliasOf
+	autoAliases map[int64]string
+	// outer is the context of the statement embedding the subquery, resolves the aliases of the correlated tables
+	outer *buildContext
+}
+
+func newBuildContext(sb stringWriter, args []any, dialect Dialect) *buildContext {
+	return &buildContext{
+		sb:      sb,
+		args:    args,
+		dialect: dialect,
+	}
+}
+
+func (c *buildContext) writeString(s string) {
+	c.sb.WriteString(s)
+}
+
+// addArg collects the argument and returns the placeholder allocated for it.
+func (c *buildContext) addArg(value any) string {
+	c.args = append(c.args, value)
+	return c.placeholder(len(c.args))
+}
+
+// placeholder returns the placeholder of the n-th argument collected by the context.
+func (c *buildContext) placeholder(n int) string {
+	if c.markBound {
+		return boundArgMarker
+	}
+	return c.dialect.placeholder(n)
+}
+
+// tableAlias returns the alias of the table chosen by the builder of the statement or the outer statements.
+func (c *buildContext) tableAlias(table GenericTableToUse) string {
+	for ctx := c; ctx != nil; ctx = ctx.outer {
+		if alias, found := ctx.autoAliases[table.uniqueIdentity()]; found {
+			return alias
+		}
+	}
+	return table.tableAlias()
+}
+
+func (c *buildContext) writeColumn(column GenericColumnToUse) {
+	switch c.columnStyle {
+	case columnStyleNameOnly:
+		c.writeString(column.name)
+	case columnStyleWithTableName:
+		c.writeString(column.table.tableName())
+		c.writeString(".")
+		c.writeString(column.name)
+	default:
+		c.writeString(c.tableAlias(column.table))
+		c.writeString(".")
+		c.writeString(column.name)
+	}
+}
+
+// writeTableReference writes '[table] AS [alias]' of the table used in FROM and JOIN.
+func (c *buildContext) writeTableReference(table GenericTableToUse) {
+	if v, ok := table.(*ValuesRelation); ok {
+		c.writeValuesRelation(v)
+		return
+	}
+	c.writeString(table.tableName())
+	alias := c.tableAlias(table)
+	if c.omitRedundantAlias && alias == table.tableName() {
+		return
+	}
+	c.writeString(" AS ")
+	c.writeString(alias)
+}
+
+// writeTokens writes the user provided tokens, each token is prefixed by a space.
+// The clause is used in the panic message when an unsupported token is provided.
+func (c *buildContext) writeTokens(tokens []any, clause string) {
+	for _, token := range tokens {
+		c.writeString(" ")
+		switch t := token.(type) {
+		case string:
+			c.writeString(strings.TrimSpace(t))
+		default:
+			c.writeToken(t, clause)
+		}
+	}
+}
+
+// writeArenaTokens writes the tokens of the list stored in the arena, see writeTokens.
+func (c *buildContext) writeArenaTokens(arena *tokenArena, list tokenList, clause string) {
+	c.writeTokens(arena.list(list), clause)
+}
+
+// writeToken writes a single token as is.
+func (c *buildContext) writeToken(token any, clause string) {
+	switch t := token.(type) {
+	case string:
+		c.writeString(t)
+	case GenericColumnToUse:
+		c.writeColumn(t)
+	case Expr:
+		for _, et := range t.tokens {
+			c.writeToken(et, clause)
+		}
+	case Aggregate:
+		c.writeAggregate(t, clause)
+	case subquery:
+		c.writeSubquery(t.b, clause)
+	case rawSql:
+		c.writeRawSql(t)
+	case boundArg:
+		c.writeString(c.addArg(t.value))
+	case int8, uint8, int16, uint16, int32, uint32, int64, uint64, int, uint:
+		c.writeString(fmt.Sprintf("%d", t))
+	case bool:
+		if t {
+			c.writeString("TRUE")
+		} else {
+			c.writeString("FALSE")
+		}
+	case *SqlBuilder:
+		panic(fmt.Sprintf("unexpected %s token type %T, use Exists or NotExists to embed the subquery", clause, t))
+	case SqlFragment:
+		t.WriteSql(&BuildContext{c: c, clause: clause})
+	case fmt.Stringer: // bound as the value itself (converted by the driver, e.g. driver.Valuer), never inlined, implement SqlFragment to write raw SQL
+		c.writeString(c.addArg(t))
+	default:
+		panic(fmt.Sprintf("unexpected %s token type %T", clause, t))
+	}
+}
+
+// writeCast writes the token cast to the SQL type, '[token]::[type]' for Postgres, 'CAST([token] AS [type])' otherwise.
+func (c *buildContext) writeCast(token any, sqlType string, clause string) {
+	if c.dialect == DialectPostgres {
+		c.writeToken(token, clause)
+		c.writeString("::")
+		c.writeString(sqlType)
+		return
+	}
+	c.writeString("CAST(")
+	c.writeToken(token, clause)
+	c.writeString(" AS ")
+	c.writeString(sqlType)
+	c.writeString(")")
+}
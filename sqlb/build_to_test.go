@@ -0,0 +1,82 @@
+package sqlb
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("broken pipe")
+}
+
+func TestSqlBuilder_BuildTo(t *testing.T) {
+	records := make([]testStruct1, 1000)
+	for i := range records {
+		records[i] = testStruct1{Pk1: "pk", Pk2: i}
+	}
+
+	tests := []struct {
+		name    string
+		builder func() *SqlBuilder
+	}{
+		{
+			name: "streamed insert",
+			builder: func() *SqlBuilder {
+				table1 := UseTable[testStruct1]().Seal()
+				return InsertInto(table1).Values(table1.ValuesToAny(records)...).
+					OnConflict(table1.PrimaryKeyColumns()...).
+					DoUpdateExceptPrimaryKeys()
+			},
+		},
+		{
+			name: "insert requires post-processing",
+			builder: func() *SqlBuilder {
+				table1 := UseTable[testStruct1]().Seal()
+				return InsertInto(table1).Values(table1.ValuesToAny(records)...).WithFormat(FormatPretty)
+			},
+		},
+		{
+			name: "select",
+			builder: func() *SqlBuilder {
+				table1 := UseTable[testStruct1]().Alias("t").Seal()
+				return Select(table1.Columns()...).From(table1).Where(Eq(table1.Col("pk1"), "a")).WithDialect(DialectMySQL)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wantSql, wantArgs := tt.builder().Build()
+
+			var buf bytes.Buffer
+			gotArgs, err := tt.builder().BuildTo(&buf)
+			require.NoError(t, err)
+			require.Equal(t, wantSql, buf.String())
+			require.Equal(t, wantArgs, gotArgs)
+		})
+	}
+
+	t.Run("writes nothing on failure", func(t *testing.T) {
+		table1 := UseTable[testStruct1]().Seal()
+		var buf bytes.Buffer
+		_, err := InsertInto(table1).Values(table1.ValuesToAny(records)...).
+			Returning(table1.Col("pk1")).WithDialect(DialectMySQL).BuildTo(&buf)
+		require.Equal(t, ErrUnsupportedByDialect{Feature: "RETURNING", Dialect: DialectMySQL}, err)
+		require.Zero(t, buf.Len())
+
+		_, err = InsertInto(table1).Values(table1.ValuesToAny(records)...).
+			OnConflict(table1.PrimaryKeyColumns()...).DoNothing().WithDialect(DialectSQLServer).BuildTo(&buf)
+		require.Equal(t, ErrUnsupportedByDialect{Feature: "ON CONFLICT", Dialect: DialectSQLServer, Hint: "use MERGE instead"}, err)
+		require.Zero(t, buf.Len())
+	})
+
+	t.Run("write error", func(t *testing.T) {
+		table1 := UseTable[testStruct1]().Seal()
+		_, err := InsertInto(table1).Values(table1.ValuesToAny(records)...).BuildTo(failingWriter{})
+		require.ErrorContains(t, err, "broken pipe")
+	})
+}
@@ -1,44 +1,73 @@
 package sqlb
 
 import (
+	"bufio"
 	"fmt"
+	"io"
 	"strings"
+	"time"
+
+	"github.com/pkg/errors"
 )
 
 type SqlBuilder struct {
 	//
-	_type                sqlBuilderType
-	previousAction       previousAddedBuilderAction
-	aliasToTableUniqueId map[string]int64 // alias to unique id of the using table, used to validate input
-	tableUniqueIdToAlias map[int64]string // unique id to alias of the using table
+	_type              sqlBuilderType
+	format             SqlFormat
+	dialect            Dialect
+	omitRedundantAlias bool               // omitRedundantAlias omits 'AS [alias]' of the tables aliased by their name, see OmitRedundantAlias
+	timeout            time.Duration      // timeout is the deadline of the execution, zero means no deadline
+	statementTimeout   bool               // statementTimeout indicates SET LOCAL statement_timeout before the execution
+	retryPolicy        *RetryPolicy       // retryPolicy re-executes the statement on transient errors, nil means no retry
+	cache              QueryCache         // cache caches the query results, nil means no caching
+	singleflightGroup  *SingleflightGroup // singleflightGroup collapses the concurrent executions of the same query
+	notifyPayload      string             // notifyPayload is the JSON payload sent to the channel of the table after Exec
+	metricsHook        MetricsHook        // metricsHook observes the executions, nil means no metrics
+	slowQueryThreshold time.Duration      // slowQueryThreshold is the duration of the executions reported to slowQueryHook
+	slowQueryHook      SlowQueryHook      // slowQueryHook receives the slow executions with the plan, nil means no detection
+	transformWorkers   int                // transformWorkers is the number of workers running the transforms of the scanned rows, see ParallelTransform
+	maxRows            int                // maxRows is the limit of the scanned rows, zero means the default limit, see SetDefaultMaxRows
+	caller             string             // caller is the call site constructing the builder, see SetCallerTagging
+	previousAction     previousAddedBuilderAction
+	compiled           bool                         // compiled indicates the builder is frozen by Compile
+	aliasToTable       map[string]GenericTableToUse // alias to the using table, used to validate input
+	autoAliases        map[int64]string             // autoAliases are the aliases chosen for the tables of UseTableAutoAlias, by unique identity
+	aliasConflict      error                        // aliasConflict is the first alias used by multiple tables, reported at Build time
+	tokens             tokenArena                   // tokens stores the columns and values of the clause tokens
 	// special fields for type select
-	selectType      selectType
-	selectColumns   []GenericColumnToUse
-	selectFromTable []GenericTableToUse
-	joinsOn         []joinOn
-	whereTokens     []any
-	whereArgs       []any // whereArgs is the arguments for the whereCondition clause
-	orders          []orderBy
-	offset          uint // offset is the number of rows to skip
-	limit           uint // limit is the number of rows to return
+	selectType       selectType
+	selectColumns    []GenericColumnToUse
+	selectFromTable  []GenericTableToUse
+	joinsOn          []joinOn
+	whereTokens      tokenList
+	whereArgs        []any // whereArgs is the arguments for the whereCondition clause
+	groupBy          []groupingElement
+	havingTokens     tokenList
+	selectAggregates []Aggregate // selectAggregates are selected after the columns
+	orders           []orderBy
+	offset           uint // offset is the number of rows to skip
+	limit            uint // limit is the number of rows to return
 	// special fields for type insert
 	insertIntoTable                     GenericTableToUse
 	insertColumns                       []GenericColumnToUse
 	insertValues                        []any
+	insertColumnOverrides               map[string]any // insertColumnOverrides replaces the values of the columns of every row, eg: the parent key of InsertWithChildren
 	insertOnConflictKeys                []GenericColumnToUse
-	insertOnConflictDoUpdateTokens      []any
-	insertOnConflictDoUpdateWhereTokens []any
+	insertOnConflictDoUpdateTokens      tokenList
+	insertOnConflictDoUpdateWhereTokens tokenList
 	insertOnConflictDoNothing           bool
+	insertWhereNotExists                *SqlBuilder // when provided, INSERT ... SELECT ... WHERE NOT EXISTS
+	insertReturningColumns              []GenericColumnToUse
 }
 
 func newSqlBuilder() *SqlBuilder {
 	return &SqlBuilder{
 		//
-		_type:                sqlBuilderTypeSelect,
-		selectType:           notSelectTypeBasic,
-		previousAction:       nonePrevious,
-		aliasToTableUniqueId: make(map[string]int64),
-		tableUniqueIdToAlias: make(map[int64]string),
+		_type:          sqlBuilderTypeSelect,
+		selectType:     notSelectTypeBasic,
+		previousAction: nonePrevious,
+		aliasToTable:   make(map[string]GenericTableToUse),
+		caller:         captureCaller(),
 	}
 }
 
@@ -62,16 +91,21 @@ func Select(selectColumns ...GenericColumnToUse) *SqlBuilder {
 	return b.Select(selectColumns...)
 }
 
+// InsertInto creates the INSERT builder of the columns, all the columns except the generated and read-only ones if not provided.
 func InsertInto[T any](use *TableToUse[T], columns ...GenericColumnToUse) *SqlBuilder {
 	b := newSqlBuilder()
 	b._type = sqlBuilderTypeInsert
 	defer b.setPreviousAction(previousIsInsertInto)
 
+	mustNotMaterializedView(use.metadata)
 	if len(columns) == 0 {
-		for _, c := range GetTableMetadata[T]().Columns() {
-			columns = append(columns, use.Col(c.name))
+		for _, name := range use.metadata.insertableColumnsName() {
+			columns = append(columns, use.Col(name))
 		}
 	}
+	for _, column := range columns {
+		column.mustWritable("inserted")
+	}
 	b.insertColumns = columns
 
 	b.registerUsingTable(use)
@@ -80,23 +114,65 @@ func InsertInto[T any](use *TableToUse[T], columns ...GenericColumnToUse) *SqlBu
 }
 
 // registerUsingTable performs validation and registration of the using table.
+// Alias conflict does not panic, the first conflict is kept and reported at Build time, see TryBuild.
 func (b *SqlBuilder) registerUsingTable(use GenericTableToUse) {
 	use.mustSealed()
-	alias := use.tableAlias()
-	uid := use.uniqueIdentity()
+	alias := b.resolveAutoAlias(use)
 
 	// one alias cannot be used by multiple using tables
-	if byTableUid, found := b.aliasToTableUniqueId[alias]; found && byTableUid != uid {
-		panic(fmt.Sprintf("alias %s already used by table (alias): %s", alias, b.tableUniqueIdToAlias[byTableUid]))
+	if registered, found := b.aliasToTable[alias]; found {
+		if registered.uniqueIdentity() != use.uniqueIdentity() && b.aliasConflict == nil {
+			b.aliasConflict = errors.Wrapf(ErrAliasConflict, "alias %s is used by both table %s and table %s", alias, registered.tableName(), use.tableName())
+		}
+		return
 	}
 
 	// set
-	b.aliasToTableUniqueId[alias] = uid
-	b.tableUniqueIdToAlias[uid] = alias
+	b.aliasToTable[alias] = use
+}
+
+// resolveAutoAlias returns the alias of the table in this builder, the alias of the table of UseTableAutoAlias
+// is chosen when registered for the first time: the table name, suffixed by _2, _3,... if already used.
+func (b *SqlBuilder) resolveAutoAlias(use GenericTableToUse) string {
+	if auto, ok := use.(interface{ autoAliased() bool }); !ok || !auto.autoAliased() {
+		return use.tableAlias()
+	}
+	if alias, found := b.autoAliases[use.uniqueIdentity()]; found {
+		return alias
+	}
+
+	alias := use.tableAlias()
+	for i := 2; ; i++ {
+		if _, taken := b.aliasToTable[alias]; !taken {
+			break
+		}
+		alias = fmt.Sprintf("%s_%d", use.tableAlias(), i)
+	}
+	if b.autoAliases == nil {
+		b.autoAliases = make(map[int64]string)
+	}
+	b.autoAliases[use.uniqueIdentity()] = alias
+	return alias
+}
+
+// aliasOf returns the alias of the table in this builder, see resolveAutoAlias.
+func (b *SqlBuilder) aliasOf(table GenericTableToUse) string {
+	if alias, found := b.autoAliases[table.uniqueIdentity()]; found {
+		return alias
+	}
+	return table.tableAlias()
+}
+
+// nameWithAlias returns '[alias].[column]' of the column, the alias of the table in this builder.
+func (b *SqlBuilder) nameWithAlias(column GenericColumnToUse) string {
+	return b.aliasOf(column.table) + "." + column.name
 }
 
 // mustPreviousAction checks if the previous action is one of the expected actions.
 func (b *SqlBuilder) mustPreviousAction(expected ...previousAddedBuilderAction) {
+	if b.compiled {
+		panic("the builder is compiled, it can not be modified")
+	}
 	var matchAny bool
 	for _, e := range expected {
 		if b.previousAction == e {
@@ -105,15 +181,11 @@ func (b *SqlBuilder) mustPreviousAction(expected ...previousAddedBuilderAction)
 		}
 	}
 	if !matchAny {
-		if len(expected) == 1 {
-			panic(fmt.Sprintf("unexpected previous action %s, expected %s", b.previousAction, expected[0]))
-		} else {
-			var expectedStr []string
-			for _, e := range expected {
-				expectedStr = append(expectedStr, string(e))
-			}
-			panic(fmt.Sprintf("unexpected previous action %s, expected any of [%s]", b.previousAction, strings.Join(expectedStr, ",")))
+		var expectedStr []string
+		for _, e := range expected {
+			expectedStr = append(expectedStr, string(e))
 		}
+		panic(ErrBadClauseOrder{Got: string(b.previousAction), Want: expectedStr})
 	}
 }
 
@@ -183,16 +255,16 @@ func (b *SqlBuilder) Join(joinType JoinType, joinOnTable GenericTableToUse, onKe
 	}
 	defer b.setPreviousAction(previousIsSelectJoin)
 
-	joinOnTableName := joinOnTable.tableName()
-	// loop through each pair
+	joinOnTableUid := joinOnTable.uniqueIdentity()
+	// loop through each pair, tables are compared by identity so the same table can be joined with different aliases
 	for i := 0; i < len(onKeyPairs); i += 2 {
 		leftTable := onKeyPairs[i].table
 		rightTable := onKeyPairs[i+1].table
 
-		if leftTable.tableName() == rightTable.tableName() {
+		if leftTable.uniqueIdentity() == rightTable.uniqueIdentity() {
 			panic(fmt.Sprintf("join on the same table at pair no.%d", i/2+1))
-		} else if leftTable.tableName() != joinOnTableName && rightTable.tableName() != joinOnTableName {
-			panic(fmt.Sprintf("either of the join must be table %s, got %s and %s", joinOnTableName, leftTable.tableName(), rightTable.tableName()))
+		} else if leftTable.uniqueIdentity() != joinOnTableUid && rightTable.uniqueIdentity() != joinOnTableUid {
+			panic(fmt.Sprintf("either of the join must be table %s, got %s and %s", joinOnTable.tableAlias(), leftTable.tableAlias(), rightTable.tableAlias()))
 		}
 
 		b.registerUsingTable(leftTable)
@@ -207,18 +279,55 @@ func (b *SqlBuilder) Join(joinType JoinType, joinOnTable GenericTableToUse, onKe
 	return b
 }
 
+// JoinOn add JOIN...ON clause with the condition provided as tokens, the same as WHERE,
+// e.g. JoinOn(LeftJoin, table2, Eq(table1.Col("id"), table2.Col("ref")), "AND", Gt(table2.Col("created_at"), since)).
+// The bound arguments of the condition are allocated in order with the arguments of the other clauses.
+func (b *SqlBuilder) JoinOn(joinType JoinType, joinOnTable GenericTableToUse, onTokens ...any) *SqlBuilder {
+	b.mustTypeSelect()
+	b.mustPreviousAction(previousIsSelectFrom, previousIsSelectJoin)
+	if len(onTokens) == 0 {
+		panic("JOIN ON must have at least one token")
+	}
+	defer b.setPreviousAction(previousIsSelectJoin)
+
+	b.registerUsingTable(joinOnTable)
+	b.joinsOn = append(b.joinsOn, joinOn{
+		joinType:     joinType,
+		joinOnTable:  joinOnTable,
+		joinOnTokens: b.tokens.append(tokenList{}, onTokens...),
+	})
+	return b
+}
+
+// AndOn continues the ON condition of the last JOIN with AND,
+// e.g. Join(InnerJoin, table2, table1.Col("id"), table2.Col("ref")).AndOn(Gt(table2.Col("created_at"), since)).
+func (b *SqlBuilder) AndOn(onTokens ...any) *SqlBuilder {
+	b.mustTypeSelect()
+	b.mustPreviousAction(previousIsSelectJoin)
+	if len(onTokens) == 0 {
+		panic("AND must have at least one token")
+	}
+
+	last := &b.joinsOn[len(b.joinsOn)-1]
+	if last.joinOnTokens.len() > 0 {
+		last.joinOnTokens = b.tokens.append(last.joinOnTokens, "AND")
+	}
+	last.joinOnTokens = b.tokens.append(last.joinOnTokens, onTokens...)
+	return b
+}
+
 // Where adds the WHERE clause. If having argument on SELECT, need to call Args
 func (b *SqlBuilder) Where(whereTokens ...any) *SqlBuilder {
 	if b._type == sqlBuilderTypeSelect {
 		b.mustPreviousAction(previousIsSelectFrom, previousIsSelectJoin, previousIsSelectWhere)
 		defer b.setPreviousAction(previousIsSelectWhere)
 
-		b.whereTokens = append(b.whereTokens, whereTokens...)
+		b.whereTokens = b.tokens.append(b.whereTokens, whereTokens...)
 	} else if b._type == sqlBuilderTypeInsert {
 		b.mustPreviousAction(previousIsInsertIntoOnConflictDoUpdate)
 		defer b.setPreviousAction(previousIsInsertIntoOnConflictDoUpdateWhere)
 
-		b.insertOnConflictDoUpdateWhereTokens = whereTokens
+		b.insertOnConflictDoUpdateWhereTokens = b.tokens.append(tokenList{}, whereTokens...)
 	} else {
 		panic(fmt.Sprintf("WHERE is not supported for this type %s", b._type))
 	}
@@ -230,25 +339,25 @@ func (b *SqlBuilder) And(whereTokens ...any) *SqlBuilder {
 	if b._type == sqlBuilderTypeSelect {
 		b.mustPreviousAction(previousIsSelectWhere)
 
-		if len(b.whereTokens) == 0 {
+		if b.whereTokens.len() == 0 {
 			panic("AND must be after WHERE")
 		} else if len(whereTokens) == 0 {
 			panic("AND must have at least one token")
 		}
 
-		b.whereTokens = append(b.whereTokens, "AND")
-		b.whereTokens = append(b.whereTokens, whereTokens...)
+		b.whereTokens = b.tokens.append(b.whereTokens, "AND")
+		b.whereTokens = b.tokens.append(b.whereTokens, whereTokens...)
 	} else if b._type == sqlBuilderTypeInsert {
 		b.mustPreviousAction(previousIsInsertIntoOnConflictDoUpdateWhere)
 
-		if len(b.insertOnConflictDoUpdateWhereTokens) == 0 {
+		if b.insertOnConflictDoUpdateWhereTokens.len() == 0 {
 			panic("AND must be after WHERE")
 		} else if len(whereTokens) == 0 {
 			panic("AND must have at least one token")
 		}
 
-		b.insertOnConflictDoUpdateWhereTokens = append(b.insertOnConflictDoUpdateWhereTokens, "AND")
-		b.insertOnConflictDoUpdateWhereTokens = append(b.insertOnConflictDoUpdateWhereTokens, whereTokens...)
+		b.insertOnConflictDoUpdateWhereTokens = b.tokens.append(b.insertOnConflictDoUpdateWhereTokens, "AND")
+		b.insertOnConflictDoUpdateWhereTokens = b.tokens.append(b.insertOnConflictDoUpdateWhereTokens, whereTokens...)
 	} else {
 		panic(fmt.Sprintf("WHERE is not supported for this type %s", b._type))
 	}
@@ -260,25 +369,25 @@ func (b *SqlBuilder) Or(whereTokens ...any) *SqlBuilder {
 	if b._type == sqlBuilderTypeSelect {
 		b.mustPreviousAction(previousIsSelectWhere)
 
-		if len(b.whereTokens) == 0 {
+		if b.whereTokens.len() == 0 {
 			panic("OR must be after WHERE")
 		} else if len(whereTokens) == 0 {
 			panic("OR must have at least one token")
 		}
 
-		b.whereTokens = append(b.whereTokens, "OR")
-		b.whereTokens = append(b.whereTokens, whereTokens...)
+		b.whereTokens = b.tokens.append(b.whereTokens, "OR")
+		b.whereTokens = b.tokens.append(b.whereTokens, whereTokens...)
 	} else if b._type == sqlBuilderTypeInsert {
 		b.mustPreviousAction(previousIsInsertIntoOnConflictDoUpdateWhere)
 
-		if len(b.insertOnConflictDoUpdateWhereTokens) == 0 {
+		if b.insertOnConflictDoUpdateWhereTokens.len() == 0 {
 			panic("OR must be after WHERE")
 		} else if len(whereTokens) == 0 {
 			panic("OR must have at least one token")
 		}
 
-		b.insertOnConflictDoUpdateWhereTokens = append(b.insertOnConflictDoUpdateWhereTokens, "OR")
-		b.insertOnConflictDoUpdateWhereTokens = append(b.insertOnConflictDoUpdateWhereTokens, whereTokens...)
+		b.insertOnConflictDoUpdateWhereTokens = b.tokens.append(b.insertOnConflictDoUpdateWhereTokens, "OR")
+		b.insertOnConflictDoUpdateWhereTokens = b.tokens.append(b.insertOnConflictDoUpdateWhereTokens, whereTokens...)
 	} else {
 		panic(fmt.Sprintf("WHERE is not supported for this type %s", b._type))
 	}
@@ -295,7 +404,7 @@ func (b *SqlBuilder) Args(whereArgs ...any) *SqlBuilder {
 
 func (b *SqlBuilder) AnyWhereTokens() bool {
 	if b._type == sqlBuilderTypeSelect {
-		return len(b.whereTokens) > 0
+		return b.whereTokens.len() > 0
 	} else {
 		panic(fmt.Sprintf("the operation does not support type %s", b._type))
 	}
@@ -305,7 +414,7 @@ func (b *SqlBuilder) AnyWhereTokens() bool {
 func (b *SqlBuilder) OrderBy(column GenericColumnToUse, asc OrderType) *SqlBuilder {
 	b.mustTypeSelect()
 	b.mustBasicSelect()
-	b.mustPreviousAction(previousIsSelectFrom, previousIsSelectJoin, previousIsSelectWhere, previousIsSelectOrderBy)
+	b.mustPreviousAction(previousIsSelectFrom, previousIsSelectJoin, previousIsSelectWhere, previousIsSelectGroupBy, previousIsSelectHaving, previousIsSelectOrderBy)
 	defer b.setPreviousAction(previousIsSelectOrderBy)
 
 	b.orders = append(b.orders, orderBy{
@@ -328,6 +437,79 @@ func (b *SqlBuilder) ThenBy(column GenericColumnToUse, asc OrderType) *SqlBuilde
 	return b
 }
 
+// OrderByExpr starts the ORDER BY clause with an expression, e.g. OrderByExpr(Similarity(col, text), DESC).
+// Arguments bound into the expression are allocated after the ones of WHERE.
+func (b *SqlBuilder) OrderByExpr(expr Expr, asc OrderType) *SqlBuilder {
+	b.mustTypeSelect()
+	b.mustBasicSelect()
+	b.mustPreviousAction(previousIsSelectFrom, previousIsSelectJoin, previousIsSelectWhere, previousIsSelectGroupBy, previousIsSelectHaving, previousIsSelectOrderBy)
+	defer b.setPreviousAction(previousIsSelectOrderBy)
+
+	b.orders = append(b.orders, orderBy{
+		expr: &expr,
+		asc:  bool(asc),
+	})
+	return b
+}
+
+// ThenByExpr continues the ORDER BY clause with another expression.
+func (b *SqlBuilder) ThenByExpr(expr Expr, asc OrderType) *SqlBuilder {
+	b.mustTypeSelect()
+	b.mustBasicSelect()
+	b.mustPreviousAction(previousIsSelectOrderBy)
+
+	b.orders = append(b.orders, orderBy{
+		expr: &expr,
+		asc:  bool(asc),
+	})
+	return b
+}
+
+// OrderByName adds the column resolved by name from the allow-list to the ORDER BY clause,
+// as the first order or continues the existing ones.
+// Used for the user-supplied sort field, returns error instead of panicking when the field is not allowed.
+//
+// The name is either the column name or [alias].[column] to pick from the allowed columns of different tables.
+func (b *SqlBuilder) OrderByName(columnName string, dir OrderType, allowed ...GenericColumnToUse) (*SqlBuilder, error) {
+	column, err := b.resolveColumnByName(columnName, allowed)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(b.orders) == 0 {
+		return b.OrderBy(column, dir), nil
+	}
+	return b.ThenBy(column, dir), nil
+}
+
+// resolveColumnByName finds the column by name, or by [alias].[column], from the allowed columns.
+func (b *SqlBuilder) resolveColumnByName(columnName string, allowed []GenericColumnToUse) (GenericColumnToUse, error) {
+	alias, name := "", columnName
+	if i := strings.LastIndex(columnName, "."); i >= 0 {
+		alias, name = columnName[:i], columnName[i+1:]
+	}
+
+	var matches []GenericColumnToUse
+	for _, column := range allowed {
+		if strings.Trim(column.name, `"`) != name {
+			continue
+		}
+		if alias != "" && b.aliasOf(column.table) != alias {
+			continue
+		}
+		matches = append(matches, column)
+	}
+
+	switch len(matches) {
+	case 0:
+		return GenericColumnToUse{}, ErrUnknownColumn{Column: columnName}
+	case 1:
+		return matches[0], nil
+	default:
+		return GenericColumnToUse{}, errors.Errorf("column %q is ambiguous, qualify it with the table alias", columnName)
+	}
+}
+
 // Pagination adds the OFFSET and LIMIT clauses if the pagination is not nil and the values are greater than 0.
 func (b *SqlBuilder) Pagination(pagination *Pagination) *SqlBuilder {
 	if pagination == nil {
@@ -346,7 +528,7 @@ func (b *SqlBuilder) Pagination(pagination *Pagination) *SqlBuilder {
 func (b *SqlBuilder) Offset(offset uint) *SqlBuilder {
 	b.mustTypeSelect()
 	b.mustBasicSelect()
-	b.mustPreviousAction(previousIsSelectFrom, previousIsSelectJoin, previousIsSelectWhere, previousIsSelectOrderBy, previousIsSelectLimit)
+	b.mustPreviousAction(previousIsSelectFrom, previousIsSelectJoin, previousIsSelectWhere, previousIsSelectGroupBy, previousIsSelectHaving, previousIsSelectOrderBy, previousIsSelectLimit)
 	defer b.setPreviousAction(previousIsSelectOffset)
 
 	b.offset = offset
@@ -356,7 +538,7 @@ func (b *SqlBuilder) Offset(offset uint) *SqlBuilder {
 func (b *SqlBuilder) Limit(limit uint) *SqlBuilder {
 	b.mustTypeSelect()
 	b.mustBasicSelect()
-	b.mustPreviousAction(previousIsSelectFrom, previousIsSelectJoin, previousIsSelectWhere, previousIsSelectOrderBy, previousIsSelectOffset)
+	b.mustPreviousAction(previousIsSelectFrom, previousIsSelectJoin, previousIsSelectWhere, previousIsSelectGroupBy, previousIsSelectHaving, previousIsSelectOrderBy, previousIsSelectOffset)
 	defer b.setPreviousAction(previousIsSelectLimit)
 
 	b.limit = limit
@@ -378,9 +560,17 @@ func (b *SqlBuilder) Values(values ...any) *SqlBuilder {
 	defer b.setPreviousAction(previousIsInsertIntoValues)
 
 	// validation
-	for _, value := range values {
-		if getStructTypeName(value) != b.insertIntoTable.genericTableMeta().typeName() {
-			panic(fmt.Sprintf("value %T is not of type %s", value, b.insertIntoTable.genericTableMeta().typeName()))
+	columnsName := make([]string, len(b.insertColumns))
+	for i, column := range b.insertColumns {
+		columnsName[i] = column.name
+	}
+	meta := b.insertIntoTable.genericTableMeta()
+	for i, value := range values {
+		if getStructTypeName(value) != meta.typeName() {
+			panic(fmt.Sprintf("value %T is not of type %s", value, meta.typeName()))
+		}
+		if err := meta.validateColumns(value, columnsName...); err != nil {
+			panic(fmt.Sprintf("invalid value at index %d: %v", i, err))
 		}
 	}
 
@@ -389,6 +579,35 @@ func (b *SqlBuilder) Values(values ...any) *SqlBuilder {
 	return b
 }
 
+// WhereNotExists turns the statement into 'INSERT INTO [table] (...) SELECT $1, $2 WHERE NOT EXISTS (SELECT 1 FROM ...)',
+// the row is only inserted when the SELECT EXISTS subquery finds nothing.
+// Used instead of ON CONFLICT when there is no unique constraint to rely on, only one row is supported.
+//
+// Arguments of the subquery are re-numbered to follow the inserted values.
+// The values are cast to the SQL types of the columns, e.g. 'SELECT $1::BIGINT', since the database can not infer
+// the types of the placeholders of the SELECT list, the inserted columns must declare the SQL type (see SqlType).
+// MySQL does not cast the values, its CAST does not accept the types of the columns.
+func (b *SqlBuilder) WhereNotExists(sub *SqlBuilder) *SqlBuilder {
+	b.mustTypeInsert()
+	b.mustPreviousAction(previousIsInsertIntoValues)
+	defer b.setPreviousAction(previousIsInsertIntoWhereNotExists)
+
+	// validation
+	mustExistsSubquery(sub)
+	if len(b.insertValues) != 1 {
+		panic(fmt.Sprintf("INSERT WHERE NOT EXISTS requires exactly one row, got %d", len(b.insertValues)))
+	}
+	for _, column := range b.insertColumns {
+		if column.sqlType == "" {
+			panic(fmt.Sprintf("column %s has no SQL type, required to cast the value of INSERT WHERE NOT EXISTS", column.name))
+		}
+	}
+
+	// set
+	b.insertWhereNotExists = sub
+	return b
+}
+
 // OnConflict adds the ON CONFLICT clause with the columns to be checked.
 func (b *SqlBuilder) OnConflict(columns ...GenericColumnToUse) *SqlBuilder {
 	b.mustTypeInsert()
@@ -404,6 +623,9 @@ func (b *SqlBuilder) OnConflict(columns ...GenericColumnToUse) *SqlBuilder {
 			panic(fmt.Sprintf("column %s is not from table %s", column.name, b.insertIntoTable.tableName()))
 		}
 	}
+	if len(columns) > 0 {
+		mustConflictTargetUnique(b.insertIntoTable.genericTableMeta().schema(), columns)
+	}
 
 	// set
 	b.insertOnConflictKeys = columns
@@ -411,6 +633,11 @@ func (b *SqlBuilder) OnConflict(columns ...GenericColumnToUse) *SqlBuilder {
 }
 
 // DoUpdate adds the ON CONFLICT UPDATE clause.
+// The column of the first token is the target of the assignment, it must not be generated or read-only.
+//
+// The columns are rendered as [column], use NameWithTableName to refer the current value of the row unambiguously.
+// Arguments bound via Arg or predicates are numbered after the VALUES parameters,
+// e.g. DoUpdate(col, "=", col.NameWithTableName(), "+", Arg(1)) generates 'amount = table.amount + $n'.
 func (b *SqlBuilder) DoUpdate(tokens ...any) *SqlBuilder {
 	b.mustTypeInsert()
 	b.mustPreviousAction(previousIsInsertIntoOnConflict, previousIsInsertIntoOnConflictDoUpdate)
@@ -419,20 +646,32 @@ func (b *SqlBuilder) DoUpdate(tokens ...any) *SqlBuilder {
 	if len(b.insertOnConflictKeys) < 1 {
 		panic("ON CONFLICT keys not added")
 	}
-	if len(b.insertOnConflictDoUpdateTokens) > 0 {
-		b.insertOnConflictDoUpdateTokens = append(b.insertOnConflictDoUpdateTokens, ",\n")
+	if len(tokens) > 0 {
+		if target, ok := tokens[0].(GenericColumnToUse); ok {
+			target.mustWritable("updated")
+		}
+	}
+	if b.insertOnConflictDoUpdateTokens.len() > 0 {
+		b.insertOnConflictDoUpdateTokens = b.tokens.append(b.insertOnConflictDoUpdateTokens, ",\n")
 	}
-	b.insertOnConflictDoUpdateTokens = append(b.insertOnConflictDoUpdateTokens, tokens...)
+	b.insertOnConflictDoUpdateTokens = b.tokens.append(b.insertOnConflictDoUpdateTokens, tokens...)
 	return b
 }
 
-// DoUpdateExceptPrimaryKeys adds the ON CONFLICT UPDATE clause to excluded, except the primary keys.
+// DoUpdateSet adds the assignment '[column] = $n' to the ON CONFLICT UPDATE clause,
+// the value is bound as an argument unless it is a column or an expression,
+// e.g. DoUpdateSet(lastSeenAt, Now()) only bumps the column instead of taking the inserted value.
+func (b *SqlBuilder) DoUpdateSet(column GenericColumnToUse, value any) *SqlBuilder {
+	return b.DoUpdate(column, "=", valueToken(value))
+}
+
+// DoUpdateExceptPrimaryKeys adds the ON CONFLICT UPDATE clause to excluded, except the primary keys, the generated and read-only columns.
 func (b *SqlBuilder) DoUpdateExceptPrimaryKeys() *SqlBuilder {
 	b.mustTypeInsert()
 
 	var tokens []any
 	for _, column := range b.insertIntoTable.allColumns() {
-		if column.isPk {
+		if column.isPk || column.isGenerated || column.isReadOnly {
 			continue
 		}
 		if len(tokens) > 0 {
@@ -454,24 +693,261 @@ func (b *SqlBuilder) DoNothing() *SqlBuilder {
 	return b
 }
 
+// Returning adds the RETURNING clause with the columns of the inserted rows.
+func (b *SqlBuilder) Returning(columns ...GenericColumnToUse) *SqlBuilder {
+	b.mustTypeInsert()
+	b.mustPreviousAction(
+		previousIsInsertIntoValues,
+		previousIsInsertIntoWhereNotExists,
+		previousIsInsertIntoOnConflictDoUpdate,
+		previousIsInsertIntoOnConflictDoUpdateWhere,
+		previousIsInsertIntoOnConflictDoNoThing,
+	)
+	defer b.setPreviousAction(previousIsInsertIntoReturning)
+
+	// validation
+	if len(columns) == 0 {
+		panic("no columns to return")
+	}
+	for _, column := range columns {
+		if column.table.tableName() != b.insertIntoTable.tableName() {
+			panic(fmt.Sprintf("column %s is not from table %s", column.name, b.insertIntoTable.tableName()))
+		}
+	}
+
+	// set
+	b.insertReturningColumns = columns
+	return b
+}
+
 // Build
 
+// Build renders the statement and the arguments, panics if the builder is invalid, e.g. alias conflict,
+// or the statement has slots, which are bound by the compiled query only, see Compile.
 func (b *SqlBuilder) Build() (sql string, args []any) {
+	sql, args = b.build()
+	if err := unboundSlots(args); err != nil {
+		panic(err)
+	}
+	return sql, args
+}
+
+// build renders the statement and the arguments, the slots are kept in the arguments.
+func (b *SqlBuilder) build() (sql string, args []any) {
+	if err := b.validate(); err != nil {
+		panic(err)
+	}
+
 	switch b._type {
 	case sqlBuilderTypeSelect:
-		return b.buildSelect()
+		sql, args = b.buildSelect()
 	case sqlBuilderTypeInsert:
-		return b.buildInsert()
+		sql, args = b.buildInsert()
 	default:
 		panic(fmt.Sprintf("unknown builder type: %s", b._type))
 	}
+	return b.tagCaller(sql), args
+}
+
+// TryBuild is the same as Build, but returns the error instead of panic when the builder is invalid, e.g. alias conflict,
+// useful when the query is assembled dynamically. The failures are typed, e.g. ErrAliasConflict, ErrNoColumns.
+func (b *SqlBuilder) TryBuild() (sql string, args []any, err error) {
+	return b.tryBuild(b.Build)
+}
+
+// tryBuild builds the statement via build, returns the typed failures instead of panic.
+func (b *SqlBuilder) tryBuild(build func() (string, []any)) (sql string, args []any, err error) {
+	if err = b.validate(); err != nil {
+		return "", nil, err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			e, ok := asBuildFailure(r) // typed failures, other panics are programming errors
+			if !ok {
+				panic(r)
+			}
+			sql, args, err = "", nil, e
+		}
+	}()
+
+	sql, args = build()
+	return sql, args, nil
+}
+
+// TryAssemble assembles the builder via fn, but returns the error instead of panic when the assembling failed,
+// useful when the query is assembled dynamically, e.g. from the request. The failures are typed same as TryBuild,
+// e.g. ErrBadClauseOrder of the clauses added in unexpected order, ErrUnknownColumn of the columns resolved by name.
+func TryAssemble(fn func() *SqlBuilder) (b *SqlBuilder, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			e, ok := asBuildFailure(r)
+			if !ok {
+				panic(r)
+			}
+			b, err = nil, e
+		}
+	}()
+
+	return fn(), nil
+}
+
+// asBuildFailure returns the error if the recovered value is a typed failure of building the statement.
+func asBuildFailure(r any) (error, bool) {
+	err, ok := r.(error)
+	if !ok {
+		return nil, false
+	}
+	switch errors.Cause(err).(type) {
+	case ErrBadClauseOrder, ErrUnknownColumn, ErrUnsupportedByDialect:
+		return err, true
+	}
+	for _, target := range []error{ErrNoColumns, ErrNoValues, ErrAliasConflict, ErrColumnCollision, ErrPositionalArgs, ErrUnboundSlot} {
+		if errors.Is(err, target) {
+			return err, true
+		}
+	}
+	return nil, false
+}
+
+// validate returns the error detected while assembling the builder.
+func (b *SqlBuilder) validate() error {
+	if b.aliasConflict != nil {
+		return b.aliasConflict
+	}
+	if b._type == sqlBuilderTypeInsert {
+		return b.validateInsert()
+	}
+	if err := b.groupingSupported(b.dialect); err != nil {
+		return err
+	}
+	return b.validateSelectColumns()
+}
+
+// validateInsert returns the error of the INSERT statement can not be built, checked before writing anything.
+func (b *SqlBuilder) validateInsert() error {
+	if len(b.insertColumns) == 0 {
+		return errors.Wrap(ErrNoColumns, "failed to build INSERT")
+	}
+	if len(b.insertValues) == 0 {
+		return ErrNoValues
+	}
+	switch {
+	case b.dialect == DialectSQLServer && (b.insertOnConflictDoNothing || len(b.insertOnConflictKeys) > 0):
+		return ErrUnsupportedByDialect{Feature: "ON CONFLICT", Dialect: b.dialect, Hint: "use MERGE instead"}
+	case b.dialect.isMySQL() && !b.insertOnConflictDoNothing && len(b.insertOnConflictKeys) > 0 && b.insertOnConflictDoUpdateWhereTokens.len() > 0:
+		return ErrUnsupportedByDialect{Feature: "ON CONFLICT DO UPDATE WHERE", Dialect: b.dialect}
+	case b.dialect.isMySQL() && len(b.insertReturningColumns) > 0:
+		return ErrUnsupportedByDialect{Feature: "RETURNING", Dialect: b.dialect}
+	}
+	return nil
+}
+
+// validateSelectColumns returns the error of a column selected more than once for the same alias.
+func (b *SqlBuilder) validateSelectColumns() error {
+	for i, column := range b.selectColumns {
+		alias := b.aliasOf(column.table)
+		for _, previous := range b.selectColumns[:i] {
+			if previous.name == column.name && b.aliasOf(previous.table) == alias {
+				return errors.Wrapf(ErrColumnCollision, "column %s is selected more than once", b.nameWithAlias(column))
+			}
+		}
+	}
+	return nil
+}
+
+// BuildTo builds the statement same as Build, but writes the statement into the writer.
+//
+// INSERT statements in default format are streamed into the writer without allocating the whole statement,
+// useful for bulk inserts with thousands of rows. The error is returned when the builder is invalid or writing failed,
+// the failures are typed same as TryBuild and nothing is written into the writer on failure.
+func (b *SqlBuilder) BuildTo(w io.Writer) (args []any, err error) {
+	if err := b.validate(); err != nil {
+		return nil, err
+	}
+
+	var stmt string
+	_, _, convertQuotes := b.dialect.identifierQuotes()
+	if b._type == sqlBuilderTypeInsert && b.format == FormatDefault && !convertQuotes { // no post-processing needed
+		// dry run, the statement is discarded to detect the failures before writing anything
+		args, err := b.tryWriteInsert(io.Discard.(stringWriter))
+		if err == nil {
+			err = unboundSlots(args)
+		}
+		if err != nil {
+			return nil, err
+		}
+	} else if stmt, args, err = b.TryBuild(); err != nil {
+		return nil, err
+	}
+
+	bw := bufio.NewWriter(w)
+	if stmt == "" {
+		_, _ = bw.WriteString(b.tagCaller(""))
+		args = b.writeInsert(bw)
+	} else {
+		_, _ = bw.WriteString(stmt) // error is sticky, returned by Flush
+	}
+
+	if err := bw.Flush(); err != nil {
+		return nil, errors.Wrap(err, "failed to write statement")
+	}
+	return args, nil
+}
+
+// tryWriteInsert is the same as writeInsert, but returns the typed failures instead of panic, see TryBuild.
+func (b *SqlBuilder) tryWriteInsert(sb stringWriter) (args []any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			e, ok := asBuildFailure(r)
+			if !ok {
+				panic(r)
+			}
+			args, err = nil, e
+		}
+	}()
+
+	return b.writeInsert(sb), nil
 }
 
 func (b *SqlBuilder) buildSelect() (sql string, args []any) {
-	if len(b.selectColumns) == 0 {
+	sb := strings.Builder{}
+	args = b.writeSelect(&sb, nil)
+
+	return b.wrapSelect(b.dialect.quoteIdentifiers(formatSql(sb.String(), b.format))), args
+}
+
+// wrapSelect wraps the statement of the select type: EXISTS of the rows, or COUNT of the groups when grouping.
+// The basic SELECT and the COUNT without grouping are returned as is.
+func (b *SqlBuilder) wrapSelect(stmt string) string {
+	var prefix, suffix string
+	switch {
+	case b.selectType == selectTypeExists && b.dialect == DialectSQLServer: // EXISTS can not be selected directly
+		prefix, suffix = "SELECT CASE WHEN EXISTS(", ") THEN 1 ELSE 0 END"
+	case b.selectType == selectTypeExists:
+		prefix, suffix = "SELECT EXISTS(", ")"
+	case b.selectType == selectTypeCount && len(b.groupBy) > 0: // COUNT(1) with GROUP BY counts rows per group
+		prefix, suffix = "SELECT COUNT(1) FROM (", ") AS grouped"
+	default:
+		return stmt
+	}
+
+	switch b.format {
+	case FormatPretty:
+		return fmt.Sprintf("%s\n%s\n%s", prefix, indentLines(stmt), suffix)
+	default:
+		return prefix + stmt + suffix
+	}
+}
+
+// writeSelect writes the SELECT statement in default layout, without dialect-specific identifier quoting
+// and the wrapper of the select type, see wrapSelect.
+// The outer is the context of the statement embedding the subquery, nil for the statement itself.
+func (b *SqlBuilder) writeSelect(sb *strings.Builder, outer *buildContext) (args []any) {
+	if len(b.selectColumns) == 0 && len(b.selectAggregates) == 0 {
 		switch b.selectType {
 		case selectTypeBasic:
-			panic("no columns selected")
+			panic(ErrNoColumns)
 		case selectTypeExists, selectTypeCount:
 			// valid
 		default:
@@ -482,12 +958,19 @@ func (b *SqlBuilder) buildSelect() (sql string, args []any) {
 		panic("no tables selected")
 	}
 
-	sb := strings.Builder{}
+	ctx := newBuildContext(sb, b.whereArgs[:len(b.whereArgs):len(b.whereArgs)], b.dialect) // auto-allocated args are placed after the provided args
+	ctx.omitRedundantAlias = b.omitRedundantAlias
+	ctx.autoAliases, ctx.outer = b.autoAliases, outer
+	// positional placeholders are bound in order of appearance, the args are re-ordered after writing, see orderPositionalArgs
+	ctx.markBound = b.dialect.positional() && len(b.whereArgs) > 0
+	start := sb.Len()
 
 	// SELECT
 	sb.WriteString("SELECT ")
 	if b.selectType == selectTypeExists {
 		sb.WriteString("1 ")
+	} else if b.selectType == selectTypeCount && len(b.groupBy) > 0 {
+		sb.WriteString("1 ") // the groups are counted by the wrapper
 	} else if b.selectType == selectTypeCount {
 		sb.WriteString("COUNT(1) ")
 	} else {
@@ -495,7 +978,15 @@ func (b *SqlBuilder) buildSelect() (sql string, args []any) {
 			if i > 0 {
 				sb.WriteString(", ")
 			}
-			sb.WriteString(column.nameWithAlias())
+			ctx.writeColumn(column)
+		}
+		for i, aggregate := range b.selectAggregates {
+			if i > 0 || len(b.selectColumns) > 0 {
+				sb.WriteString(", ")
+			}
+			ctx.writeAggregate(aggregate, "SELECT")
+			sb.WriteString(" AS ")
+			sb.WriteString(aggregate.alias)
 		}
 		sb.WriteString("\n")
 	}
@@ -506,9 +997,7 @@ func (b *SqlBuilder) buildSelect() (sql string, args []any) {
 		if i > 0 {
 			sb.WriteString(", ")
 		}
-		sb.WriteString(table.tableName())
-		sb.WriteString(" AS ")
-		sb.WriteString(table.tableAlias())
+		ctx.writeTableReference(table)
 	}
 	sb.WriteString("\n")
 
@@ -522,48 +1011,38 @@ func (b *SqlBuilder) buildSelect() (sql string, args []any) {
 		default:
 			sb.WriteString("INNER JOIN ")
 		}
-		sb.WriteString(joinOn.joinOnTable.tableName())
-		sb.WriteString(" AS ")
-		sb.WriteString(joinOn.joinOnTable.tableAlias())
-		sb.WriteString(" ON ")
+		ctx.writeTableReference(joinOn.joinOnTable)
+		sb.WriteString(" ON")
 		for i := 0; i < len(joinOn.joinOnColumns); i += 2 {
 			if i > 0 {
-				sb.WriteString(" AND ")
+				sb.WriteString(" AND")
 			}
 			left := joinOn.joinOnColumns[i]
 			right := joinOn.joinOnColumns[i+1]
-			sb.WriteString(left.nameWithAlias())
+			sb.WriteString(" ")
+			ctx.writeColumn(left)
 			sb.WriteString(" = ")
-			sb.WriteString(right.nameWithAlias())
+			ctx.writeColumn(right)
+		}
+		if joinOn.joinOnTokens.len() > 0 {
+			if len(joinOn.joinOnColumns) > 0 {
+				sb.WriteString(" AND")
+			}
+			ctx.writeArenaTokens(&b.tokens, joinOn.joinOnTokens, "JOIN ON")
 		}
 		sb.WriteString("\n")
 	}
 
 	// WHERE
-	if len(b.whereTokens) > 0 {
+	if b.whereTokens.len() > 0 {
 		sb.WriteString("WHERE")
-		for _, token := range b.whereTokens {
-			sb.WriteString(" ")
-			switch t := token.(type) {
-			case string:
-				sb.WriteString(strings.TrimSpace(t))
-			case GenericColumnToUse:
-				sb.WriteString(t.nameWithAlias())
-			case int8, uint8, int16, uint16, int32, uint32, int64, uint64, int, uint:
-				sb.WriteString(fmt.Sprintf("%d", t))
-			case bool:
-				if t {
-					sb.WriteString("TRUE")
-				} else {
-					sb.WriteString("FALSE")
-				}
-			default:
-				panic(fmt.Sprintf("unexpected WHERE token type %T", t))
-			}
-		}
+		ctx.writeArenaTokens(&b.tokens, b.whereTokens, "WHERE")
 		sb.WriteString("\n")
 	}
 
+	// GROUP BY & HAVING
+	b.writeGroupBy(ctx)
+
 	// ORDER BY
 	if len(b.orders) > 0 {
 		sb.WriteString("ORDER BY ")
@@ -571,7 +1050,11 @@ func (b *SqlBuilder) buildSelect() (sql string, args []any) {
 			if i > 0 {
 				sb.WriteString(", ")
 			}
-			sb.WriteString(order.column.nameWithAlias())
+			if order.expr != nil {
+				ctx.writeToken(*order.expr, "ORDER BY")
+			} else {
+				ctx.writeColumn(order.column)
+			}
 			if order.asc {
 				sb.WriteString(" ASC")
 			} else {
@@ -582,7 +1065,22 @@ func (b *SqlBuilder) buildSelect() (sql string, args []any) {
 	}
 
 	// OFFSET & LIMIT
-	if b.offset > 0 && b.limit > 0 {
+	if b.dialect == DialectSQLServer && (b.offset > 0 || b.limit > 0) {
+		if len(b.orders) == 0 {
+			sb.WriteString("ORDER BY (SELECT NULL)\n") // OFFSET/FETCH requires ORDER BY
+		}
+		sb.WriteString(fmt.Sprintf("OFFSET %d ROWS", b.offset))
+		if b.limit > 0 {
+			sb.WriteString(fmt.Sprintf(" FETCH NEXT %d ROWS ONLY", b.limit))
+		}
+		sb.WriteString("\n")
+	} else if noLimit := b.dialect.noLimit(); noLimit != "" && b.offset > 0 { // OFFSET must be after LIMIT
+		limit := fmt.Sprintf("%d", b.limit)
+		if b.limit == 0 {
+			limit = noLimit
+		}
+		sb.WriteString(fmt.Sprintf("LIMIT %s OFFSET %d\n", limit, b.offset))
+	} else if b.offset > 0 && b.limit > 0 {
 		sb.WriteString(fmt.Sprintf("OFFSET %d LIMIT %d\n", b.offset, b.limit))
 	} else if b.offset > 0 {
 		sb.WriteString("OFFSET ")
@@ -594,26 +1092,34 @@ func (b *SqlBuilder) buildSelect() (sql string, args []any) {
 		sb.WriteString("\n")
 	}
 
-	stmt := sb.String()
-	if b.selectType == selectTypeExists {
-		stmt = fmt.Sprintf("SELECT EXISTS(%s)", stmt)
+	if ctx.markBound {
+		stmt, args := orderPositionalArgs(sb.String()[start:], ctx.args, len(b.whereArgs))
+		prefix := sb.String()[:start]
+		sb.Reset()
+		sb.WriteString(prefix)
+		sb.WriteString(stmt)
+		return args
 	}
-
-	return stmt, b.whereArgs
+	return ctx.args
 }
 
 func (b *SqlBuilder) buildInsert() (sql string, args []any) {
-	if len(b.insertColumns) == 0 {
-		panic("no columns selected for inserting")
-	}
+	sb := strings.Builder{}
+	args = b.writeInsert(&sb)
+	return b.dialect.quoteIdentifiers(formatSql(sb.String(), b.format)), args
+}
+
+// writeInsert writes the INSERT statement in default layout, without dialect-specific identifier quoting.
+func (b *SqlBuilder) writeInsert(sb stringWriter) (args []any) {
 	if b.insertIntoTable == nil {
 		panic("no tables selected for inserting")
 	}
-	if len(b.insertValues) == 0 {
-		panic("no values for inserting")
+	if err := b.validateInsert(); err != nil {
+		panic(err)
 	}
 
-	sb := strings.Builder{}
+	ctx := newBuildContext(sb, make([]any, 0, len(b.insertColumns)*len(b.insertValues)), b.dialect)
+	ctx.autoAliases = b.autoAliases
 
 	// INSERT INTO
 	sb.WriteString("INSERT INTO ")
@@ -627,34 +1133,68 @@ func (b *SqlBuilder) buildInsert() (sql string, args []any) {
 		sb.WriteString(column.name)
 		columnsName[i] = column.name
 	}
+	sb.WriteString(")")
+	// OUTPUT, the RETURNING equivalent of SQL Server
+	if b.dialect == DialectSQLServer && len(b.insertReturningColumns) > 0 {
+		sb.WriteString("\nOUTPUT ")
+		for i, column := range b.insertReturningColumns {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString("INSERTED.")
+			sb.WriteString(column.name)
+		}
+	}
 	// VALUES
-	sb.WriteString(")\nVALUES ")
-	columnsCount := len(b.insertColumns)
-	values := make([]any, 0, columnsCount*len(b.insertValues))
-	for i, record := range b.insertValues {
-		vi := i * columnsCount
-
-		if i > 0 {
-			sb.WriteString(",")
+	insertSpec := b.insertIntoTable.genericTableMeta().insertSpecOfColumns(columnsName...)
+	var values []any // reused by the records
+	writeRecord := func(record any, cast bool) {
+		values = insertSpec(record, values[:0])
+		for i, column := range b.insertColumns {
+			if value, found := b.insertColumnOverrides[column.name]; found {
+				values[i] = value
+			}
 		}
-
-		sb.WriteString("(")
-		for paramIdx := 1; paramIdx <= columnsCount; paramIdx++ {
-			if paramIdx > 1 {
+		for i, value := range values {
+			if i > 0 {
 				sb.WriteString(",")
 			}
 
-			sb.WriteString(fmt.Sprintf("$%d", vi+paramIdx))
+			if expr, ok := value.(Expr); ok { // SQL expression provided by insert spec, eg: gen_random_uuid()
+				ctx.writeToken(expr, "VALUES")
+			} else if cast {
+				ctx.writeCast(ctx.addArg(value), b.insertColumns[i].sqlType, "VALUES")
+			} else {
+				sb.WriteString(ctx.addArg(value))
+			}
 		}
+	}
+	if b.insertWhereNotExists != nil {
+		sb.WriteString("\nSELECT ")
+		writeRecord(b.insertValues[0], !b.dialect.isMySQL())
+		if b.dialect.isMySQL() {
+			sb.WriteString(" FROM DUAL") // WHERE requires FROM
+		}
+		sb.WriteString("\nWHERE NOT EXISTS (")
+		ctx.writeSubquery(b.insertWhereNotExists, "INSERT WHERE NOT EXISTS")
 		sb.WriteString(")")
+	} else {
+		sb.WriteString("\nVALUES ")
+		for i, record := range b.insertValues {
+			if i > 0 {
+				sb.WriteString(",")
+			}
 
-		for _, isf := range b.insertIntoTable.genericTableMeta().insertSpecOfColumns(columnsName...) {
-			values = append(values, isf(record))
+			sb.WriteString("(")
+			writeRecord(record, false)
+			sb.WriteString(")")
 		}
 	}
 
 	// ON CONFLICT
-	if b.insertOnConflictDoNothing {
+	if b.dialect.isMySQL() {
+		b.writeOnDuplicateKeyUpdate(ctx)
+	} else if b.insertOnConflictDoNothing {
 		if len(b.insertOnConflictKeys) > 0 {
 			sb.WriteString("\nON CONFLICT (")
 			for i, column := range b.insertOnConflictKeys {
@@ -678,50 +1218,25 @@ func (b *SqlBuilder) buildInsert() (sql string, args []any) {
 		sb.WriteString(") ")
 
 		sb.WriteString("DO UPDATE SET\n")
-		for _, token := range b.insertOnConflictDoUpdateTokens {
-			sb.WriteString(" ")
-			switch t := token.(type) {
-			case string:
-				sb.WriteString(strings.TrimSpace(t))
-			case GenericColumnToUse:
-				sb.WriteString(t.name)
-			case int8, uint8, int16, uint16, int32, uint32, int64, uint64, int, uint:
-				sb.WriteString(fmt.Sprintf("%d", t))
-			case bool:
-				if t {
-					sb.WriteString("TRUE")
-				} else {
-					sb.WriteString("FALSE")
-				}
-			default:
-				panic(fmt.Sprintf("unexpected ON CONFLICT UPDATE token type %T", t))
-			}
-		}
-		if len(b.insertOnConflictDoUpdateWhereTokens) > 0 {
+		ctx.columnStyle = columnStyleNameOnly
+		ctx.writeArenaTokens(&b.tokens, b.insertOnConflictDoUpdateTokens, "ON CONFLICT UPDATE")
+		if b.insertOnConflictDoUpdateWhereTokens.len() > 0 {
 			sb.WriteString("\nWHERE")
-			for _, token := range b.insertOnConflictDoUpdateWhereTokens {
-				sb.WriteString(" ")
-				switch t := token.(type) {
-				case string:
-					sb.WriteString(strings.TrimSpace(t))
-				case GenericColumnToUse:
-					sb.WriteString(t.table.tableName())
-					sb.WriteString(".")
-					sb.WriteString(t.name)
-				case int8, uint8, int16, uint16, int32, uint32, int64, uint64, int, uint:
-					sb.WriteString(fmt.Sprintf("%d", t))
-				case bool:
-					if t {
-						sb.WriteString("TRUE")
-					} else {
-						sb.WriteString("FALSE")
-					}
-				default:
-					panic(fmt.Sprintf("unexpected ON CONFLICT UPDATE WHERE token type %T", t))
-				}
+			ctx.columnStyle = columnStyleWithTableName
+			ctx.writeArenaTokens(&b.tokens, b.insertOnConflictDoUpdateWhereTokens, "ON CONFLICT UPDATE WHERE")
+		}
+	}
+
+	// RETURNING
+	if len(b.insertReturningColumns) > 0 && b.dialect != DialectSQLServer {
+		sb.WriteString("\nRETURNING ")
+		for i, column := range b.insertReturningColumns {
+			if i > 0 {
+				sb.WriteString(", ")
 			}
+			sb.WriteString(column.name)
 		}
 	}
 
-	return sb.String(), values
+	return ctx.args
 }
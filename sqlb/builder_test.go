@@ -1,8 +1,10 @@
 package sqlb
 
 import (
+	"context"
 	"testing"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 )
 
@@ -203,6 +205,34 @@ WHERE t1.pk1 = 2
 `,
 			wantArgs: nil,
 		},
+		{
+			name: "select exists with auto-allocated args",
+			builder: func() *SqlBuilder {
+				table1 := UseTable[testStruct1]().Alias("t1").Seal()
+				return SelectExists().
+					From(table1).
+					Where(Eq(table1.Col("pk1"), "2")).
+					And(In(table1.Col("pk2"), 3, 4))
+			},
+			wantSql: `SELECT EXISTS(SELECT 1 FROM table1 AS t1
+WHERE t1.pk1 = $1 AND t1.pk2 IN ($2, $3)
+)`,
+			wantArgs: []any{"2", 3, 4},
+		},
+		{
+			name: "select count with provided and auto-allocated args",
+			builder: func() *SqlBuilder {
+				table1 := UseTable[testStruct1]().Alias("t1").Seal()
+				return SelectCount().
+					From(table1).
+					Where(table1.Col("pk1"), "= $1").Args("2").
+					And(Gte(table1.Col("amount"), 10))
+			},
+			wantSql: `SELECT COUNT(1) FROM table1 AS t1
+WHERE t1.pk1 = $1 AND t1.amount >= $2
+`,
+			wantArgs: []any{"2", 10},
+		},
 		{
 			name: "multi-operations",
 			builder: func() *SqlBuilder {
@@ -236,6 +266,43 @@ OFFSET 10 LIMIT 20
 `,
 			wantArgs: []any{1, 2},
 		},
+		{
+			name: "select with join on condition has bound args",
+			builder: func() *SqlBuilder {
+				table1 := UseTable[testStruct1]().Alias("t1").Seal()
+				table2 := UseTable[testStruct2]().Alias("t2").Seal()
+				return Select(table1.Col("pk1"), table2.Col("pk3")).
+					From(table1).
+					Join(InnerJoin, table2, table1.Col("pk1"), table2.Col("pk1")).
+					AndOn(Gt(table2.Col("amount"), 10)).
+					AndOn(Eq(table2.Col("pk2"), "b")).
+					Where(table1.Col("pk2"), "= $1").Args("a").
+					And(Eq(table1.Col("amount"), 20))
+			},
+			wantSql: `SELECT t1.pk1, t2.pk3
+FROM table1 AS t1
+INNER JOIN table2 AS t2 ON t1.pk1 = t2.pk1 AND t2.amount > $2 AND t2.pk2 = $3
+WHERE t1.pk2 = $1 AND t1.amount = $4
+`,
+			wantArgs: []any{"a", 10, "b", 20},
+		},
+		{
+			name: "select with join on expression",
+			builder: func() *SqlBuilder {
+				table1 := UseTable[testStruct1]().Alias("t1").Seal()
+				table2 := UseTable[testStruct2]().Alias("t2").Seal()
+				return Select(table1.Col("pk1")).
+					From(table1).
+					JoinOn(LeftJoin, table2, Eq(table1.Col("pk1"), table2.Col("pk1")), "OR", Eq(table2.Col("pk2"), "b")).
+					Where(Eq(table1.Col("amount"), 20))
+			},
+			wantSql: `SELECT t1.pk1
+FROM table1 AS t1
+LEFT JOIN table2 AS t2 ON t1.pk1 = t2.pk1 OR t2.pk2 = $1
+WHERE t1.amount = $2
+`,
+			wantArgs: []any{"b", 20},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -465,6 +532,56 @@ ON CONFLICT (pk1, pk2) DO UPDATE SET
 WHERE table1.cost > excluded.cost`,
 			wantArgs: []any{"1", 2, 3, "4testa", "5", 6, 7, "8testa"},
 		},
+		{
+			name: "INSERT INTO TABLE ON CONFLICT DO UPDATE with bound args",
+			builder: func() *SqlBuilder {
+				table1 := UseTable[testStruct1]().Seal()
+				return InsertInto(table1, table1.Col("pk1"), table1.Col("amount")).
+					Values(testStruct1{Pk1: "1", Amount: 3}, testStruct1{Pk1: "5", Amount: 7}).
+					OnConflict(table1.Col("pk1")).
+					DoUpdate(table1.Col("amount"), "=", table1.Col("amount").NameWithTableName(), "+", Arg(10)).
+					DoUpdate(table1.Col("pk2"), "=", Coalesce(table1.Col("pk2").NameWithTableName(), Arg(0))).
+					Where(Lt(table1.Col("amount"), 100))
+			},
+			wantSql: `INSERT INTO table1 (pk1, amount)
+VALUES ($1,$2),($3,$4)
+ON CONFLICT (pk1) DO UPDATE SET
+ amount = table1.amount + $5 , pk2 = COALESCE(table1.pk2, $6)
+WHERE table1.amount < $7`,
+			wantArgs: []any{"1", 3, "5", 7, 10, 0, 100},
+		},
+		{
+			name: "INSERT INTO TABLE ON CONFLICT DO UPDATE SET values",
+			builder: func() *SqlBuilder {
+				table1 := UseTable[testStruct1]().Seal()
+				return InsertInto(table1, table1.Col("pk1"), table1.Col("amount")).
+					Values(testStruct1{Pk1: "1", Amount: 3}).
+					OnConflict(table1.Col("pk1")).
+					DoUpdateSet(table1.Col("cost"), Now()).
+					DoUpdateSet(table1.Col("amount"), 0)
+			},
+			wantSql: `INSERT INTO table1 (pk1, amount)
+VALUES ($1,$2)
+ON CONFLICT (pk1) DO UPDATE SET
+ cost = NOW() , amount = $3`,
+			wantArgs: []any{"1", 3, 0},
+		},
+		{
+			name: "INSERT INTO TABLE ON CONFLICT DO UPDATE counters",
+			builder: func() *SqlBuilder {
+				table1 := UseTable[testStruct1]().Seal()
+				return InsertInto(table1, table1.Col("pk1"), table1.Col("amount")).
+					Values(testStruct1{Pk1: "1", Amount: 3}).
+					OnConflict(table1.Col("pk1")).
+					DoUpdate(table1.Col("amount").AddExcluded()).
+					DoUpdate(table1.Col("pk2").Increment(1))
+			},
+			wantSql: `INSERT INTO table1 (pk1, amount)
+VALUES ($1,$2)
+ON CONFLICT (pk1) DO UPDATE SET
+ amount = table1.amount + excluded.amount , pk2 = table1.pk2 + $3`,
+			wantArgs: []any{"1", 3, 1},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -476,20 +593,13 @@ WHERE table1.cost > excluded.cost`,
 }
 
 func TestSqlBuilder_registerUsingTable(t *testing.T) {
-	sb := &SqlBuilder{
-		aliasToTableUniqueId: make(map[string]int64),
-		tableUniqueIdToAlias: make(map[int64]string),
-	}
-
 	table1 := UseTable[testStruct1]().Alias("t1").Seal()
 	table2 := UseTable[testStruct2]().Alias("t2").Seal()
 
-	sb.registerUsingTable(table1)
-
 	tests := []struct {
-		name      string
-		table     GenericTableToUse
-		wantPanic bool
+		name    string
+		table   GenericTableToUse
+		wantErr string
 	}{
 		{
 			name:  "pass - can register",
@@ -504,31 +614,202 @@ func TestSqlBuilder_registerUsingTable(t *testing.T) {
 			table: UseTable[testStruct1]().Alias("still1").Seal(),
 		},
 		{
-			name:      "fail - reject same table if alias taken",
-			table:     UseTable[testStruct1]().Alias(table1.tableAlias()).Seal(),
-			wantPanic: true,
+			name:    "fail - reject same table if alias taken",
+			table:   UseTable[testStruct1]().Alias(table1.tableAlias()).Seal(),
+			wantErr: "alias t1 is used by both table table1 and table table1",
 		},
 		{
-			name:      "fail - reject table if alias taken",
-			table:     UseTable[testStruct2]().Alias(table1.tableAlias()).Seal(),
-			wantPanic: true,
-		},
-		{
-			name:      "fail - reject another instance with same alias",
-			table:     UseTable[testStruct1]().Alias(table1.tableAlias()).Seal(),
-			wantPanic: true,
+			name:    "fail - reject table if alias taken",
+			table:   UseTable[testStruct2]().Alias(table1.tableAlias()).Seal(),
+			wantErr: "alias t1 is used by both table table1 and table table2",
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if tt.wantPanic {
-				require.Panics(t, func() {
-					sb.registerUsingTable(tt.table)
-				})
+			sb := newSqlBuilder()
+			sb.registerUsingTable(table1)
+			sb.registerUsingTable(tt.table)
+
+			if tt.wantErr != "" {
+				require.ErrorIs(t, sb.validate(), ErrAliasConflict)
+				require.ErrorContains(t, sb.validate(), tt.wantErr)
 				return
 			}
+			require.NoError(t, sb.validate())
+		})
+	}
+}
 
-			sb.registerUsingTable(tt.table)
+func TestUseTableAutoAlias(t *testing.T) {
+	parent := UseTableAutoAlias[testStruct1]().Seal()
+	child := UseTableAutoAlias[testStruct1]().Seal()
+	grandchild := UseTableAutoAlias[testStruct1]().Seal()
+
+	gotSql, _, err := Select(parent.Col("pk1"), child.Col("pk1"), grandchild.Col("pk1")).
+		From(parent).
+		Join(InnerJoin, child, parent.Col("pk1"), child.Col("pk2")).
+		Join(InnerJoin, grandchild, child.Col("pk1"), grandchild.Col("pk2")).
+		TryBuild()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT table1.pk1, table1_2.pk1, table1_3.pk1\nFROM table1 AS table1\nINNER JOIN table1 AS table1_2 ON table1.pk1 = table1_2.pk2\nINNER JOIN table1 AS table1_3 ON table1_2.pk1 = table1_3.pk2\n", gotSql)
+
+	t.Run("the alias is chosen per builder", func(t *testing.T) {
+		gotSql, _, err := Select(child.Col("pk1")).From(child).TryBuild()
+		require.NoError(t, err)
+		require.Equal(t, "SELECT table1.pk1\nFROM table1 AS table1\n", gotSql)
+		require.Equal(t, "table1", child.tableAlias(), "the shared table is not modified")
+
+		gotSql, _, err = Select(grandchild.Col("pk1"), parent.Col("pk1")).
+			From(grandchild).
+			Join(InnerJoin, parent, grandchild.Col("pk1"), parent.Col("pk2")).
+			TryBuild()
+		require.NoError(t, err)
+		require.Equal(t, "SELECT table1.pk1, table1_2.pk1\nFROM table1 AS table1\nINNER JOIN table1 AS table1_2 ON table1.pk1 = table1_2.pk2\n", gotSql)
+	})
+
+	t.Run("correlated subquery", func(t *testing.T) {
+		gotSql, _, err := Select(parent.Col("pk1")).
+			From(parent).
+			Join(InnerJoin, child, parent.Col("pk1"), child.Col("pk2")).
+			Where(Exists(SelectExists().From(grandchild).Where(Eq(grandchild.Col("pk2"), child.Col("pk1"))))).
+			TryBuild()
+		require.NoError(t, err)
+		require.Contains(t, gotSql, "WHERE table1.pk2 = table1_2.pk1", "the correlated table is resolved by the outer builder")
+	})
+
+	t.Run("read rows", func(t *testing.T) {
+		b := Select(parent.Col("pk1"), child.Col("pk1")).
+			From(parent).
+			Join(InnerJoin, child, parent.Col("pk1"), child.Col("pk2"))
+		rows := &valuesRows{rows: [][]any{{"p", "c"}}}
+		scanned, err := b.scanRows(rows, nil)
+		require.NoError(t, err)
+		require.True(t, scanned.Next())
+		require.Equal(t, "p", parent.ReadFromRow(scanned).Pk1)
+		require.Equal(t, "c", child.ReadFromRow(scanned).Pk1)
+	})
+
+	t.Run("explicit alias", func(t *testing.T) {
+		table := UseTableAutoAlias[testStruct1]().Alias("t1").Seal()
+		sb := newSqlBuilder()
+		sb.registerUsingTable(UseTable[testStruct1]().Alias("t1").Seal())
+		sb.registerUsingTable(table)
+		require.ErrorIs(t, sb.validate(), ErrAliasConflict, "the explicit alias is not changed")
+	})
+}
+
+func TestSqlBuilder_TryBuild(t *testing.T) {
+	table1 := UseTable[testStruct1]().Alias("t").Seal()
+	table2 := UseTable[testStruct2]().Alias("t").Seal()
+
+	b := Select(table1.Col("pk1")).
+		From(table1).
+		Join(InnerJoin, table2, table1.Col("pk1"), table2.Col("pk1"))
+
+	const wantErr = "alias t is used by both table table1 and table table2: alias conflict"
+	_, _, err := b.TryBuild()
+	require.ErrorIs(t, err, ErrAliasConflict)
+	require.EqualError(t, err, wantErr)
+	require.PanicsWithError(t, wantErr, func() {
+		b.Build()
+	})
+	_, err = b.QueryWithExecutor(context.Background(), &recordingExecutor{})
+	require.EqualError(t, err, wantErr)
+
+	gotSql, gotArgs, err := Select(table1.Col("pk1")).From(table1).TryBuild()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT t.pk1\nFROM table1 AS t\n", gotSql)
+	require.Empty(t, gotArgs)
+
+	t.Run("other panics are not recovered", func(t *testing.T) {
+		require.PanicsWithError(t, "boom", func() {
+			_, _, _ = Select(table1.Col("pk1")).From(table1).Where(panicFragment{err: errors.New("boom")}).TryBuild()
+		})
+		require.PanicsWithError(t, "assignment to entry in nil map", func() {
+			_, _, _ = Select(table1.Col("pk1")).From(table1).Where(panicFragment{}).TryBuild()
 		})
+	})
+}
+
+// panicFragment panics with the error, or a runtime error if nil, when being written, for the sake of the test.
+type panicFragment struct {
+	err error
+}
+
+func (f panicFragment) WriteSql(*BuildContext) {
+	if f.err == nil {
+		var m map[string]int
+		m["x"] = 1
 	}
+	panic(f.err)
+}
+
+func TestSqlBuilder_columnCollision(t *testing.T) {
+	table1 := UseTable[testStruct1]().Alias("t").Seal()
+	table2 := UseTable[testStruct2]().Alias("t").Seal()
+
+	t.Run("column selected more than once", func(t *testing.T) {
+		b := Select(table1.Col("pk1"), table1.Col("amount"), table1.Col("pk1")).From(table1)
+
+		_, _, err := b.TryBuild()
+		require.ErrorIs(t, err, ErrColumnCollision)
+		require.EqualError(t, err, "column t.pk1 is selected more than once: column collision")
+
+		_, err = b.ScanRows(&valuesRows{rows: [][]any{{"a", 1, "a"}}})
+		require.ErrorIs(t, err, ErrColumnCollision)
+	})
+
+	t.Run("same column of the tables of different aliases", func(t *testing.T) {
+		other := UseTable[testStruct1]().Alias("o").Seal()
+		_, _, err := Select(table1.Col("pk1"), other.Col("pk1")).From(table1, other).TryBuild()
+		require.NoError(t, err)
+	})
+
+	t.Run("tables of the same alias are detected when scanning", func(t *testing.T) {
+		b := Select(table1.Col("pk1"), table2.Col("pk3")).From(table1, table2)
+
+		_, err := b.ScanRows(&valuesRows{rows: [][]any{{"a", int64(1)}}})
+		require.ErrorIs(t, err, ErrAliasConflict)
+		require.EqualError(t, err, "alias t is used by both table table1 and table table2: alias conflict")
+	})
+}
+
+func TestSqlBuilder_OrderByName(t *testing.T) {
+	table1 := UseTable[testStruct1]().Alias("t1").Seal()
+	table2 := UseTable[testStruct2]().Alias("t2").Seal()
+	allowed := []GenericColumnToUse{table1.Col("pk1"), table1.Col("amount"), table2.Col("pk1")}
+
+	newBuilder := func() *SqlBuilder {
+		return Select(table1.Col("pk1")).From(table1, table2)
+	}
+
+	b, err := newBuilder().OrderByName("amount", DESC, allowed...)
+	require.NoError(t, err)
+	b, err = b.OrderByName("t2.pk1", ASC, allowed...)
+	require.NoError(t, err)
+	gotSql, _ := b.Build()
+	require.Equal(t, "SELECT t1.pk1\nFROM table1 AS t1, table2 AS t2\nORDER BY t1.amount DESC, t2.pk1 ASC\n", gotSql)
+
+	_, err = newBuilder().OrderByName("cost", ASC, allowed...)
+	require.EqualError(t, err, `column "cost" is not allowed`)
+
+	_, err = newBuilder().OrderByName("t3.pk1", ASC, allowed...)
+	require.EqualError(t, err, `column "t3.pk1" is not allowed`)
+
+	_, err = newBuilder().OrderByName("pk1", ASC, allowed...)
+	require.EqualError(t, err, `column "pk1" is ambiguous, qualify it with the table alias`)
+}
+
+func TestSqlBuilder_OrderByExpr(t *testing.T) {
+	table1 := UseTable[testStruct1]().Alias("t1").Seal()
+
+	gotSql, gotArgs := Select(table1.Col("pk1")).
+		From(table1).
+		Where(Similar(table1.Col("pk1"), "jon", 0.3)).
+		OrderByExpr(Similarity(table1.Col("pk1"), "jon"), DESC).
+		ThenBy(table1.Col("pk2"), ASC).
+		ThenByExpr(NewExpr(table1.Col("amount"), "*", Arg(2)), ASC).
+		Build()
+	require.Equal(t, "SELECT t1.pk1\nFROM table1 AS t1\nWHERE similarity(t1.pk1, $1) > $2\nORDER BY similarity(t1.pk1, $3) DESC, t1.pk2 ASC, t1.amount * $4 ASC\n", gotSql)
+	require.Equal(t, []any{"jon", 0.3, "jon", 2}, gotArgs)
 }
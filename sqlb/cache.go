@@ -0,0 +1,259 @@
+package sqlb
+
+import (
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// QueryCache caches the results of the queries, keyed by fingerprint of the statement and the arguments.
+//
+// The entries are tagged by the name of the tables the query reads from,
+// so the executions modify any of the tables can invalidate them.
+type QueryCache interface {
+	// Get returns the cached value of the key.
+	Get(key string) (value any, found bool)
+	// Set caches the value of the key, tagged by the name of the tables the query reads from.
+	Set(key string, tables []string, value any)
+	// InvalidateTables removes the entries tagged by any of the tables.
+	InvalidateTables(tables ...string)
+}
+
+// Cache uses the cache for the query results.
+//
+// Query* consults the cache before execution and stores the result after.
+// Exec* invalidates the entries of the table it writes to, after successful execution.
+// Within the transaction of InTransaction, the entries are invalidated after the commit, and not at all if rolled back.
+// Other transactions (e.g. sqlb.WrapExecutor(tx)) invalidate the entries immediately,
+// so the queries executed by others before the commit may cache the rows not yet updated.
+// The entries are scoped by the executor, so the databases sharing the cache do not read the results of each other,
+// and the queries executed within a transaction (*sql.Tx) are neither read from nor stored into the cache.
+//
+// Only the entries of the given cache are invalidated, so the builders writing to the tables must use the same cache
+// as the builders reading them, the writes made otherwise must invalidate the entries via QueryCache.InvalidateTables.
+//
+// The cached rows share the scanned values, the values read from the cached rows must not be mutated.
+func (b *SqlBuilder) Cache(cache QueryCache) *SqlBuilder {
+	if cache == nil {
+		panic("cache is nil")
+	}
+	b.cache = cache
+	return b
+}
+
+// resultKind is the kind of the result of a query, the results of the same statement scanned differently,
+// e.g. ScannedRows of QueryWithExecutor and the records of ScanAll, are cached and collapsed separately.
+type resultKind string
+
+const (
+	resultKindRows    resultKind = "rows"    // *ScannedRows
+	resultKindRecords resultKind = "records" // records of ScanAll
+	resultKindScalar  resultKind = "scalar"  // EXISTS, COUNT
+)
+
+// queryFingerprint returns the fingerprint of the kind of the result, the executor, the statement and the arguments,
+// used as the cache key. Returns false if an argument can not be fingerprinted, e.g. the driver.Valuer fails.
+func queryFingerprint(kind resultKind, exec Executor, stmt string, args []any) (string, bool) {
+	h := sha256.New()
+	h.Write([]byte(kind))
+	h.Write([]byte{0})
+	h.Write([]byte(executorScope(exec)))
+	h.Write([]byte{0})
+	h.Write([]byte(stmt))
+	for _, arg := range args {
+		value, ok := fingerprintArg(arg)
+		if !ok {
+			return "", false
+		}
+		_, _ = fmt.Fprintf(h, "\x00%T:%v", value, value)
+	}
+	return hex.EncodeToString(h.Sum(nil)), true
+}
+
+// fingerprintArg returns the value of the argument as bound by the driver: the pointers are dereferenced
+// and the driver.Valuer are resolved, so the fingerprint depends on the value rather than the address.
+func fingerprintArg(arg any) (any, bool) {
+	for {
+		if arg == nil {
+			return nil, true
+		}
+		rv := reflect.ValueOf(arg)
+		if rv.Kind() == reflect.Ptr && rv.IsNil() {
+			return nil, true
+		}
+		if valuer, ok := arg.(driver.Valuer); ok {
+			value, err := valuer.Value()
+			return value, err == nil
+		}
+		if rv.Kind() != reflect.Ptr {
+			return arg, true
+		}
+		arg = rv.Elem().Interface()
+	}
+}
+
+// executorScope returns the identity of the executor the results are scoped by,
+// the executors of non-pointer types are identified by their type.
+func executorScope(exec Executor) string {
+	var target any = exec
+	switch std := exec.(type) {
+	case stdExecutor:
+		target = std.std
+	case *txExecutor:
+		target = std.std
+	}
+	if reflect.ValueOf(target).Kind() == reflect.Ptr {
+		return fmt.Sprintf("%T@%p", target, target)
+	}
+	return fmt.Sprintf("%T", target)
+}
+
+// usingTablesName returns name of the tables used by the builder, the partitions are tagged by the parent table.
+func (b *SqlBuilder) usingTablesName() []string {
+	var tables []GenericTableToUse
+	if b._type == sqlBuilderTypeInsert {
+		tables = append(tables, b.insertIntoTable)
+	} else {
+		tables = append(tables, b.selectFromTable...)
+		for _, j := range b.joinsOn {
+			tables = append(tables, j.joinOnTable)
+		}
+	}
+
+	var names []string
+	unique := make(map[string]struct{})
+	for _, table := range tables {
+		name := table.genericTableMeta().Name()
+		if _, found := unique[name]; found {
+			continue
+		}
+		unique[name] = struct{}{}
+		names = append(names, name)
+	}
+	return names
+}
+
+// cached returns the cached result of the query, if any.
+func (b *SqlBuilder) cached(key string) (value any, found bool) {
+	if b.cache == nil {
+		return nil, false
+	}
+	value, found = b.cache.Get(key)
+	if sr, ok := value.(*ScannedRows); ok {
+		value = sr.clone()
+	}
+	return
+}
+
+// storeCache caches the result of the query.
+func (b *SqlBuilder) storeCache(key string, value any) {
+	if b.cache == nil {
+		return
+	}
+	if sr, ok := value.(*ScannedRows); ok {
+		value = sr.clone()
+	}
+	b.cache.Set(key, b.usingTablesName(), value)
+}
+
+// invalidateCache removes the cached entries of the tables used by the builder,
+// after the commit if executed within the transaction of InTransaction.
+func (b *SqlBuilder) invalidateCache(exec Executor) {
+	if b.cache == nil {
+		return
+	}
+	cache, tables := b.cache, b.usingTablesName()
+	if tx, ok := exec.(*txExecutor); ok {
+		tx.onCommit(func() {
+			cache.InvalidateTables(tables...)
+		})
+		return
+	}
+	cache.InvalidateTables(tables...)
+}
+
+var _ QueryCache = (*MemoryCache)(nil)
+
+// MemoryCache is an in-memory QueryCache, entries expire after the TTL.
+type MemoryCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]memoryCacheEntry
+}
+
+type memoryCacheEntry struct {
+	value     any
+	tables    []string
+	expiresAt time.Time
+}
+
+// NewMemoryCache creates an in-memory cache, entries expire after the TTL.
+func NewMemoryCache(ttl time.Duration) *MemoryCache {
+	if ttl <= 0 {
+		panic("ttl must be positive")
+	}
+	return &MemoryCache{
+		ttl:     ttl,
+		entries: make(map[string]memoryCacheEntry),
+	}
+}
+
+func (c *MemoryCache) Get(key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, found := c.entries[key]
+	if !found {
+		return nil, false
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.value, true
+}
+
+func (c *MemoryCache) Set(key string, tables []string, value any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[key] = memoryCacheEntry{
+		value:     value,
+		tables:    tables,
+		expiresAt: time.Now().Add(c.ttl),
+	}
+}
+
+func (c *MemoryCache) InvalidateTables(tables ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, entry := range c.entries {
+		if anyTableOf(entry.tables, tables) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// Len returns number of the entries, including the expired ones not yet evicted.
+func (c *MemoryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.entries)
+}
+
+func anyTableOf(tables []string, lookup []string) bool {
+	for _, t := range tables {
+		for _, l := range lookup {
+			if t == l {
+				return true
+			}
+		}
+	}
+	return false
+}
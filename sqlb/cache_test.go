@@ -0,0 +1,338 @@
+package sqlb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// scalarRows is a fake SqlRows returns a single row of a single int value, for the sake of the test.
+type scalarRows struct {
+	value int
+	read  bool
+}
+
+func (r *scalarRows) Next() bool {
+	next := !r.read
+	r.read = true
+	return next
+}
+
+func (r *scalarRows) Scan(dest ...any) error {
+	switch d := dest[0].(type) {
+	case *int64:
+		*d = int64(r.value)
+	default:
+		*d.(*int) = r.value
+	}
+	return nil
+}
+
+func (r *scalarRows) Close() error {
+	return nil
+}
+
+func (r *scalarRows) Err() error {
+	return nil
+}
+
+// scalarExecutor counts the queries and returns the scalar value, for the sake of the test.
+type scalarExecutor struct {
+	recordingExecutor
+	value   int
+	queries atomic.Int32
+	delay   time.Duration
+}
+
+func (e *scalarExecutor) QueryContext(context.Context, string, ...any) (SqlRows, error) {
+	e.queries.Add(1)
+	time.Sleep(e.delay)
+	return &scalarRows{value: e.value}, nil
+}
+
+func (e *scalarExecutor) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return e.recordingExecutor.ExecContext(ctx, query, args...)
+}
+
+func TestSqlBuilder_Cache(t *testing.T) {
+	cache := NewMemoryCache(time.Minute)
+	exec := &scalarExecutor{value: 7}
+
+	count := func(id int64) int64 {
+		products := UseTable[testDdlRow]().Seal()
+		count, err := SelectCount().
+			From(products).
+			Where(products.Col("id"), "=", "$1").
+			Args(id).
+			Cache(cache).
+			QueryCountWithExecutor(context.Background(), exec)
+		require.NoError(t, err)
+		return count
+	}
+
+	require.Equal(t, int64(7), count(1))
+	require.Equal(t, int64(7), count(1))
+	require.Equal(t, int32(1), exec.queries.Load(), "second query must be served from cache")
+
+	require.Equal(t, int64(7), count(2))
+	require.Equal(t, int32(2), exec.queries.Load(), "different args must not share the entry")
+	require.Equal(t, 2, cache.Len())
+
+	_, err := InsertInto(UseTable[testDdlRow]().Seal()).
+		Values(testDdlRow{Id: 1, Title: "book"}).
+		Cache(cache).
+		ExecWithExecutor(context.Background(), exec)
+	require.NoError(t, err)
+	require.Equal(t, 0, cache.Len(), "insert must invalidate entries of the table")
+
+	require.Equal(t, int64(7), count(1))
+	require.Equal(t, int32(3), exec.queries.Load())
+}
+
+func TestMemoryCache(t *testing.T) {
+	cache := NewMemoryCache(time.Minute)
+	cache.Set("k1", []string{"a", "b"}, 1)
+	cache.Set("k2", []string{"c"}, 2)
+
+	value, found := cache.Get("k1")
+	require.True(t, found)
+	require.Equal(t, 1, value)
+
+	cache.InvalidateTables("b")
+	_, found = cache.Get("k1")
+	require.False(t, found)
+	_, found = cache.Get("k2")
+	require.True(t, found)
+
+	cache = NewMemoryCache(time.Nanosecond)
+	cache.Set("k1", nil, 1)
+	time.Sleep(time.Millisecond)
+	_, found = cache.Get("k1")
+	require.False(t, found, "entry must be expired")
+
+	require.Panics(t, func() {
+		NewMemoryCache(0)
+	})
+}
+
+func TestScannedRows_clone(t *testing.T) {
+	value := &testDdlRow{Id: 1}
+	sr := &ScannedRows{
+		rowsOfAliasToRow: []map[string]*row{
+			{"products": {valueFunc: func() any { return value }}},
+		},
+	}
+	require.True(t, sr.Next())
+	require.Equal(t, value, sr.GetTable("products"))
+
+	cloned := sr.clone()
+	require.Equal(t, 1, cloned.Count())
+	require.True(t, cloned.Next(), "reading state must be reset")
+	require.Equal(t, value, cloned.GetTable("products"))
+	require.False(t, cloned.Next())
+}
+
+func Test_queryFingerprint(t *testing.T) {
+	exec := &recordingExecutor{}
+	fingerprint := func(kind resultKind, exec Executor, stmt string, args ...any) string {
+		key, ok := queryFingerprint(kind, exec, stmt, args)
+		require.True(t, ok)
+		return key
+	}
+
+	require.Equal(t, fingerprint(resultKindRows, exec, "SELECT $1", 1), fingerprint(resultKindRows, exec, "SELECT $1", 1))
+	require.NotEqual(t, fingerprint(resultKindRows, exec, "SELECT $1", 1), fingerprint(resultKindRows, exec, "SELECT $1", "1"))
+	require.NotEqual(t, fingerprint(resultKindRows, exec, "SELECT $1", 1), fingerprint(resultKindRows, exec, "SELECT $2", 1))
+	require.NotEqual(t, fingerprint(resultKindRows, exec, "SELECT $1", 1), fingerprint(resultKindRecords, exec, "SELECT $1", 1))
+	require.NotEqual(t, fingerprint(resultKindRows, exec, "SELECT $1", 1), fingerprint(resultKindRows, &recordingExecutor{}, "SELECT $1", 1), "scoped by executor")
+
+	a, b, c := 1, 1, 2
+	require.Equal(t, fingerprint(resultKindRows, exec, "SELECT $1", &a), fingerprint(resultKindRows, exec, "SELECT $1", &b), "pointers are dereferenced")
+	require.NotEqual(t, fingerprint(resultKindRows, exec, "SELECT $1", &a), fingerprint(resultKindRows, exec, "SELECT $1", &c))
+	require.Equal(t, fingerprint(resultKindRows, exec, "SELECT $1", sql.NullString{String: "a", Valid: true}), fingerprint(resultKindRows, exec, "SELECT $1", "a"), "driver.Valuer are resolved")
+	require.Equal(t, fingerprint(resultKindRows, exec, "SELECT $1", (*sql.NullString)(nil)), fingerprint(resultKindRows, exec, "SELECT $1", nil))
+
+	_, ok := queryFingerprint(resultKindRows, exec, "SELECT $1", []any{failingValuer{}})
+	require.False(t, ok, "failing driver.Valuer can not be fingerprinted")
+}
+
+// failingValuer is a driver.Valuer always fails, for the sake of the test.
+type failingValuer struct{}
+
+func (failingValuer) Value() (driver.Value, error) {
+	return nil, errors.New("failed")
+}
+
+// txConnector is the connector of a fake database/sql driver, to execute the statements within a real *sql.Tx.
+// The queries return the queued values, a single row of a single column each. For the sake of the test.
+type txConnector struct {
+	mu     sync.Mutex
+	values []driver.Value
+	events []string // BEGIN, COMMIT, ROLLBACK and the executed statements
+}
+
+func (c *txConnector) record(event string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.events = append(c.events, event)
+}
+
+func (c *txConnector) Connect(context.Context) (driver.Conn, error) {
+	return txConn{c: c}, nil
+}
+
+func (c *txConnector) Driver() driver.Driver {
+	return txDriver{c: c}
+}
+
+type txDriver struct {
+	c *txConnector
+}
+
+func (d txDriver) Open(string) (driver.Conn, error) {
+	return txConn(d), nil
+}
+
+type txConn struct {
+	c *txConnector
+}
+
+func (c txConn) Prepare(query string) (driver.Stmt, error) {
+	return txStmt{c: c.c, query: query}, nil
+}
+
+func (c txConn) Close() error {
+	return nil
+}
+
+func (c txConn) Begin() (driver.Tx, error) {
+	c.c.record("BEGIN")
+	return c, nil
+}
+
+func (c txConn) Commit() error {
+	c.c.record("COMMIT")
+	return nil
+}
+
+func (c txConn) Rollback() error {
+	c.c.record("ROLLBACK")
+	return nil
+}
+
+type txStmt struct {
+	c     *txConnector
+	query string
+}
+
+func (s txStmt) Close() error {
+	return nil
+}
+
+func (s txStmt) NumInput() int {
+	return -1
+}
+
+func (s txStmt) Exec([]driver.Value) (driver.Result, error) {
+	s.c.record(s.query)
+	return driver.RowsAffected(1), nil
+}
+
+func (s txStmt) Query([]driver.Value) (driver.Rows, error) {
+	s.c.record(s.query)
+	s.c.mu.Lock()
+	defer s.c.mu.Unlock()
+	if len(s.c.values) == 0 {
+		return nil, errors.New("no queued values")
+	}
+	value := s.c.values[0]
+	s.c.values = s.c.values[1:]
+	return &txRows{value: value}, nil
+}
+
+type txRows struct {
+	value driver.Value
+	read  bool
+}
+
+func (r *txRows) Columns() []string {
+	return []string{"value"}
+}
+
+func (r *txRows) Close() error {
+	return nil
+}
+
+func (r *txRows) Next(dest []driver.Value) error {
+	if r.read {
+		return io.EOF
+	}
+	r.read = true
+	dest[0] = r.value
+	return nil
+}
+
+func TestSqlBuilder_Cache_transaction(t *testing.T) {
+	connector := &txConnector{values: []driver.Value{int64(7), int64(8)}}
+	db := sql.OpenDB(connector)
+	defer func() {
+		_ = db.Close()
+	}()
+	tx, err := db.Begin()
+	require.NoError(t, err)
+
+	cache := NewMemoryCache(time.Minute)
+	products := UseTable[testDdlRow]().Seal()
+	for _, want := range []int64{7, 8} {
+		count, err := SelectCount().From(products).Cache(cache).QueryCountWithExecutor(context.Background(), WrapExecutor(tx))
+		require.NoError(t, err)
+		require.Equal(t, want, count, "read within the transaction, never from the cache")
+	}
+	require.Equal(t, 0, cache.Len(), "results read within the transaction are not cached")
+	require.NoError(t, tx.Commit())
+}
+
+func TestSqlBuilder_Cache_invalidateOnCommit(t *testing.T) {
+	connector := &txConnector{values: []driver.Value{int64(7)}}
+	db := sql.OpenDB(connector)
+	defer func() {
+		_ = db.Close()
+	}()
+
+	cache := NewMemoryCache(time.Minute)
+	products := UseTable[testDdlRow]().Seal()
+	_, err := SelectCount().From(products).Cache(cache).QueryCountWithExecutor(context.Background(), WrapExecutor(db))
+	require.NoError(t, err)
+	require.Equal(t, 1, cache.Len())
+
+	insert := func(exec Executor) error {
+		_, err := InsertInto(UseTable[testDdlRow]().Seal()).
+			Values(testDdlRow{Id: 1, Title: "book"}).
+			Cache(cache).
+			ExecWithExecutor(context.Background(), exec)
+		return err
+	}
+
+	err = InTransaction(context.Background(), db, nil, func(exec Executor) error {
+		require.NoError(t, insert(exec))
+		return errors.New("rollback")
+	})
+	require.EqualError(t, err, "rollback")
+	require.Equal(t, 1, cache.Len(), "rolled back insert must not invalidate entries")
+
+	err = InTransaction(context.Background(), db, nil, func(exec Executor) error {
+		require.NoError(t, insert(exec))
+		require.Equal(t, 1, cache.Len(), "entries are invalidated after the commit")
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 0, cache.Len())
+}
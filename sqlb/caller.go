@@ -0,0 +1,57 @@
+package sqlb
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+// callerTagging is non-zero when the call site constructing the builder is captured, see SetCallerTagging.
+var callerTagging int32
+
+// sqlbFunctionPrefix is the prefix of the functions of this package, used to skip the frames of the builder itself.
+var sqlbFunctionPrefix = reflect.TypeOf(SqlBuilder{}).PkgPath() + "."
+
+// SetCallerTagging enables capturing the call site constructing the builder (Select, InsertInto,...)
+// and embedding it as the comment of the built statement, e.g. /* orders/repository.go:42 */ SELECT ...,
+// so the entries of pg_stat_statements and the database logs can be traced back to the Go code.
+//
+// Only the builders constructed after enabling are tagged. Capturing costs a stack walk per builder.
+func SetCallerTagging(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&callerTagging, v)
+}
+
+// captureCaller returns [dir]/[file]:[line] of the first frame outside this package, empty if tagging is disabled.
+func captureCaller() string {
+	if atomic.LoadInt32(&callerTagging) == 0 {
+		return ""
+	}
+
+	pcs := make([]uintptr, 16)
+	n := runtime.Callers(2, pcs) // skip runtime.Callers and captureCaller
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, sqlbFunctionPrefix) || strings.HasSuffix(frame.File, "_test.go") {
+			return fmt.Sprintf("%s/%s:%d", filepath.Base(filepath.Dir(frame.File)), filepath.Base(frame.File), frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+// tagCaller prefixes the statement with the comment of the captured call site, if any.
+func (b *SqlBuilder) tagCaller(stmt string) string {
+	if b.caller == "" {
+		return stmt
+	}
+	return "/* " + strings.ReplaceAll(b.caller, "*/", "* /") + " */ " + stmt
+}
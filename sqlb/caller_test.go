@@ -0,0 +1,36 @@
+package sqlb
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetCallerTagging(t *testing.T) {
+	table1 := UseTable[testStruct1]().Alias("t1").Seal()
+
+	SetCallerTagging(true)
+	defer SetCallerTagging(false)
+
+	_, _, line, _ := runtime.Caller(0)
+	selectBuilder := SelectExists().From(table1)
+	insertBuilder := InsertInto(table1, table1.Col("pk1")).Values(testStruct1{Pk1: "a"})
+
+	gotSql, _ := selectBuilder.Build()
+	require.Equal(t, fmt.Sprintf("/* sqlb/caller_test.go:%d */ SELECT EXISTS(SELECT 1 FROM table1 AS t1\n)", line+1), gotSql)
+
+	gotSql, _ = insertBuilder.Build()
+	require.Equal(t, fmt.Sprintf("/* sqlb/caller_test.go:%d */ INSERT INTO table1 (pk1)\nVALUES ($1)", line+2), gotSql)
+
+	var buf bytes.Buffer
+	_, err := insertBuilder.BuildTo(&buf)
+	require.NoError(t, err)
+	require.Equal(t, gotSql, buf.String())
+
+	SetCallerTagging(false)
+	gotSql, _ = SelectExists().From(table1).Build()
+	require.Equal(t, "SELECT EXISTS(SELECT 1 FROM table1 AS t1\n)", gotSql)
+}
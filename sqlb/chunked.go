@@ -0,0 +1,77 @@
+package sqlb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// QueryAllChunkedIn splits the keys into chunks of at most chunkSize, queries each chunk via the builder created by
+// the factory, and concatenates the rows of table T in order of the chunks.
+// Single statements with tens of thousands of parameters fail or perform badly, chunk them instead.
+//
+// The chunk is passed as []any to be used directly with In, eg:
+//
+//	QueryAllChunkedIn[Order](ctx, db, func(chunk []any) *SqlBuilder {
+//		orders := UseTable[Order]().Seal()
+//		return Select(orders.Columns()...).From(orders).Where(In(orders.Col("id"), chunk...))
+//	}, ids, 1000)
+//
+// The builder must select columns of exactly one table of type T and nothing else.
+func QueryAllChunkedIn[T any, K any](ctx context.Context, db *sql.DB, builderFactory func(chunk []any) *SqlBuilder, keys []K, chunkSize int) ([]T, error) {
+	return QueryAllChunkedInWithExecutor[T](ctx, WrapExecutor(db), builderFactory, keys, chunkSize)
+}
+
+// QueryAllChunkedInWithExecutor is the same as QueryAllChunkedIn, using the given executor.
+func QueryAllChunkedInWithExecutor[T any, K any](ctx context.Context, exec Executor, builderFactory func(chunk []any) *SqlBuilder, keys []K, chunkSize int) ([]T, error) {
+	if chunkSize < 1 {
+		panic("chunk size must be positive")
+	}
+
+	result := make([]T, 0, len(keys))
+	for start := 0; start < len(keys); start += chunkSize {
+		end := start + chunkSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		chunk := make([]any, end-start)
+		for i, key := range keys[start:end] {
+			chunk[i] = key
+		}
+
+		b := builderFactory(chunk)
+		table := usingTableOfType[T](b)
+		rows, err := b.QueryWithExecutor(ctx, exec)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to query chunk of keys [%d:%d]", start, end)
+		}
+		result = append(result, table.ReadAllFromRows(rows)...)
+	}
+	return result, nil
+}
+
+// usingTableOfType returns the only table of type T, selected from or joined by the builder.
+func usingTableOfType[T any](b *SqlBuilder) *TableToUse[T] {
+	tables := make([]GenericTableToUse, 0, len(b.selectFromTable)+len(b.joinsOn))
+	tables = append(tables, b.selectFromTable...)
+	for _, j := range b.joinsOn {
+		tables = append(tables, j.joinOnTable)
+	}
+
+	var found *TableToUse[T]
+	for _, table := range tables {
+		if t, ok := table.(*TableToUse[T]); ok {
+			if found != nil {
+				panic(fmt.Sprintf("multiple tables of type %s are used", getStructTypeName(new(T))))
+			}
+			found = t
+		}
+	}
+	if found == nil {
+		panic(fmt.Sprintf("no table of type %s is used", getStructTypeName(new(T))))
+	}
+	return found
+}
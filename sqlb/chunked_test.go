@@ -0,0 +1,77 @@
+package sqlb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// chunkExecutor returns one product per key argument and records the arguments of every query, for the sake of the test.
+type chunkExecutor struct {
+	argsOfQueries [][]any
+	err           error
+}
+
+func (e *chunkExecutor) QueryContext(_ context.Context, _ string, args ...any) (SqlRows, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	e.argsOfQueries = append(e.argsOfQueries, args)
+	rows := make([][]any, len(args))
+	for i, arg := range args {
+		rows[i] = []any{arg, fmt.Sprintf("product %d", arg)}
+	}
+	return &valuesRows{rows: rows}, nil
+}
+
+func (e *chunkExecutor) ExecContext(context.Context, string, ...any) (sql.Result, error) {
+	return nil, errors.New("not supported")
+}
+
+func TestQueryAllChunkedIn(t *testing.T) {
+	factory := func(chunk []any) *SqlBuilder {
+		products := UseTable[testDdlRow]().Seal()
+		return Select(products.Columns("id", "title")...).
+			From(products).
+			Where(In(products.Col("id"), chunk...))
+	}
+
+	t.Run("split keys into chunks and concatenate results", func(t *testing.T) {
+		exec := &chunkExecutor{}
+		got, err := QueryAllChunkedInWithExecutor[testDdlRow](context.Background(), exec, factory, []int64{1, 2, 3, 4, 5}, 2)
+		require.NoError(t, err)
+		require.Equal(t, [][]any{{int64(1), int64(2)}, {int64(3), int64(4)}, {int64(5)}}, exec.argsOfQueries)
+		require.Len(t, got, 5)
+		for i, row := range got {
+			require.Equal(t, int64(i+1), row.Id)
+			require.Equal(t, fmt.Sprintf("product %d", i+1), row.Title)
+		}
+	})
+
+	t.Run("no keys, no queries", func(t *testing.T) {
+		exec := &chunkExecutor{}
+		got, err := QueryAllChunkedInWithExecutor[testDdlRow](context.Background(), exec, factory, []int64{}, 2)
+		require.NoError(t, err)
+		require.Empty(t, got)
+		require.Empty(t, exec.argsOfQueries)
+	})
+
+	t.Run("failed to query", func(t *testing.T) {
+		exec := &chunkExecutor{err: errors.New("boom")}
+		_, err := QueryAllChunkedInWithExecutor[testDdlRow](context.Background(), exec, factory, []int64{1, 2, 3}, 2)
+		require.ErrorContains(t, err, "failed to query chunk of keys [0:2]: boom")
+	})
+
+	t.Run("invalid usages", func(t *testing.T) {
+		require.Panics(t, func() {
+			_, _ = QueryAllChunkedInWithExecutor[testDdlRow](context.Background(), &chunkExecutor{}, factory, []int64{1}, 0)
+		}, "chunk size must be positive")
+		require.Panics(t, func() {
+			_, _ = QueryAllChunkedInWithExecutor[testStruct1](context.Background(), &chunkExecutor{}, factory, []int64{1}, 1)
+		}, "no table of the type")
+	})
+}
@@ -0,0 +1,127 @@
+package sqlb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// CollectedStatement is a statement recorded by CollectingExecutor.
+type CollectedStatement struct {
+	Sql   string
+	Args  []any
+	Query bool // Query is true if the statement was received via QueryContext
+}
+
+// CollectingExecutor records the statements an operation would run without touching the database,
+// for dry-running data migration scripts and snapshot testing the business flows.
+//
+// Executions are never forwarded, they affect no rows.
+// Queries are forwarded to the reader if provided, so the flow reading the data can proceed, otherwise they return no rows.
+type CollectingExecutor struct {
+	reader     Executor
+	mu         sync.Mutex
+	statements []CollectedStatement
+}
+
+var _ Executor = (*CollectingExecutor)(nil)
+
+// NewCollectingExecutor returns the executor collects the statements, the reader is optional.
+func NewCollectingExecutor(reader Executor) *CollectingExecutor {
+	return &CollectingExecutor{
+		reader: reader,
+	}
+}
+
+func (e *CollectingExecutor) QueryContext(ctx context.Context, query string, args ...any) (SqlRows, error) {
+	e.collect(CollectedStatement{Sql: query, Args: args, Query: true})
+	if e.reader == nil {
+		return noRows{}, nil
+	}
+	return e.reader.QueryContext(ctx, query, args...)
+}
+
+func (e *CollectingExecutor) ExecContext(_ context.Context, query string, args ...any) (sql.Result, error) {
+	e.collect(CollectedStatement{Sql: query, Args: args})
+	return collectedResult{}, nil
+}
+
+func (e *CollectingExecutor) collect(statement CollectedStatement) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.statements = append(e.statements, statement)
+}
+
+// Statements returns the collected statements, in order.
+func (e *CollectingExecutor) Statements() []CollectedStatement {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	clone := make([]CollectedStatement, len(e.statements))
+	copy(clone, e.statements)
+	return clone
+}
+
+// Executions returns the collected statements received via ExecContext, the statements would modify the database.
+func (e *CollectingExecutor) Executions() []CollectedStatement {
+	var executions []CollectedStatement
+	for _, statement := range e.Statements() {
+		if !statement.Query {
+			executions = append(executions, statement)
+		}
+	}
+	return executions
+}
+
+// Script renders the collected statements for review, each statement is followed by the comment of its arguments.
+func (e *CollectingExecutor) Script() string {
+	sb := strings.Builder{}
+	for i, statement := range e.Statements() {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(strings.TrimRight(statement.Sql, "\n"))
+		sb.WriteString(";\n")
+		if len(statement.Args) > 0 {
+			sb.WriteString("-- args:")
+			for j, arg := range statement.Args {
+				sb.WriteString(fmt.Sprintf(" $%d=%#v", j+1, arg))
+			}
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+// noRows is the SqlRows without any row.
+type noRows struct{}
+
+func (noRows) Next() bool {
+	return false
+}
+
+func (noRows) Scan(...any) error {
+	return errors.New("no row to scan")
+}
+
+func (noRows) Close() error {
+	return nil
+}
+
+func (noRows) Err() error {
+	return nil
+}
+
+// collectedResult is the result of the collected execution, which affects no rows.
+type collectedResult struct{}
+
+func (collectedResult) LastInsertId() (int64, error) {
+	return 0, errors.New("statement was collected, not executed")
+}
+
+func (collectedResult) RowsAffected() (int64, error) {
+	return 0, nil
+}
@@ -0,0 +1,49 @@
+package sqlb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectingExecutor(t *testing.T) {
+	table1 := UseTable[testStruct1]().Seal()
+	ctx := context.Background()
+
+	t.Run("without reader", func(t *testing.T) {
+		exec := NewCollectingExecutor(nil)
+
+		rows, err := Select(table1.Col("pk1")).From(table1).QueryWithExecutor(ctx, exec)
+		require.NoError(t, err)
+		require.False(t, rows.Next())
+
+		_, err = InsertInto(table1, table1.Col("pk1"), table1.Col("amount")).
+			Values(testStruct1{Pk1: "a", Amount: 1}).
+			ExecWithExecutor(ctx, exec)
+		require.NoError(t, err)
+
+		require.Equal(t, []CollectedStatement{
+			{Sql: "SELECT table1.pk1\nFROM table1 AS table1\n", Query: true},
+			{Sql: "INSERT INTO table1 (pk1, amount)\nVALUES ($1,$2)", Args: []any{"a", 1}},
+		}, exec.Statements())
+		require.Equal(t, exec.Statements()[1:], exec.Executions())
+		require.Equal(t, `SELECT table1.pk1
+FROM table1 AS table1;
+
+INSERT INTO table1 (pk1, amount)
+VALUES ($1,$2);
+-- args: $1="a" $2=1
+`, exec.Script())
+	})
+
+	t.Run("queries are forwarded to the reader", func(t *testing.T) {
+		exec := NewCollectingExecutor(&fixedRowsExecutor{rows: [][]any{{"a"}}})
+
+		rows, err := Select(table1.Col("pk1")).From(table1).QueryWithExecutor(ctx, exec)
+		require.NoError(t, err)
+		require.True(t, rows.Next())
+		require.Len(t, exec.Statements(), 1)
+		require.Empty(t, exec.Executions())
+	})
+}
@@ -0,0 +1,54 @@
+package sqlb
+
+// ColumnGroup is a reusable set of columns (audit fields, tenant id, soft-delete marker,...),
+// defined once and attached to many tables via TableMetadataBuilder.AddColumnGroup.
+type ColumnGroup[T any] struct {
+	columns []ColumnMetadata[T]
+}
+
+// NewColumnGroup creates a column group from the column builders, the columns are copied so the builders can not alter the group later.
+func NewColumnGroup[T any](columns ...*ColumnMetadataBuilder[T]) ColumnGroup[T] {
+	if len(columns) == 0 {
+		panic("no columns in group")
+	}
+
+	group := ColumnGroup[T]{
+		columns: make([]ColumnMetadata[T], len(columns)),
+	}
+	for i, cb := range columns {
+		group.columns[i] = cb.build()
+	}
+	return group
+}
+
+// EmbedColumnGroup maps the column group of the embedded struct E into column group of the row type T,
+// via the accessor of the embedded field.
+func EmbedColumnGroup[T any, E any](group ColumnGroup[E], field func(*T) *E) ColumnGroup[T] {
+	result := ColumnGroup[T]{
+		columns: make([]ColumnMetadata[T], len(group.columns)),
+	}
+	for i, column := range group.columns {
+		result.columns[i] = embedColumn(field, column).column
+	}
+	return result
+}
+
+// ColumnsName returns name of the columns in the group.
+func (g ColumnGroup[T]) ColumnsName() []string {
+	names := make([]string, len(g.columns))
+	for i, column := range g.columns {
+		names[i] = column.name
+	}
+	return names
+}
+
+// builders returns new column builders of the group, to be added to a table.
+func (g ColumnGroup[T]) builders() []*ColumnMetadataBuilder[T] {
+	result := make([]*ColumnMetadataBuilder[T], len(g.columns))
+	for i, column := range g.columns {
+		result[i] = &ColumnMetadataBuilder[T]{
+			column: column,
+		}
+	}
+	return result
+}
@@ -0,0 +1,83 @@
+package sqlb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testAuditGroup = NewColumnGroup(testAuditColumns()...)
+
+type testGroupRow1 struct {
+	Id string
+	testAudit
+}
+
+type testGroupRow2 struct {
+	Code  int
+	Audit testAudit
+}
+
+var tableTestGroup1 = NewTableMetadata[testGroupRow1]("group_table1").
+	AddColumns(
+		NewColumnMetadata[testGroupRow1]("id").
+			PrimaryKey().
+			InsertSpec(func(r testGroupRow1) any {
+				return r.Id
+			}).
+			SelectSpec(func(r *testGroupRow1) ResultColumnSelectSpec {
+				return ResultColumnSelectSpec{
+					ToQueryArg: func() any {
+						return &r.Id
+					},
+				}
+			}),
+	).
+	AddColumnGroup(EmbedColumnGroup(testAuditGroup, func(r *testGroupRow1) *testAudit { return &r.testAudit })).
+	Build(TableMetadataBuildOption{
+		ExpectedPkColumns: []string{"id"},
+	})
+
+var tableTestGroup2 = NewTableMetadata[testGroupRow2]("group_table2").
+	AddColumnGroup(EmbedColumnGroup(testAuditGroup, func(r *testGroupRow2) *testAudit { return &r.Audit })).
+	AddColumns(
+		NewColumnMetadata[testGroupRow2]("code").
+			PrimaryKey().
+			InsertSpec(func(r testGroupRow2) any {
+				return r.Code
+			}).
+			SelectSpec(func(r *testGroupRow2) ResultColumnSelectSpec {
+				return ResultColumnSelectSpec{
+					ToQueryArg: func() any {
+						return &r.Code
+					},
+				}
+			}),
+	).
+	Build(TableMetadataBuildOption{
+		ExpectedPkColumns: []string{"code"},
+	})
+
+func TestColumnGroup(t *testing.T) {
+	require.Equal(t, []string{"created_at", "updated_at"}, testAuditGroup.ColumnsName())
+	require.Equal(t, []string{"id", "created_at", "updated_at"}, tableTestGroup1.ColumnsName())
+	require.Equal(t, []string{"created_at", "updated_at", "code"}, tableTestGroup2.ColumnsName())
+
+	updatedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	_, insertSpec := tableTestGroup2.MustGetColumnByName("updated_at").InsertSpec()
+	require.Equal(t, updatedAt, insertSpec(testGroupRow2{Audit: testAudit{UpdatedAt: updatedAt}}))
+
+	var r testGroupRow1
+	_, selectSpec := tableTestGroup1.MustGetColumnByName("updated_at").SelectSpec()
+	*selectSpec(&r).ToQueryArg().(*time.Time) = updatedAt
+	require.Equal(t, updatedAt, r.UpdatedAt)
+
+	t.Run("group is not altered by the builders", func(t *testing.T) {
+		columns := testAuditColumns()
+		group := NewColumnGroup(columns...)
+		columns[0].PrimaryKey()
+		require.False(t, group.columns[0].isPk)
+	})
+}
@@ -1,5 +1,17 @@
 package sqlb
 
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
 //goland:noinspection GoSnakeCaseUsage
 type (
 	ColumnInsertSpec[T any] func(T) (insertArg any)
@@ -16,16 +28,52 @@ type (
 )
 
 type ColumnMetadata[T any] struct {
-	name       string
-	isPk       bool // indicate this column is PK or a part of multi-columns-PK
+	columnAttributes
 	insertSpec ColumnInsertSpec[T]
 	selectSpec ColumnSelectSpec[T]
+	validator  func(T) error // optional, validates the record before inserting
+}
+
+// columnAttributes are the attributes of a column independent of the row type,
+// kept apart from the specs so converting the column to another row type (see EmbeddedColumns) copies all of them.
+type columnAttributes struct {
+	name      string
+	isPk      bool   // indicate this column is PK or a part of multi-columns-PK
+	sqlType   string // optional, data type used in DDL
+	notNull   bool
+	generated bool // computed by the database, never inserted
+	readOnly  bool // maintained by triggers or the database, never inserted
 }
 
 func (c ColumnMetadata[T]) Name() string {
 	return c.name
 }
 
+// IsPrimaryKey returns true if this column is PK or a part of multi-columns-PK.
+func (c ColumnMetadata[T]) IsPrimaryKey() bool {
+	return c.isPk
+}
+
+// SqlType returns the data type of this column used in DDL, empty if not provided.
+func (c ColumnMetadata[T]) SqlType() string {
+	return c.sqlType
+}
+
+// NotNull returns true if this column is declared as NOT NULL. Primary key columns are always NOT NULL.
+func (c ColumnMetadata[T]) NotNull() bool {
+	return c.notNull || c.isPk
+}
+
+// IsGenerated returns true if this column is computed by the database, see ColumnMetadataBuilder.Generated.
+func (c ColumnMetadata[T]) IsGenerated() bool {
+	return c.generated
+}
+
+// IsReadOnly returns true if this column is maintained by triggers or the database, see ColumnMetadataBuilder.ReadOnly.
+func (c ColumnMetadata[T]) IsReadOnly() bool {
+	return c.readOnly
+}
+
 func (c ColumnMetadata[T]) InsertSpec() (columnName string, spec ColumnInsertSpec[T]) {
 	return c.name, c.insertSpec
 }
@@ -35,7 +83,9 @@ func (c ColumnMetadata[T]) SelectSpec() (columnName string, spec ColumnSelectSpe
 }
 
 type ColumnMetadataBuilder[T any] struct {
-	column ColumnMetadata[T]
+	column        ColumnMetadata[T]
+	codec         ColumnCodec // optional, see Encrypted
+	insertDefault *Expr       // optional, see DefaultOnInsert
 }
 
 func NewColumnMetadata[T any](
@@ -43,7 +93,9 @@ func NewColumnMetadata[T any](
 ) *ColumnMetadataBuilder[T] {
 	return &ColumnMetadataBuilder[T]{
 		column: ColumnMetadata[T]{
-			name: name,
+			columnAttributes: columnAttributes{
+				name: name,
+			},
 		},
 	}
 }
@@ -60,8 +112,356 @@ func (b *ColumnMetadataBuilder[T]) InsertSpec(spec ColumnInsertSpec[T]) *ColumnM
 	return b
 }
 
+// Validator sets the function to validate the record before inserting, invoked when putting values to be inserted.
+func (b *ColumnMetadataBuilder[T]) Validator(validator func(T) error) *ColumnMetadataBuilder[T] {
+	b.column.validator = validator
+	return b
+}
+
+// SqlType sets the data type of this column, eg: BIGINT, TEXT, TIMESTAMPTZ. Used to generate DDL.
+func (b *ColumnMetadataBuilder[T]) SqlType(sqlType string) *ColumnMetadataBuilder[T] {
+	b.column.sqlType = strings.TrimSpace(sqlType)
+	return b
+}
+
+// NotNull marks this column as NOT NULL. Used to generate DDL.
+func (b *ColumnMetadataBuilder[T]) NotNull() *ColumnMetadataBuilder[T] {
+	b.column.notNull = true
+	return b
+}
+
+// Generated marks this column as computed by the database (GENERATED ALWAYS AS ... or identity),
+// it is excluded from the default INSERT columns and ON CONFLICT DO UPDATE, while remaining selectable.
+func (b *ColumnMetadataBuilder[T]) Generated() *ColumnMetadataBuilder[T] {
+	b.column.generated = true
+	return b
+}
+
+// ReadOnly marks this column as maintained by triggers or the database (e.g. updated_at set by trigger),
+// it is skipped by the default INSERT columns and ON CONFLICT DO UPDATE, and panics when included explicitly.
+func (b *ColumnMetadataBuilder[T]) ReadOnly() *ColumnMetadataBuilder[T] {
+	b.column.readOnly = true
+	return b
+}
+
+// DefaultOnInsert sets the SQL expression rendered inline in VALUES when the insert spec does not provide a value,
+// that is nil, a nil pointer or an invalid sql.Null* (or there is no insert spec), while the zero values are inserted as is,
+// eg: DefaultOnInsert(Now()) or DefaultOnInsert(NewExpr("gen_random_uuid()")).
+func (b *ColumnMetadataBuilder[T]) DefaultOnInsert(expr Expr) *ColumnMetadataBuilder[T] {
+	b.insertDefault = &expr
+	return b
+}
+
 // PrimaryKey marks this column is a part of multi-columns-PK
 func (b *ColumnMetadataBuilder[T]) PrimaryKey() *ColumnMetadataBuilder[T] {
 	b.column.isPk = true
 	return b
 }
+
+// StringArray sets the insert & select specs for TEXT[] column, mapped to the struct field.
+func (b *ColumnMetadataBuilder[T]) StringArray(field func(*T) *[]string) *ColumnMetadataBuilder[T] {
+	return b.
+		InsertSpec(func(v T) any {
+			return StringArray(*field(&v))
+		}).
+		SelectSpec(func(v *T) ResultColumnSelectSpec {
+			return ResultColumnSelectSpec{
+				ToQueryArg: func() any {
+					return (*StringArray)(field(v))
+				},
+			}
+		})
+}
+
+// Int64Array sets the insert & select specs for BIGINT[] (or INT[]) column, mapped to the struct field.
+func (b *ColumnMetadataBuilder[T]) Int64Array(field func(*T) *[]int64) *ColumnMetadataBuilder[T] {
+	return b.
+		InsertSpec(func(v T) any {
+			return Int64Array(*field(&v))
+		}).
+		SelectSpec(func(v *T) ResultColumnSelectSpec {
+			return ResultColumnSelectSpec{
+				ToQueryArg: func() any {
+					return (*Int64Array)(field(v))
+				},
+			}
+		})
+}
+
+// TimeRange sets the insert & select specs for TSTZRANGE, TSRANGE or DATERANGE column, mapped to the struct field.
+func (b *ColumnMetadataBuilder[T]) TimeRange(field func(*T) *TimeRange) *ColumnMetadataBuilder[T] {
+	return b.
+		InsertSpec(func(v T) any {
+			return *field(&v)
+		}).
+		SelectSpec(func(v *T) ResultColumnSelectSpec {
+			return ResultColumnSelectSpec{
+				ToQueryArg: func() any {
+					return field(v)
+				},
+			}
+		})
+}
+
+// Hstore sets the insert & select specs for HSTORE column, mapped to the struct field. NULL values inside are not supported.
+func (b *ColumnMetadataBuilder[T]) Hstore(field func(*T) *map[string]string) *ColumnMetadataBuilder[T] {
+	return b.
+		InsertSpec(func(v T) any {
+			return Hstore(*field(&v))
+		}).
+		SelectSpec(func(v *T) ResultColumnSelectSpec {
+			return ResultColumnSelectSpec{
+				ToQueryArg: func() any {
+					return (*Hstore)(field(v))
+				},
+			}
+		})
+}
+
+// JsonbMap sets the insert & select specs for JSON/JSONB column holds a flat object of strings, mapped to the struct field.
+// Same as JsonColumn of map[string]string.
+func (b *ColumnMetadataBuilder[T]) JsonbMap(field func(*T) *map[string]string) *ColumnMetadataBuilder[T] {
+	jsonColumn := JsonColumn[T, map[string]string](
+		b.column.name,
+		func(v T) map[string]string {
+			return *field(&v)
+		},
+		func(v *T, value map[string]string) {
+			*field(v) = value
+		},
+	).column
+	return b.
+		InsertSpec(jsonColumn.insertSpec).
+		SelectSpec(jsonColumn.selectSpec)
+}
+
+// NullableSelectSpec returns the select spec for nullable column mapped to a non-pointer field (string, int64, time.Time,...),
+// NULL is scanned as the zero value.
+func NullableSelectSpec[T any, V any](field func(*T) *V) ColumnSelectSpec[T] {
+	return func(v *T) ResultColumnSelectSpec {
+		var raw sql.Null[V]
+		return ResultColumnSelectSpec{
+			ToQueryArg: func() any {
+				return &raw
+			},
+			OptionalTransform: func() error {
+				if raw.Valid {
+					*field(v) = raw.V
+				} else {
+					var zero V
+					*field(v) = zero
+				}
+				return nil
+			},
+		}
+	}
+}
+
+// PointerSelectSpec returns the select spec for nullable column mapped to a pointer field (*string, *time.Time,...),
+// NULL is scanned as nil.
+func PointerSelectSpec[T any, V any](field func(*T) **V) ColumnSelectSpec[T] {
+	return func(v *T) ResultColumnSelectSpec {
+		return ResultColumnSelectSpec{
+			ToQueryArg: func() any {
+				return field(v)
+			},
+		}
+	}
+}
+
+// NullableInsertSpec returns the insert spec for nullable column mapped to a non-pointer field, zero value is inserted as NULL.
+func NullableInsertSpec[T any, V comparable](field func(*T) *V) ColumnInsertSpec[T] {
+	return func(v T) any {
+		var zero V
+		if value := *field(&v); value != zero {
+			return value
+		}
+		return nil
+	}
+}
+
+// PointerInsertSpec returns the insert spec for nullable column mapped to a pointer field, nil is inserted as NULL.
+func PointerInsertSpec[T any, V any](field func(*T) **V) ColumnInsertSpec[T] {
+	return func(v T) any {
+		if value := *field(&v); value != nil {
+			return *value
+		}
+		return nil
+	}
+}
+
+// JsonColumn returns the metadata builder of JSON/JSONB column mapped to the struct field,
+// the field value is marshalled on insert and unmarshalled on select. NULL is scanned as the zero value.
+func JsonColumn[T any, F any](name string, get func(T) F, set func(*T, F)) *ColumnMetadataBuilder[T] {
+	return NewColumnMetadata[T](name).
+		InsertSpec(func(v T) any {
+			return jsonValue{value: get(v)}
+		}).
+		SelectSpec(func(v *T) ResultColumnSelectSpec {
+			var raw []byte
+			return ResultColumnSelectSpec{
+				ToQueryArg: func() any {
+					return &raw
+				},
+				OptionalTransform: func() error {
+					var value F
+					if raw != nil {
+						if err := json.Unmarshal(raw, &value); err != nil {
+							return errors.Wrapf(err, "failed to unmarshal JSON column %s", name)
+						}
+					}
+					set(v, value)
+					return nil
+				},
+			}
+		})
+}
+
+// jsonValue marshals the value when being bound as argument.
+type jsonValue struct {
+	value any
+}
+
+var _ driver.Valuer = jsonValue{}
+
+func (j jsonValue) Value() (driver.Value, error) {
+	bz, err := json.Marshal(j.value)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal JSON value")
+	}
+	return string(bz), nil
+}
+
+// Numeric sets the insert & select specs for NUMERIC column mapped to *big.Rat field, nil is NULL.
+//
+// The value is inserted as a decimal string with the given scale (number of digits after the decimal point),
+// values with more digits are rounded to the nearest, halves away from zero.
+func (b *ColumnMetadataBuilder[T]) Numeric(field func(*T) **big.Rat, scale int) *ColumnMetadataBuilder[T] {
+	if scale < 0 {
+		panic("scale must not be negative")
+	}
+	return b.
+		InsertSpec(func(v T) any {
+			if value := *field(&v); value != nil {
+				return value.FloatString(scale)
+			}
+			return nil
+		}).
+		SelectSpec(func(v *T) ResultColumnSelectSpec {
+			var raw sql.NullString
+			return ResultColumnSelectSpec{
+				ToQueryArg: func() any {
+					return &raw
+				},
+				OptionalTransform: func() error {
+					if !raw.Valid {
+						*field(v) = nil
+						return nil
+					}
+					value, ok := new(big.Rat).SetString(raw.String)
+					if !ok {
+						return errors.Errorf("failed to parse numeric value: %s", raw.String)
+					}
+					*field(v) = value
+					return nil
+				},
+			}
+		})
+}
+
+// EnumColumn returns the metadata builder of enum column (TEXT, VARCHAR or PostgreSQL ENUM) mapped to the string-based struct field.
+// Only the allowed values can be inserted, inserting or selecting other values results in error.
+func EnumColumn[T any, E ~string](name string, field func(*T) *E, allowed ...E) *ColumnMetadataBuilder[T] {
+	if len(allowed) == 0 {
+		panic(fmt.Sprintf("no allowed values for enum column %s", name))
+	}
+	allowedSet := make(map[E]struct{}, len(allowed))
+	for _, value := range allowed {
+		allowedSet[value] = struct{}{}
+	}
+	validate := func(value E) error {
+		if _, found := allowedSet[value]; !found {
+			return errors.Errorf("invalid value %q for enum column %s, allowed values: %q", string(value), name, allowed)
+		}
+		return nil
+	}
+
+	return NewColumnMetadata[T](name).
+		InsertSpec(func(v T) any {
+			return string(*field(&v))
+		}).
+		SelectSpec(func(v *T) ResultColumnSelectSpec {
+			var raw string
+			return ResultColumnSelectSpec{
+				ToQueryArg: func() any {
+					return &raw
+				},
+				OptionalTransform: func() error {
+					if err := validate(E(raw)); err != nil {
+						return err
+					}
+					*field(v) = E(raw)
+					return nil
+				},
+			}
+		}).
+		Validator(func(v T) error {
+			return validate(*field(&v))
+		})
+}
+
+// UuidPrimaryKey marks this column as PK and sets the insert & select specs for UUID column mapped to the string field.
+//
+// When the field is empty, a new UUID is generated on insert, either by the client or by the database (UuidOption.ServerSide).
+// The generated value is not written back to the struct, assign it beforehand via NewUuidV4/NewUuidV7 if the value is needed.
+func (b *ColumnMetadataBuilder[T]) UuidPrimaryKey(field func(*T) *string, opt UuidOption) *ColumnMetadataBuilder[T] {
+	if opt.Version != 0 && opt.Version != UuidV4 && opt.Version != UuidV7 {
+		panic(fmt.Sprintf("unsupported UUID version %d", opt.Version))
+	}
+	return b.
+		PrimaryKey().
+		InsertSpec(func(v T) any {
+			if value := *field(&v); value != "" {
+				return value
+			}
+			if opt.ServerSide {
+				return NewExpr("gen_random_uuid()")
+			}
+			return newUuid(opt.Version)
+		}).
+		SelectSpec(func(v *T) ResultColumnSelectSpec {
+			return ResultColumnSelectSpec{
+				ToQueryArg: func() any {
+					return field(v)
+				},
+			}
+		})
+}
+
+// insertSpecWithDefault wraps the insert spec, the default expression is used when the value is not provided, see isNullInsertValue.
+func insertSpecWithDefault[T any](insertSpec ColumnInsertSpec[T], defaultExpr Expr) ColumnInsertSpec[T] {
+	return func(v T) any {
+		if insertSpec != nil {
+			if value := insertSpec(v); !isNullInsertValue(value) {
+				return value
+			}
+		}
+		return defaultExpr
+	}
+}
+
+// isNullInsertValue returns true if the value is nil, a nil pointer or a driver.Valuer of NULL (e.g. an invalid sql.NullString).
+// The zero values, e.g. 0 or "", are provided values.
+func isNullInsertValue(value any) bool {
+	if value == nil {
+		return true
+	}
+	if rv := reflect.ValueOf(value); rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return true
+	}
+	if valuer, ok := value.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		return err == nil && v == nil
+	}
+	return false
+}
@@ -0,0 +1,421 @@
+package sqlb
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNullableSpecs(t *testing.T) {
+	type row struct {
+		Note      string
+		Count     int64
+		DeletedAt *time.Time
+	}
+
+	noteSelect := NullableSelectSpec(func(r *row) *string { return &r.Note })
+	countSelect := NullableSelectSpec(func(r *row) *int64 { return &r.Count })
+	deletedAtSelect := PointerSelectSpec(func(r *row) **time.Time { return &r.DeletedAt })
+
+	scan := func(spec ColumnSelectSpec[row], r *row, src any) {
+		rs := spec(r)
+		require.NoError(t, rs.ToQueryArg().(sql.Scanner).Scan(src))
+		if rs.OptionalTransform != nil {
+			require.NoError(t, rs.OptionalTransform())
+		}
+	}
+
+	t.Run("select NULL", func(t *testing.T) {
+		r := row{Note: "x", Count: 1}
+		scan(noteSelect, &r, nil)
+		scan(countSelect, &r, nil)
+		require.Equal(t, row{}, r)
+
+		// pointer destination is handled by database/sql directly
+		require.IsType(t, (**time.Time)(nil), deletedAtSelect(&r).ToQueryArg())
+	})
+
+	t.Run("select value", func(t *testing.T) {
+		var r row
+		scan(noteSelect, &r, "note")
+		scan(countSelect, &r, int64(2))
+		require.Equal(t, row{Note: "note", Count: 2}, r)
+	})
+
+	t.Run("insert", func(t *testing.T) {
+		noteInsert := NullableInsertSpec(func(r *row) *string { return &r.Note })
+		deletedAtInsert := PointerInsertSpec(func(r *row) **time.Time { return &r.DeletedAt })
+
+		require.Nil(t, noteInsert(row{}))
+		require.Equal(t, "a", noteInsert(row{Note: "a"}))
+
+		now := time.Now()
+		require.Nil(t, deletedAtInsert(row{}))
+		require.Equal(t, now, deletedAtInsert(row{DeletedAt: &now}))
+	})
+}
+
+func TestJsonColumn(t *testing.T) {
+	type settings struct {
+		Theme string   `json:"theme"`
+		Tags  []string `json:"tags,omitempty"`
+	}
+	type row struct {
+		Settings settings
+	}
+
+	column := JsonColumn[row]("settings",
+		func(r row) settings { return r.Settings },
+		func(r *row, s settings) { r.Settings = s },
+	).column
+
+	t.Run("insert", func(t *testing.T) {
+		value, err := column.insertSpec(row{Settings: settings{Theme: "dark"}}).(driver.Valuer).Value()
+		require.NoError(t, err)
+		require.Equal(t, `{"theme":"dark"}`, value)
+	})
+
+	t.Run("select", func(t *testing.T) {
+		var r row
+		rs := column.selectSpec(&r)
+		*rs.ToQueryArg().(*[]byte) = []byte(`{"theme":"light","tags":["a"]}`)
+		require.NoError(t, rs.OptionalTransform())
+		require.Equal(t, settings{Theme: "light", Tags: []string{"a"}}, r.Settings)
+	})
+
+	t.Run("select NULL", func(t *testing.T) {
+		r := row{Settings: settings{Theme: "dark"}}
+		rs := column.selectSpec(&r)
+		_ = rs.ToQueryArg()
+		require.NoError(t, rs.OptionalTransform())
+		require.Equal(t, settings{}, r.Settings)
+	})
+
+	t.Run("select invalid JSON", func(t *testing.T) {
+		var r row
+		rs := column.selectSpec(&r)
+		*rs.ToQueryArg().(*[]byte) = []byte(`{`)
+		require.Error(t, rs.OptionalTransform())
+	})
+}
+
+func TestColumnMetadataBuilder_Numeric(t *testing.T) {
+	type row struct {
+		Price *big.Rat
+	}
+
+	column := NewColumnMetadata[row]("price").Numeric(func(r *row) **big.Rat { return &r.Price }, 2).column
+
+	t.Run("insert", func(t *testing.T) {
+		require.Equal(t, "12.35", column.insertSpec(row{Price: big.NewRat(12345, 1000)}))
+		require.Equal(t, "0.33", column.insertSpec(row{Price: big.NewRat(1, 3)}))
+		require.Nil(t, column.insertSpec(row{}))
+	})
+
+	t.Run("select", func(t *testing.T) {
+		var r row
+		rs := column.selectSpec(&r)
+		require.NoError(t, rs.ToQueryArg().(sql.Scanner).Scan([]byte("12.345")))
+		require.NoError(t, rs.OptionalTransform())
+		require.Equal(t, 0, big.NewRat(12345, 1000).Cmp(r.Price))
+
+		rs = column.selectSpec(&r)
+		require.NoError(t, rs.ToQueryArg().(sql.Scanner).Scan(nil))
+		require.NoError(t, rs.OptionalTransform())
+		require.Nil(t, r.Price)
+
+		rs = column.selectSpec(&r)
+		require.NoError(t, rs.ToQueryArg().(sql.Scanner).Scan("NaN"))
+		require.Error(t, rs.OptionalTransform())
+	})
+}
+
+type testOrderStatus string
+
+type testEnumRow struct {
+	Id     string
+	Status testOrderStatus
+}
+
+var tableTestEnum = NewTableMetadata[testEnumRow]("orders").
+	AddColumns(
+		NewColumnMetadata[testEnumRow]("id").
+			PrimaryKey().
+			InsertSpec(func(r testEnumRow) any {
+				return r.Id
+			}).
+			SelectSpec(func(r *testEnumRow) ResultColumnSelectSpec {
+				return ResultColumnSelectSpec{
+					ToQueryArg: func() any {
+						return &r.Id
+					},
+				}
+			}),
+		EnumColumn("status", func(r *testEnumRow) *testOrderStatus { return &r.Status }, "pending", "paid"),
+	).Build(TableMetadataBuildOption{
+	ExpectedPkColumns: []string{"id"},
+})
+
+func TestEnumColumn(t *testing.T) {
+	_, insertSpec := tableTestEnum.MustGetColumnByName("status").InsertSpec()
+	_, selectSpec := tableTestEnum.MustGetColumnByName("status").SelectSpec()
+
+	t.Run("insert", func(t *testing.T) {
+		require.Equal(t, "paid", insertSpec(testEnumRow{Status: "paid"}))
+	})
+
+	t.Run("select", func(t *testing.T) {
+		var r testEnumRow
+		rs := selectSpec(&r)
+		*rs.ToQueryArg().(*string) = "pending"
+		require.NoError(t, rs.OptionalTransform())
+		require.Equal(t, testOrderStatus("pending"), r.Status)
+
+		rs = selectSpec(&r)
+		*rs.ToQueryArg().(*string) = "refunded"
+		require.ErrorContains(t, rs.OptionalTransform(), `invalid value "refunded" for enum column status`)
+		require.Equal(t, testOrderStatus("pending"), r.Status)
+	})
+
+	t.Run("validate values", func(t *testing.T) {
+		orders := UseTable[testEnumRow]().Alias("o").Seal()
+
+		require.NotPanics(t, func() {
+			InsertInto(orders).Values(testEnumRow{Id: "1", Status: "paid"})
+		})
+		require.PanicsWithValue(t, `invalid value at index 1: invalid value "" for enum column status, allowed values: ["pending" "paid"]`, func() {
+			InsertInto(orders).Values(testEnumRow{Id: "1", Status: "paid"}, testEnumRow{Id: "2"})
+		})
+		require.NotPanics(t, func() {
+			InsertInto(orders, orders.Col("id")).Values(testEnumRow{Id: "2"})
+		}, "columns not being inserted are not validated")
+
+		require.Panics(t, func() {
+			EnumColumn[testEnumRow, testOrderStatus]("status", func(r *testEnumRow) *testOrderStatus { return &r.Status })
+		}, "must provide allowed values")
+	})
+}
+
+func TestColumnMetadataBuilder_UuidPrimaryKey(t *testing.T) {
+	type row struct {
+		Id string
+	}
+
+	clientSide := NewColumnMetadata[row]("id").UuidPrimaryKey(func(r *row) *string { return &r.Id }, UuidOption{Version: UuidV7}).column
+	serverSide := NewColumnMetadata[row]("id").UuidPrimaryKey(func(r *row) *string { return &r.Id }, UuidOption{ServerSide: true}).column
+
+	require.True(t, clientSide.isPk)
+	require.Equal(t, "0190a1b2-0000-7000-8000-000000000000", clientSide.insertSpec(row{Id: "0190a1b2-0000-7000-8000-000000000000"}))
+	require.Equal(t, "0190a1b2-0000-7000-8000-000000000000", serverSide.insertSpec(row{Id: "0190a1b2-0000-7000-8000-000000000000"}))
+
+	generated := clientSide.insertSpec(row{}).(string)
+	require.Len(t, generated, 36)
+	require.Equal(t, byte('7'), generated[14])
+
+	require.Equal(t, NewExpr("gen_random_uuid()"), serverSide.insertSpec(row{}))
+
+	var r row
+	*clientSide.selectSpec(&r).ToQueryArg().(*string) = "x"
+	require.Equal(t, "x", r.Id)
+
+	require.Panics(t, func() {
+		NewColumnMetadata[row]("id").UuidPrimaryKey(func(r *row) *string { return &r.Id }, UuidOption{Version: 5})
+	})
+}
+
+type testUuidRow struct {
+	Id   string
+	Note string
+}
+
+var tableTestUuid = NewTableMetadata[testUuidRow]("devices").
+	AddColumns(
+		NewColumnMetadata[testUuidRow]("id").UuidPrimaryKey(func(r *testUuidRow) *string { return &r.Id }, UuidOption{ServerSide: true}),
+		NewColumnMetadata[testUuidRow]("note").
+			InsertSpec(func(r testUuidRow) any {
+				return r.Note
+			}).
+			SelectSpec(func(r *testUuidRow) ResultColumnSelectSpec {
+				return ResultColumnSelectSpec{
+					ToQueryArg: func() any {
+						return &r.Note
+					},
+				}
+			}),
+	).Build(TableMetadataBuildOption{
+	ExpectedPkColumns: []string{"id"},
+})
+
+func TestSqlBuilder_buildInsert_serverSideUuid(t *testing.T) {
+	devices := UseTable[testUuidRow]().Seal()
+
+	sql, args := InsertInto(devices).Values(
+		testUuidRow{Note: "a"},
+		testUuidRow{Id: "0190a1b2-0000-7000-8000-000000000000", Note: "b"},
+	).Build()
+	require.Equal(t, `INSERT INTO devices (id, note)
+VALUES (gen_random_uuid(),$1),($2,$3)`, sql)
+	require.Equal(t, []any{"a", "0190a1b2-0000-7000-8000-000000000000", "b"}, args)
+}
+
+type testGeneratedRow struct {
+	Id       int64
+	Quantity int64
+	Total    int64
+}
+
+var tableTestGenerated = NewTableMetadata[testGeneratedRow]("cart_items").
+	AddColumns(
+		NewColumnMetadata[testGeneratedRow]("id").
+			PrimaryKey().
+			InsertSpec(func(r testGeneratedRow) any {
+				return r.Id
+			}),
+		NewColumnMetadata[testGeneratedRow]("quantity").
+			InsertSpec(func(r testGeneratedRow) any {
+				return r.Quantity
+			}),
+		NewColumnMetadata[testGeneratedRow]("total").
+			SqlType("BIGINT GENERATED ALWAYS AS (quantity * 10) STORED").
+			Generated(),
+	).
+	Build(TableMetadataBuildOption{
+		ExpectedPkColumns: []string{"id"},
+	})
+
+func TestColumnMetadataBuilder_Generated(t *testing.T) {
+	require.True(t, tableTestGenerated.MustGetColumnByName("total").IsGenerated())
+	require.False(t, tableTestGenerated.MustGetColumnByName("quantity").IsGenerated())
+
+	items := UseTable[testGeneratedRow]().Seal()
+
+	gotSql, gotArgs := InsertInto(items).
+		Values(testGeneratedRow{Id: 1, Quantity: 2, Total: 99}).
+		OnConflict(items.PrimaryKeyColumns()...).
+		DoUpdateExceptPrimaryKeys().
+		Build()
+	require.Equal(t, "INSERT INTO cart_items (id, quantity)\nVALUES ($1,$2)\nON CONFLICT (id) DO UPDATE SET\n quantity = excluded.quantity", gotSql)
+	require.Equal(t, []any{int64(1), int64(2)}, gotArgs)
+
+	gotSql, _ = Select(items.Columns()...).From(items).Build()
+	require.Equal(t, "SELECT cart_items.id, cart_items.quantity, cart_items.total\nFROM cart_items AS cart_items\n", gotSql)
+
+	require.PanicsWithValue(t, "column total is generated, cannot be inserted", func() {
+		InsertInto(items, items.Col("id"), items.Col("total"))
+	})
+	require.PanicsWithValue(t, "column total is generated, cannot be updated", func() {
+		InsertInto(items).Values(testGeneratedRow{}).OnConflict(items.Col("id")).DoUpdateSet(items.Col("total"), 0)
+	})
+}
+
+type testReadOnlyRow struct {
+	Id        int64
+	Name      string
+	UpdatedAt time.Time
+}
+
+var tableTestReadOnly = NewTableMetadata[testReadOnlyRow]("profiles").
+	AddColumns(
+		NewColumnMetadata[testReadOnlyRow]("id").
+			PrimaryKey().
+			InsertSpec(func(r testReadOnlyRow) any {
+				return r.Id
+			}),
+		NewColumnMetadata[testReadOnlyRow]("display_name").
+			InsertSpec(func(r testReadOnlyRow) any {
+				return r.Name
+			}),
+		NewColumnMetadata[testReadOnlyRow]("updated_at").
+			ReadOnly(),
+	).
+	Build(TableMetadataBuildOption{
+		ExpectedPkColumns: []string{"id"},
+	})
+
+func TestColumnMetadataBuilder_ReadOnly(t *testing.T) {
+	require.True(t, tableTestReadOnly.MustGetColumnByName("updated_at").IsReadOnly())
+	require.False(t, tableTestReadOnly.MustGetColumnByName("display_name").IsReadOnly())
+
+	profiles := UseTable[testReadOnlyRow]().Seal()
+
+	gotSql, _ := InsertInto(profiles).
+		Values(testReadOnlyRow{Id: 1, Name: "a"}).
+		OnConflict(profiles.PrimaryKeyColumns()...).
+		DoUpdateExceptPrimaryKeys().
+		Build()
+	require.Equal(t, "INSERT INTO profiles (id, display_name)\nVALUES ($1,$2)\nON CONFLICT (id) DO UPDATE SET\n display_name = excluded.display_name", gotSql)
+
+	require.PanicsWithValue(t, "column updated_at is read-only, cannot be inserted", func() {
+		InsertInto(profiles, profiles.Col("id"), profiles.Col("updated_at"))
+	})
+	require.PanicsWithValue(t, "column updated_at is read-only, cannot be updated", func() {
+		InsertInto(profiles).Values(testReadOnlyRow{}).OnConflict(profiles.Col("id")).DoUpdateSet(profiles.Col("updated_at"), Now())
+	})
+	require.PanicsWithValue(t, "column updated_at is read-only, cannot be updated", func() {
+		InsertInto(profiles).Values(testReadOnlyRow{}).OnConflict(profiles.Col("id")).
+			DoUpdate(profiles.Col("display_name"), "=", profiles.Col("updated_at")).
+			DoUpdate(profiles.Col("updated_at"), "=", Now())
+	})
+}
+
+type testDefaultRow struct {
+	Id        int64
+	Token     string
+	CreatedAt *time.Time
+	ExpiresAt sql.NullTime
+	Attempts  int
+}
+
+var tableTestDefault = NewTableMetadata[testDefaultRow]("sessions").
+	AddColumns(
+		NewColumnMetadata[testDefaultRow]("id").
+			PrimaryKey().
+			InsertSpec(func(r testDefaultRow) any {
+				return r.Id
+			}),
+		NewColumnMetadata[testDefaultRow]("token").
+			DefaultOnInsert(NewExpr("gen_random_uuid()")),
+		NewColumnMetadata[testDefaultRow]("created_at").
+			InsertSpec(func(r testDefaultRow) any {
+				return r.CreatedAt
+			}).
+			DefaultOnInsert(Now()),
+		NewColumnMetadata[testDefaultRow]("expires_at").
+			InsertSpec(func(r testDefaultRow) any {
+				return r.ExpiresAt
+			}).
+			DefaultOnInsert(NewExpr("NOW() + INTERVAL '1 day'")),
+		NewColumnMetadata[testDefaultRow]("attempts").
+			InsertSpec(func(r testDefaultRow) any {
+				return r.Attempts
+			}).
+			DefaultOnInsert(NewExpr("3")),
+	).
+	Build(TableMetadataBuildOption{
+		ExpectedPkColumns: []string{"id"},
+	})
+
+func TestColumnMetadataBuilder_DefaultOnInsert(t *testing.T) {
+	sessions := UseTable[testDefaultRow]().Seal()
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	expiresAt := sql.NullTime{Time: createdAt, Valid: true}
+
+	gotSql, gotArgs := InsertInto(sessions).
+		Values(testDefaultRow{Id: 1}, testDefaultRow{Id: 2, CreatedAt: &createdAt, ExpiresAt: expiresAt, Attempts: 1}).
+		Build()
+	require.Equal(t, "INSERT INTO sessions (id, token, created_at, expires_at, attempts)\nVALUES ($1,gen_random_uuid(),NOW(),NOW() + INTERVAL '1 day',$2),($3,gen_random_uuid(),$4,$5,$6)", gotSql)
+	require.Equal(t, []any{int64(1), 0, int64(2), &createdAt, expiresAt, 1}, gotArgs, "the zero value is provided")
+
+	require.Panics(t, func() {
+		NewTableMetadata[testDefaultRow]("sessions_encrypted").
+			AddColumns(
+				NewColumnMetadata[testDefaultRow]("id").PrimaryKey(),
+				NewColumnMetadata[testDefaultRow]("token").Encrypted(prefixCodec{}).DefaultOnInsert(Now()),
+			).
+			Build(TableMetadataBuildOption{ExpectedPkColumns: []string{"id"}})
+	})
+}
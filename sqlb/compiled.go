@@ -0,0 +1,179 @@
+package sqlb
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+// ErrUnboundSlot is the cause of the errors of binding a compiled query without the value of a slot.
+var ErrUnboundSlot = errors.New("slot is not bound")
+
+// ErrUnknownSlot is the cause of the errors of binding a value to a slot the compiled query does not have.
+var ErrUnknownSlot = errors.New("unknown slot")
+
+// slot is the placeholder of a named argument, the value is provided when binding the compiled query.
+type slot struct {
+	name string
+}
+
+// Slot is a named argument of a compiled query, the value is provided by CompiledQuery.Bind.
+// It can be used as a token, a value of the predicates or an argument of Args,
+// e.g. Where(Eq(table.Col("owner"), Slot("owner"))).
+// The same slot can be used multiple times, all positions are bound to the same value.
+func Slot(name string) Expr {
+	if name == "" {
+		panic("slot name is empty")
+	}
+	return Arg(slot{name: name})
+}
+
+// slotOf returns the slot of the argument, the argument is either the slot or the Slot expression provided to Args.
+func slotOf(arg any) (slot, bool) {
+	switch v := arg.(type) {
+	case slot:
+		return v, true
+	case Expr:
+		if len(v.tokens) == 1 {
+			if ba, ok := v.tokens[0].(boundArg); ok {
+				s, ok := ba.value.(slot)
+				return s, ok
+			}
+		}
+	}
+	return slot{}, false
+}
+
+// unboundSlots returns ErrUnboundSlot if any of the arguments is a slot, the slots are bound by the compiled query only.
+func unboundSlots(args []any) error {
+	for _, arg := range args {
+		if s, ok := slotOf(arg); ok {
+			return errors.Wrapf(ErrUnboundSlot, "slot %s, bind it via Compile", s.name)
+		}
+	}
+	return nil
+}
+
+// CompiledQuery is the statement rendered once by Compile, executed many times with different slot values.
+// It is immutable and safe for concurrent use.
+type CompiledQuery struct {
+	b     *SqlBuilder // b is the snapshot of the builder at compile time, the options set later are not applied
+	sql   string
+	args  []any            // args are the fixed arguments, the positions of the slots are nil
+	slots map[string][]int // slots are the positions in args of each slot
+	names []string         // names of the slots in order of the first appearance
+}
+
+// Compile renders the statement and freezes the builder, the builder can not be modified anymore.
+// The named arguments declared by Slot are bound on each execution without rendering the statement again,
+// useful for the hot paths executing the same query with different arguments.
+//
+// The options, e.g. Cache, Retry, Metrics, WithDialect, Timeout, MaxRows, are captured at compile time,
+// the options set on the builder afterward do not affect the compiled query.
+func (b *SqlBuilder) Compile() (*CompiledQuery, error) {
+	stmt, args, err := b.tryBuild(b.build)
+	if err != nil {
+		return nil, err
+	}
+	b.compiled = true
+	snapshot := *b
+
+	q := &CompiledQuery{
+		b:     &snapshot,
+		sql:   stmt,
+		args:  args,
+		slots: make(map[string][]int),
+	}
+	for i, arg := range args {
+		s, ok := slotOf(arg)
+		if !ok {
+			continue
+		}
+		if _, found := q.slots[s.name]; !found {
+			q.names = append(q.names, s.name)
+		}
+		q.slots[s.name] = append(q.slots[s.name], i)
+		args[i] = nil
+	}
+	return q, nil
+}
+
+// Sql returns the compiled statement.
+func (q *CompiledQuery) Sql() string {
+	return q.sql
+}
+
+// Slots returns the names of the slots, in order of the first appearance in the statement.
+func (q *CompiledQuery) Slots() []string {
+	return append([]string(nil), q.names...)
+}
+
+// Bind returns the arguments of the statement with the slots bound to the given values.
+// Every slot must be bound and every value must belong to a slot, see ErrUnboundSlot and ErrUnknownSlot.
+func (q *CompiledQuery) Bind(args map[string]any) ([]any, error) {
+	for name := range args {
+		if _, found := q.slots[name]; !found {
+			return nil, errors.Wrapf(ErrUnknownSlot, "slot %s", name)
+		}
+	}
+
+	bound := make([]any, len(q.args))
+	copy(bound, q.args)
+	for _, name := range q.names {
+		value, found := args[name]
+		if !found {
+			return nil, errors.Wrapf(ErrUnboundSlot, "slot %s", name)
+		}
+		for _, i := range q.slots[name] {
+			bound[i] = value
+		}
+	}
+	return bound, nil
+}
+
+// QueryWithExecutor executes the compiled SELECT statement with the slots bound to the given values and scans the result rows.
+// The options of the builder, e.g. Cache, Retry, Metrics, are applied the same as SqlBuilder.QueryWithExecutor.
+func (q *CompiledQuery) QueryWithExecutor(ctx context.Context, exec Executor, args map[string]any) (*ScannedRows, error) {
+	q.b.mustBasicSelect()
+	bound, err := q.Bind(args)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := q.b.query(ctx, exec, resultKindRows, q.sql, bound, func(ctx context.Context) (any, error) {
+		return q.b.scanRows(exec.QueryContext(ctx, q.sql, bound...))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return value.(*ScannedRows), nil
+}
+
+// ExecWithExecutor executes the compiled INSERT statement with the slots bound to the given values.
+// The options of the builder are applied the same as SqlBuilder.ExecWithExecutor.
+func (q *CompiledQuery) ExecWithExecutor(ctx context.Context, exec Executor, args map[string]any) (sql.Result, error) {
+	q.b.mustTypeInsert()
+	q.b.mustNotifySupported()
+	bound, err := q.Bind(args)
+	if err != nil {
+		return nil, err
+	}
+
+	var result sql.Result
+	err = q.b.execute(ctx, exec, q.sql, bound, true, func(ctx context.Context) (err error) {
+		result, err = exec.ExecContext(ctx, q.sql, bound...)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	q.b.invalidateCache(exec)
+
+	if err := q.b.notify(ctx, exec, result); err != nil {
+		return result, err
+	}
+	return result, nil
+}
@@ -0,0 +1,113 @@
+package sqlb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSqlBuilder_Compile(t *testing.T) {
+	table1 := UseTable[testStruct1]().Seal()
+	ctx := context.Background()
+
+	builder := Select(table1.Col("pk1"), table1.Col("amount")).
+		From(table1).
+		Where(Eq(table1.Col("pk1"), Slot("pk1"))).
+		And(table1.Col("pk2"), "=", 2).
+		And(table1.Col("amount"), "> $1 OR", table1.Col("cost"), "> $2").
+		Args(Slot("min"), Slot("min"))
+	compiled, err := builder.Compile()
+	require.NoError(t, err)
+
+	require.Equal(t, "SELECT table1.pk1, table1.amount\nFROM table1 AS table1\nWHERE table1.pk1 = $3 AND table1.pk2 = 2 AND table1.amount > $1 OR table1.cost > $2\n", compiled.Sql())
+	require.Equal(t, []string{"min", "pk1"}, compiled.Slots())
+
+	t.Run("bind", func(t *testing.T) {
+		args, err := compiled.Bind(map[string]any{"pk1": "a", "min": 10})
+		require.NoError(t, err)
+		require.Equal(t, []any{10, 10, "a"}, args)
+
+		args, err = compiled.Bind(map[string]any{"pk1": "b", "min": 20})
+		require.NoError(t, err)
+		require.Equal(t, []any{20, 20, "b"}, args)
+	})
+
+	t.Run("unbound slot", func(t *testing.T) {
+		_, err := compiled.Bind(map[string]any{"pk1": "a"})
+		require.True(t, errors.Is(err, ErrUnboundSlot))
+		require.EqualError(t, err, "slot min: slot is not bound")
+	})
+
+	t.Run("unknown slot", func(t *testing.T) {
+		_, err := compiled.Bind(map[string]any{"pk1": "a", "min": 1, "max": 2})
+		require.True(t, errors.Is(err, ErrUnknownSlot))
+		require.EqualError(t, err, "slot max: unknown slot")
+	})
+
+	t.Run("the builder is frozen", func(t *testing.T) {
+		require.PanicsWithValue(t, "the builder is compiled, it can not be modified", func() {
+			builder.And(table1.Col("pk2"), "= 3")
+		})
+	})
+
+	t.Run("query", func(t *testing.T) {
+		exec := NewCollectingExecutor(nil)
+		_, err := compiled.QueryWithExecutor(ctx, exec, map[string]any{"pk1": "a", "min": 10})
+		require.NoError(t, err)
+		_, err = compiled.QueryWithExecutor(ctx, exec, map[string]any{"pk1": "a"})
+		require.True(t, errors.Is(err, ErrUnboundSlot))
+
+		require.Equal(t, []CollectedStatement{
+			{Sql: compiled.Sql(), Args: []any{10, 10, "a"}, Query: true},
+		}, exec.Statements())
+	})
+
+	t.Run("exec", func(t *testing.T) {
+		compiled, err := InsertInto(table1, table1.Col("pk1"), table1.Col("pk2"), table1.Col("amount")).
+			Values(testStruct1{Pk1: "a", Pk2: 1, Amount: 1}).
+			OnConflict(table1.Col("pk1"), table1.Col("pk2")).
+			DoUpdate(table1.Col("amount"), "=", table1.Col("amount").NameWithTableName(), "+", Slot("delta")).
+			Compile()
+		require.NoError(t, err)
+
+		exec := NewCollectingExecutor(nil)
+		_, err = compiled.ExecWithExecutor(ctx, exec, map[string]any{"delta": 5})
+		require.NoError(t, err)
+		require.Equal(t, []CollectedStatement{
+			{Sql: "INSERT INTO table1 (pk1, pk2, amount)\nVALUES ($1,$2,$3)\nON CONFLICT (pk1, pk2) DO UPDATE SET\n amount = table1.amount + $4", Args: []any{"a", 1, 1, 5}},
+		}, exec.Statements())
+	})
+
+	t.Run("options are captured at compile time", func(t *testing.T) {
+		builder := Select(table1.Col("pk1")).From(table1).Where(Eq(table1.Col("pk1"), Slot("pk1"))).MaxRows(1)
+		compiled, err := builder.Compile()
+		require.NoError(t, err)
+		builder.WithDialect(DialectMySQL).MaxRows(10).Timeout(time.Second)
+
+		require.Equal(t, "SELECT table1.pk1\nFROM table1 AS table1\nWHERE table1.pk1 = $1\n", compiled.Sql())
+		require.Equal(t, DialectPostgres, compiled.b.dialect)
+		require.Equal(t, 1, compiled.b.maxRows)
+		require.Zero(t, compiled.b.timeout)
+	})
+
+	t.Run("slots are not bound by the builder", func(t *testing.T) {
+		require.PanicsWithError(t, "slot min, bind it via Compile: slot is not bound", func() {
+			builder.Build()
+		})
+		_, _, err := builder.TryBuild()
+		require.True(t, errors.Is(err, ErrUnboundSlot))
+
+		exec := NewCollectingExecutor(nil)
+		_, err = builder.QueryWithExecutor(ctx, exec)
+		require.True(t, errors.Is(err, ErrUnboundSlot))
+		require.Empty(t, exec.Statements())
+	})
+
+	t.Run("invalid builder", func(t *testing.T) {
+		_, err := Select(table1.Col("pk1")).From(table1, UseTable[testStruct2]().Alias("table1").Seal()).Compile()
+		require.True(t, errors.Is(err, ErrAliasConflict))
+	})
+}
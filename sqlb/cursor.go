@@ -0,0 +1,117 @@
+package sqlb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/rand/v2"
+
+	"github.com/pkg/errors"
+)
+
+// Cursor iterates the result rows of the SELECT statement in batches, via a server-side cursor (PostgreSQL),
+// so multi-million-row exports don't require LIMIT/OFFSET loops.
+//
+//	cursor, err := builder.QueryCursor(ctx, tx, 1000)
+//	if err != nil { ... }
+//	defer cursor.Close(ctx)
+//	for cursor.Next(ctx) {
+//		rows := cursor.Rows()
+//		...
+//	}
+//	if err := cursor.Err(); err != nil { ... }
+type Cursor struct {
+	b         *SqlBuilder
+	exec      Executor
+	name      string
+	fetchSize int
+	rows      *ScannedRows
+	done      bool
+	closed    bool
+	err       error
+}
+
+// QueryCursor declares a cursor for the SELECT statement within the transaction, the rows are fetched in batches of fetchSize.
+// The cursor lives until it is closed or the transaction ends.
+func (b *SqlBuilder) QueryCursor(ctx context.Context, sqlTx *sql.Tx, fetchSize int) (*Cursor, error) {
+	return b.QueryCursorWithExecutor(ctx, WrapExecutor(sqlTx), fetchSize)
+}
+
+// QueryCursorWithExecutor is the same as QueryCursor, the executor must be bound to a transaction.
+func (b *SqlBuilder) QueryCursorWithExecutor(ctx context.Context, exec Executor, fetchSize int) (*Cursor, error) {
+	b.mustTypeSelect()
+	b.mustBasicSelect()
+	if b.dialect != DialectPostgres {
+		panic(fmt.Sprintf("cursor is not supported by dialect %s", b.dialect))
+	}
+	if fetchSize < 1 {
+		panic("fetch size must be positive")
+	}
+
+	stmt, args, err := b.TryBuild()
+	if err != nil {
+		return nil, err
+	}
+	name := fmt.Sprintf("sqlb_cursor_%08x", rand.Uint32())
+	if _, err := exec.ExecContext(ctx, "DECLARE "+name+" NO SCROLL CURSOR FOR "+stmt, args...); err != nil {
+		return nil, errors.Wrap(err, "failed to declare cursor")
+	}
+
+	return &Cursor{
+		b:         b,
+		exec:      exec,
+		name:      name,
+		fetchSize: fetchSize,
+	}, nil
+}
+
+// Next fetches the next batch of rows, returns false when no more rows or error occurred, see Err.
+func (c *Cursor) Next(ctx context.Context) bool {
+	if c.done || c.closed || c.err != nil {
+		return false
+	}
+
+	rows, err := c.b.scanRows(c.exec.QueryContext(ctx, fmt.Sprintf("FETCH FORWARD %d FROM %s", c.fetchSize, c.name)))
+	if err != nil {
+		c.err = errors.Wrap(err, "failed to fetch from cursor")
+		c.rows = nil
+		return false
+	}
+	if rows.Count() < c.fetchSize {
+		c.done = true
+	}
+	if rows.Count() == 0 {
+		c.rows = nil
+		return false
+	}
+
+	c.rows = rows
+	return true
+}
+
+// Rows returns the current batch of rows, fetched by Next.
+func (c *Cursor) Rows() *ScannedRows {
+	if c.rows == nil {
+		panic("require Next() returns true first")
+	}
+	return c.rows
+}
+
+// Err returns the error occurred while fetching, if any.
+func (c *Cursor) Err() error {
+	return c.err
+}
+
+// Close closes the cursor, it is safe to call multiple times.
+func (c *Cursor) Close(ctx context.Context) error {
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	c.rows = nil
+
+	if _, err := c.exec.ExecContext(ctx, "CLOSE "+c.name); err != nil {
+		return errors.Wrap(err, "failed to close cursor")
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package sqlb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// cursorExecutor returns the batches in order for each fetch, and records the executed statements, for the sake of the test.
+type cursorExecutor struct {
+	recordingExecutor
+	batches [][][]any
+	fetches []string
+	err     error
+}
+
+func (e *cursorExecutor) QueryContext(_ context.Context, query string, _ ...any) (SqlRows, error) {
+	e.fetches = append(e.fetches, query)
+	if e.err != nil {
+		return nil, e.err
+	}
+	if len(e.batches) == 0 {
+		return &valuesRows{}, nil
+	}
+	batch := e.batches[0]
+	e.batches = e.batches[1:]
+	return &valuesRows{rows: batch}, nil
+}
+
+func TestSqlBuilder_QueryCursor(t *testing.T) {
+	newBuilder := func() (*SqlBuilder, *TableToUse[testDdlRow]) {
+		products := UseTable[testDdlRow]().Seal()
+		return Select(products.Columns("id", "title")...).
+			From(products).
+			Where(Gt(products.Col("id"), 10)), products
+	}
+
+	t.Run("fetch all batches", func(t *testing.T) {
+		b, products := newBuilder()
+		exec := &cursorExecutor{
+			batches: [][][]any{
+				{{int64(11), "a"}, {int64(12), "b"}},
+				{{int64(13), "c"}},
+			},
+		}
+
+		cursor, err := b.QueryCursorWithExecutor(context.Background(), exec, 2)
+		require.NoError(t, err)
+		require.Len(t, exec.statements, 1)
+		require.Regexp(t, `^DECLARE sqlb_cursor_[0-9a-f]{8} NO SCROLL CURSOR FOR SELECT products\.id, products\.title\nFROM products AS products\nWHERE products\.id > \$1\n$`, exec.statements[0])
+
+		var titles []string
+		for cursor.Next(context.Background()) {
+			for _, row := range products.ReadAllFromRows(cursor.Rows()) {
+				titles = append(titles, row.Title)
+			}
+		}
+		require.NoError(t, cursor.Err())
+		require.Equal(t, []string{"a", "b", "c"}, titles)
+		require.Len(t, exec.fetches, 2, "partial batch means no more rows")
+		require.Equal(t, "FETCH FORWARD 2 FROM "+cursor.name, exec.fetches[0])
+
+		require.NoError(t, cursor.Close(context.Background()))
+		require.NoError(t, cursor.Close(context.Background()))
+		require.Equal(t, []string{exec.statements[0], "CLOSE " + cursor.name}, exec.statements)
+	})
+
+	t.Run("failed to fetch", func(t *testing.T) {
+		b, _ := newBuilder()
+		exec := &cursorExecutor{err: errors.New("boom")}
+		cursor, err := b.QueryCursorWithExecutor(context.Background(), exec, 2)
+		require.NoError(t, err)
+		require.False(t, cursor.Next(context.Background()))
+		require.ErrorContains(t, cursor.Err(), "failed to fetch from cursor: boom")
+		require.Panics(t, func() {
+			cursor.Rows()
+		})
+	})
+
+	t.Run("failed to declare", func(t *testing.T) {
+		b, _ := newBuilder()
+		exec := &cursorExecutor{recordingExecutor: recordingExecutor{err: errors.New("boom")}}
+		_, err := b.QueryCursorWithExecutor(context.Background(), exec, 2)
+		require.ErrorContains(t, err, "failed to declare cursor: boom")
+	})
+
+	t.Run("invalid usages", func(t *testing.T) {
+		require.Panics(t, func() {
+			b, _ := newBuilder()
+			_, _ = b.QueryCursorWithExecutor(context.Background(), &cursorExecutor{}, 0)
+		}, "fetch size must be positive")
+		require.Panics(t, func() {
+			b, _ := newBuilder()
+			_, _ = b.WithDialect(DialectMySQL).QueryCursorWithExecutor(context.Background(), &cursorExecutor{}, 2)
+		}, "cursor is not supported by dialect")
+	})
+}
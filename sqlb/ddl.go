@@ -0,0 +1,217 @@
+package sqlb
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// TableSchema describes the table structure, used to generate DDL.
+type TableSchema struct {
+	Name    string
+	Columns []ColumnSchema
+	Uniques []UniqueConstraint
+	Checks  []CheckConstraint
+}
+
+// ColumnSchema describes the column structure, used to generate DDL.
+type ColumnSchema struct {
+	Name       string
+	SqlType    string // empty if not provided
+	NotNull    bool
+	PrimaryKey bool
+}
+
+// UniqueConstraint is the unique constraint of the table, declared via TableMetadataBuilder.Unique.
+type UniqueConstraint struct {
+	Name    string
+	Columns []string
+}
+
+// CheckConstraint is the check constraint of the table, declared via TableMetadataBuilder.Check.
+type CheckConstraint struct {
+	Name       string
+	Expression string
+}
+
+// Schema returns the table structure described by the metadata.
+func (t TableMetadata[T]) Schema() TableSchema {
+	schema := TableSchema{
+		Name:    t.name,
+		Columns: make([]ColumnSchema, len(t.columns)),
+		Uniques: t.UniqueConstraints(),
+		Checks:  t.CheckConstraints(),
+	}
+	for i, column := range t.columns {
+		schema.Columns[i] = ColumnSchema{
+			Name:       column.name,
+			SqlType:    column.sqlType,
+			NotNull:    column.NotNull(),
+			PrimaryKey: column.isPk,
+		}
+	}
+	return schema
+}
+
+// RegisteredTableSchemas returns structure of all the registered tables, sorted by name.
+// The materialized views are not included, their structure is defined by the query of the view.
+func RegisteredTableSchemas() []TableSchema {
+	mutexRegisterTable.Lock()
+	defer mutexRegisterTable.Unlock()
+
+	schemas := make([]TableSchema, 0, len(registeredTables))
+	for _, table := range registeredTables {
+		if table.(genericTableMetadata).isMaterializedView() {
+			continue
+		}
+		schemas = append(schemas, table.(genericTableMetadata).schema())
+	}
+	sort.Slice(schemas, func(i, j int) bool {
+		return schemas[i].Name < schemas[j].Name
+	})
+	return schemas
+}
+
+// Column returns the column by name, false if not found.
+func (s TableSchema) Column(name string) (ColumnSchema, bool) {
+	name = wrapWithDoubleQuoteIfSqlKeyword(name)
+	for _, column := range s.Columns {
+		if column.Name == name {
+			return column, true
+		}
+	}
+	return ColumnSchema{}, false
+}
+
+// CreateTableStatement builds the DDL to create the table if not exists.
+// All columns must have the data type provided.
+func (s TableSchema) CreateTableStatement() string {
+	return s.createTableStatement("CREATE TABLE IF NOT EXISTS "+s.Name, "")
+}
+
+// CreateTempTableStatement builds the DDL to create a temporary table with the same structure,
+// the temporary table is dropped at the end of the transaction (PostgreSQL).
+func (s TableSchema) CreateTempTableStatement(name string) string {
+	if name == "" {
+		panic("name cannot be empty")
+	}
+	return s.createTableStatement("CREATE TEMPORARY TABLE "+name, " ON COMMIT DROP")
+}
+
+func (s TableSchema) createTableStatement(prefix, suffix string) string {
+	sb := strings.Builder{}
+	sb.WriteString(prefix)
+	sb.WriteString(" (")
+	var pkColumnsName []string
+	for i, column := range s.Columns {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		sb.WriteString("\n")
+		sb.WriteString(prettyIndent)
+		sb.WriteString(s.columnDefinition(column))
+		if column.PrimaryKey {
+			pkColumnsName = append(pkColumnsName, column.Name)
+		}
+	}
+	if len(pkColumnsName) > 0 {
+		sb.WriteString(",\n")
+		sb.WriteString(prettyIndent)
+		sb.WriteString("PRIMARY KEY (")
+		sb.WriteString(strings.Join(pkColumnsName, ", "))
+		sb.WriteString(")")
+	}
+	for _, unique := range s.Uniques {
+		sb.WriteString(",\n")
+		sb.WriteString(prettyIndent)
+		sb.WriteString(fmt.Sprintf("CONSTRAINT %s UNIQUE (%s)", unique.Name, strings.Join(unique.Columns, ", ")))
+	}
+	for _, check := range s.Checks {
+		sb.WriteString(",\n")
+		sb.WriteString(prettyIndent)
+		sb.WriteString(fmt.Sprintf("CONSTRAINT %s CHECK (%s)", check.Name, check.Expression))
+	}
+	sb.WriteString("\n)")
+	sb.WriteString(suffix)
+	return sb.String()
+}
+
+// AddColumnStatement builds the DDL to add the column to the table if not exists.
+func (s TableSchema) AddColumnStatement(columnName string) string {
+	column, found := s.Column(columnName)
+	if !found {
+		panic(fmt.Sprintf("column with name %s not found", columnName))
+	}
+	return fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s", s.Name, s.columnDefinition(column))
+}
+
+// columnDefinition returns the column definition used in DDL: [name] [type] [NOT NULL].
+func (s TableSchema) columnDefinition(column ColumnSchema) string {
+	if column.SqlType == "" {
+		panic(fmt.Sprintf("data type of column %s.%s is not provided", s.Name, column.Name))
+	}
+	definition := column.Name + " " + column.SqlType
+	if column.NotNull {
+		definition += " NOT NULL"
+	}
+	return definition
+}
+
+// CreateTableStatement builds the DDL to create table T if not exists, from the table metadata.
+// All columns must have the data type provided via ColumnMetadataBuilder.SqlType.
+func CreateTableStatement[T any]() string {
+	return mustNotMaterializedView(GetTableMetadata[T]()).Schema().CreateTableStatement()
+}
+
+// AddColumnStatement builds the DDL to add the column to table T if not exists, from the table metadata.
+func AddColumnStatement[T any](columnName string) string {
+	return mustNotMaterializedView(GetTableMetadata[T]()).Schema().AddColumnStatement(columnName)
+}
+
+// mustConflictTargetUnique ensures the ON CONFLICT target matches the primary key or one of the declared unique constraints,
+// only validated when the table declares any unique constraint.
+func mustConflictTargetUnique(schema TableSchema, columns []GenericColumnToUse) {
+	if len(schema.Uniques) == 0 {
+		return
+	}
+
+	sameColumns := func(names []string) bool {
+		if len(names) != len(columns) {
+			return false
+		}
+		for _, column := range columns {
+			var found bool
+			for _, name := range names {
+				if name == column.name {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	}
+
+	var pkColumnsName []string
+	for _, column := range schema.Columns {
+		if column.PrimaryKey {
+			pkColumnsName = append(pkColumnsName, column.Name)
+		}
+	}
+	if sameColumns(pkColumnsName) {
+		return
+	}
+	for _, unique := range schema.Uniques {
+		if sameColumns(unique.Columns) {
+			return
+		}
+	}
+
+	targetColumnsName := make([]string, len(columns))
+	for i, column := range columns {
+		targetColumnsName[i] = column.name
+	}
+	panic(fmt.Sprintf("ON CONFLICT (%s) matches neither the primary key nor any unique constraint of table %s", strings.Join(targetColumnsName, ", "), schema.Name))
+}
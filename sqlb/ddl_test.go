@@ -0,0 +1,152 @@
+package sqlb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testDdlRow struct {
+	Id    int64
+	Title string
+	Note  *string
+}
+
+var tableTestDdl = NewTableMetadata[testDdlRow]("products").
+	AddColumns(
+		NewColumnMetadata[testDdlRow]("id").
+			PrimaryKey().
+			SqlType("BIGINT").
+			InsertSpec(func(r testDdlRow) any {
+				return r.Id
+			}).
+			SelectSpec(func(r *testDdlRow) ResultColumnSelectSpec {
+				return ResultColumnSelectSpec{
+					ToQueryArg: func() any {
+						return &r.Id
+					},
+				}
+			}),
+		NewColumnMetadata[testDdlRow]("title").
+			SqlType("TEXT").
+			NotNull().
+			InsertSpec(func(r testDdlRow) any {
+				return r.Title
+			}).
+			SelectSpec(func(r *testDdlRow) ResultColumnSelectSpec {
+				return ResultColumnSelectSpec{
+					ToQueryArg: func() any {
+						return &r.Title
+					},
+				}
+			}),
+		NewColumnMetadata[testDdlRow]("note").
+			SqlType("TEXT").
+			InsertSpec(PointerInsertSpec(func(r *testDdlRow) **string { return &r.Note })).
+			SelectSpec(PointerSelectSpec(func(r *testDdlRow) **string { return &r.Note })),
+	).Build(TableMetadataBuildOption{
+	ExpectedPkColumns: []string{"id"},
+})
+
+func TestCreateTableStatement(t *testing.T) {
+	require.Equal(t, `CREATE TABLE IF NOT EXISTS products (
+  id BIGINT NOT NULL,
+  title TEXT NOT NULL,
+  note TEXT,
+  PRIMARY KEY (id)
+)`, CreateTableStatement[testDdlRow]())
+
+	require.PanicsWithValue(t, "data type of column table1.pk1 is not provided", func() {
+		CreateTableStatement[testStruct1]()
+	})
+}
+
+func TestAddColumnStatement(t *testing.T) {
+	require.Equal(t, "ALTER TABLE products ADD COLUMN IF NOT EXISTS note TEXT", AddColumnStatement[testDdlRow]("note"))
+	require.Equal(t, "ALTER TABLE products ADD COLUMN IF NOT EXISTS title TEXT NOT NULL", AddColumnStatement[testDdlRow]("title"))
+}
+
+type testConstrainedRow struct {
+	Id     int64
+	Email  string
+	Amount int64
+}
+
+var tableTestConstrained = NewTableMetadata[testConstrainedRow]("subscriptions").
+	AddColumns(
+		NewColumnMetadata[testConstrainedRow]("id").
+			PrimaryKey().
+			SqlType("BIGINT").
+			InsertSpec(func(r testConstrainedRow) any {
+				return r.Id
+			}),
+		NewColumnMetadata[testConstrainedRow]("email").
+			SqlType("TEXT").
+			NotNull().
+			InsertSpec(func(r testConstrainedRow) any {
+				return r.Email
+			}),
+		NewColumnMetadata[testConstrainedRow]("amount").
+			SqlType("BIGINT").
+			NotNull().
+			InsertSpec(func(r testConstrainedRow) any {
+				return r.Amount
+			}),
+	).
+	Unique("subscriptions_email_key", "email").
+	Check("subscriptions_amount_check", "amount >= 0").
+	Build(TableMetadataBuildOption{
+		ExpectedPkColumns: []string{"id"},
+	})
+
+func TestConstraints(t *testing.T) {
+	t.Run("DDL", func(t *testing.T) {
+		require.Equal(t, []UniqueConstraint{{Name: "subscriptions_email_key", Columns: []string{"email"}}}, tableTestConstrained.UniqueConstraints())
+		require.Equal(t, []CheckConstraint{{Name: "subscriptions_amount_check", Expression: "amount >= 0"}}, tableTestConstrained.CheckConstraints())
+		require.Equal(t, `CREATE TABLE IF NOT EXISTS subscriptions (
+  id BIGINT NOT NULL,
+  email TEXT NOT NULL,
+  amount BIGINT NOT NULL,
+  PRIMARY KEY (id),
+  CONSTRAINT subscriptions_email_key UNIQUE (email),
+  CONSTRAINT subscriptions_amount_check CHECK (amount >= 0)
+)`, CreateTableStatement[testConstrainedRow]())
+	})
+
+	t.Run("conflict target must be unique", func(t *testing.T) {
+		subscriptions := UseTable[testConstrainedRow]().Seal()
+		newInsert := func() *SqlBuilder {
+			return InsertInto(subscriptions).Values(testConstrainedRow{Id: 1, Email: "a@b.c"})
+		}
+		require.NotPanics(t, func() {
+			newInsert().OnConflict(subscriptions.Col("id")).DoNothing()
+			newInsert().OnConflict(subscriptions.Col("email")).DoNothing()
+			newInsert().OnConflict().DoNothing()
+		})
+		require.PanicsWithValue(t, "ON CONFLICT (amount) matches neither the primary key nor any unique constraint of table subscriptions", func() {
+			newInsert().OnConflict(subscriptions.Col("amount"))
+		})
+		require.Panics(t, func() {
+			newInsert().OnConflict(subscriptions.Col("id"), subscriptions.Col("email"))
+		})
+	})
+
+	t.Run("invalid declarations", func(t *testing.T) {
+		require.Panics(t, func() {
+			NewTableMetadata[testConstrainedRow]("subscriptions_invalid").
+				AddColumns(NewColumnMetadata[testConstrainedRow]("id")).
+				Unique("unknown_key", "unknown").
+				Build(TableMetadataBuildOption{})
+		}, "column of unique constraint not found")
+		require.Panics(t, func() {
+			NewTableMetadata[testConstrainedRow]("subscriptions_invalid").
+				AddColumns(NewColumnMetadata[testConstrainedRow]("id")).
+				Unique("id_key", "id").
+				Check("id_key", "id > 0").
+				Build(TableMetadataBuildOption{})
+		}, "duplicated constraint name")
+		require.Panics(t, func() {
+			NewTableMetadata[testConstrainedRow]("subscriptions_invalid").Check("empty", " ")
+		})
+	})
+}
@@ -0,0 +1,114 @@
+package sqlb
+
+import (
+	"fmt"
+	"strings"
+)
+
+var _ fmt.Stringer = (*SqlBuilder)(nil)
+
+// String dumps the internal state of the builder, for debugging purpose.
+func (b *SqlBuilder) String() string {
+	sb := strings.Builder{}
+
+	writeField := func(name string, value any) {
+		sb.WriteString("  ")
+		sb.WriteString(name)
+		sb.WriteString(": ")
+		sb.WriteString(fmt.Sprint(value))
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("SqlBuilder{\n")
+	writeField("type", b._type)
+	writeField("previous action", b.previousAction)
+	writeField("format", b.format)
+	writeField("dialect", b.dialect)
+
+	switch b._type {
+	case sqlBuilderTypeSelect:
+		writeField("select type", b.selectType)
+		writeField("select columns", b.describeColumns(b.selectColumns, true))
+		writeField("from", b.describeTables(b.selectFromTable))
+		joins := make([]string, len(b.joinsOn))
+		for i, j := range b.joinsOn {
+			joins[i] = fmt.Sprintf("%s %s ON %s", j.joinType, b.describeTable(j.joinOnTable), b.describeColumns(j.joinOnColumns, true))
+			if j.joinOnTokens.len() > 0 {
+				joins[i] += " AND " + b.describeTokens(b.tokens.list(j.joinOnTokens))
+			}
+		}
+		writeField("joins", "["+strings.Join(joins, ", ")+"]")
+		writeField("where tokens", b.describeTokens(b.tokens.list(b.whereTokens)))
+		writeField("where args", fmt.Sprintf("%v", b.whereArgs))
+		orders := make([]string, len(b.orders))
+		for i, o := range b.orders {
+			if o.expr != nil {
+				sql, _ := o.expr.render()
+				orders[i] = fmt.Sprintf("%s %s", sql, OrderType(o.asc))
+			} else {
+				orders[i] = fmt.Sprintf("%s %s", b.nameWithAlias(o.column), OrderType(o.asc))
+			}
+		}
+		writeField("order by", "["+strings.Join(orders, ", ")+"]")
+		writeField("offset", b.offset)
+		writeField("limit", b.limit)
+	case sqlBuilderTypeInsert:
+		if b.insertIntoTable != nil {
+			writeField("insert into", b.describeTable(b.insertIntoTable))
+		} else {
+			writeField("insert into", "<nil>")
+		}
+		writeField("insert columns", b.describeColumns(b.insertColumns, false))
+		writeField("values count", len(b.insertValues))
+		writeField("on conflict keys", b.describeColumns(b.insertOnConflictKeys, false))
+		writeField("on conflict do update tokens", b.describeTokens(b.tokens.list(b.insertOnConflictDoUpdateTokens)))
+		writeField("on conflict do update where tokens", b.describeTokens(b.tokens.list(b.insertOnConflictDoUpdateWhereTokens)))
+		writeField("on conflict do nothing", b.insertOnConflictDoNothing)
+		writeField("returning", b.describeColumns(b.insertReturningColumns, false))
+	}
+
+	sb.WriteString("}")
+	return sb.String()
+}
+
+func (b *SqlBuilder) describeTable(table GenericTableToUse) string {
+	return table.tableName() + " AS " + b.aliasOf(table)
+}
+
+func (b *SqlBuilder) describeTables(tables []GenericTableToUse) string {
+	described := make([]string, len(tables))
+	for i, table := range tables {
+		described[i] = b.describeTable(table)
+	}
+	return "[" + strings.Join(described, ", ") + "]"
+}
+
+func (b *SqlBuilder) describeColumns(columns []GenericColumnToUse, withAlias bool) string {
+	described := make([]string, len(columns))
+	for i, column := range columns {
+		if withAlias {
+			described[i] = b.nameWithAlias(column)
+		} else {
+			described[i] = column.name
+		}
+	}
+	return "[" + strings.Join(described, ", ") + "]"
+}
+
+func (b *SqlBuilder) describeTokens(tokens []any) string {
+	described := make([]string, len(tokens))
+	for i, token := range tokens {
+		switch t := token.(type) {
+		case string:
+			described[i] = fmt.Sprintf("%q", t)
+		case GenericColumnToUse:
+			described[i] = fmt.Sprintf("column(%s)", b.nameWithAlias(t))
+		case Expr:
+			sql, args := t.render()
+			described[i] = fmt.Sprintf("expr(%s; args %v)", sql, args)
+		default:
+			described[i] = fmt.Sprintf("%T(%v)", t, t)
+		}
+	}
+	return "[" + strings.Join(described, ", ") + "]"
+}
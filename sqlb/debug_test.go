@@ -0,0 +1,59 @@
+package sqlb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSqlBuilder_String(t *testing.T) {
+	t.Run("select", func(t *testing.T) {
+		table1 := UseTable[testStruct1]().Alias("t1").Seal()
+		table2 := UseTable[testStruct2]().Alias("t2").Seal()
+		b := Select(table1.Columns("pk1", "cost")...).
+			From(table1).
+			Join(LeftJoin, table2, table1.Col("pk1"), table2.Col("pk1")).
+			Where(table1.Col("pk2"), "= $1").Args(2).
+			OrderBy(table1.Col("cost"), DESC).
+			Limit(10)
+
+		require.Equal(t, `SqlBuilder{
+  type: SELECT
+  previous action: SELECT LIMIT
+  format: default
+  dialect: postgres
+  select type: SELECT
+  select columns: [t1.pk1, t1.cost]
+  from: [table1 AS t1]
+  joins: [LEFT JOIN table2 AS t2 ON [t1.pk1, t2.pk1]]
+  where tokens: [column(t1.pk2), "= $1"]
+  where args: [2]
+  order by: [t1.cost DESC]
+  offset: 0
+  limit: 10
+}`, b.String())
+	})
+
+	t.Run("insert", func(t *testing.T) {
+		table1 := UseTable[testStruct1]().Seal()
+		b := InsertInto(table1, table1.Col("pk1"), table1.Col("amount")).
+			Values(testStruct1{}, testStruct1{}).
+			OnConflict(table1.Col("pk1")).
+			DoUpdate(table1.Col("amount").FromExcluded())
+
+		require.Equal(t, `SqlBuilder{
+  type: INSERT
+  previous action: INSERT ON CONFLICT DO UPDATE
+  format: default
+  dialect: postgres
+  insert into: table1 AS table1
+  insert columns: [pk1, amount]
+  values count: 2
+  on conflict keys: [pk1]
+  on conflict do update tokens: ["amount = excluded.amount"]
+  on conflict do update where tokens: []
+  on conflict do nothing: false
+  returning: []
+}`, b.String())
+	})
+}
@@ -0,0 +1,176 @@
+package sqlb
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// WithDialect sets the SQL dialect of the generated statement, default is DialectPostgres.
+//
+// Placeholders of the auto-allocated arguments and the double-quoted identifiers are rendered in the dialect,
+// placeholders written directly in the tokens must be in the dialect already.
+// The positional placeholders '?' (MySQL, SQLite) of the args provided via Args and of the bound args
+// can be mixed, the args are ordered by the appearance of the placeholders.
+func (b *SqlBuilder) WithDialect(dialect Dialect) *SqlBuilder {
+	switch dialect {
+	case DialectPostgres, DialectMySQL, DialectMySQL8, DialectSQLite, DialectSQLServer:
+	default:
+		panic("unknown dialect")
+	}
+	b.dialect = dialect
+	return b
+}
+
+// isMySQL returns true if the dialect is any version of MySQL.
+func (d Dialect) isMySQL() bool {
+	return d == DialectMySQL || d == DialectMySQL8
+}
+
+// placeholder returns the placeholder of the argument at position n, starting from 1.
+func (d Dialect) placeholder(n int) string {
+	switch {
+	case d.positional():
+		return "?"
+	case d == DialectSQLServer:
+		return fmt.Sprintf("@p%d", n)
+	default:
+		return fmt.Sprintf("$%d", n)
+	}
+}
+
+// positional returns true if the placeholders of the dialect are not numbered, bound in order of appearance.
+func (d Dialect) positional() bool {
+	return d.isMySQL() || d == DialectSQLite
+}
+
+// boundArgMarker is the placeholder of the bound args while writing a statement of positional placeholders
+// mixing the args provided via Args, replaced by '?' in orderPositionalArgs.
+const boundArgMarker = "\x00"
+
+// orderPositionalArgs orders the args in order of appearance of the placeholders: '?' written in the tokens binds
+// the next arg provided via Args (the first providedCount args), boundArgMarker binds the next bound arg.
+// The markers are replaced by '?'.
+func orderPositionalArgs(stmt string, args []any, providedCount int) (string, []any) {
+	provided, bound := args[:providedCount], args[providedCount:]
+	ordered := make([]any, 0, len(args))
+
+	sb := strings.Builder{}
+	sb.Grow(len(stmt))
+	inLiteral := false
+	for i := 0; i < len(stmt); i++ {
+		c := stmt[i]
+		switch {
+		case c == '\'':
+			inLiteral = !inLiteral
+		case inLiteral:
+		case c == '?':
+			if len(provided) == 0 {
+				panic(errors.Wrapf(ErrPositionalArgs, "more placeholders ? than the %d args provided via Args", providedCount))
+			}
+			ordered = append(ordered, provided[0])
+			provided = provided[1:]
+		case c == boundArgMarker[0]:
+			ordered = append(ordered, bound[0])
+			bound = bound[1:]
+			sb.WriteByte('?')
+			continue
+		}
+		sb.WriteByte(c)
+	}
+	if len(provided) > 0 {
+		panic(errors.Wrapf(ErrPositionalArgs, "fewer placeholders ? than the %d args provided via Args", providedCount))
+	}
+	return sb.String(), ordered
+}
+
+// noLimit returns the LIMIT value represents no limit, for the dialects do not support OFFSET without LIMIT.
+// Empty if OFFSET can be used alone.
+func (d Dialect) noLimit() string {
+	switch {
+	case d.isMySQL():
+		return "18446744073709551615"
+	case d == DialectSQLite:
+		return "-1"
+	default:
+		return ""
+	}
+}
+
+// quoteIdentifiers converts the double-quoted identifiers of the statement to the quote style of the dialect,
+// string literals are kept as is.
+func (d Dialect) quoteIdentifiers(stmt string) string {
+	open, close, convert := d.identifierQuotes()
+	if !convert || !strings.Contains(stmt, `"`) {
+		return stmt
+	}
+
+	bz := []byte(stmt)
+	inLiteral := false
+	inIdentifier := false
+	for i, c := range bz {
+		switch {
+		case c == '\'' && !inIdentifier:
+			inLiteral = !inLiteral
+		case c == '"' && !inLiteral:
+			if inIdentifier {
+				bz[i] = close
+			} else {
+				bz[i] = open
+			}
+			inIdentifier = !inIdentifier
+		}
+	}
+	return string(bz)
+}
+
+// identifierQuotes returns the quotes of identifiers in the dialect, convert is false if double quotes are used.
+func (d Dialect) identifierQuotes() (open, close byte, convert bool) {
+	switch {
+	case d.isMySQL():
+		return '`', '`', true
+	case d == DialectSQLServer:
+		return '[', ']', true
+	default:
+		return '"', '"', false
+	}
+}
+
+// excludedColumnPattern matches the reference to the excluded row: excluded.[column]
+var excludedColumnPattern = regexp.MustCompile(`\bexcluded\.("[^"]+"|\w+)`)
+
+// writeOnDuplicateKeyUpdate writes the MySQL equivalent of ON CONFLICT clause.
+// MySQL checks all the unique keys, so the conflict keys are not rendered.
+func (b *SqlBuilder) writeOnDuplicateKeyUpdate(ctx *buildContext) {
+	if b.insertOnConflictDoNothing {
+		// no-op update, unlike INSERT IGNORE, other errors are not suppressed
+		column := b.insertColumns[0]
+		if len(b.insertOnConflictKeys) > 0 {
+			column = b.insertOnConflictKeys[0]
+		}
+		ctx.writeString("\nON DUPLICATE KEY UPDATE ")
+		ctx.writeString(column.name)
+		ctx.writeString(" = ")
+		ctx.writeString(column.name)
+		return
+	}
+	if len(b.insertOnConflictKeys) < 1 {
+		return
+	}
+	ctx.columnStyle = columnStyleNameOnly
+	if b.dialect == DialectMySQL8 {
+		ctx.writeString("\nAS excluded\nON DUPLICATE KEY UPDATE\n")
+		ctx.writeArenaTokens(&b.tokens, b.insertOnConflictDoUpdateTokens, "ON CONFLICT UPDATE")
+		return
+	}
+
+	ctx.writeString("\nON DUPLICATE KEY UPDATE\n")
+	sb := ctx.sb
+	tokens := strings.Builder{}
+	ctx.sb = &tokens
+	ctx.writeArenaTokens(&b.tokens, b.insertOnConflictDoUpdateTokens, "ON CONFLICT UPDATE")
+	ctx.sb = sb
+	ctx.writeString(excludedColumnPattern.ReplaceAllString(tokens.String(), "VALUES($1)"))
+}
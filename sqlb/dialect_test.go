@@ -0,0 +1,392 @@
+package sqlb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSqlBuilder_WithDialect_MySQL(t *testing.T) {
+	record := testStruct1{
+		Pk1:    "1",
+		Pk2:    2,
+		Amount: 3,
+		Cost: Money{
+			Currency: "testa",
+			Amount:   4,
+		},
+	}
+
+	tests := []struct {
+		name     string
+		builder  func() *SqlBuilder
+		wantSql  string
+		wantArgs []any
+	}{
+		{
+			name: "upsert",
+			builder: func() *SqlBuilder {
+				table1 := UseTable[testStruct1]().Seal()
+				return InsertInto(table1).Values(record).
+					OnConflict(table1.PrimaryKeyColumns()...).
+					DoUpdate(table1.Col("amount").EqualsToCurrent(), "+", table1.Col("amount").Excluded()).
+					DoUpdate(table1.Col("cost").FromExcluded()).
+					WithDialect(DialectMySQL)
+			},
+			wantSql: `INSERT INTO table1 (pk1, pk2, amount, cost)
+VALUES (?,?,?,?)
+ON DUPLICATE KEY UPDATE
+ amount = table1.amount + VALUES(amount) , cost = VALUES(cost)`,
+			wantArgs: []any{"1", 2, 3, "4testa"},
+		},
+		{
+			name: "upsert with bound args",
+			builder: func() *SqlBuilder {
+				table1 := UseTable[testStruct1]().Seal()
+				return InsertInto(table1).Values(record).
+					OnConflict(table1.PrimaryKeyColumns()...).
+					DoUpdate(table1.Col("amount"), "=", table1.Col("amount").NameWithTableName(), "+", Arg(5)).
+					WithDialect(DialectMySQL)
+			},
+			wantSql: `INSERT INTO table1 (pk1, pk2, amount, cost)
+VALUES (?,?,?,?)
+ON DUPLICATE KEY UPDATE
+ amount = table1.amount + ?`,
+			wantArgs: []any{"1", 2, 3, "4testa", 5},
+		},
+		{
+			name: "upsert except primary keys, row alias form",
+			builder: func() *SqlBuilder {
+				table1 := UseTable[testStruct1]().Seal()
+				return InsertInto(table1).Values(record).
+					OnConflict(table1.PrimaryKeyColumns()...).
+					DoUpdateExceptPrimaryKeys().
+					WithDialect(DialectMySQL8)
+			},
+			wantSql: `INSERT INTO table1 (pk1, pk2, amount, cost)
+VALUES (?,?,?,?)
+AS excluded
+ON DUPLICATE KEY UPDATE
+ amount = excluded.amount , cost = excluded.cost`,
+			wantArgs: []any{"1", 2, 3, "4testa"},
+		},
+		{
+			name: "upsert except primary keys",
+			builder: func() *SqlBuilder {
+				table1 := UseTable[testStruct1]().Seal()
+				return InsertInto(table1).Values(record).
+					OnConflict(table1.PrimaryKeyColumns()...).
+					DoUpdateExceptPrimaryKeys().
+					WithDialect(DialectMySQL)
+			},
+			wantSql: `INSERT INTO table1 (pk1, pk2, amount, cost)
+VALUES (?,?,?,?)
+ON DUPLICATE KEY UPDATE
+ amount = VALUES(amount) , cost = VALUES(cost)`,
+			wantArgs: []any{"1", 2, 3, "4testa"},
+		},
+		{
+			name: "do nothing",
+			builder: func() *SqlBuilder {
+				table1 := UseTable[testStruct1]().Seal()
+				return InsertInto(table1).Values(record).
+					OnConflict().
+					DoNothing().
+					WithDialect(DialectMySQL)
+			},
+			wantSql: `INSERT INTO table1 (pk1, pk2, amount, cost)
+VALUES (?,?,?,?)
+ON DUPLICATE KEY UPDATE pk1 = pk1`,
+			wantArgs: []any{"1", 2, 3, "4testa"},
+		},
+		{
+			name: "select with auto-allocated args and pagination",
+			builder: func() *SqlBuilder {
+				table1 := UseTable[testStruct1]().Alias("t").Seal()
+				return Select(table1.Col("pk1")).From(table1).
+					Where(table1.Col("pk2"), "= ?").
+					And(Eq(table1.Col("amount"), 3)).
+					Args(2).
+					Offset(10).
+					WithDialect(DialectMySQL)
+			},
+			wantSql: `SELECT t.pk1
+FROM table1 AS t
+WHERE t.pk2 = ? AND t.amount = ?
+LIMIT 18446744073709551615 OFFSET 10
+`,
+			wantArgs: []any{2, 3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotSql, gotArgs := tt.builder().Build()
+			require.Equal(t, tt.wantSql, gotSql)
+			require.Equal(t, tt.wantArgs, gotArgs)
+		})
+	}
+
+	t.Run("DO UPDATE WHERE is not supported", func(t *testing.T) {
+		table1 := UseTable[testStruct1]().Seal()
+		require.PanicsWithError(t, "ON CONFLICT DO UPDATE WHERE is not supported by dialect mysql", func() {
+			InsertInto(table1).Values(record).
+				OnConflict(table1.PrimaryKeyColumns()...).
+				DoUpdateExceptPrimaryKeys().
+				Where(table1.Col("amount"), "< 10").
+				WithDialect(DialectMySQL).
+				Build()
+		})
+	})
+}
+
+func TestDialect_quoteIdentifiers(t *testing.T) {
+	stmt := `SELECT t."name", 'say "hi"' FROM "order" AS t`
+	require.Equal(t, stmt, DialectPostgres.quoteIdentifiers(stmt))
+	require.Equal(t, "SELECT t.`name`, 'say \"hi\"' FROM `order` AS t", DialectMySQL.quoteIdentifiers(stmt))
+	require.Equal(t, `SELECT t.[name], 'say "hi"' FROM [order] AS t`, DialectSQLServer.quoteIdentifiers(stmt))
+}
+
+func TestSqlBuilder_positionalArgs_MySQL(t *testing.T) {
+	products := UseTable[testDdlRow]().Alias("p").Seal()
+	table1 := UseTable[testStruct1]().Alias("t1").Seal()
+
+	for _, dialect := range []Dialect{DialectMySQL, DialectMySQL8} {
+		t.Run(dialect.String(), func(t *testing.T) {
+			tests := []struct {
+				name     string
+				builder  func() *SqlBuilder
+				wantSql  string
+				wantArgs []any
+			}{
+				{
+					name: "predicate before the provided args",
+					builder: func() *SqlBuilder {
+						return Select(products.Col("id")).From(products).
+							Where(Eq(products.Col("title"), "T")).
+							And(products.Col("id"), "=", "?").Args(int64(5))
+					},
+					wantSql:  "SELECT p.id\nFROM products AS p\nWHERE p.title = ? AND p.id = ?\n",
+					wantArgs: []any{"T", int64(5)},
+				},
+				{
+					name: "provided args around the predicates",
+					builder: func() *SqlBuilder {
+						return Select(products.Col("id")).From(products, table1).
+							Where(products.Col("id"), "> ?").
+							And(Gt(table1.Col("pk2"), 7)).
+							And(products.Col("title"), "= ?").Args(int64(3), "T")
+					},
+					wantSql:  "SELECT p.id\nFROM products AS p, table1 AS t1\nWHERE p.id > ? AND t1.pk2 > ? AND p.title = ?\n",
+					wantArgs: []any{int64(3), 7, "T"},
+				},
+				{
+					name: "raw, subquery and order by expression",
+					builder: func() *SqlBuilder {
+						return Select(products.Col("id")).From(products).
+							Where(Raw("p.title LIKE ?", "a%")).
+							And(Exists(SelectExists().From(table1).Where(Eq(table1.Col("pk1"), "x")).WithDialect(dialect))).
+							And(products.Col("id"), "> ?").Args(int64(1)).
+							OrderByExpr(Raw("FIELD(p.title, ?)", "b"), ASC)
+					},
+					wantSql:  "SELECT p.id\nFROM products AS p\nWHERE p.title LIKE ? AND EXISTS (SELECT 1 FROM table1 AS t1 WHERE t1.pk1 = ?) AND p.id > ?\nORDER BY FIELD(p.title, ?) ASC\n",
+					wantArgs: []any{"a%", "x", int64(1), "b"},
+				},
+				{
+					name: "join on and aggregate",
+					builder: func() *SqlBuilder {
+						return Select(products.Col("id")).
+							SelectAggregates(Sum(table1.Col("amount")).As("total")).
+							From(products).
+							JoinOn(InnerJoin, table1, table1.Col("pk1"), "=", products.Col("title"), "AND", Gt(table1.Col("pk2"), 7)).
+							Where(products.Col("id"), "= ?").Args(int64(3)).
+							GroupBy(products.Col("id"))
+					},
+					wantSql:  "SELECT p.id, SUM(t1.amount) AS total\nFROM products AS p\nINNER JOIN table1 AS t1 ON t1.pk1 = p.title AND t1.pk2 > ?\nWHERE p.id = ?\nGROUP BY p.id\n",
+					wantArgs: []any{7, int64(3)},
+				},
+			}
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					gotSql, gotArgs := tt.builder().WithDialect(dialect).Build()
+					require.Equal(t, tt.wantSql, gotSql)
+					require.Equal(t, tt.wantArgs, gotArgs)
+				})
+			}
+
+			t.Run("compiled slots", func(t *testing.T) {
+				compiled, err := Select(products.Col("id")).From(products).
+					Where(Eq(products.Col("title"), Slot("title"))).
+					And(products.Col("id"), "= ?").Args(Slot("id")).
+					WithDialect(dialect).
+					Compile()
+				require.NoError(t, err)
+				args, err := compiled.Bind(map[string]any{"title": "T", "id": int64(5)})
+				require.NoError(t, err)
+				require.Equal(t, []any{"T", int64(5)}, args)
+			})
+
+			t.Run("placeholders do not match the provided args", func(t *testing.T) {
+				_, _, err := Select(products.Col("id")).From(products).
+					Where(Eq(products.Col("title"), "T")).
+					And(products.Col("id"), "= 1").Args(int64(5)).
+					WithDialect(dialect).
+					TryBuild()
+				require.ErrorIs(t, err, ErrPositionalArgs)
+			})
+		})
+	}
+}
+
+func TestSqlBuilder_WithDialect_SQLite(t *testing.T) {
+	record := testStruct1{
+		Pk1:    "1",
+		Pk2:    2,
+		Amount: 3,
+		Cost: Money{
+			Currency: "testa",
+			Amount:   4,
+		},
+	}
+
+	t.Run("upsert returning", func(t *testing.T) {
+		table1 := UseTable[testStruct1]().Seal()
+		gotSql, gotArgs := InsertInto(table1).Values(record).
+			OnConflict(table1.PrimaryKeyColumns()...).
+			DoUpdateExceptPrimaryKeys().
+			Where(table1.Col("amount"), "<", Arg(10)).
+			Returning(table1.Col("amount"), table1.Col("cost")).
+			WithDialect(DialectSQLite).
+			Build()
+		require.Equal(t, `INSERT INTO table1 (pk1, pk2, amount, cost)
+VALUES (?,?,?,?)
+ON CONFLICT (pk1, pk2) DO UPDATE SET
+ amount = excluded.amount , cost = excluded.cost
+WHERE table1.amount < ?
+RETURNING amount, cost`, gotSql)
+		require.Equal(t, []any{"1", 2, 3, "4testa", 10}, gotArgs)
+	})
+
+	t.Run("select with pagination", func(t *testing.T) {
+		table1 := UseTable[testStruct1]().Alias("t").Seal()
+		gotSql, gotArgs := Select(table1.Col("pk1")).From(table1).
+			Where(Eq(table1.Col("pk2"), 2)).
+			Offset(10).
+			WithDialect(DialectSQLite).
+			Build()
+		require.Equal(t, `SELECT t.pk1
+FROM table1 AS t
+WHERE t.pk2 = ?
+LIMIT -1 OFFSET 10
+`, gotSql)
+		require.Equal(t, []any{2}, gotArgs)
+	})
+
+	t.Run("provided args mixed with bound args", func(t *testing.T) {
+		table1 := UseTable[testStruct1]().Alias("t").Seal()
+		gotSql, gotArgs := Select(table1.Col("pk1")).
+			SelectAggregates(CountAll().Filter(Eq(table1.Col("pk2"), 1)).As("total")).
+			From(table1).
+			Where(In(table1.Col("pk1"), "a", "b")).
+			And(table1.Col("amount"), "BETWEEN ? AND ?").Args(10, 20).
+			And(Raw("t.cost <> ?", "0")).
+			GroupBy(table1.Col("pk1")).
+			WithDialect(DialectSQLite).
+			Build()
+		require.Equal(t, `SELECT t.pk1, COUNT(*) FILTER (WHERE t.pk2 = ?) AS total
+FROM table1 AS t
+WHERE t.pk1 IN (?, ?) AND t.amount BETWEEN ? AND ? AND t.cost <> ?
+GROUP BY t.pk1
+`, gotSql)
+		require.Equal(t, []any{1, "a", "b", 10, 20, "0"}, gotArgs)
+	})
+}
+
+func TestSqlBuilder_Returning(t *testing.T) {
+	table1 := UseTable[testStruct1]().Seal()
+	table2 := UseTable[testStruct2]().Seal()
+	record := testStruct1{Pk1: "1", Pk2: 2}
+
+	gotSql, _ := InsertInto(table1).Values(record).
+		OnConflict().
+		DoNothing().
+		Returning(table1.PrimaryKeyColumns()...).
+		Build()
+	require.Equal(t, `INSERT INTO table1 (pk1, pk2, amount, cost)
+VALUES ($1,$2,$3,$4)
+ON CONFLICT DO NOTHING
+RETURNING pk1, pk2`, gotSql)
+
+	require.Panics(t, func() {
+		InsertInto(table1).Values(record).Returning(table2.Col("pk3"))
+	}, "column from another table")
+	require.Panics(t, func() {
+		InsertInto(table1).Returning(table1.Col("pk1"))
+	}, "must be after VALUES")
+	require.PanicsWithError(t, "RETURNING is not supported by dialect mysql", func() {
+		InsertInto(table1).Values(record).Returning(table1.Col("pk1")).WithDialect(DialectMySQL).Build()
+	})
+}
+
+func TestSqlBuilder_WithDialect_SQLServer(t *testing.T) {
+	t.Run("select with pagination", func(t *testing.T) {
+		table1 := UseTable[testStruct1]().Alias("t").Seal()
+		gotSql, gotArgs := Select(table1.Col("pk1")).From(table1).
+			Where(Eq(table1.Col("pk2"), 2)).
+			And(In(table1.Col("amount"), 3, 4)).
+			OrderBy(table1.Col("pk1"), ASC).
+			Offset(20).
+			Limit(10).
+			WithDialect(DialectSQLServer).
+			Build()
+		require.Equal(t, `SELECT t.pk1
+FROM table1 AS t
+WHERE t.pk2 = @p1 AND t.amount IN (@p2, @p3)
+ORDER BY t.pk1 ASC
+OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY
+`, gotSql)
+		require.Equal(t, []any{2, 3, 4}, gotArgs)
+	})
+
+	t.Run("limit without order", func(t *testing.T) {
+		table1 := UseTable[testStruct1]().Alias("t").Seal()
+		gotSql, _ := Select(table1.Col("pk1")).From(table1).
+			Limit(10).
+			WithDialect(DialectSQLServer).
+			Build()
+		require.Equal(t, `SELECT t.pk1
+FROM table1 AS t
+ORDER BY (SELECT NULL)
+OFFSET 0 ROWS FETCH NEXT 10 ROWS ONLY
+`, gotSql)
+	})
+
+	t.Run("exists", func(t *testing.T) {
+		table1 := UseTable[testStruct1]().Alias("t").Seal()
+		gotSql, _ := SelectExists().From(table1).
+			Where(table1.Col("pk1"), "= @p1").
+			WithDialect(DialectSQLServer).
+			Build()
+		require.Equal(t, `SELECT CASE WHEN EXISTS(SELECT 1 FROM table1 AS t
+WHERE t.pk1 = @p1
+) THEN 1 ELSE 0 END`, gotSql)
+	})
+
+	t.Run("insert output", func(t *testing.T) {
+		table1 := UseTable[testStruct1]().Seal()
+		gotSql, _ := InsertInto(table1, table1.Col("pk1"), table1.Col("pk2")).
+			Values(testStruct1{Pk1: "1", Pk2: 2}).
+			Returning(table1.Col("pk1")).
+			WithDialect(DialectSQLServer).
+			Build()
+		require.Equal(t, `INSERT INTO table1 (pk1, pk2)
+OUTPUT INSERTED.pk1
+VALUES (@p1,@p2)`, gotSql)
+	})
+
+	t.Run("upsert is not supported", func(t *testing.T) {
+		table1 := UseTable[testStruct1]().Seal()
+		require.PanicsWithError(t, "ON CONFLICT is not supported by dialect sqlserver, use MERGE instead", func() {
+			InsertInto(table1).Values(testStruct1{}).OnConflict().DoNothing().WithDialect(DialectSQLServer).Build()
+		})
+	})
+}
@@ -0,0 +1,48 @@
+package sqlb
+
+// EmbeddedColumns maps the columns defined once for the embedded struct E (eg: Audit{CreatedAt, UpdatedAt})
+// into columns of the row type T, via the accessor of the embedded field.
+//
+//	NewTableMetadata[User]("users").
+//		AddColumns(...).
+//		AddColumns(EmbeddedColumns(func(u *User) *Audit { return &u.Audit }, auditColumns()...)...)
+func EmbeddedColumns[T any, E any](field func(*T) *E, columns ...*ColumnMetadataBuilder[E]) []*ColumnMetadataBuilder[T] {
+	if len(columns) == 0 {
+		panic("no columns to embed")
+	}
+
+	result := make([]*ColumnMetadataBuilder[T], len(columns))
+	for i, cb := range columns {
+		result[i] = embedColumn(field, cb.build())
+	}
+	return result
+}
+
+// embedColumn converts the column of the embedded struct E to the column of T,
+// the attributes are copied as is while the specs are mapped via the accessor of the embedded field.
+// The column is built beforehand, so the specs already carry the default on insert and the codec.
+func embedColumn[T any, E any](field func(*T) *E, column ColumnMetadata[E]) *ColumnMetadataBuilder[T] {
+	cb := &ColumnMetadataBuilder[T]{
+		column: ColumnMetadata[T]{
+			columnAttributes: column.columnAttributes,
+		},
+	}
+
+	if insertSpec := column.insertSpec; insertSpec != nil {
+		cb.InsertSpec(func(v T) any {
+			return insertSpec(*field(&v))
+		})
+	}
+	if selectSpec := column.selectSpec; selectSpec != nil {
+		cb.SelectSpec(func(v *T) ResultColumnSelectSpec {
+			return selectSpec(field(v))
+		})
+	}
+	if validator := column.validator; validator != nil {
+		cb.Validator(func(v T) error {
+			return validator(*field(&v))
+		})
+	}
+
+	return cb
+}
@@ -0,0 +1,139 @@
+package sqlb
+
+import (
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testAudit struct {
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func testAuditColumns() []*ColumnMetadataBuilder[testAudit] {
+	return []*ColumnMetadataBuilder[testAudit]{
+		NewColumnMetadata[testAudit]("created_at").
+			InsertSpec(func(a testAudit) any {
+				return a.CreatedAt
+			}).
+			SelectSpec(func(a *testAudit) ResultColumnSelectSpec {
+				return ResultColumnSelectSpec{
+					ToQueryArg: func() any {
+						return &a.CreatedAt
+					},
+				}
+			}),
+		NewColumnMetadata[testAudit]("updated_at").
+			InsertSpec(func(a testAudit) any {
+				return a.UpdatedAt
+			}).
+			SelectSpec(func(a *testAudit) ResultColumnSelectSpec {
+				return ResultColumnSelectSpec{
+					ToQueryArg: func() any {
+						return &a.UpdatedAt
+					},
+				}
+			}),
+	}
+}
+
+type testEmbeddedRow struct {
+	Id string
+	testAudit
+}
+
+var tableTestEmbedded = NewTableMetadata[testEmbeddedRow]("customers").
+	AddColumns(
+		NewColumnMetadata[testEmbeddedRow]("id").
+			PrimaryKey().
+			InsertSpec(func(r testEmbeddedRow) any {
+				return r.Id
+			}).
+			SelectSpec(func(r *testEmbeddedRow) ResultColumnSelectSpec {
+				return ResultColumnSelectSpec{
+					ToQueryArg: func() any {
+						return &r.Id
+					},
+				}
+			}),
+	).
+	AddColumns(EmbeddedColumns(func(r *testEmbeddedRow) *testAudit { return &r.testAudit }, testAuditColumns()...)...).
+	Build(TableMetadataBuildOption{
+		ExpectedPkColumns: []string{"id"},
+	})
+
+func TestEmbeddedColumns(t *testing.T) {
+	require.Equal(t, []string{"id", "created_at", "updated_at"}, tableTestEmbedded.ColumnsName())
+
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run("insert", func(t *testing.T) {
+		customers := UseTable[testEmbeddedRow]().Seal()
+		_, args := InsertInto(customers).Values(testEmbeddedRow{
+			Id: "1",
+			testAudit: testAudit{
+				CreatedAt: createdAt,
+			},
+		}).Build()
+		require.Equal(t, []any{"1", createdAt, time.Time{}}, args)
+	})
+
+	t.Run("select", func(t *testing.T) {
+		var r testEmbeddedRow
+		_, selectSpec := tableTestEmbedded.MustGetColumnByName("created_at").SelectSpec()
+		*selectSpec(&r).ToQueryArg().(*time.Time) = createdAt
+		require.Equal(t, createdAt, r.CreatedAt)
+	})
+}
+
+func TestEmbeddedColumns_attributes(t *testing.T) {
+	field := func(r *testEmbeddedRow) *testAudit { return &r.testAudit }
+
+	t.Run("ddl", func(t *testing.T) {
+		column := EmbeddedColumns(field, NewColumnMetadata[testAudit]("created_at").SqlType("TIMESTAMPTZ").NotNull())[0].build()
+		require.Equal(t, "TIMESTAMPTZ", column.SqlType())
+		require.True(t, column.NotNull())
+	})
+
+	t.Run("generated", func(t *testing.T) {
+		column := EmbeddedColumns(field, NewColumnMetadata[testAudit]("created_at").Generated())[0].build()
+		require.True(t, column.IsGenerated())
+	})
+
+	t.Run("read only", func(t *testing.T) {
+		column := EmbeddedColumns(field, NewColumnMetadata[testAudit]("updated_at").ReadOnly())[0].build()
+		require.True(t, column.IsReadOnly())
+	})
+
+	t.Run("all attributes", func(t *testing.T) {
+		source := NewColumnMetadata[testAudit]("created_at").PrimaryKey().SqlType("TIMESTAMPTZ").NotNull().Generated().ReadOnly()
+		column := EmbeddedColumns(field, source)[0].build()
+		require.Equal(t, source.build().columnAttributes, column.columnAttributes)
+	})
+
+	t.Run("default on insert", func(t *testing.T) {
+		column := EmbeddedColumns(field, NewColumnMetadata[testAudit]("updated_at").
+			InsertSpec(func(testAudit) any {
+				return nil
+			}).
+			DefaultOnInsert(Now()))[0].build()
+		require.Equal(t, Now(), column.insertSpec(testEmbeddedRow{}))
+	})
+
+	t.Run("codec", func(t *testing.T) {
+		column := EmbeddedColumns(field, NewColumnMetadata[testAudit]("created_at").
+			InsertSpec(func(testAudit) any {
+				return "2024"
+			}).
+			SelectSpec(func(*testAudit) ResultColumnSelectSpec {
+				return ResultColumnSelectSpec{ToQueryArg: func() any { return new(string) }}
+			}).
+			Encrypted(prefixCodec{}))[0].build()
+		value, err := column.insertSpec(testEmbeddedRow{}).(driver.Valuer).Value()
+		require.NoError(t, err)
+		require.Equal(t, []byte("enc:2024"), value)
+	})
+}
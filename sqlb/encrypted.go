@@ -0,0 +1,138 @@
+package sqlb
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// ColumnCodec encrypts the column value before inserting and decrypts it after selecting,
+// the encrypted value is stored as binary (BYTEA).
+type ColumnCodec interface {
+	Encrypt(plaintext []byte) (ciphertext []byte, err error)
+	Decrypt(ciphertext []byte) (plaintext []byte, err error)
+}
+
+// Encrypted makes the column value encrypted by the codec, the insert & select specs are wrapped when building the table metadata.
+//
+// The insert spec must provide string, []byte or driver.Valuer of those, NULL is kept as is.
+// The select spec receives the decrypted value via its query arg, which must be *string, *[]byte or sql.Scanner.
+func (b *ColumnMetadataBuilder[T]) Encrypted(codec ColumnCodec) *ColumnMetadataBuilder[T] {
+	if codec == nil {
+		panic("codec is required")
+	}
+	b.codec = codec
+	return b
+}
+
+// build returns the column metadata, with the insert spec wrapped by the default expression
+// and the specs wrapped by the codec if any.
+func (b *ColumnMetadataBuilder[T]) build() ColumnMetadata[T] {
+	column := b.column
+	if b.insertDefault != nil {
+		if b.codec != nil {
+			panic(fmt.Sprintf("default on insert is not supported for encrypted column %s", column.name))
+		}
+		column.insertSpec = insertSpecWithDefault(column.insertSpec, *b.insertDefault)
+	}
+	if b.codec == nil {
+		return column
+	}
+
+	if column.insertSpec == nil || column.selectSpec == nil {
+		panic(fmt.Sprintf("insert & select specs are required for encrypted column %s", column.name))
+	}
+
+	codec := b.codec
+	insertSpec := column.insertSpec
+	selectSpec := column.selectSpec
+
+	column.insertSpec = func(v T) any {
+		return encryptedValue{
+			codec: codec,
+			value: insertSpec(v),
+		}
+	}
+	column.selectSpec = func(v *T) ResultColumnSelectSpec {
+		inner := selectSpec(v)
+		var raw []byte
+		return ResultColumnSelectSpec{
+			ToQueryArg: func() any {
+				return &raw
+			},
+			OptionalTransform: func() error {
+				var plaintext []byte
+				if raw != nil {
+					var err error
+					if plaintext, err = codec.Decrypt(raw); err != nil {
+						return errors.Wrapf(err, "failed to decrypt column %s", column.name)
+					}
+				}
+				if err := assignDecrypted(inner.ToQueryArg(), plaintext); err != nil {
+					return errors.Wrapf(err, "failed to assign decrypted value of column %s", column.name)
+				}
+				if inner.OptionalTransform != nil {
+					return inner.OptionalTransform()
+				}
+				return nil
+			},
+		}
+	}
+	return column
+}
+
+// encryptedValue encrypts the value when being bound as argument.
+type encryptedValue struct {
+	codec ColumnCodec
+	value any
+}
+
+var _ driver.Valuer = encryptedValue{}
+
+func (e encryptedValue) Value() (driver.Value, error) {
+	value := e.value
+	if valuer, ok := value.(driver.Valuer); ok {
+		var err error
+		if value, err = valuer.Value(); err != nil {
+			return nil, err
+		}
+	}
+
+	var plaintext []byte
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		plaintext = []byte(v)
+	case []byte:
+		plaintext = v
+	default:
+		return nil, errors.Errorf("unsupported type %T for encrypting", value)
+	}
+
+	ciphertext, err := e.codec.Encrypt(plaintext)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encrypt")
+	}
+	return ciphertext, nil
+}
+
+// assignDecrypted assigns the decrypted value to the destination, nil plaintext represents NULL.
+func assignDecrypted(dest any, plaintext []byte) error {
+	switch d := dest.(type) {
+	case sql.Scanner:
+		if plaintext == nil {
+			return d.Scan(nil)
+		}
+		return d.Scan(plaintext)
+	case *string:
+		*d = string(plaintext)
+	case *[]byte:
+		*d = plaintext
+	default:
+		return errors.Errorf("unsupported destination type %T", dest)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package sqlb
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// prefixCodec is a mock codec prefixes the plaintext, for the sake of the test.
+type prefixCodec struct{}
+
+func (prefixCodec) Encrypt(plaintext []byte) ([]byte, error) {
+	return append([]byte("enc:"), plaintext...), nil
+}
+
+func (prefixCodec) Decrypt(ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) < 4 || string(ciphertext[:4]) != "enc:" {
+		return nil, errors.New("bad ciphertext")
+	}
+	return ciphertext[4:], nil
+}
+
+func TestColumnMetadataBuilder_Encrypted(t *testing.T) {
+	type row struct {
+		Email string
+		Phone sql.NullString
+	}
+
+	email := NewColumnMetadata[row]("email").
+		InsertSpec(func(r row) any {
+			return r.Email
+		}).
+		SelectSpec(func(r *row) ResultColumnSelectSpec {
+			return ResultColumnSelectSpec{
+				ToQueryArg: func() any {
+					return &r.Email
+				},
+			}
+		}).
+		Encrypted(prefixCodec{}).
+		build()
+	phone := NewColumnMetadata[row]("phone").
+		InsertSpec(func(r row) any {
+			return r.Phone
+		}).
+		SelectSpec(func(r *row) ResultColumnSelectSpec {
+			return ResultColumnSelectSpec{
+				ToQueryArg: func() any {
+					return &r.Phone
+				},
+			}
+		}).
+		Encrypted(prefixCodec{}).
+		build()
+
+	value := func(v any) driver.Value {
+		dv, err := v.(driver.Valuer).Value()
+		require.NoError(t, err)
+		return dv
+	}
+
+	t.Run("insert", func(t *testing.T) {
+		require.Equal(t, []byte("enc:a@b.c"), value(email.insertSpec(row{Email: "a@b.c"})))
+		require.Equal(t, []byte("enc:123"), value(phone.insertSpec(row{Phone: sql.NullString{String: "123", Valid: true}})))
+		require.Nil(t, value(phone.insertSpec(row{})))
+	})
+
+	t.Run("select", func(t *testing.T) {
+		var r row
+
+		rs := email.selectSpec(&r)
+		*rs.ToQueryArg().(*[]byte) = []byte("enc:a@b.c")
+		require.NoError(t, rs.OptionalTransform())
+		require.Equal(t, "a@b.c", r.Email)
+
+		rs = phone.selectSpec(&r)
+		*rs.ToQueryArg().(*[]byte) = []byte("enc:123")
+		require.NoError(t, rs.OptionalTransform())
+		require.Equal(t, sql.NullString{String: "123", Valid: true}, r.Phone)
+
+		rs = phone.selectSpec(&r)
+		require.NoError(t, rs.OptionalTransform())
+		require.Equal(t, sql.NullString{}, r.Phone)
+
+		rs = email.selectSpec(&r)
+		*rs.ToQueryArg().(*[]byte) = []byte("plain")
+		require.ErrorContains(t, rs.OptionalTransform(), "failed to decrypt column email")
+	})
+
+	t.Run("specs are required", func(t *testing.T) {
+		require.Panics(t, func() {
+			NewColumnMetadata[row]("email").Encrypted(prefixCodec{}).build()
+		})
+	})
+}
@@ -0,0 +1,128 @@
+package sqlb
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ErrNoColumns is the failure of building a SELECT statement without any column selected.
+var ErrNoColumns = errors.New("no columns selected")
+
+// ErrNoValues is the failure of building an INSERT statement without any value to insert.
+var ErrNoValues = errors.New("no values for inserting")
+
+// ErrAliasConflict is the cause of the errors of an alias used by multiple tables, see TryBuild.
+var ErrAliasConflict = errors.New("alias conflict")
+
+// ErrColumnCollision is the cause of the errors of a column selected more than once, the scanned values would be ambiguous.
+var ErrColumnCollision = errors.New("column collision")
+
+// ErrPositionalArgs is the failure of binding the arguments provided via Args to the positional placeholders '?'
+// (MySQL, SQLite) mixed with the bound arguments: the number of the placeholders '?' written in the tokens
+// does not match the number of the arguments, so the order of the values can not be determined.
+var ErrPositionalArgs = errors.New("arguments do not match the positional placeholders")
+
+// ErrInvalidRegex is the cause of the errors of the regular expressions not supported by the database, see TryRegexMatch.
+var ErrInvalidRegex = errors.New("invalid POSIX regular expression")
+
+// ErrNotInTransaction is the failure of executing a statement requiring a transaction (eg: the statement timeout)
+// via an executor which is not bound to a transaction, see TxExecutor.
+var ErrNotInTransaction = errors.New("executor is not bound to a transaction")
+
+// ErrBadClauseOrder is the failure of adding a clause after an unexpected one, e.g. WHERE before FROM.
+type ErrBadClauseOrder struct {
+	Got  string   // Got is the previous clause
+	Want []string // Want is the clauses expected to be the previous one
+}
+
+func (e ErrBadClauseOrder) Error() string {
+	if len(e.Want) == 1 {
+		return fmt.Sprintf("unexpected previous action %s, expected %s", e.Got, e.Want[0])
+	}
+	return fmt.Sprintf("unexpected previous action %s, expected any of [%s]", e.Got, strings.Join(e.Want, ","))
+}
+
+// ErrUnknownColumn is the failure of resolving a column by name.
+// Table is empty when the column is resolved from an allow-list, e.g. OrderByName.
+type ErrUnknownColumn struct {
+	Table  string
+	Column string
+}
+
+func (e ErrUnknownColumn) Error() string {
+	if e.Table == "" {
+		return fmt.Sprintf("column %q is not allowed", e.Column)
+	}
+	return fmt.Sprintf("column %s does not exist in table %s", e.Column, e.Table)
+}
+
+// ErrUnsupportedByDialect is the failure of building a clause the dialect does not support, e.g. RETURNING on MySQL.
+type ErrUnsupportedByDialect struct {
+	Feature string  // Feature is the unsupported clause, e.g. ON CONFLICT
+	Dialect Dialect // Dialect is the dialect of the builder
+	Hint    string  // Hint is the optional alternative, e.g. use MERGE instead
+}
+
+func (e ErrUnsupportedByDialect) Error() string {
+	if e.Hint == "" {
+		return fmt.Sprintf("%s is not supported by dialect %s", e.Feature, e.Dialect)
+	}
+	return fmt.Sprintf("%s is not supported by dialect %s, %s", e.Feature, e.Dialect, e.Hint)
+}
+
+// ErrTooManyRows is the failure of scanning more rows than the limit, see MaxRows and SetDefaultMaxRows.
+type ErrTooManyRows struct {
+	Max int // Max is the limit of the rows
+}
+
+func (e ErrTooManyRows) Error() string {
+	return fmt.Sprintf("too many rows, the limit is %d", e.Max)
+}
+
+// ScanError is the failure of scanning a row or transforming the scanned value of a column, see ResultColumnSelectSpec.
+type ScanError struct {
+	Row    int    // Row is the index of the row in the result, starting from 0
+	Table  string // Table is the alias of the table of the transformed column, empty for the failures of scanning the row
+	Column string // Column is the name of the transformed column, empty for the failures of scanning the row
+	Err    error
+}
+
+func (e ScanError) Error() string {
+	if e.Column == "" {
+		return fmt.Sprintf("failed to scan row %d: %v", e.Row, e.Err)
+	}
+	return fmt.Sprintf("failed to transform column %s.%s of row %d: %v", e.Table, e.Column, e.Row, e.Err)
+}
+
+func (e ScanError) Unwrap() error {
+	return e.Err
+}
+
+// Cause returns the underlying error, see errors.Cause.
+func (e ScanError) Cause() error {
+	return e.Err
+}
+
+// NotifyError is the failure of sending the notification (see Notify) after the successful execution of the statement,
+// the statement is applied (committed unless executed within a transaction) and Result is the result of its execution.
+type NotifyError struct {
+	Channel string     // Channel is the channel of the notification
+	Result  sql.Result // Result is the result of the execution of the statement
+	Err     error
+}
+
+func (e NotifyError) Error() string {
+	return fmt.Sprintf("statement executed but failed to notify channel %s: %v", e.Channel, e.Err)
+}
+
+func (e NotifyError) Unwrap() error {
+	return e.Err
+}
+
+// Cause returns the underlying error, see errors.Cause.
+func (e NotifyError) Cause() error {
+	return e.Err
+}
@@ -0,0 +1,89 @@
+package sqlb
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrors(t *testing.T) {
+	table1 := UseTable[testStruct1]().Alias("t1").Seal()
+
+	t.Run("no columns", func(t *testing.T) {
+		_, _, err := Select().From(table1).TryBuild()
+		require.ErrorIs(t, err, ErrNoColumns)
+	})
+
+	t.Run("bad clause order", func(t *testing.T) {
+		require.PanicsWithError(t, "unexpected previous action SELECT, expected any of [SELECT FROM,SELECT JOIN,SELECT WHERE]", func() {
+			Select(table1.Col("pk1")).Where(table1.Col("pk1"), "= 1")
+		})
+
+		var got ErrBadClauseOrder
+		func() {
+			defer func() {
+				require.True(t, errors.As(recover().(error), &got))
+			}()
+			Select(table1.Col("pk1")).Where(table1.Col("pk1"), "= 1")
+		}()
+		require.Equal(t, ErrBadClauseOrder{
+			Got:  "SELECT",
+			Want: []string{"SELECT FROM", "SELECT JOIN", "SELECT WHERE"},
+		}, got)
+	})
+
+	t.Run("bad clause order via TryAssemble", func(t *testing.T) {
+		b, err := TryAssemble(func() *SqlBuilder {
+			return Select(table1.Col("pk1")).Where(table1.Col("pk1"), "= 1")
+		})
+		require.Nil(t, b)
+		require.Equal(t, ErrBadClauseOrder{
+			Got:  "SELECT",
+			Want: []string{"SELECT FROM", "SELECT JOIN", "SELECT WHERE"},
+		}, err)
+
+		b, err = TryAssemble(func() *SqlBuilder {
+			return Select(table1.Col("pk1")).From(table1)
+		})
+		require.NoError(t, err)
+		require.NotNil(t, b)
+
+		require.PanicsWithValue(t, "boom", func() {
+			_, _ = TryAssemble(func() *SqlBuilder {
+				panic("boom")
+			})
+		}, "other panics are programming errors")
+	})
+
+	t.Run("unknown column", func(t *testing.T) {
+		_, err := Select(table1.Col("pk1")).From(table1).OrderByName("cost", ASC, table1.Col("pk1"))
+		var got ErrUnknownColumn
+		require.True(t, errors.As(err, &got))
+		require.Equal(t, ErrUnknownColumn{Column: "cost"}, got)
+
+		data, err := json.Marshal(Select(table1.Col("pk1")).From(table1))
+		require.NoError(t, err)
+		data = bytes.Replace(data, []byte(`"pk1"`), []byte(`"dropped"`), 1)
+		err = json.Unmarshal(data, &SqlBuilder{})
+		require.True(t, errors.As(err, &got))
+		require.Equal(t, ErrUnknownColumn{Table: "table1", Column: "dropped"}, got)
+	})
+
+	t.Run("unknown column by name", func(t *testing.T) {
+		_, err := TryAssemble(func() *SqlBuilder {
+			return Select(table1.Col("dropped")).From(table1)
+		})
+		require.Equal(t, ErrUnknownColumn{Table: "table1", Column: "dropped"}, err)
+	})
+
+	t.Run("unsupported by dialect", func(t *testing.T) {
+		_, _, err := Select(table1.Col("pk1")).From(table1).Cube(table1.Col("pk1")).WithDialect(DialectSQLite).TryBuild()
+		require.Equal(t, ErrUnsupportedByDialect{Feature: "CUBE", Dialect: DialectSQLite}, err)
+
+		_, _, err = InsertInto(table1).Values(testStruct1{}).Returning(table1.Col("pk1")).WithDialect(DialectMySQL).TryBuild()
+		require.Equal(t, ErrUnsupportedByDialect{Feature: "RETURNING", Dialect: DialectMySQL}, err)
+	})
+}
@@ -0,0 +1,68 @@
+package sqlb
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Executor executes the statements generated by the builder.
+//
+// Use WrapExecutor to adapt *sql.DB, *sql.Tx or *sql.Conn.
+type Executor interface {
+	QueryContext(ctx context.Context, query string, args ...any) (SqlRows, error)
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// StdExecutor is implemented by *sql.DB, *sql.Tx and *sql.Conn.
+type StdExecutor interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+var _ StdExecutor = (*sql.DB)(nil)
+var _ StdExecutor = (*sql.Tx)(nil)
+var _ StdExecutor = (*sql.Conn)(nil)
+
+// TxExecutor is implemented by the custom executors which can be bound to a transaction (eg: pgx.Tx adapters),
+// the executors wrapping *sql.Tx via WrapExecutor are bound to a transaction without implementing it.
+type TxExecutor interface {
+	Executor
+	InTransaction() bool
+}
+
+// WrapExecutor adapts the standard library executor (*sql.DB, *sql.Tx or *sql.Conn) to Executor.
+func WrapExecutor(std StdExecutor) Executor {
+	if std == nil {
+		panic("executor is nil")
+	}
+	return stdExecutor{std: std}
+}
+
+type stdExecutor struct {
+	std StdExecutor
+}
+
+func (e stdExecutor) QueryContext(ctx context.Context, query string, args ...any) (SqlRows, error) {
+	rows, err := e.std.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (e stdExecutor) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return e.std.ExecContext(ctx, query, args...)
+}
+
+// inTransaction returns true if the executor is bound to a transaction.
+func inTransaction(exec Executor) bool {
+	if tx, ok := exec.(TxExecutor); ok {
+		return tx.InTransaction()
+	}
+	std, ok := exec.(stdExecutor)
+	if !ok {
+		return false
+	}
+	_, ok = std.std.(*sql.Tx)
+	return ok
+}
@@ -0,0 +1,65 @@
+package sqlb
+
+import (
+	"strings"
+)
+
+// Expr is an SQL expression composed of tokens, can be used anywhere a token is accepted (WHERE, DO UPDATE,...).
+//
+// Arguments bound into the expression via Arg are collected into the statement's args,
+// their placeholders are allocated when building, after the arguments provided via Args.
+type Expr struct {
+	tokens []any
+}
+
+// boundArg is a token represents an argument, rendered as a placeholder.
+type boundArg struct {
+	value any
+}
+
+// NewExpr creates an expression from tokens, tokens are separated by a space, same as WHERE tokens.
+func NewExpr(tokens ...any) Expr {
+	var e Expr
+	for i, token := range tokens {
+		if i > 0 {
+			e.tokens = append(e.tokens, " ")
+		}
+		if s, ok := token.(string); ok {
+			token = strings.TrimSpace(s)
+		}
+		e.tokens = append(e.tokens, token)
+	}
+	return e
+}
+
+// Arg creates an expression binds the value as an argument.
+func Arg(value any) Expr {
+	return Expr{
+		tokens: []any{boundArg{value: value}},
+	}
+}
+
+// concatExpr creates an expression from tokens without any separator.
+func concatExpr(tokens ...any) Expr {
+	return Expr{
+		tokens: tokens,
+	}
+}
+
+// valueToken returns the token as is if it is a column, an expression or an aggregate, otherwise binds it as an argument.
+func valueToken(value any) any {
+	switch v := value.(type) {
+	case GenericColumnToUse, Expr, Aggregate:
+		return v
+	default:
+		return boundArg{value: v}
+	}
+}
+
+// render renders the expression with placeholders starting from $1, used for debugging.
+func (e Expr) render() (sql string, args []any) {
+	sb := strings.Builder{}
+	ctx := newBuildContext(&sb, nil, DialectPostgres)
+	ctx.writeToken(e, "expression")
+	return sb.String(), ctx.args
+}
@@ -0,0 +1,83 @@
+package sqlb
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// SelectByFieldMask creates the SELECT builder of the columns resolved from the field mask, see ColumnsByFieldMask.
+func SelectByFieldMask[T any](table *TableToUse[T], mask []string) (*SqlBuilder, error) {
+	columns, err := table.ColumnsByFieldMask(mask)
+	if err != nil {
+		return nil, err
+	}
+	return Select(columns...).From(table), nil
+}
+
+// ColumnsByFieldMask resolves the API field mask (eg: paths of protobuf FieldMask or GraphQL selections) to the columns,
+// in order of the table columns. Empty mask resolves to all the columns.
+//
+// The paths match the column names, either as-is or converted from lowerCamelCase (eg: createdAt matches created_at),
+// nested paths produce error, unknown fields produce ErrUnknownColumn.
+func (t *TableToUse[T]) ColumnsByFieldMask(mask []string) ([]GenericColumnToUse, error) {
+	t.mustSealed()
+	if len(mask) == 0 {
+		return t.Columns(), nil
+	}
+
+	requested := make(map[string]struct{}, len(mask))
+	for _, path := range mask {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			return nil, errors.New("field mask contains empty path")
+		}
+		if strings.Contains(path, ".") {
+			return nil, errors.Errorf("nested field mask path %q is not supported", path)
+		}
+
+		columnName, found := t.columnNameOfField(path)
+		if !found {
+			return nil, ErrUnknownColumn{Table: t.name, Column: path}
+		}
+		requested[columnName] = struct{}{}
+	}
+
+	var columns []string
+	for _, columnName := range t.metadata.ColumnsName() {
+		if _, found := requested[columnName]; found {
+			columns = append(columns, columnName)
+		}
+	}
+	return t.Columns(columns...), nil
+}
+
+// columnNameOfField returns name of the column matches the field mask path.
+func (t *TableToUse[T]) columnNameOfField(path string) (string, bool) {
+	candidates := []string{path, camelToSnakeCase(path)}
+	for _, columnName := range t.metadata.ColumnsName() {
+		unquoted := strings.Trim(columnName, `"`)
+		for _, candidate := range candidates {
+			if unquoted == candidate {
+				return columnName, true
+			}
+		}
+	}
+	return "", false
+}
+
+// camelToSnakeCase converts lowerCamelCase to snake_case, eg: createdAt to created_at.
+func camelToSnakeCase(s string) string {
+	var sb strings.Builder
+	for i, r := range s {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				sb.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
@@ -0,0 +1,45 @@
+package sqlb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectByFieldMask(t *testing.T) {
+	table1 := UseTable[testStruct1]().Alias("t1").Seal()
+
+	b, err := SelectByFieldMask(table1, []string{"cost", "pk1", "cost"})
+	require.NoError(t, err)
+	gotSql, _ := b.Build()
+	require.Equal(t, "SELECT t1.pk1, t1.cost\nFROM table1 AS t1\n", gotSql, "must follow order of the table columns")
+
+	b, err = SelectByFieldMask(table1, nil)
+	require.NoError(t, err)
+	gotSql, _ = b.Build()
+	require.Equal(t, "SELECT t1.pk1, t1.pk2, t1.amount, t1.cost\nFROM table1 AS t1\n", gotSql)
+
+	_, err = SelectByFieldMask(table1, []string{"secret"})
+	require.Equal(t, ErrUnknownColumn{Table: "table1", Column: "secret"}, err)
+
+	_, err = SelectByFieldMask(table1, []string{"cost.currency"})
+	require.EqualError(t, err, `nested field mask path "cost.currency" is not supported`)
+
+	_, err = SelectByFieldMask(table1, []string{" "})
+	require.EqualError(t, err, "field mask contains empty path")
+}
+
+func TestTableToUse_ColumnsByFieldMask(t *testing.T) {
+	audit := UseTable[testEmbeddedRow]().Seal()
+	columns, err := audit.ColumnsByFieldMask([]string{"createdAt", "updated_at"})
+	require.NoError(t, err)
+	require.Len(t, columns, 2)
+	require.Equal(t, "created_at", columns[0].NameOnly())
+	require.Equal(t, "updated_at", columns[1].NameOnly())
+}
+
+func Test_camelToSnakeCase(t *testing.T) {
+	require.Equal(t, "created_at", camelToSnakeCase("createdAt"))
+	require.Equal(t, "id", camelToSnakeCase("id"))
+	require.Equal(t, "user_id_hash", camelToSnakeCase("userIdHash"))
+}
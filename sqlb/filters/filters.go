@@ -0,0 +1,258 @@
+// Package filters maps the HTTP query parameters (eg: ?status=active&created_at[gte]=2024-01-01&sort=-created_at&page=2)
+// onto a SELECT builder, using a per-table allow-list of the filterable and sortable columns.
+//
+// Values are always bound as arguments, columns are resolved from the allow-list only,
+// so the user input never reaches the statement text.
+package filters
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/VictorTrustyDev/simple-go-sql-builder/sqlb"
+)
+
+// ErrInvalidParameter is the cause of the errors of the invalid query parameters, typically mapped to HTTP 400.
+var ErrInvalidParameter = errors.New("invalid query parameter")
+
+const (
+	ParamSort     = "sort"
+	ParamPage     = "page"
+	ParamPageSize = "page_size"
+)
+
+// Operator is the comparison operator of the filter, provided as suffix of the parameter name, eg: created_at[gte].
+type Operator string
+
+const (
+	OpEq       Operator = "eq"       // [column] = $n, or IN when multiple values provided. Default when no suffix.
+	OpNotEq    Operator = "ne"       // [column] <> $n
+	OpLt       Operator = "lt"       // [column] < $n
+	OpLte      Operator = "lte"      // [column] <= $n
+	OpGt       Operator = "gt"       // [column] > $n
+	OpGte      Operator = "gte"      // [column] >= $n
+	OpContains Operator = "contains" // [column] ILIKE %value%, the value is matched literally
+)
+
+// Filter is a filterable column.
+type Filter struct {
+	Column sqlb.GenericColumnToUse
+	// Operators are the allowed operators in addition to OpEq, which is always allowed.
+	Operators []Operator
+	// Parse converts the raw value before binding, default binds the raw string.
+	Parse func(raw string) (any, error)
+}
+
+// Spec is the allow-list of a table, keyed by the parameter name exposed to the clients.
+type Spec struct {
+	Filters map[string]Filter
+	Sorts   map[string]sqlb.GenericColumnToUse
+	// DefaultSort is applied when no sort parameter provided, same format as the parameter, eg: -created_at,id.
+	DefaultSort string
+	// DefaultPageSize is applied when no page_size parameter provided, zero means no pagination unless requested.
+	DefaultPageSize int
+	// MaxPageSize caps the page_size parameter, zero means no limit.
+	MaxPageSize int
+}
+
+// Apply adds the WHERE, ORDER BY, OFFSET and LIMIT clauses to the SELECT builder from the query parameters.
+//
+// The builder must be right after FROM/JOIN or WHERE, the filters are AND-ed to the existing WHERE clause.
+// Parameters out of the allow-list produce error wraps ErrInvalidParameter, the builder is untouched in that case.
+func Apply(b *sqlb.SqlBuilder, spec Spec, params url.Values) (*sqlb.SqlBuilder, error) {
+	predicates, err := spec.predicates(params)
+	if err != nil {
+		return nil, err
+	}
+
+	sortParam := params.Get(ParamSort)
+	if sortParam == "" {
+		sortParam = spec.DefaultSort
+	}
+	orders, err := spec.orders(sortParam)
+	if err != nil {
+		return nil, err
+	}
+
+	pagination, err := spec.pagination(params)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, predicate := range predicates {
+		if b.AnyWhereTokens() {
+			b.And(predicate)
+		} else {
+			b.Where(predicate)
+		}
+	}
+	for i, o := range orders {
+		if i == 0 {
+			b.OrderBy(o.column, o.order)
+		} else {
+			b.ThenBy(o.column, o.order)
+		}
+	}
+	return b.Pagination(pagination), nil
+}
+
+// predicates builds the predicates from the filter parameters, in order of the parameter name.
+func (s Spec) predicates(params url.Values) ([]sqlb.Expr, error) {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		if name == ParamSort || name == ParamPage || name == ParamPageSize {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	predicates := make([]sqlb.Expr, 0, len(names))
+	for _, name := range names {
+		field, op := parseParamName(name)
+		filter, found := s.Filters[field]
+		if !found {
+			return nil, errors.Wrapf(ErrInvalidParameter, "unknown filter %q", field)
+		}
+		if !filter.allows(op) {
+			return nil, errors.Wrapf(ErrInvalidParameter, "operator %q is not allowed for filter %q", op, field)
+		}
+
+		values := make([]any, 0, len(params[name]))
+		for _, raw := range params[name] {
+			value, err := filter.parse(raw)
+			if err != nil {
+				return nil, errors.Wrapf(ErrInvalidParameter, "invalid value of filter %q: %v", field, err)
+			}
+			values = append(values, value)
+		}
+		if len(values) > 1 && op != OpEq {
+			return nil, errors.Wrapf(ErrInvalidParameter, "multiple values are only allowed for operator %q of filter %q", OpEq, field)
+		}
+
+		predicates = append(predicates, predicate(filter.Column, op, values))
+	}
+	return predicates, nil
+}
+
+// parseParamName splits the parameter name into the field and the operator, eg: created_at[gte].
+func parseParamName(name string) (field string, op Operator) {
+	if strings.HasSuffix(name, "]") {
+		if i := strings.LastIndex(name, "["); i > 0 {
+			return name[:i], Operator(name[i+1 : len(name)-1])
+		}
+	}
+	return name, OpEq
+}
+
+func (f Filter) allows(op Operator) bool {
+	if op == OpEq {
+		return true
+	}
+	for _, allowed := range f.Operators {
+		if allowed == op {
+			return true
+		}
+	}
+	return false
+}
+
+func (f Filter) parse(raw string) (any, error) {
+	if f.Parse == nil {
+		return raw, nil
+	}
+	return f.Parse(raw)
+}
+
+func predicate(column sqlb.GenericColumnToUse, op Operator, values []any) sqlb.Expr {
+	switch op {
+	case OpEq:
+		if len(values) > 1 {
+			return sqlb.In(column, values...)
+		}
+		return sqlb.Eq(column, values[0])
+	case OpNotEq:
+		return sqlb.NotEq(column, values[0])
+	case OpLt:
+		return sqlb.Lt(column, values[0])
+	case OpLte:
+		return sqlb.Lte(column, values[0])
+	case OpGt:
+		return sqlb.Gt(column, values[0])
+	case OpGte:
+		return sqlb.Gte(column, values[0])
+	case OpContains:
+		return sqlb.ILike(column, "%"+sqlb.EscapeLikePattern(fmt.Sprint(values[0]))+"%")
+	default:
+		panic("unknown operator " + string(op))
+	}
+}
+
+type order struct {
+	column sqlb.GenericColumnToUse
+	order  sqlb.OrderType
+}
+
+// orders resolves the sort parameter, comma-separated, prefixed by - for descending.
+func (s Spec) orders(sortParam string) ([]order, error) {
+	if sortParam == "" {
+		return nil, nil
+	}
+
+	var orders []order
+	for _, field := range strings.Split(sortParam, ",") {
+		o := order{order: sqlb.ASC}
+		if strings.HasPrefix(field, "-") {
+			o.order = sqlb.DESC
+			field = field[1:]
+		}
+
+		column, found := s.Sorts[field]
+		if !found {
+			return nil, errors.Wrapf(ErrInvalidParameter, "unknown sort field %q", field)
+		}
+		o.column = column
+		orders = append(orders, o)
+	}
+	return orders, nil
+}
+
+// pagination returns the pagination from the page and page_size parameters, nil if not paginated.
+func (s Spec) pagination(params url.Values) (*sqlb.Pagination, error) {
+	page, err := positiveIntParam(params, ParamPage, 1)
+	if err != nil {
+		return nil, err
+	}
+	size, err := positiveIntParam(params, ParamPageSize, s.DefaultPageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	if size == 0 {
+		if params.Has(ParamPage) {
+			return nil, errors.Wrapf(ErrInvalidParameter, "%s is required", ParamPageSize)
+		}
+		return nil, nil
+	}
+	if s.MaxPageSize > 0 && size > s.MaxPageSize {
+		return nil, errors.Wrapf(ErrInvalidParameter, "%s must not exceed %d", ParamPageSize, s.MaxPageSize)
+	}
+	return sqlb.NewPaginationFromPagingConfig(page, size), nil
+}
+
+func positiveIntParam(params url.Values, name string, defaultValue int) (int, error) {
+	raw := params.Get(name)
+	if raw == "" {
+		return defaultValue, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < 1 {
+		return 0, errors.Wrapf(ErrInvalidParameter, "%s must be a positive integer", name)
+	}
+	return value, nil
+}
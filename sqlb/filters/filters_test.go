@@ -0,0 +1,186 @@
+package filters
+
+import (
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/VictorTrustyDev/simple-go-sql-builder/sqlb"
+)
+
+type testTicket struct {
+	Id       int64
+	Status   string
+	Title    string
+	Priority int
+}
+
+var _ = sqlb.NewTableMetadata[testTicket]("tickets").
+	AddColumns(
+		sqlb.NewColumnMetadata[testTicket]("id").
+			PrimaryKey().
+			InsertSpec(func(t testTicket) any {
+				return t.Id
+			}).
+			SelectSpec(func(t *testTicket) sqlb.ResultColumnSelectSpec {
+				return sqlb.ResultColumnSelectSpec{
+					ToQueryArg: func() any {
+						return &t.Id
+					},
+				}
+			}),
+		sqlb.NewColumnMetadata[testTicket]("status").
+			InsertSpec(func(t testTicket) any {
+				return t.Status
+			}).
+			SelectSpec(func(t *testTicket) sqlb.ResultColumnSelectSpec {
+				return sqlb.ResultColumnSelectSpec{
+					ToQueryArg: func() any {
+						return &t.Status
+					},
+				}
+			}),
+		sqlb.NewColumnMetadata[testTicket]("title").
+			InsertSpec(func(t testTicket) any {
+				return t.Title
+			}).
+			SelectSpec(func(t *testTicket) sqlb.ResultColumnSelectSpec {
+				return sqlb.ResultColumnSelectSpec{
+					ToQueryArg: func() any {
+						return &t.Title
+					},
+				}
+			}),
+		sqlb.NewColumnMetadata[testTicket]("priority").
+			InsertSpec(func(t testTicket) any {
+				return t.Priority
+			}).
+			SelectSpec(func(t *testTicket) sqlb.ResultColumnSelectSpec {
+				return sqlb.ResultColumnSelectSpec{
+					ToQueryArg: func() any {
+						return &t.Priority
+					},
+				}
+			}),
+	).Build(sqlb.TableMetadataBuildOption{
+	ExpectedPkColumns: []string{"id"},
+})
+
+func newTestSpec(tickets *sqlb.TableToUse[testTicket]) Spec {
+	return Spec{
+		Filters: map[string]Filter{
+			"status": {Column: tickets.Col("status")},
+			"title":  {Column: tickets.Col("title"), Operators: []Operator{OpContains}},
+			"priority": {
+				Column:    tickets.Col("priority"),
+				Operators: []Operator{OpGte, OpLt},
+				Parse: func(raw string) (any, error) {
+					return strconv.Atoi(raw)
+				},
+			},
+		},
+		Sorts: map[string]sqlb.GenericColumnToUse{
+			"id":       tickets.Col("id"),
+			"priority": tickets.Col("priority"),
+		},
+		DefaultSort: "id",
+		MaxPageSize: 100,
+	}
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		where    bool
+		wantSql  string
+		wantArgs []any
+		wantErr  string
+	}{
+		{
+			name:    "no params",
+			query:   "",
+			wantSql: "SELECT t.id\nFROM tickets AS t\nORDER BY t.id ASC\n",
+		},
+		{
+			name:     "filters, sort and pagination",
+			query:    "status=open&status=pending&priority[gte]=2&title[contains]=50%25&sort=-priority,id&page=3&page_size=20",
+			wantSql:  "SELECT t.id\nFROM tickets AS t\nWHERE t.priority >= $1 AND t.status IN ($2, $3) AND t.title ILIKE $4\nORDER BY t.priority DESC, t.id ASC\nOFFSET 40 LIMIT 20\n",
+			wantArgs: []any{2, "open", "pending", `%50\%%`},
+		},
+		{
+			name:     "and-ed to the existing where clause",
+			query:    "status=open",
+			where:    true,
+			wantSql:  "SELECT t.id\nFROM tickets AS t\nWHERE t.id > $1 AND t.status = $2\nORDER BY t.id ASC\n",
+			wantArgs: []any{int64(10), "open"},
+		},
+		{
+			name:    "unknown filter",
+			query:   "secret=1",
+			wantErr: `unknown filter "secret"`,
+		},
+		{
+			name:    "operator not allowed",
+			query:   "status[ne]=open",
+			wantErr: `operator "ne" is not allowed for filter "status"`,
+		},
+		{
+			name:    "multiple values of non-eq operator",
+			query:   "priority[gte]=1&priority[gte]=2",
+			wantErr: `multiple values are only allowed for operator "eq" of filter "priority"`,
+		},
+		{
+			name:    "invalid value",
+			query:   "priority=high",
+			wantErr: `invalid value of filter "priority"`,
+		},
+		{
+			name:    "unknown sort field",
+			query:   "sort=-title",
+			wantErr: `unknown sort field "title"`,
+		},
+		{
+			name:    "page size exceeds max",
+			query:   "page_size=1000",
+			wantErr: "page_size must not exceed 100",
+		},
+		{
+			name:    "page without page size",
+			query:   "page=2",
+			wantErr: "page_size is required",
+		},
+		{
+			name:    "invalid page",
+			query:   "page=0&page_size=10",
+			wantErr: "page must be a positive integer",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tickets := sqlb.UseTable[testTicket]().Alias("t").Seal()
+			params, err := url.ParseQuery(tt.query)
+			require.NoError(t, err)
+
+			b := sqlb.Select(tickets.Col("id")).From(tickets)
+			if tt.where {
+				b.Where(sqlb.Gt(tickets.Col("id"), int64(10)))
+			}
+
+			b, err = Apply(b, newTestSpec(tickets), params)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				require.True(t, errors.Is(err, ErrInvalidParameter))
+				return
+			}
+			require.NoError(t, err)
+
+			gotSql, gotArgs := b.Build()
+			require.Equal(t, tt.wantSql, gotSql)
+			require.Equal(t, tt.wantArgs, gotArgs)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package sqlb
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// ErrNotFound is returned by QueryFirst and QuerySingle when no rows match.
+var ErrNotFound = errors.New("not found")
+
+// ErrMultipleRows is returned by QuerySingle when more than one row matches.
+var ErrMultipleRows = errors.New("multiple rows found")
+
+// QueryFirst executes the SELECT statement with LIMIT 1 using the given executor,
+// returns the scanned rows contain exactly one row, or ErrNotFound if no rows match.
+// Provide ORDER BY to get deterministic result.
+func (b *SqlBuilder) QueryFirst(ctx context.Context, exec Executor) (*ScannedRows, error) {
+	return b.queryAtMost(ctx, exec, 1)
+}
+
+// QuerySingle executes the SELECT statement using the given executor, expects exactly one row matches.
+// Returns ErrNotFound if no rows match, ErrMultipleRows if more than one row matches.
+// At most 2 rows are fetched, or the limit provided by Limit if higher.
+func (b *SqlBuilder) QuerySingle(ctx context.Context, exec Executor) (*ScannedRows, error) {
+	limit := b.limit
+	if limit < 2 { // a lower limit would hide the other rows
+		limit = 2
+	}
+	rows, err := b.queryAtMost(ctx, exec, limit)
+	if err != nil {
+		return nil, err
+	}
+	if rows.Count() > 1 {
+		return nil, ErrMultipleRows
+	}
+	return rows, nil
+}
+
+// queryAtMost executes the SELECT statement fetching at most limit rows, regardless of the limit provided by Limit.
+func (b *SqlBuilder) queryAtMost(ctx context.Context, exec Executor, limit uint) (*ScannedRows, error) {
+	b.mustTypeSelect()
+	b.mustBasicSelect()
+
+	// the limit is applied to a copy, the builder may be shared by the concurrent callers
+	limited := *b
+	limited.limit = limit
+
+	rows, err := limited.QueryWithExecutor(ctx, exec)
+	if err != nil {
+		return nil, err
+	}
+	if rows.Count() == 0 {
+		return nil, ErrNotFound
+	}
+	return rows, nil
+}
@@ -0,0 +1,87 @@
+package sqlb
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// fixedRowsExecutor returns the rows and records the statements of the queries, for the sake of the test.
+type fixedRowsExecutor struct {
+	rows    [][]any
+	queries []string
+}
+
+func (e *fixedRowsExecutor) QueryContext(_ context.Context, query string, _ ...any) (SqlRows, error) {
+	e.queries = append(e.queries, query)
+	return &valuesRows{rows: e.rows}, nil
+}
+
+func (e *fixedRowsExecutor) ExecContext(context.Context, string, ...any) (sql.Result, error) {
+	return nil, errors.New("not supported")
+}
+
+func TestSqlBuilder_QueryFirst(t *testing.T) {
+	products := UseTable[testDdlRow]().Seal()
+	newBuilder := func() *SqlBuilder {
+		return Select(products.Columns("id", "title")...).From(products).OrderBy(products.Col("id"), ASC)
+	}
+
+	t.Run("first", func(t *testing.T) {
+		exec := &fixedRowsExecutor{rows: [][]any{{int64(1), "a"}}}
+		b := newBuilder()
+		rows, err := b.QueryFirst(context.Background(), exec)
+		require.NoError(t, err)
+		require.True(t, rows.Next())
+		require.Equal(t, "a", products.ReadFromRow(rows).Title)
+		require.Equal(t, "SELECT products.id, products.title\nFROM products AS products\nORDER BY products.id ASC\nLIMIT 1\n", exec.queries[0])
+
+		gotSql, _ := b.Build()
+		require.NotContains(t, gotSql, "LIMIT", "limit of the builder must be restored")
+
+		_, err = newBuilder().QueryFirst(context.Background(), &fixedRowsExecutor{})
+		require.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("single", func(t *testing.T) {
+		exec := &fixedRowsExecutor{rows: [][]any{{int64(1), "a"}}}
+		rows, err := newBuilder().QuerySingle(context.Background(), exec)
+		require.NoError(t, err)
+		require.Equal(t, 1, rows.Count())
+		require.Contains(t, exec.queries[0], "LIMIT 2")
+
+		_, err = newBuilder().QuerySingle(context.Background(), &fixedRowsExecutor{rows: [][]any{{int64(1), "a"}, {int64(2), "b"}}})
+		require.ErrorIs(t, err, ErrMultipleRows)
+
+		_, err = newBuilder().QuerySingle(context.Background(), &fixedRowsExecutor{})
+		require.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("lower limit does not hide multiple rows", func(t *testing.T) {
+		exec := &fixedRowsExecutor{rows: [][]any{{int64(1), "a"}, {int64(2), "b"}}}
+		_, err := newBuilder().Limit(1).QuerySingle(context.Background(), exec)
+		require.ErrorIs(t, err, ErrMultipleRows)
+		require.Contains(t, exec.queries[0], "LIMIT 2")
+	})
+
+	t.Run("shared builder", func(t *testing.T) {
+		b := newBuilder().Limit(5)
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, _ = b.QueryFirst(context.Background(), &fixedRowsExecutor{rows: [][]any{{int64(1), "a"}}})
+				_, _ = b.QuerySingle(context.Background(), &fixedRowsExecutor{rows: [][]any{{int64(1), "a"}}})
+			}()
+		}
+		wg.Wait()
+
+		gotSql, _ := b.Build()
+		require.Contains(t, gotSql, "LIMIT 5", "the builder is not modified")
+	})
+}
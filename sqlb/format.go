@@ -0,0 +1,138 @@
+package sqlb
+
+import (
+	"strings"
+)
+
+// WithFormat sets the layout of the generated statement, default is FormatDefault.
+func (b *SqlBuilder) WithFormat(format SqlFormat) *SqlBuilder {
+	switch format {
+	case FormatDefault, FormatSingleLine, FormatPretty:
+	default:
+		panic("unknown format")
+	}
+	b.format = format
+	return b
+}
+
+// OmitRedundantAlias omits 'AS [alias]' of the tables in FROM and JOIN when the alias equals the table name,
+// e.g. 'FROM orders' instead of 'FROM orders AS orders'. The columns are still qualified by the alias, which is the table name.
+func (b *SqlBuilder) OmitRedundantAlias() *SqlBuilder {
+	b.omitRedundantAlias = true
+	return b
+}
+
+// formatSql re-layouts the statement generated in default layout into the requested format.
+func formatSql(stmt string, format SqlFormat) string {
+	switch format {
+	case FormatSingleLine:
+		return normalizeSqlSpaces(stmt)
+	case FormatPretty:
+		var lines []string
+		for _, line := range strings.Split(stmt, "\n") {
+			isContinuation := len(line) > 0 && (line[0] == ' ' || line[0] == '\t')
+			line = normalizeSqlSpaces(line)
+			if line == "" {
+				continue
+			}
+			if isContinuation {
+				for _, part := range splitTopLevelComma(line) {
+					lines = append(lines, prettyIndent+part)
+				}
+				continue
+			}
+			lines = append(lines, line)
+		}
+		return strings.Join(lines, "\n")
+	default:
+		return stmt
+	}
+}
+
+const prettyIndent = "  "
+
+// indentLines prefixes every line of the statement with the pretty indentation.
+func indentLines(stmt string) string {
+	lines := strings.Split(stmt, "\n")
+	for i, line := range lines {
+		lines[i] = prettyIndent + line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// normalizeSqlSpaces collapses whitespaces into a single space, removes spaces before comma & closing parenthesis
+// and after opening parenthesis. Quoted literals and identifiers are kept as is.
+func normalizeSqlSpaces(stmt string) string {
+	sb := strings.Builder{}
+	sb.Grow(len(stmt))
+
+	var quote, last byte
+	pendingSpace := false
+	for i := 0; i < len(stmt); i++ {
+		c := stmt[i]
+
+		if quote != 0 {
+			sb.WriteByte(c)
+			last = c
+			if c == quote {
+				quote = 0
+			}
+			continue
+		}
+
+		switch c {
+		case ' ', '\t', '\n', '\r':
+			pendingSpace = true
+			continue
+		case ',', ')':
+			pendingSpace = false
+		}
+
+		if pendingSpace && last != 0 && last != '(' {
+			sb.WriteByte(' ')
+		}
+		pendingSpace = false
+
+		if c == '\'' || c == '"' {
+			quote = c
+		}
+		sb.WriteByte(c)
+		last = c
+	}
+
+	return sb.String()
+}
+
+// splitTopLevelComma splits the normalized statement by commas which are not inside parentheses or quotes.
+func splitTopLevelComma(stmt string) []string {
+	var parts []string
+	var quote byte
+	depth := 0
+	start := 0
+	for i := 0; i < len(stmt); i++ {
+		c := stmt[i]
+		if quote != 0 {
+			if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '\'', '"':
+			quote = c
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(stmt[start:i+1]))
+				start = i + 1
+			}
+		}
+	}
+	if rest := strings.TrimSpace(stmt[start:]); rest != "" {
+		parts = append(parts, rest)
+	}
+	return parts
+}
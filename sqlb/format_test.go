@@ -0,0 +1,110 @@
+package sqlb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+//goland:noinspection SqlNoDataSourceInspection
+func TestSqlBuilder_WithFormat(t *testing.T) {
+	newSelect := func() *SqlBuilder {
+		table1 := UseTable[testStruct1]().Alias("t1").Seal()
+		return Select(table1.Columns("pk1", "cost")...).
+			From(table1).
+			Where(table1.Col("pk1"), "= $1").Args("a").
+			OrderBy(table1.Col("cost"), DESC)
+	}
+	newInsert := func() *SqlBuilder {
+		table1 := UseTable[testStruct1]().Seal()
+		return InsertInto(table1).Values(testStruct1{Pk1: "1"}).
+			OnConflict(table1.PrimaryKeyColumns()...).
+			DoUpdateExceptPrimaryKeys().
+			Where(table1.Col("amount"), "< 'a  b'")
+	}
+
+	tests := []struct {
+		name    string
+		builder func() *SqlBuilder
+		format  SqlFormat
+		wantSql string
+	}{
+		{
+			name:    "select single line",
+			builder: newSelect,
+			format:  FormatSingleLine,
+			wantSql: `SELECT t1.pk1, t1.cost FROM table1 AS t1 WHERE t1.pk1 = $1 ORDER BY t1.cost DESC`,
+		},
+		{
+			name:    "select pretty",
+			builder: newSelect,
+			format:  FormatPretty,
+			wantSql: `SELECT t1.pk1, t1.cost
+FROM table1 AS t1
+WHERE t1.pk1 = $1
+ORDER BY t1.cost DESC`,
+		},
+		{
+			name: "select exists single line",
+			builder: func() *SqlBuilder {
+				table1 := UseTable[testStruct1]().Alias("t1").Seal()
+				return SelectExists().From(table1).Where(table1.Col("pk1"), "=", 2)
+			},
+			format:  FormatSingleLine,
+			wantSql: `SELECT EXISTS(SELECT 1 FROM table1 AS t1 WHERE t1.pk1 = 2)`,
+		},
+		{
+			name: "select exists pretty",
+			builder: func() *SqlBuilder {
+				table1 := UseTable[testStruct1]().Alias("t1").Seal()
+				return SelectExists().From(table1).Where(table1.Col("pk1"), "=", 2)
+			},
+			format: FormatPretty,
+			wantSql: `SELECT EXISTS(
+  SELECT 1 FROM table1 AS t1
+  WHERE t1.pk1 = 2
+)`,
+		},
+		{
+			name:    "insert single line, keep quoted literal as is",
+			builder: newInsert,
+			format:  FormatSingleLine,
+			wantSql: `INSERT INTO table1 (pk1, pk2, amount, cost) VALUES ($1,$2,$3,$4) ON CONFLICT (pk1, pk2) DO UPDATE SET amount = excluded.amount, cost = excluded.cost WHERE table1.amount < 'a  b'`,
+		},
+		{
+			name:    "insert pretty",
+			builder: newInsert,
+			format:  FormatPretty,
+			wantSql: `INSERT INTO table1 (pk1, pk2, amount, cost)
+VALUES ($1,$2,$3,$4)
+ON CONFLICT (pk1, pk2) DO UPDATE SET
+  amount = excluded.amount,
+  cost = excluded.cost
+WHERE table1.amount < 'a  b'`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotSql, _ := tt.builder().WithFormat(tt.format).Build()
+			require.Equal(t, tt.wantSql, gotSql)
+		})
+	}
+}
+
+func TestSqlBuilder_OmitRedundantAlias(t *testing.T) {
+	table1 := UseTable[testStruct1]().Seal()
+	table2 := UseTable[testStruct2]().Alias("t2").Seal()
+	builder := Select(table1.Col("pk1"), table2.Col("pk3")).
+		From(table1).
+		Join(InnerJoin, table2, table1.Col("pk1"), table2.Col("pk1")).
+		OmitRedundantAlias()
+
+	gotSql, _ := builder.Build()
+	require.Equal(t, "SELECT table1.pk1, t2.pk3\nFROM table1\nINNER JOIN table2 AS t2 ON table1.pk1 = t2.pk1\n", gotSql)
+
+	rows, err := builder.ScanRows(&valuesRows{rows: [][]any{{"a", int64(1)}}})
+	require.NoError(t, err)
+	require.True(t, rows.Next())
+	require.Equal(t, testStruct1{Pk1: "a"}, table1.ReadFromRow(rows))
+	require.Equal(t, testStruct2{Pk3: 1}, table2.ReadFromRow(rows))
+}
@@ -0,0 +1,50 @@
+package sqlb
+
+// SqlFragment is a custom expression type, rendered by writing into the build context.
+// Can be used anywhere a token is accepted (WHERE, DO UPDATE, expressions,...).
+type SqlFragment interface {
+	WriteSql(ctx *BuildContext)
+}
+
+// BuildContext is the statement being rendered, passed to SqlFragment to write into.
+type BuildContext struct {
+	c      *buildContext
+	clause string
+}
+
+// WriteString writes the raw SQL as is.
+func (ctx *BuildContext) WriteString(sql string) {
+	ctx.c.writeString(sql)
+}
+
+// WriteToken writes the token the same way as provided to the clause: column, expression, Arg, number,...
+func (ctx *BuildContext) WriteToken(token any) {
+	ctx.c.writeToken(token, ctx.clause)
+}
+
+// AddArg collects the argument and returns the placeholder allocated for it, e.g. $3 or ? depends on the dialect.
+// The placeholder must be written by the caller, see WriteArg.
+func (ctx *BuildContext) AddArg(value any) string {
+	return ctx.c.addArg(value)
+}
+
+// WriteArg collects the argument and writes the placeholder allocated for it.
+func (ctx *BuildContext) WriteArg(value any) {
+	ctx.c.writeString(ctx.c.addArg(value))
+}
+
+// WriteSubquery writes the SELECT statement, its placeholders are renumbered after the arguments collected so far.
+// Parentheses are not written.
+func (ctx *BuildContext) WriteSubquery(sub *SqlBuilder) {
+	ctx.c.writeSubquery(sub, ctx.clause)
+}
+
+// Dialect returns the dialect of the statement being rendered.
+func (ctx *BuildContext) Dialect() Dialect {
+	return ctx.c.dialect
+}
+
+// ArgsCount returns the number of arguments collected so far.
+func (ctx *BuildContext) ArgsCount() int {
+	return len(ctx.c.args)
+}
@@ -0,0 +1,132 @@
+package sqlb
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// jsonPathExists is a custom expression type generates 'jsonb_path_exists([column], $n)'.
+type jsonPathExists struct {
+	column GenericColumnToUse
+	path   string
+}
+
+func (e jsonPathExists) WriteSql(ctx *BuildContext) {
+	ctx.WriteString("jsonb_path_exists(")
+	ctx.WriteToken(e.column)
+	ctx.WriteString(", ")
+	ctx.WriteToken(Arg(e.path))
+	ctx.WriteString(")")
+}
+
+type testStatus string
+
+func (s testStatus) String() string {
+	return string(s)
+}
+
+func TestSqlFragment(t *testing.T) {
+	table1 := UseTable[testStruct1]().Alias("t1").Seal()
+
+	gotSql, gotArgs := Select(table1.Col("pk1")).
+		From(table1).
+		Where(Eq(table1.Col("pk2"), 1)).
+		And(jsonPathExists{column: table1.Col("cost"), path: "$.amount"}).
+		And(table1.Col("pk1"), "=", testStatus("active")).
+		Build()
+	require.Equal(t, "SELECT t1.pk1\nFROM table1 AS t1\nWHERE t1.pk2 = $1 AND jsonb_path_exists(t1.cost, $2) AND t1.pk1 = $3\n", gotSql)
+	require.Equal(t, []any{1, "$.amount", testStatus("active")}, gotArgs, "the Stringer is bound as the value itself")
+
+	t.Run("usable in DO UPDATE", func(t *testing.T) {
+		table1 := UseTable[testStruct1]().Seal()
+		gotSql, gotArgs := InsertInto(table1, table1.Col("pk1")).
+			Values(testStruct1{Pk1: "a"}).
+			OnConflict(table1.Col("pk1")).
+			DoUpdateSet(table1.Col("amount"), NewExpr(jsonPathExists{column: table1.Col("cost"), path: "$.x"})).
+			Build()
+		require.Equal(t, "INSERT INTO table1 (pk1)\nVALUES ($1)\nON CONFLICT (pk1) DO UPDATE SET\n amount = jsonb_path_exists(cost, $2)", gotSql)
+		require.Equal(t, []any{"a", "$.x"}, gotArgs)
+	})
+
+	t.Run("builder is not a token", func(t *testing.T) {
+		require.PanicsWithValue(t, "unexpected WHERE token type *sqlb.SqlBuilder, use Exists or NotExists to embed the subquery", func() {
+			Select(table1.Col("pk1")).From(table1).Where(SelectExists().From(table1)).Build()
+		})
+	})
+}
+
+// pointInBox is a custom expression type generates '[x] BETWEEN $n AND $n+1 AND [y] BETWEEN $n+2 AND $n+3'.
+type pointInBox struct {
+	x, y                   GenericColumnToUse
+	minX, minY, maxX, maxY float64
+}
+
+func (e pointInBox) WriteSql(ctx *BuildContext) {
+	ctx.WriteToken(e.x)
+	ctx.WriteString(" BETWEEN " + ctx.AddArg(e.minX) + " AND " + ctx.AddArg(e.maxX) + " AND ")
+	ctx.WriteToken(e.y)
+	ctx.WriteString(" BETWEEN ")
+	ctx.WriteArg(e.minY)
+	ctx.WriteString(" AND ")
+	ctx.WriteArg(e.maxY)
+}
+
+// argsCountFragment writes the dialect and the number of collected arguments.
+type argsCountFragment struct{}
+
+func (argsCountFragment) WriteSql(ctx *BuildContext) {
+	ctx.WriteString(fmt.Sprintf("'%s' = '%d'", ctx.Dialect(), ctx.ArgsCount()))
+}
+
+// inSubquery is a custom expression type generates '[column] IN ([subquery])'.
+type inSubquery struct {
+	column GenericColumnToUse
+	sub    *SqlBuilder
+}
+
+func (e inSubquery) WriteSql(ctx *BuildContext) {
+	ctx.WriteToken(e.column)
+	ctx.WriteString(" IN (")
+	ctx.WriteSubquery(e.sub)
+	ctx.WriteString(")")
+}
+
+func TestBuildContext(t *testing.T) {
+	table1 := UseTable[testStruct1]().Alias("t1").Seal()
+	table2 := UseTable[testStruct2]().Alias("t2").Seal()
+	box := pointInBox{x: table1.Col("amount"), y: table1.Col("cost"), minX: 1, minY: 2, maxX: 3, maxY: 4}
+
+	t.Run("placeholders are allocated", func(t *testing.T) {
+		gotSql, gotArgs := Select(table1.Col("pk1")).
+			From(table1).
+			Where(Eq(table1.Col("pk1"), "a")).
+			And(box).
+			And(argsCountFragment{}).
+			Build()
+		require.Equal(t, "SELECT t1.pk1\nFROM table1 AS t1\nWHERE t1.pk1 = $1 AND t1.amount BETWEEN $2 AND $3 AND t1.cost BETWEEN $4 AND $5 AND 'postgres' = '5'\n", gotSql)
+		require.Equal(t, []any{"a", 1.0, 3.0, 2.0, 4.0}, gotArgs)
+	})
+
+	t.Run("placeholders follow the dialect", func(t *testing.T) {
+		gotSql, gotArgs := Select(table1.Col("pk1")).
+			From(table1).
+			Where(box).
+			WithDialect(DialectMySQL).
+			Build()
+		require.Equal(t, "SELECT t1.pk1\nFROM table1 AS t1\nWHERE t1.amount BETWEEN ? AND ? AND t1.cost BETWEEN ? AND ?\n", gotSql)
+		require.Equal(t, []any{1.0, 3.0, 2.0, 4.0}, gotArgs)
+	})
+
+	t.Run("subquery placeholders are renumbered", func(t *testing.T) {
+		sub := Select(table2.Col("pk1")).From(table2).Where(Eq(table2.Col("pk1"), "b"))
+		gotSql, gotArgs := Select(table1.Col("pk1")).
+			From(table1).
+			Where(Eq(table1.Col("pk2"), "a")).
+			And(inSubquery{column: table1.Col("pk1"), sub: sub}).
+			Build()
+		require.Equal(t, "SELECT t1.pk1\nFROM table1 AS t1\nWHERE t1.pk2 = $1 AND t1.pk1 IN (SELECT t2.pk1 FROM table2 AS t2 WHERE t2.pk1 = $2)\n", gotSql)
+		require.Equal(t, []any{"a", "b"}, gotArgs)
+	})
+}
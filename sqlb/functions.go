@@ -0,0 +1,109 @@
+package sqlb
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Coalesce generates 'COALESCE([expr1], [expr2],...)'.
+//
+// Arguments are expression tokens: string is raw SQL, use Arg to bind a value.
+func Coalesce(exprs ...any) Expr {
+	if len(exprs) < 1 {
+		panic("COALESCE requires at least one argument")
+	}
+	return functionExpr("COALESCE", exprs...)
+}
+
+// NullIf generates 'NULLIF([a], [b])'.
+func NullIf(a, b any) Expr {
+	return functionExpr("NULLIF", a, b)
+}
+
+// Greatest generates 'GREATEST([expr1], [expr2],...)'.
+func Greatest(exprs ...any) Expr {
+	if len(exprs) < 1 {
+		panic("GREATEST requires at least one argument")
+	}
+	return functionExpr("GREATEST", exprs...)
+}
+
+// Least generates 'LEAST([expr1], [expr2],...)'.
+func Least(exprs ...any) Expr {
+	if len(exprs) < 1 {
+		panic("LEAST requires at least one argument")
+	}
+	return functionExpr("LEAST", exprs...)
+}
+
+// functionExpr generates '[name]([arg1], [arg2],...)'.
+func functionExpr(name string, args ...any) Expr {
+	tokens := []any{name, "("}
+	for i, arg := range args {
+		if arg == nil {
+			panic(fmt.Sprintf("argument no.%d of %s is nil, use \"NULL\" instead", i+1, name))
+		}
+		if i > 0 {
+			tokens = append(tokens, ", ")
+		}
+		tokens = append(tokens, arg)
+	}
+	tokens = append(tokens, ")")
+	return concatExpr(tokens...)
+}
+
+// Now generates 'NOW()'.
+func Now() Expr {
+	return concatExpr("NOW()")
+}
+
+var dateTruncFields = map[string]struct{}{
+	"microseconds": {}, "milliseconds": {}, "second": {}, "minute": {}, "hour": {}, "day": {}, "week": {},
+	"month": {}, "quarter": {}, "year": {}, "decade": {}, "century": {}, "millennium": {},
+}
+
+// DateTrunc generates 'date_trunc('[field]', [expr])', e.g. DateTrunc("day", col).
+func DateTrunc(field string, expr any) Expr {
+	field = strings.ToLower(field)
+	if _, found := dateTruncFields[field]; !found {
+		panic(fmt.Sprintf("unsupported date_trunc field %s", field))
+	}
+	return concatExpr("date_trunc('", field, "', ", expr, ")")
+}
+
+var extractFields = map[string]struct{}{
+	"century": {}, "day": {}, "decade": {}, "dow": {}, "doy": {}, "epoch": {}, "hour": {}, "isodow": {},
+	"isoyear": {}, "julian": {}, "microseconds": {}, "millennium": {}, "milliseconds": {}, "minute": {},
+	"month": {}, "quarter": {}, "second": {}, "timezone": {}, "timezone_hour": {}, "timezone_minute": {},
+	"week": {}, "year": {},
+}
+
+// Extract generates 'EXTRACT([field] FROM [expr])', e.g. Extract("epoch", col).
+func Extract(field string, expr any) Expr {
+	field = strings.ToLower(field)
+	if _, found := extractFields[field]; !found {
+		panic(fmt.Sprintf("unsupported EXTRACT field %s", field))
+	}
+	return concatExpr("EXTRACT(", field, " FROM ", expr, ")")
+}
+
+var intervalPattern = regexp.MustCompile(`^[0-9A-Za-z .:+-]+$`)
+
+// AddInterval generates '[expr] + INTERVAL [interval]', the interval is quoted, e.g. AddInterval(col, "7 days").
+func AddInterval(expr any, interval string) Expr {
+	return concatExpr(expr, " + INTERVAL ", intervalLiteral(interval))
+}
+
+// SubtractInterval generates '[expr] - INTERVAL [interval]', the interval is quoted, e.g. SubtractInterval(Now(), "1 hour").
+func SubtractInterval(expr any, interval string) Expr {
+	return concatExpr(expr, " - INTERVAL ", intervalLiteral(interval))
+}
+
+func intervalLiteral(interval string) string {
+	interval = strings.TrimSpace(interval)
+	if !intervalPattern.MatchString(interval) {
+		panic(fmt.Sprintf("invalid interval %q", interval))
+	}
+	return quoteLiteral(interval)
+}
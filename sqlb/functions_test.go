@@ -0,0 +1,103 @@
+package sqlb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFunctions(t *testing.T) {
+	table1 := UseTable[testStruct1]().Alias("t1").Seal()
+
+	tests := []struct {
+		name     string
+		expr     Expr
+		wantSql  string
+		wantArgs []any
+	}{
+		{
+			name:     "Coalesce",
+			expr:     Coalesce(table1.Col("pk1"), Arg("default")),
+			wantSql:  "COALESCE(t1.pk1, $1)",
+			wantArgs: []any{"default"},
+		},
+		{
+			name:    "NullIf",
+			expr:    NullIf(table1.Col("pk1"), "''"),
+			wantSql: "NULLIF(t1.pk1, '')",
+		},
+		{
+			name:     "Greatest & Least",
+			expr:     Eq(Greatest(table1.Col("amount"), 0), Least(table1.Col("pk2"), Arg(10))),
+			wantSql:  "GREATEST(t1.amount, 0) = LEAST(t1.pk2, $1)",
+			wantArgs: []any{10},
+		},
+		{
+			name:    "nested",
+			expr:    Coalesce(NullIf(table1.Col("pk1"), "''"), "'none'"),
+			wantSql: "COALESCE(NULLIF(t1.pk1, ''), 'none')",
+		},
+		{
+			name:    "date/time",
+			expr:    Gte(DateTrunc("DAY", table1.Col("pk1")), SubtractInterval(Now(), "7 days")),
+			wantSql: "date_trunc('day', t1.pk1) >= NOW() - INTERVAL '7 days'",
+		},
+		{
+			name:     "interval arithmetic & extract",
+			expr:     Lt(Extract("epoch", AddInterval(table1.Col("pk1"), "1 hour 30 minutes")), Arg(1700000000)),
+			wantSql:  "EXTRACT(epoch FROM t1.pk1 + INTERVAL '1 hour 30 minutes') < $1",
+			wantArgs: []any{1700000000},
+		},
+		{
+			name: "PostGIS",
+			expr: NewExpr(
+				StDWithin(AsGeography(table1.Col("pk1")), AsGeography(MakePoint(106.7, 10.8)), 500), "AND",
+				StContains(table1.Col("pk2"), AsGeometry(Arg("POINT(1 2)"))),
+			),
+			wantSql:  "ST_DWithin(t1.pk1::geography, ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography, $3) AND ST_Contains(t1.pk2, $4::geometry)",
+			wantArgs: []any{106.7, 10.8, float64(500), "POINT(1 2)"},
+		},
+		{
+			name:     "PostGIS distance & intersects",
+			expr:     NewExpr(StDistance(table1.Col("pk1"), MakePoint(1, 2)), "<", Arg(10), "AND", StIntersects(table1.Col("pk1"), table1.Col("pk2"))),
+			wantSql:  "ST_Distance(t1.pk1, ST_SetSRID(ST_MakePoint($1, $2), 4326)) < $3 AND ST_Intersects(t1.pk1, t1.pk2)",
+			wantArgs: []any{float64(1), float64(2), 10},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotSql, gotArgs := tt.expr.render()
+			require.Equal(t, tt.wantSql, gotSql)
+			require.Equal(t, tt.wantArgs, gotArgs)
+		})
+	}
+
+	t.Run("usable in DO UPDATE", func(t *testing.T) {
+		table1 := UseTable[testStruct1]().Seal()
+		gotSql, gotArgs := InsertInto(table1, table1.Col("pk1"), table1.Col("amount")).
+			Values(testStruct1{Pk1: "a", Amount: 1}).
+			OnConflict(table1.Col("pk1")).
+			DoUpdate(table1.Col("amount"), "=", Greatest(table1.Col("amount"), table1.Col("amount").Excluded(), Arg(0))).
+			Build()
+		require.Equal(t, "INSERT INTO table1 (pk1, amount)\nVALUES ($1,$2)\nON CONFLICT (pk1) DO UPDATE SET\n amount = GREATEST(amount, excluded.amount, $3)", gotSql)
+		require.Equal(t, []any{"a", 1, 0}, gotArgs)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		require.Panics(t, func() {
+			Coalesce()
+		})
+		require.Panics(t, func() {
+			Greatest(nil)
+		})
+		require.Panics(t, func() {
+			DateTrunc("day'); DROP TABLE x; --", "col")
+		})
+		require.Panics(t, func() {
+			Extract("unknown", "col")
+		})
+		require.Panics(t, func() {
+			AddInterval("col", "1 day'; --")
+		})
+	})
+}
@@ -0,0 +1,223 @@
+package sqlb
+
+import (
+	"fmt"
+)
+
+// groupingKind is the kind of the element of the GROUP BY clause.
+type groupingKind uint8
+
+const (
+	groupingColumns groupingKind = iota // [col1], [col2]
+	groupingRollup                      // ROLLUP ([col1], [col2])
+	groupingCube                        // CUBE ([col1], [col2])
+	groupingSets                        // GROUPING SETS (([col1], [col2]), ([col1]), ())
+)
+
+// String returns the SQL name of the grouping kind.
+func (k groupingKind) String() string {
+	switch k {
+	case groupingColumns:
+		return "GROUP BY"
+	case groupingRollup:
+		return "ROLLUP"
+	case groupingCube:
+		return "CUBE"
+	case groupingSets:
+		return "GROUPING SETS"
+	default:
+		return fmt.Sprintf("groupingKind(%d)", uint8(k))
+	}
+}
+
+type groupingElement struct {
+	kind    groupingKind
+	columns []GenericColumnToUse   // columns of groupingColumns, groupingRollup and groupingCube
+	sets    [][]GenericColumnToUse // sets of groupingSets
+}
+
+// GroupBy adds the columns to the GROUP BY clause.
+//
+// Also supported by SelectExists (exists any group) and SelectCount (counts the groups).
+func (b *SqlBuilder) GroupBy(columns ...GenericColumnToUse) *SqlBuilder {
+	if len(columns) == 0 {
+		panic("GROUP BY must have at least one column")
+	}
+	return b.addGrouping(groupingElement{
+		kind:    groupingColumns,
+		columns: columns,
+	})
+}
+
+// Rollup adds 'ROLLUP ([col1], [col2],...)' to the GROUP BY clause, generates the subtotal rows of the hierarchy.
+// MySQL renders 'GROUP BY [col1], [col2],... WITH ROLLUP', the rollup must be the only grouping. Not supported by SQLite.
+//
+// The rolled-up columns are NULL in the subtotal rows, the scanner leaves the fields as zero value,
+// use ScannedRows.IsNullGrouping to distinguish the subtotal rows.
+func (b *SqlBuilder) Rollup(columns ...GenericColumnToUse) *SqlBuilder {
+	if len(columns) == 0 {
+		panic("ROLLUP must have at least one column")
+	}
+	return b.addGrouping(groupingElement{
+		kind:    groupingRollup,
+		columns: columns,
+	})
+}
+
+// Cube adds 'CUBE ([col1], [col2],...)' to the GROUP BY clause, generates the subtotal rows of all the combinations.
+//
+// See Rollup for scanning of the subtotal rows. Not supported by MySQL and SQLite.
+func (b *SqlBuilder) Cube(columns ...GenericColumnToUse) *SqlBuilder {
+	if len(columns) == 0 {
+		panic("CUBE must have at least one column")
+	}
+	return b.addGrouping(groupingElement{
+		kind:    groupingCube,
+		columns: columns,
+	})
+}
+
+// GroupingSets adds 'GROUPING SETS (([col1], [col2]), ([col1]), ())' to the GROUP BY clause,
+// an empty set generates the grand total row.
+//
+// See Rollup for scanning of the subtotal rows. Not supported by MySQL and SQLite.
+func (b *SqlBuilder) GroupingSets(sets ...[]GenericColumnToUse) *SqlBuilder {
+	if len(sets) == 0 {
+		panic("GROUPING SETS must have at least one set")
+	}
+	return b.addGrouping(groupingElement{
+		kind: groupingSets,
+		sets: sets,
+	})
+}
+
+func (b *SqlBuilder) addGrouping(element groupingElement) *SqlBuilder {
+	b.mustTypeSelect()
+	b.mustPreviousAction(previousIsSelectFrom, previousIsSelectJoin, previousIsSelectWhere, previousIsSelectGroupBy)
+	defer b.setPreviousAction(previousIsSelectGroupBy)
+
+	b.groupBy = append(b.groupBy, element)
+	return b
+}
+
+// Having adds the HAVING clause, aggregates can be used as tokens, eg: Having(Gt(CountAll(), 1)).
+func (b *SqlBuilder) Having(tokens ...any) *SqlBuilder {
+	b.mustTypeSelect()
+	b.mustPreviousAction(previousIsSelectGroupBy)
+	defer b.setPreviousAction(previousIsSelectHaving)
+
+	if len(tokens) == 0 {
+		panic("HAVING must have at least one token")
+	}
+	b.havingTokens = b.tokens.append(tokenList{}, tokens...)
+	return b
+}
+
+// isNullableGrouping returns true if the column is grouped by ROLLUP, CUBE or GROUPING SETS,
+// so it is NULL in the subtotal rows.
+func (b *SqlBuilder) isNullableGrouping(column GenericColumnToUse) bool {
+	sameColumn := func(c GenericColumnToUse) bool {
+		return c.name == column.name && b.aliasOf(c.table) == b.aliasOf(column.table)
+	}
+	for _, element := range b.groupBy {
+		switch element.kind {
+		case groupingRollup, groupingCube:
+			for _, c := range element.columns {
+				if sameColumn(c) {
+					return true
+				}
+			}
+		case groupingSets:
+			for _, set := range element.sets {
+				for _, c := range set {
+					if sameColumn(c) {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+// groupingSupported returns ErrUnsupportedByDialect if ROLLUP, CUBE or GROUPING SETS is not supported by the dialect:
+// MySQL supports only the rollup of all the grouped columns (WITH ROLLUP), SQLite supports none of them.
+func (b *SqlBuilder) groupingSupported(dialect Dialect) error {
+	for _, element := range b.groupBy {
+		if element.kind == groupingColumns {
+			continue
+		}
+		switch {
+		case dialect == DialectSQLite,
+			dialect.isMySQL() && element.kind != groupingRollup:
+			return ErrUnsupportedByDialect{Feature: element.kind.String(), Dialect: dialect}
+		case dialect.isMySQL() && len(b.groupBy) > 1:
+			return ErrUnsupportedByDialect{Feature: "ROLLUP with other groupings", Dialect: dialect, Hint: "ROLLUP must be the only grouping, rendered as WITH ROLLUP"}
+		}
+	}
+	return nil
+}
+
+// writeGroupBy writes the GROUP BY and HAVING clauses.
+func (b *SqlBuilder) writeGroupBy(ctx *buildContext) {
+	if len(b.groupBy) == 0 {
+		return
+	}
+
+	writeColumns := func(columns []GenericColumnToUse) {
+		for i, column := range columns {
+			if i > 0 {
+				ctx.writeString(", ")
+			}
+			ctx.writeColumn(column)
+		}
+	}
+
+	if err := b.groupingSupported(ctx.dialect); err != nil { // the subqueries are rendered in dialect of the statement
+		panic(err)
+	}
+
+	ctx.writeString("GROUP BY ")
+	for i, element := range b.groupBy {
+		if i > 0 {
+			ctx.writeString(", ")
+		}
+		switch element.kind {
+		case groupingColumns:
+			writeColumns(element.columns)
+		case groupingRollup, groupingCube:
+			if ctx.dialect.isMySQL() { // the only grouping, see groupingSupported
+				writeColumns(element.columns)
+				ctx.writeString(" WITH ROLLUP")
+				break
+			}
+			if element.kind == groupingRollup {
+				ctx.writeString("ROLLUP (")
+			} else {
+				ctx.writeString("CUBE (")
+			}
+			writeColumns(element.columns)
+			ctx.writeString(")")
+		case groupingSets:
+			ctx.writeString("GROUPING SETS (")
+			for j, set := range element.sets {
+				if j > 0 {
+					ctx.writeString(", ")
+				}
+				ctx.writeString("(")
+				writeColumns(set)
+				ctx.writeString(")")
+			}
+			ctx.writeString(")")
+		default:
+			panic(fmt.Sprintf("unexpected grouping kind %d", element.kind))
+		}
+	}
+	ctx.writeString("\n")
+
+	if b.havingTokens.len() > 0 {
+		ctx.writeString("HAVING")
+		ctx.writeArenaTokens(&b.tokens, b.havingTokens, "HAVING")
+		ctx.writeString("\n")
+	}
+}
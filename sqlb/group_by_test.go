@@ -0,0 +1,312 @@
+package sqlb
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// valuesRows is a fake SqlRows returns the provided driver values, for the sake of the test.
+type valuesRows struct {
+	rows   [][]any
+	rowIdx int
+	err    error // err is returned by Err after the rows are iterated
+}
+
+func (r *valuesRows) Next() bool {
+	r.rowIdx++
+	return r.rowIdx <= len(r.rows)
+}
+
+func (r *valuesRows) Scan(dest ...any) error {
+	for i, d := range dest {
+		value := r.rows[r.rowIdx-1][i]
+		if scanner, ok := d.(*nullGroupingScanner); ok {
+			if err := scanner.Scan(value); err != nil {
+				return err
+			}
+			continue
+		}
+		if p, ok := d.(*any); ok {
+			*p = value
+			continue
+		}
+		if err := assignScanned(d, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *valuesRows) Close() error {
+	return nil
+}
+
+func (r *valuesRows) Err() error {
+	if r.rowIdx > len(r.rows) {
+		return r.err
+	}
+	return nil
+}
+
+func TestSqlBuilder_GroupBy(t *testing.T) {
+	tests := []struct {
+		name     string
+		builder  func() *SqlBuilder
+		wantSql  string
+		wantArgs []any
+	}{
+		{
+			name: "group by with aggregates and having",
+			builder: func() *SqlBuilder {
+				table1 := UseTable[testStruct1]().Alias("t1").Seal()
+				return Select(table1.Col("pk1")).
+					SelectAggregates(CountAll().As("total"), Sum(table1.Col("amount")).As("amount")).
+					From(table1).
+					Where(Gt(table1.Col("pk2"), 1)).
+					GroupBy(table1.Col("pk1")).
+					Having(Gt(CountAll(), 10)).
+					OrderBy(table1.Col("pk1"), ASC).
+					Limit(5)
+			},
+			wantSql: `SELECT t1.pk1, COUNT(*) AS total, SUM(t1.amount) AS amount
+FROM table1 AS t1
+WHERE t1.pk2 > $1
+GROUP BY t1.pk1
+HAVING COUNT(*) > $2
+ORDER BY t1.pk1 ASC
+LIMIT 5
+`,
+			wantArgs: []any{1, 10},
+		},
+		{
+			name: "rollup, cube and grouping sets",
+			builder: func() *SqlBuilder {
+				table1 := UseTable[testStruct1]().Alias("t1").Seal()
+				return Select(table1.Col("pk1"), table1.Col("pk2"), table1.Col("cost")).
+					SelectAggregates(Max(table1.Col("amount")).As("max_amount"), CountDistinct(table1.Col("cost")).As("costs")).
+					From(table1).
+					GroupBy(table1.Col("cost")).
+					Rollup(table1.Col("pk1"), table1.Col("pk2")).
+					Cube(table1.Col("pk2")).
+					GroupingSets([]GenericColumnToUse{table1.Col("pk1")}, nil)
+			},
+			wantSql: `SELECT t1.pk1, t1.pk2, t1.cost, MAX(t1.amount) AS max_amount, COUNT(DISTINCT t1.cost) AS costs
+FROM table1 AS t1
+GROUP BY t1.cost, ROLLUP (t1.pk1, t1.pk2), CUBE (t1.pk2), GROUPING SETS ((t1.pk1), ())
+`,
+		},
+		{
+			name: "aggregates only",
+			builder: func() *SqlBuilder {
+				table1 := UseTable[testStruct1]().Alias("t1").Seal()
+				return Select().
+					SelectAggregates(Avg(table1.Col("amount")).As("avg"), Min(table1.Col("amount")).As("min"), Count(table1.Col("pk1")).As("n")).
+					From(table1)
+			},
+			wantSql: `SELECT AVG(t1.amount) AS avg, MIN(t1.amount) AS min, COUNT(t1.pk1) AS n
+FROM table1 AS t1
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotSql, gotArgs := tt.builder().Build()
+			require.Equal(t, tt.wantSql, gotSql)
+			require.Equal(t, tt.wantArgs, gotArgs)
+		})
+	}
+
+	t.Run("dialects", func(t *testing.T) {
+		table1 := UseTable[testStruct1]().Alias("t1").Seal()
+		newBuilder := func(dialect Dialect) *SqlBuilder {
+			return Select(table1.Col("pk1"), table1.Col("pk2")).
+				SelectAggregates(Sum(table1.Col("amount")).As("amount")).
+				From(table1).
+				WithDialect(dialect)
+		}
+
+		for _, dialect := range []Dialect{DialectMySQL, DialectMySQL8} {
+			gotSql, _ := newBuilder(dialect).Rollup(table1.Col("pk1"), table1.Col("pk2")).Build()
+			require.Equal(t, "SELECT t1.pk1, t1.pk2, SUM(t1.amount) AS amount\nFROM table1 AS t1\nGROUP BY t1.pk1, t1.pk2 WITH ROLLUP\n", gotSql)
+
+			require.PanicsWithError(t, "CUBE is not supported by dialect "+dialect.String(), func() {
+				newBuilder(dialect).Cube(table1.Col("pk1")).Build()
+			})
+			require.PanicsWithError(t, "GROUPING SETS is not supported by dialect "+dialect.String(), func() {
+				newBuilder(dialect).GroupingSets([]GenericColumnToUse{table1.Col("pk1")}).Build()
+			})
+			require.PanicsWithError(t, "ROLLUP with other groupings is not supported by dialect "+dialect.String()+", ROLLUP must be the only grouping, rendered as WITH ROLLUP", func() {
+				newBuilder(dialect).GroupBy(table1.Col("pk1")).Rollup(table1.Col("pk2")).Build()
+			})
+		}
+
+		require.PanicsWithError(t, "ROLLUP is not supported by dialect sqlite", func() {
+			newBuilder(DialectSQLite).Rollup(table1.Col("pk1")).Build()
+		})
+		gotSql, _ := newBuilder(DialectSQLite).GroupBy(table1.Col("pk1"), table1.Col("pk2")).Build()
+		require.Equal(t, "SELECT t1.pk1, t1.pk2, SUM(t1.amount) AS amount\nFROM table1 AS t1\nGROUP BY t1.pk1, t1.pk2\n", gotSql)
+
+		gotSql, _ = newBuilder(DialectSQLServer).Cube(table1.Col("pk1"), table1.Col("pk2")).Build()
+		require.Equal(t, "SELECT t1.pk1, t1.pk2, SUM(t1.amount) AS amount\nFROM table1 AS t1\nGROUP BY CUBE (t1.pk1, t1.pk2)\n", gotSql)
+	})
+
+	t.Run("invalid usage", func(t *testing.T) {
+		table1 := UseTable[testStruct1]().Alias("t1").Seal()
+		require.Panics(t, func() {
+			Select(table1.Col("pk1")).From(table1).GroupBy()
+		})
+		require.Panics(t, func() {
+			Select(table1.Col("pk1")).From(table1).Having(true)
+		}, "HAVING requires GROUP BY")
+		require.Panics(t, func() {
+			Select(table1.Col("pk1")).SelectAggregates(CountAll())
+		}, "alias is required")
+		require.Panics(t, func() {
+			Select(table1.Col("pk1")).SelectAggregates(CountAll().As("n"), Sum(table1.Col("amount")).As("n"))
+		}, "duplicated alias")
+	})
+}
+
+func TestSqlBuilder_scanRollup(t *testing.T) {
+	table1 := UseTable[testStruct1]().Alias("t1").Seal()
+	b := Select(table1.Col("pk1"), table1.Col("cost")).
+		SelectAggregates(Sum(table1.Col("amount")).As("amount")).
+		From(table1).
+		Rollup(table1.Col("pk1"), table1.Col("cost"))
+
+	sr, err := b.scanRows(&valuesRows{
+		rows: [][]any{
+			{"a", []byte("1 USD"), int64(10)},
+			{"a", nil, int64(10)},
+			{nil, nil, int64(10)},
+		},
+	}, nil)
+	require.NoError(t, err)
+	require.Equal(t, 3, sr.Count())
+
+	require.True(t, sr.Next())
+	row := sr.GetTable("t1").(testStruct1)
+	require.Equal(t, "a", row.Pk1)
+	require.Equal(t, Money{Amount: 1, Currency: "USD"}, row.Cost)
+	require.False(t, sr.IsNullGrouping(table1.Col("pk1")))
+	require.False(t, sr.IsNullGrouping(table1.Col("cost")))
+	require.Equal(t, int64(10), sr.GetAggregate("amount"))
+
+	require.True(t, sr.Next())
+	row = sr.GetTable("t1").(testStruct1)
+	require.Equal(t, "a", row.Pk1)
+	require.Equal(t, Money{}, row.Cost, "transform must be skipped for NULL grouping")
+	require.True(t, sr.IsNullGrouping(table1.Col("cost")))
+
+	require.True(t, sr.Next())
+	sr.GetTable("t1")
+	require.True(t, sr.IsNullGrouping(table1.Col("pk1")))
+	require.True(t, sr.IsNullGrouping(table1.Col("cost")))
+
+	require.False(t, sr.Next())
+
+	require.Panics(t, func() {
+		sr.GetAggregate("unknown")
+	})
+}
+
+func Test_assignScanned(t *testing.T) {
+	var s string
+	require.NoError(t, assignScanned(&s, []byte("abc")))
+	require.Equal(t, "abc", s)
+
+	var i int
+	require.NoError(t, assignScanned(&i, int64(5)))
+	require.Equal(t, 5, i)
+	require.NoError(t, assignScanned(&i, []byte("7")))
+	require.Equal(t, 7, i)
+
+	var f float64
+	require.NoError(t, assignScanned(&f, "1.5"))
+	require.Equal(t, 1.5, f)
+
+	var p *int64
+	require.NoError(t, assignScanned(&p, int64(3)))
+	require.Equal(t, int64(3), *p)
+
+	var b bool
+	require.NoError(t, assignScanned(&b, "true"))
+	require.True(t, b)
+
+	require.NoError(t, assignScanned(&s, int64(300)))
+	require.Equal(t, "300", s)
+
+	var ns sql.NullInt64
+	require.NoError(t, assignScanned(&ns, int64(9)))
+	require.Equal(t, sql.NullInt64{Int64: 9, Valid: true}, ns, "the scanner of the destination scans the value")
+
+	var i8 int8
+	require.Error(t, assignScanned(&i8, int64(300)), "overflow")
+	require.Error(t, assignScanned(&i, 2.7), "float to int")
+	require.NoError(t, assignScanned(&i, 3.0))
+	require.Equal(t, 3, i)
+
+	require.Error(t, assignScanned(&i, "x"))
+	require.Error(t, assignScanned(&b, int64(2)))
+	require.Error(t, assignScanned(i, int64(1)))
+}
+
+func TestAggregate_Filter(t *testing.T) {
+	table1 := UseTable[testStruct1]().Alias("t1").Seal()
+	newBuilder := func() *SqlBuilder {
+		return Select().
+			SelectAggregates(
+				CountAll().As("total"),
+				CountAll().Filter(Eq(table1.Col("cost"), "1 USD")).As("usd"),
+				Sum(table1.Col("amount")).Filter(table1.Col("pk2"), "> $1", "AND", Lt(table1.Col("amount"), 100)).As("small"),
+			).
+			From(table1).
+			Where(table1.Col("pk1"), "<> $1").Args("x").
+			GroupBy(table1.Col("pk1")).
+			Having(Gt(CountAll().Filter(Eq(table1.Col("cost"), "2 USD")), 0))
+	}
+
+	gotSql, gotArgs := newBuilder().Build()
+	require.Equal(t, `SELECT COUNT(*) AS total, COUNT(*) FILTER (WHERE t1.cost = $2) AS usd, SUM(t1.amount) FILTER (WHERE t1.pk2 > $1 AND t1.amount < $3) AS small
+FROM table1 AS t1
+WHERE t1.pk1 <> $1
+GROUP BY t1.pk1
+HAVING COUNT(*) FILTER (WHERE t1.cost = $4) > $5
+`, gotSql)
+	require.Equal(t, []any{"x", "1 USD", 100, "2 USD", 0}, gotArgs)
+
+	require.Panics(t, func() {
+		newBuilder().WithDialect(DialectMySQL).Build()
+	})
+	require.Panics(t, func() {
+		CountAll().Filter()
+	})
+}
+
+func TestSqlBuilder_GroupBy_ExistsAndCount(t *testing.T) {
+	newGrouped := func(b *SqlBuilder) *SqlBuilder {
+		table1 := UseTable[testStruct1]().Alias("t1").Seal()
+		return b.From(table1).
+			Where(Gt(table1.Col("pk2"), 1)).
+			GroupBy(table1.Col("pk1")).
+			Having(Gt(Sum(table1.Col("amount")), 100))
+	}
+
+	gotSql, gotArgs := newGrouped(SelectExists()).Build()
+	require.Equal(t, "SELECT EXISTS(SELECT 1 FROM table1 AS t1\nWHERE t1.pk2 > $1\nGROUP BY t1.pk1\nHAVING SUM(t1.amount) > $2\n)", gotSql)
+	require.Equal(t, []any{1, 100}, gotArgs)
+
+	gotSql, gotArgs = newGrouped(SelectCount()).Build()
+	require.Equal(t, "SELECT COUNT(1) FROM (SELECT 1 FROM table1 AS t1\nWHERE t1.pk2 > $1\nGROUP BY t1.pk1\nHAVING SUM(t1.amount) > $2\n) AS grouped", gotSql)
+	require.Equal(t, []any{1, 100}, gotArgs)
+
+	gotSql, _ = newGrouped(SelectCount()).WithFormat(FormatPretty).Build()
+	require.Equal(t, `SELECT COUNT(1) FROM (
+  SELECT 1 FROM table1 AS t1
+  WHERE t1.pk2 > $1
+  GROUP BY t1.pk1
+  HAVING SUM(t1.amount) > $2
+) AS grouped`, gotSql)
+}
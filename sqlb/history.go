@@ -0,0 +1,107 @@
+package sqlb
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// HistoryOperation is the operation recorded into the history table.
+type HistoryOperation string
+
+const (
+	HistoryUpdate HistoryOperation = "UPDATE"
+	HistoryDelete HistoryOperation = "DELETE"
+)
+
+// historyTableSuffix is the suffix of the history shadow table: [table]_history.
+const historyTableSuffix = "_history"
+
+// HistoryTableName returns name of the history shadow table of the table: [table]_history.
+//
+// Eg: HistoryTableName(GetTableMetadata[T]()), or GetTableMetadataByName for the other tables registered for the type.
+func HistoryTableName[T any](metadata TableMetadata[T]) string {
+	return metadata.Name() + historyTableSuffix
+}
+
+// HistoryTableSchema returns structure of the history shadow table of the table, used to generate the DDL:
+// the columns of the table (nullable, no primary key) followed by operation, actor and changed_at.
+func HistoryTableSchema[T any](metadata TableMetadata[T]) TableSchema {
+	schema := metadata.Schema()
+	history := TableSchema{
+		Name:    schema.Name + historyTableSuffix,
+		Columns: make([]ColumnSchema, 0, len(schema.Columns)+3),
+	}
+	for _, column := range schema.Columns {
+		history.Columns = append(history.Columns, ColumnSchema{
+			Name:    column.Name,
+			SqlType: column.SqlType,
+		})
+	}
+	history.Columns = append(history.Columns,
+		ColumnSchema{Name: "operation", SqlType: "TEXT", NotNull: true},
+		ColumnSchema{Name: "actor", SqlType: "TEXT", NotNull: true},
+		ColumnSchema{Name: "changed_at", SqlType: "TIMESTAMPTZ", NotNull: true},
+	)
+	return history
+}
+
+// RecordHistory copies the old values of the rows matching the WHERE tokens into the history shadow table,
+// together with the operation, the actor and the timestamp. Returns the number of recorded rows.
+//
+// Call it via the executor of the transaction (see InTransaction) right before updating or deleting the rows,
+// so the history is committed or rolled back together with the change.
+// Values of the WHERE tokens must be bound via predicates or Arg, the placeholders are allocated automatically.
+func RecordHistory[T any](ctx context.Context, exec Executor, table *TableToUse[T], operation HistoryOperation, actor string, whereTokens ...any) (int64, error) {
+	stmt, args := buildRecordHistory(table, operation, actor, whereTokens)
+	result, err := exec.ExecContext(ctx, stmt, args...)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to record history of %s", table.tableName())
+	}
+
+	recorded, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get rows affected")
+	}
+	return recorded, nil
+}
+
+// buildRecordHistory builds 'INSERT INTO [table]_history (...) SELECT ... FROM [table] WHERE ...'.
+func buildRecordHistory[T any](table *TableToUse[T], operation HistoryOperation, actor string, whereTokens []any) (string, []any) {
+	table.mustSealed()
+	switch operation {
+	case HistoryUpdate, HistoryDelete:
+	default:
+		panic("unknown history operation")
+	}
+	if len(whereTokens) == 0 {
+		panic("WHERE is required to record history")
+	}
+
+	columns := table.Columns()
+	sb := strings.Builder{}
+	ctx := newBuildContext(&sb, nil, DialectPostgres)
+
+	sb.WriteString("INSERT INTO ")
+	sb.WriteString(table.metadata.name + historyTableSuffix)
+	sb.WriteString(" (")
+	for _, column := range columns {
+		sb.WriteString(column.name)
+		sb.WriteString(", ")
+	}
+	sb.WriteString("operation, actor, changed_at)\nSELECT ")
+	for _, column := range columns {
+		ctx.writeColumn(column)
+		sb.WriteString(", ")
+	}
+	sb.WriteString(ctx.addArg(string(operation)))
+	sb.WriteString(", ")
+	sb.WriteString(ctx.addArg(actor))
+	sb.WriteString(", NOW()\nFROM ")
+	ctx.writeTableReference(table)
+	sb.WriteString("\nWHERE")
+	ctx.writeTokens(whereTokens, "WHERE")
+
+	return sb.String(), ctx.args
+}
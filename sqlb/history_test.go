@@ -0,0 +1,46 @@
+package sqlb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistoryTableSchema(t *testing.T) {
+	require.Equal(t, "products_history", HistoryTableName(GetTableMetadata[testDdlRow]()))
+	require.Equal(t, "products_history_history", HistoryTableName(GetTableMetadataByName[testDdlRow]("products_history")),
+		"the other tables registered for the type")
+	require.Equal(t, `CREATE TABLE IF NOT EXISTS products_history (
+  id BIGINT,
+  title TEXT,
+  note TEXT,
+  operation TEXT NOT NULL,
+  actor TEXT NOT NULL,
+  changed_at TIMESTAMPTZ NOT NULL
+)`, HistoryTableSchema(GetTableMetadata[testDdlRow]()).CreateTableStatement())
+}
+
+func TestRecordHistory(t *testing.T) {
+	products := UseTable[testDdlRow]().Alias("p").Seal()
+
+	exec := &affectedExecutor{affected: 2}
+	recorded, err := RecordHistory(context.Background(), exec, products, HistoryDelete, "alice", In(products.Col("id"), 1, 2))
+	require.NoError(t, err)
+	require.Equal(t, int64(2), recorded)
+	require.Equal(t, []string{`INSERT INTO products_history (id, title, note, operation, actor, changed_at)
+SELECT p.id, p.title, p.note, $1, $2, NOW()
+FROM products AS p
+WHERE p.id IN ($3, $4)`}, exec.statements)
+
+	_, err = RecordHistory(context.Background(), &affectedExecutor{recordingExecutor: recordingExecutor{err: errors.New("boom")}}, products, HistoryUpdate, "alice", Eq(products.Col("id"), 1))
+	require.ErrorContains(t, err, "failed to record history of products: boom")
+
+	require.Panics(t, func() {
+		_, _ = RecordHistory(context.Background(), exec, products, HistoryUpdate, "alice")
+	}, "WHERE is required")
+	require.Panics(t, func() {
+		_, _ = RecordHistory(context.Background(), exec, products, "INSERT", "alice", Eq(products.Col("id"), 1))
+	}, "unknown operation")
+}
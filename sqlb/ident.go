@@ -0,0 +1,59 @@
+package sqlb
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// maxIdentifierLength is the maximum length of an identifier of PostgreSQL, longer identifiers are truncated silently.
+const maxIdentifierLength = 63
+
+// Ident validates and quotes the dynamically chosen identifier, eg: per-customer table suffixes,
+// so it is safe to be placed into As, Alias or Raw fragments. Panics if the identifier is invalid, see QuoteIdent.
+//
+//	UseTable[Event]().As(sqlb.Ident("events_" + customer))
+func Ident(name string) string {
+	quoted, err := QuoteIdent(name)
+	if err != nil {
+		panic(err.Error())
+	}
+	return quoted
+}
+
+// QuoteIdent validates and quotes the identifier, schema-qualified name is quoted part by part: "schema"."table".
+//
+// Each part must be 1-63 characters of letters, digits, underscore, hyphen or dollar sign,
+// anything else (quotes, spaces, semicolons,...) is rejected rather than escaped.
+// The double-quoted identifiers are converted to the quote style of the dialect when building.
+func QuoteIdent(name string) (string, error) {
+	parts := strings.Split(name, ".")
+	if len(parts) > 2 {
+		return "", errors.Errorf("invalid identifier %q, at most schema.name is allowed", name)
+	}
+
+	quoted := make([]string, len(parts))
+	for i, part := range parts {
+		if err := validateIdentPart(part); err != nil {
+			return "", errors.Wrapf(err, "invalid identifier %q", name)
+		}
+		quoted[i] = `"` + part + `"`
+	}
+	return strings.Join(quoted, "."), nil
+}
+
+func validateIdentPart(part string) error {
+	if part == "" {
+		return errors.New("empty name")
+	}
+	if len(part) > maxIdentifierLength {
+		return fmt.Errorf("longer than %d characters", maxIdentifierLength)
+	}
+	for _, r := range part {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-' || r == '$') {
+			return fmt.Errorf("character %q is not allowed", r)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package sqlb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuoteIdent(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr string
+	}{
+		{name: "events_acme", want: `"events_acme"`},
+		{name: "tenant-42", want: `"tenant-42"`},
+		{name: "archive.events", want: `"archive"."events"`},
+		{name: "", wantErr: "empty name"},
+		{name: "a.b.c", wantErr: "at most schema.name is allowed"},
+		{name: `events"; DROP TABLE users; --`, wantErr: `character '"' is not allowed`},
+		{name: "events x", wantErr: "character ' ' is not allowed"},
+		{name: "events.", wantErr: "empty name"},
+		{name: "a123456789012345678901234567890123456789012345678901234567890123", wantErr: "longer than 63 characters"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := QuoteIdent(tt.name)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				require.Panics(t, func() {
+					Ident(tt.name)
+				})
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+			require.Equal(t, tt.want, Ident(tt.name))
+		})
+	}
+}
+
+func TestIdent_Build(t *testing.T) {
+	customer := "acme"
+	table1 := UseTable[testStruct1]().As(Ident("table1_" + customer)).Alias(Ident("t")).Seal()
+	build := func(dialect Dialect) string {
+		gotSql, _ := Select(table1.Col("pk1")).From(table1).WithDialect(dialect).Build()
+		return gotSql
+	}
+	require.Equal(t, "SELECT \"t\".pk1\nFROM \"table1_acme\" AS \"t\"\n", build(DialectPostgres))
+	require.Equal(t, "SELECT `t`.pk1\nFROM `table1_acme` AS `t`\n", build(DialectMySQL))
+}
@@ -0,0 +1,97 @@
+package sqlb
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pkg/errors"
+	"golang.org/x/exp/slices"
+)
+
+// InsertWithChildren inserts the parent row and the child rows inside a transaction of the database,
+// see InsertWithChildrenWithExecutor.
+func InsertWithChildren[P any, C any, K any](ctx context.Context, db *sql.DB, parent P, children []C) (key K, err error) {
+	err = InTransaction(ctx, db, nil, func(exec Executor) error {
+		key, err = InsertWithChildrenWithExecutor[P, C, K](ctx, exec, parent, children)
+		return err
+	})
+	return key, err
+}
+
+// InsertWithChildrenWithExecutor inserts the parent row with RETURNING the referenced key,
+// then inserts the child rows with the key as the value of the foreign key column, the child rows are not modified.
+// The executor should be bound to a transaction, so a failure does not leave the parent without children.
+//
+// The table of C must declare exactly one foreign key to the table of P via TableMetadataBuilder.ForeignKey.
+// Postgres only, as it relies on RETURNING.
+func InsertWithChildrenWithExecutor[P any, C any, K any](ctx context.Context, exec Executor, parent P, children []C) (key K, err error) {
+	parentMetadata, childMetadata := GetTableMetadata[P](), GetTableMetadata[C]()
+	foreignKey, err := foreignKeyTo(childMetadata, parentMetadata.Name())
+	if err != nil {
+		return key, err
+	}
+	if _, found := parentMetadata.columnsByName[foreignKey.ReferencedColumn]; !found {
+		return key, ErrUnknownColumn{Table: parentMetadata.Name(), Column: foreignKey.ReferencedColumn}
+	}
+	if !slices.Contains(childMetadata.insertableColumnsName(), foreignKey.Column) {
+		return key, errors.Errorf("foreign key column %s of table %s is not insertable", foreignKey.Column, childMetadata.Name())
+	}
+
+	parentTable := UseTable[P]().Seal()
+	stmt, args := InsertInto(parentTable).
+		Values(parent).
+		Returning(parentTable.Col(foreignKey.ReferencedColumn)).
+		Build()
+	rows, err := exec.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return key, errors.Wrapf(err, "failed to insert into %s", parentMetadata.Name())
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	if !rows.Next() {
+		if err = rows.Err(); err != nil {
+			return key, errors.Wrapf(err, "failed to read the key returned by inserting into %s", parentMetadata.Name())
+		}
+		return key, errors.Errorf("no key returned by inserting into %s", parentMetadata.Name())
+	}
+	if err = rows.Scan(&key); err != nil {
+		return key, errors.Wrapf(err, "failed to scan the key returned by inserting into %s", parentMetadata.Name())
+	}
+	_ = rows.Close()
+
+	if len(children) == 0 {
+		return key, nil
+	}
+
+	values := make([]any, len(children))
+	for i, child := range children {
+		values[i] = child
+	}
+	childTable := UseTable[C]().Seal()
+	childInsert := InsertInto(childTable).Values(values...)
+	childInsert.insertColumnOverrides = map[string]any{foreignKey.Column: key}
+	stmt, args = childInsert.Build()
+	if _, err = exec.ExecContext(ctx, stmt, args...); err != nil {
+		return key, errors.Wrapf(err, "failed to insert into %s", childTable.tableName())
+	}
+	return key, nil
+}
+
+// foreignKeyTo returns the only foreign key of the table references the parent table.
+func foreignKeyTo[T any](table TableMetadata[T], parentTableName string) (ForeignKey, error) {
+	var found []ForeignKey
+	for _, foreignKey := range table.foreignKeys {
+		if foreignKey.ReferencedTable == parentTableName {
+			found = append(found, foreignKey)
+		}
+	}
+	switch len(found) {
+	case 1:
+		return found[0], nil
+	case 0:
+		return ForeignKey{}, errors.Errorf("table %s has no foreign key to table %s", table.Name(), parentTableName)
+	default:
+		return ForeignKey{}, errors.Errorf("table %s has %d foreign keys to table %s", table.Name(), len(found), parentTableName)
+	}
+}
@@ -0,0 +1,169 @@
+package sqlb
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testInvoiceRow struct {
+	Id       int64
+	Customer string
+}
+
+type testInvoiceLineRow struct {
+	InvoiceId int64
+	Sku       string
+}
+
+var tableTestInvoice = NewTableMetadata[testInvoiceRow]("invoices").
+	AddColumns(
+		NewColumnMetadata[testInvoiceRow]("id").
+			PrimaryKey().
+			InsertSpec(func(r testInvoiceRow) any {
+				return Raw("DEFAULT")
+			}),
+		NewColumnMetadata[testInvoiceRow]("customer").
+			InsertSpec(func(r testInvoiceRow) any {
+				return r.Customer
+			}),
+	).
+	Build(TableMetadataBuildOption{
+		ExpectedPkColumns: []string{"id"},
+	})
+
+var tableTestInvoiceLine = NewTableMetadata[testInvoiceLineRow]("invoice_lines").
+	AddColumns(
+		NewColumnMetadata[testInvoiceLineRow]("invoice_id").
+			PrimaryKey().
+			InsertSpec(func(r testInvoiceLineRow) any {
+				return r.InvoiceId
+			}),
+		NewColumnMetadata[testInvoiceLineRow]("sku").
+			PrimaryKey().
+			InsertSpec(func(r testInvoiceLineRow) any {
+				return r.Sku
+			}),
+	).
+	ForeignKey("invoice_id", "invoices", "id").
+	Build(TableMetadataBuildOption{
+		ExpectedPkColumns: []string{"invoice_id", "sku"},
+	})
+
+type testShipmentRow struct {
+	Id int64
+}
+
+type testShipmentItemRow struct {
+	ShipmentId int64
+}
+
+// tableTestShipmentItem references the table registered later, validated when inserting.
+var tableTestShipmentItem = NewTableMetadata[testShipmentItemRow]("shipment_items").
+	AddColumns(
+		NewColumnMetadata[testShipmentItemRow]("shipment_id").
+			PrimaryKey().
+			InsertSpec(func(r testShipmentItemRow) any {
+				return r.ShipmentId
+			}),
+	).
+	ForeignKey("shipment_id", "shipments", "uid").
+	Build(TableMetadataBuildOption{
+		ExpectedPkColumns: []string{"shipment_id"},
+	})
+
+var tableTestShipment = func() TableMetadata[testShipmentRow] {
+	_ = tableTestShipmentItem // registered first
+	return NewTableMetadata[testShipmentRow]("shipments").
+		AddColumns(
+			NewColumnMetadata[testShipmentRow]("id").
+				PrimaryKey().
+				InsertSpec(func(r testShipmentRow) any {
+					return r.Id
+				}),
+		).
+		Build(TableMetadataBuildOption{
+			ExpectedPkColumns: []string{"id"},
+		})
+}()
+
+// returningExecutor returns the rows for queries and records the statements with the arguments.
+type returningExecutor struct {
+	rows       [][]any
+	statements []string
+	args       [][]any
+}
+
+func (e *returningExecutor) QueryContext(_ context.Context, query string, args ...any) (SqlRows, error) {
+	e.statements = append(e.statements, query)
+	e.args = append(e.args, args)
+	return &valuesRows{rows: e.rows}, nil
+}
+
+func (e *returningExecutor) ExecContext(_ context.Context, query string, args ...any) (sql.Result, error) {
+	e.statements = append(e.statements, query)
+	e.args = append(e.args, args)
+	return driverResult(1), nil
+}
+
+func TestInsertWithChildren(t *testing.T) {
+	t.Run("parent key is injected into children", func(t *testing.T) {
+		exec := &returningExecutor{rows: [][]any{{int64(42)}}}
+		lines := []testInvoiceLineRow{{Sku: "a"}, {Sku: "b"}}
+		id, err := InsertWithChildrenWithExecutor[testInvoiceRow, testInvoiceLineRow, int64](context.Background(), exec, testInvoiceRow{Customer: "c1"}, lines)
+		require.NoError(t, err)
+		require.Equal(t, int64(42), id)
+		require.Equal(t, []string{
+			"INSERT INTO invoices (id, customer)\nVALUES (DEFAULT,$1)\nRETURNING id",
+			"INSERT INTO invoice_lines (invoice_id, sku)\nVALUES ($1,$2),($3,$4)",
+		}, exec.statements)
+		require.Equal(t, [][]any{{"c1"}, {int64(42), "a", int64(42), "b"}}, exec.args)
+		require.Zero(t, lines[0].InvoiceId, "caller's children must not be modified")
+	})
+
+	t.Run("no children", func(t *testing.T) {
+		exec := &returningExecutor{rows: [][]any{{int64(1)}}}
+		_, err := InsertWithChildrenWithExecutor[testInvoiceRow, testInvoiceLineRow, int64](context.Background(), exec, testInvoiceRow{}, nil)
+		require.NoError(t, err)
+		require.Len(t, exec.statements, 1)
+	})
+
+	t.Run("no key returned", func(t *testing.T) {
+		exec := &returningExecutor{}
+		_, err := InsertWithChildrenWithExecutor[testInvoiceRow, testInvoiceLineRow, int64](context.Background(), exec, testInvoiceRow{}, []testInvoiceLineRow{{Sku: "a"}})
+		require.EqualError(t, err, "no key returned by inserting into invoices")
+		require.Len(t, exec.statements, 1)
+	})
+
+	t.Run("no foreign key", func(t *testing.T) {
+		_, err := InsertWithChildrenWithExecutor[testInvoiceRow, testStruct1, int64](context.Background(), &returningExecutor{}, testInvoiceRow{}, []testStruct1{{}})
+		require.EqualError(t, err, "table table1 has no foreign key to table invoices")
+	})
+
+	t.Run("referenced column of the parent registered later not found", func(t *testing.T) {
+		exec := &returningExecutor{}
+		_, err := InsertWithChildrenWithExecutor[testShipmentRow, testShipmentItemRow, int64](context.Background(), exec, testShipmentRow{}, []testShipmentItemRow{{}})
+		require.Equal(t, ErrUnknownColumn{Table: "shipments", Column: "uid"}, err)
+		require.Empty(t, exec.statements, "must not insert the parent")
+	})
+}
+
+func TestTableMetadataBuilder_ForeignKey(t *testing.T) {
+	require.Equal(t, []ForeignKey{{Column: "invoice_id", ReferencedTable: "invoices", ReferencedColumn: "id"}}, tableTestInvoiceLine.ForeignKeys())
+	require.Empty(t, tableTestInvoice.ForeignKeys())
+
+	require.PanicsWithValue(t, "referenced column missing of foreign key not found in table invoices", func() {
+		NewTableMetadata[testInvoiceLineRow]("invoice_lines_invalid_reference").
+			AddColumns(NewColumnMetadata[testInvoiceLineRow]("invoice_id").PrimaryKey()).
+			ForeignKey("invoice_id", "invoices", "missing").
+			Build(TableMetadataBuildOption{ExpectedPkColumns: []string{"invoice_id"}})
+	})
+	require.PanicsWithValue(t, "column missing of foreign key not found", func() {
+		NewTableMetadata[testInvoiceLineRow]("invoice_lines_invalid").
+			AddColumns(NewColumnMetadata[testInvoiceLineRow]("sku").PrimaryKey()).
+			ForeignKey("missing", "invoices", "id").
+			Build(TableMetadataBuildOption{ExpectedPkColumns: []string{"sku"}})
+	})
+}
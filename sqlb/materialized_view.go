@@ -0,0 +1,43 @@
+package sqlb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// RefreshMaterializedViewStatement builds the statement to refresh the materialized view T:
+//
+//	REFRESH MATERIALIZED VIEW [CONCURRENTLY] [view]
+//
+// Refreshing concurrently does not block the readers, the view must have a unique index.
+func RefreshMaterializedViewStatement[T any](concurrently bool) string {
+	metadata := GetTableMetadata[T]()
+	if !metadata.materializedView {
+		panic(fmt.Sprintf("table %s is not a materialized view", metadata.name))
+	}
+
+	if concurrently {
+		return "REFRESH MATERIALIZED VIEW CONCURRENTLY " + metadata.name
+	}
+	return "REFRESH MATERIALIZED VIEW " + metadata.name
+}
+
+// RefreshMaterializedView refreshes the materialized view T, the view is queried as the other tables via UseTable.
+// Postgres only.
+func RefreshMaterializedView[T any](ctx context.Context, exec Executor, concurrently bool) error {
+	stmt := RefreshMaterializedViewStatement[T](concurrently)
+	if _, err := exec.ExecContext(ctx, stmt); err != nil {
+		return errors.Wrapf(err, "failed to refresh materialized view: %s", stmt)
+	}
+	return nil
+}
+
+// mustNotMaterializedView panics if the table is a materialized view, which is read-only.
+func mustNotMaterializedView[T any](metadata TableMetadata[T]) TableMetadata[T] {
+	if metadata.materializedView {
+		panic(fmt.Sprintf("table %s is a materialized view, it is read-only", metadata.name))
+	}
+	return metadata
+}
@@ -0,0 +1,76 @@
+package sqlb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type testDailySalesRow struct {
+	Day   string
+	Total int64
+}
+
+var tableTestDailySales = NewTableMetadata[testDailySalesRow]("daily_sales").
+	AddColumns(
+		NewColumnMetadata[testDailySalesRow]("sales_day").
+			SqlType("DATE").
+			SelectSpec(func(r *testDailySalesRow) ResultColumnSelectSpec {
+				return ResultColumnSelectSpec{
+					ToQueryArg: func() any {
+						return &r.Day
+					},
+				}
+			}),
+		NewColumnMetadata[testDailySalesRow]("total").
+			SqlType("BIGINT").
+			SelectSpec(func(r *testDailySalesRow) ResultColumnSelectSpec {
+				return ResultColumnSelectSpec{
+					ToQueryArg: func() any {
+						return &r.Total
+					},
+				}
+			}),
+	).Build(TableMetadataBuildOption{
+	MaterializedView: true,
+})
+
+func TestRefreshMaterializedView(t *testing.T) {
+	ctx := context.Background()
+	require.True(t, tableTestDailySales.IsMaterializedView())
+
+	exec := &recordingExecutor{}
+	require.NoError(t, RefreshMaterializedView[testDailySalesRow](ctx, exec, false))
+	require.NoError(t, RefreshMaterializedView[testDailySalesRow](ctx, exec, true))
+	require.Equal(t, []string{
+		"REFRESH MATERIALIZED VIEW daily_sales",
+		"REFRESH MATERIALIZED VIEW CONCURRENTLY daily_sales",
+	}, exec.statements)
+
+	exec.err = errors.New("boom")
+	require.EqualError(t, RefreshMaterializedView[testDailySalesRow](ctx, exec, true), "failed to refresh materialized view: REFRESH MATERIALIZED VIEW CONCURRENTLY daily_sales: boom")
+
+	require.PanicsWithValue(t, "table table1 is not a materialized view", func() {
+		RefreshMaterializedViewStatement[testStruct1](false)
+	})
+}
+
+func TestMaterializedView_readOnly(t *testing.T) {
+	view := UseTable[testDailySalesRow]().Seal()
+
+	rows, err := Select(view.Columns()...).From(view).ScanRows(&valuesRows{rows: [][]any{{"2024-01-01", int64(10)}}})
+	require.NoError(t, err)
+	require.Equal(t, []testDailySalesRow{{Day: "2024-01-01", Total: 10}}, view.ReadAllFromRows(rows))
+
+	require.PanicsWithValue(t, "table daily_sales is a materialized view, it is read-only", func() {
+		InsertInto(view)
+	})
+	require.PanicsWithValue(t, "table daily_sales is a materialized view, it is read-only", func() {
+		CreateTableStatement[testDailySalesRow]()
+	})
+	for _, schema := range RegisteredTableSchemas() {
+		require.NotEqual(t, "daily_sales", schema.Name, "the views are not migrated")
+	}
+}
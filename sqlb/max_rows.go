@@ -0,0 +1,34 @@
+package sqlb
+
+import "sync/atomic"
+
+// defaultMaxRows is the limit of the scanned rows of the builders without MaxRows, zero means no limit.
+var defaultMaxRows int64
+
+// SetDefaultMaxRows sets the limit of the rows scanned by a query, applied to the builders without MaxRows.
+// Scanning more rows fails with ErrTooManyRows, protecting the service from the queries accidentally not limited.
+// Zero removes the limit, which is the default.
+func SetDefaultMaxRows(max int) {
+	if max < 0 {
+		panic("max rows must not be negative")
+	}
+	atomic.StoreInt64(&defaultMaxRows, int64(max))
+}
+
+// MaxRows sets the limit of the rows scanned by the query, overriding the default limit, see SetDefaultMaxRows.
+// Scanning more rows fails with ErrTooManyRows.
+func (b *SqlBuilder) MaxRows(max int) *SqlBuilder {
+	if max < 1 {
+		panic("max rows must be positive")
+	}
+	b.maxRows = max
+	return b
+}
+
+// effectiveMaxRows returns the limit of the scanned rows, zero means no limit.
+func (b *SqlBuilder) effectiveMaxRows() int {
+	if b.maxRows > 0 {
+		return b.maxRows
+	}
+	return int(atomic.LoadInt64(&defaultMaxRows))
+}
@@ -0,0 +1,55 @@
+package sqlb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSqlBuilder_MaxRows(t *testing.T) {
+	products := UseTable[testDdlRow]().Seal()
+	ctx := context.Background()
+	values := [][]any{{int64(1), "a"}, {int64(2), "b"}, {int64(3), "c"}}
+	newBuilder := func() *SqlBuilder {
+		return Select(products.Columns("id", "title")...).From(products)
+	}
+
+	t.Run("within the limit", func(t *testing.T) {
+		rows, err := newBuilder().MaxRows(3).ScanRows(&valuesRows{rows: values})
+		require.NoError(t, err)
+		require.Equal(t, 3, rows.Count())
+	})
+
+	t.Run("exceeded", func(t *testing.T) {
+		_, err := newBuilder().MaxRows(2).ScanRows(&valuesRows{rows: values})
+		var tooMany ErrTooManyRows
+		require.True(t, errors.As(err, &tooMany))
+		require.Equal(t, 2, tooMany.Max)
+		require.EqualError(t, err, "too many rows, the limit is 2")
+
+		var dest []testDdlRow
+		err = newBuilder().MaxRows(2).ScanAll(ctx, &fixedRowsExecutor{rows: values}, &dest)
+		require.True(t, errors.As(err, &tooMany))
+		require.Empty(t, dest)
+	})
+
+	t.Run("default", func(t *testing.T) {
+		SetDefaultMaxRows(2)
+		defer SetDefaultMaxRows(0)
+
+		_, err := newBuilder().ScanRows(&valuesRows{rows: values})
+		require.True(t, errors.As(err, &ErrTooManyRows{}))
+
+		_, err = newBuilder().MaxRows(10).ScanRows(&valuesRows{rows: values})
+		require.NoError(t, err, "overridden by the builder")
+	})
+
+	require.PanicsWithValue(t, "max rows must be positive", func() {
+		newBuilder().MaxRows(0)
+	})
+	require.PanicsWithValue(t, "max rows must not be negative", func() {
+		SetDefaultMaxRows(-1)
+	})
+}
@@ -0,0 +1,50 @@
+package sqlb
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// MetricsHook observes the executions of the statements, e.g. to count and time the queries per table.
+// See package sqlbprom for the Prometheus implementation.
+type MetricsHook interface {
+	// ObserveQuery is called after each execution, including the failed ones.
+	ObserveQuery(ctx context.Context, observation QueryObservation)
+}
+
+// QueryObservation describes an execution of the statement, reported to MetricsHook.
+type QueryObservation struct {
+	Operation string        // Operation is the type of the statement in lower case, e.g. select, insert
+	Table     string        // Table is the primary table, the first table of FROM or the table of INSERT INTO
+	Duration  time.Duration // Duration of the execution, including the retries
+	Err       error         // Err is the failure of the execution, nil on success
+}
+
+// Metrics reports each execution of the statement to the hook.
+// The results served by the cache are not executions, thus not reported.
+func (b *SqlBuilder) Metrics(hook MetricsHook) *SqlBuilder {
+	if hook == nil {
+		panic("metrics hook is nil")
+	}
+	b.metricsHook = hook
+	return b
+}
+
+// observe reports the execution started at the given time to the metrics hook, if any.
+func (b *SqlBuilder) observe(ctx context.Context, start time.Time, err error) {
+	if b.metricsHook == nil {
+		return
+	}
+
+	var table string
+	if tables := b.usingTablesName(); len(tables) > 0 {
+		table = tables[0]
+	}
+	b.metricsHook.ObserveQuery(ctx, QueryObservation{
+		Operation: strings.ToLower(string(b._type)),
+		Table:     table,
+		Duration:  time.Since(start),
+		Err:       err,
+	})
+}
@@ -0,0 +1,54 @@
+package sqlb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingMetricsHook records the observations, for the sake of the test.
+type recordingMetricsHook struct {
+	observations []QueryObservation
+}
+
+func (h *recordingMetricsHook) ObserveQuery(_ context.Context, observation QueryObservation) {
+	h.observations = append(h.observations, observation)
+}
+
+func TestSqlBuilder_Metrics(t *testing.T) {
+	table1 := UseTable[testStruct1]().Alias("t1").Seal()
+	table2 := UseTable[testStruct2]().Alias("t2").Seal()
+
+	t.Run("select", func(t *testing.T) {
+		hook := &recordingMetricsHook{}
+		exec := &fixedRowsExecutor{rows: [][]any{{"a"}}}
+		_, err := Select(table1.Col("pk1")).
+			From(table1).
+			Join(InnerJoin, table2, table1.Col("pk1"), table2.Col("pk1")).
+			Metrics(hook).
+			QueryWithExecutor(context.Background(), exec)
+		require.NoError(t, err)
+
+		require.Len(t, hook.observations, 1)
+		require.Equal(t, "select", hook.observations[0].Operation)
+		require.Equal(t, "table1", hook.observations[0].Table)
+		require.NoError(t, hook.observations[0].Err)
+	})
+
+	t.Run("insert failed", func(t *testing.T) {
+		hook := &recordingMetricsHook{}
+		exec := &recordingExecutor{err: errors.New("boom")}
+		_, err := InsertInto(UseTable[testStruct2]().Seal()).
+			Values(testStruct2{}).
+			Metrics(hook).
+			ExecWithExecutor(context.Background(), exec)
+		require.EqualError(t, err, "boom")
+
+		require.Len(t, hook.observations, 1)
+		require.Equal(t, "insert", hook.observations[0].Operation)
+		require.Equal(t, "table2", hook.observations[0].Table)
+		require.EqualError(t, hook.observations[0].Err, "boom")
+	})
+}
@@ -0,0 +1,121 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/VictorTrustyDev/simple-go-sql-builder/sqlb"
+)
+
+// liveColumn is a column of the live database, read from information_schema.
+type liveColumn struct {
+	notNull bool
+}
+
+// GenerateMigration compares the registered table metadata against the live information_schema of the current schema,
+// and generates the statements to bring the database in sync:
+//   - CREATE TABLE for missing tables.
+//   - ALTER TABLE ... ADD COLUMN / DROP COLUMN for added/removed columns.
+//   - ALTER TABLE ... ALTER COLUMN ... SET/DROP NOT NULL for changed nullability.
+//
+// The migration is meant to be reviewed before being applied by the Runner, no statements means already in sync.
+// Tables exist in the database but not registered are ignored.
+func GenerateMigration(ctx context.Context, exec sqlb.Executor, version int64) (Migration, error) {
+	live, err := readLiveColumns(ctx, exec)
+	if err != nil {
+		return Migration{}, err
+	}
+
+	migration := Migration{
+		Version: version,
+		Name:    "generated",
+	}
+	for _, table := range sqlb.RegisteredTableSchemas() {
+		statements, err := diffTable(table, live[table.Name])
+		if err != nil {
+			return Migration{}, err
+		}
+		migration.Statements = append(migration.Statements, statements...)
+	}
+	return migration, nil
+}
+
+// diffTable generates the statements to alter the live table to match the schema, the live table is nil if not exists.
+func diffTable(table sqlb.TableSchema, live map[string]liveColumn) (statements []string, err error) {
+	defer func() {
+		if r := recover(); r != nil { // DDL generation panics when the data type is not provided
+			err = errors.Errorf("failed to generate migration for table %s: %v", table.Name, r)
+		}
+	}()
+
+	if live == nil {
+		return []string{table.CreateTableStatement()}, nil
+	}
+
+	for _, column := range table.Columns {
+		liveCol, found := live[unquoteIdentifier(column.Name)]
+		if !found {
+			statements = append(statements, table.AddColumnStatement(column.Name))
+			continue
+		}
+		if liveCol.notNull != column.NotNull {
+			action := "DROP NOT NULL"
+			if column.NotNull {
+				action = "SET NOT NULL"
+			}
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s %s", table.Name, column.Name, action))
+		}
+	}
+
+	removed := make([]string, 0)
+	for name := range live {
+		if _, found := table.Column(name); !found {
+			removed = append(removed, name)
+		}
+	}
+	sort.Strings(removed)
+	for _, name := range removed {
+		statements = append(statements, fmt.Sprintf(`ALTER TABLE %s DROP COLUMN IF EXISTS "%s"`, table.Name, name))
+	}
+
+	return statements, nil
+}
+
+// readLiveColumns reads the columns of the tables in the current schema, by table name then column name.
+func readLiveColumns(ctx context.Context, exec sqlb.Executor) (map[string]map[string]liveColumn, error) {
+	rows, err := exec.QueryContext(ctx, `SELECT table_name, column_name, is_nullable
+FROM information_schema.columns
+WHERE table_schema = current_schema()`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query information_schema")
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	tables := make(map[string]map[string]liveColumn)
+	for rows.Next() {
+		var tableName, columnName, isNullable string
+		if err := rows.Scan(&tableName, &columnName, &isNullable); err != nil {
+			return nil, errors.Wrap(err, "failed to scan information_schema")
+		}
+		if tables[tableName] == nil {
+			tables[tableName] = make(map[string]liveColumn)
+		}
+		tables[tableName][columnName] = liveColumn{
+			notNull: isNullable == "NO",
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to iterate information_schema")
+	}
+	return tables, nil
+}
+
+func unquoteIdentifier(name string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(name, `"`), `"`)
+}
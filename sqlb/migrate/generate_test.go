@@ -0,0 +1,67 @@
+package migrate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/VictorTrustyDev/simple-go-sql-builder/sqlb/sqlbtest"
+)
+
+func TestGenerateMigration(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("missing table", func(t *testing.T) {
+		exec := sqlbtest.NewExecutor().PrimeRows(
+			[]any{"other", "id", "NO"},
+		)
+		migration, err := GenerateMigration(ctx, exec, 10)
+		require.NoError(t, err)
+		require.Equal(t, int64(10), migration.Version)
+		require.Equal(t, []string{`CREATE TABLE IF NOT EXISTS users (
+  id BIGINT NOT NULL,
+  email TEXT,
+  PRIMARY KEY (id)
+)`}, migration.Statements)
+	})
+
+	t.Run("added, removed & changed columns", func(t *testing.T) {
+		exec := sqlbtest.NewExecutor().PrimeRows(
+			[]any{"users", "id", "YES"},
+			[]any{"users", "nickname", "YES"},
+			[]any{"users", "legacy", "NO"},
+		)
+		migration, err := GenerateMigration(ctx, exec, 11)
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			"ALTER TABLE users ALTER COLUMN id SET NOT NULL",
+			"ALTER TABLE users ADD COLUMN IF NOT EXISTS email TEXT",
+			`ALTER TABLE users DROP COLUMN IF EXISTS "legacy"`,
+			`ALTER TABLE users DROP COLUMN IF EXISTS "nickname"`,
+		}, migration.Statements)
+	})
+
+	t.Run("in sync", func(t *testing.T) {
+		exec := sqlbtest.NewExecutor().PrimeRows(
+			[]any{"users", "id", "NO"},
+			[]any{"users", "email", "YES"},
+		)
+		migration, err := GenerateMigration(ctx, exec, 12)
+		require.NoError(t, err)
+		require.Empty(t, migration.Statements)
+	})
+
+	t.Run("query error", func(t *testing.T) {
+		exec := sqlbtest.NewExecutor().PrimeError(errors.New("connection refused"))
+		_, err := GenerateMigration(ctx, exec, 13)
+		require.ErrorContains(t, err, "connection refused")
+	})
+
+	t.Run("rows error", func(t *testing.T) {
+		exec := sqlbtest.NewExecutor().PrimeRowsError(errors.New("connection lost"), []any{"users", "id", "NO"})
+		_, err := GenerateMigration(ctx, exec, 14)
+		require.EqualError(t, err, "failed to iterate information_schema: connection lost")
+	})
+}
@@ -0,0 +1,194 @@
+// Package migrate provides a small migration engine, tracks the applied versions in a metadata table
+// and applies the pending migrations through sqlb.Executor.
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/VictorTrustyDev/simple-go-sql-builder/sqlb"
+)
+
+// DefaultMetadataTable is the default name of the table tracks the applied versions.
+const DefaultMetadataTable = "schema_migrations"
+
+// Migration is a versioned set of statements, applied in order.
+type Migration struct {
+	Version    int64
+	Name       string
+	Statements []string
+}
+
+// Sql creates a migration from the user-supplied statements.
+func Sql(version int64, name string, statements ...string) Migration {
+	if len(statements) == 0 {
+		panic("no statements")
+	}
+	return Migration{
+		Version:    version,
+		Name:       name,
+		Statements: statements,
+	}
+}
+
+// CreateTable creates a migration to create table T, from the registered table metadata.
+func CreateTable[T any](version int64) Migration {
+	return Migration{
+		Version:    version,
+		Name:       "create table " + sqlb.GetTableMetadata[T]().Name(),
+		Statements: []string{sqlb.CreateTableStatement[T]()},
+	}
+}
+
+// AddColumns creates a migration to add the columns to table T, from the registered table metadata.
+func AddColumns[T any](version int64, columnsName ...string) Migration {
+	if len(columnsName) == 0 {
+		panic("no columns to add")
+	}
+	statements := make([]string, len(columnsName))
+	for i, columnName := range columnsName {
+		statements[i] = sqlb.AddColumnStatement[T](columnName)
+	}
+	return Migration{
+		Version:    version,
+		Name:       fmt.Sprintf("add columns %s to %s", strings.Join(columnsName, ", "), sqlb.GetTableMetadata[T]().Name()),
+		Statements: statements,
+	}
+}
+
+// Runner applies the pending migrations, in order of version.
+//
+// The statements are executed through the given executor one by one, provide an executor bound to a transaction
+// (eg: sqlb.WrapExecutor(tx)) to apply all or nothing.
+type Runner struct {
+	exec          sqlb.Executor
+	metadataTable string
+	migrations    []Migration
+}
+
+// NewRunner creates a runner of the migrations, the versions must be unique.
+func NewRunner(exec sqlb.Executor, migrations ...Migration) *Runner {
+	if exec == nil {
+		panic("executor is nil")
+	}
+
+	sorted := make([]Migration, len(migrations))
+	copy(sorted, migrations)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Version < sorted[j].Version
+	})
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].Version == sorted[i-1].Version {
+			panic(fmt.Sprintf("duplicated migration version %d", sorted[i].Version))
+		}
+	}
+
+	return &Runner{
+		exec:          exec,
+		metadataTable: DefaultMetadataTable,
+		migrations:    sorted,
+	}
+}
+
+// WithMetadataTable changes the table tracks the applied versions.
+func (r *Runner) WithMetadataTable(name string) *Runner {
+	if name = strings.TrimSpace(name); name == "" {
+		panic("name cannot be empty")
+	}
+	r.metadataTable = name
+	return r
+}
+
+// AppliedVersions returns the applied versions, in ascending order.
+func (r *Runner) AppliedVersions(ctx context.Context) ([]int64, error) {
+	if err := r.ensureMetadataTable(ctx); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.exec.QueryContext(ctx, fmt.Sprintf("SELECT version FROM %s ORDER BY version", r.metadataTable))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query applied versions")
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var versions []int64
+	for rows.Next() {
+		var version int64
+		if err := rows.Scan(&version); err != nil {
+			return nil, errors.Wrap(err, "failed to scan applied version")
+		}
+		versions = append(versions, version)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to iterate applied versions")
+	}
+	return versions, nil
+}
+
+// Pending returns the migrations not yet applied, in order of version.
+func (r *Runner) Pending(ctx context.Context) ([]Migration, error) {
+	versions, err := r.AppliedVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int64]struct{}, len(versions))
+	for _, version := range versions {
+		applied[version] = struct{}{}
+	}
+
+	var pending []Migration
+	for _, migration := range r.migrations {
+		if _, found := applied[migration.Version]; !found {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// Up applies the pending migrations, returns the versions applied.
+// It stops at the first failure, the failed migration is not recorded as applied.
+func (r *Runner) Up(ctx context.Context) ([]int64, error) {
+	pending, err := r.Pending(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []int64
+	for _, migration := range pending {
+		for _, stmt := range migration.Statements {
+			if _, err := r.exec.ExecContext(ctx, stmt); err != nil {
+				return applied, errors.Wrapf(err, "failed to apply migration %d (%s)", migration.Version, migration.Name)
+			}
+		}
+
+		if _, err := r.exec.ExecContext(
+			ctx,
+			fmt.Sprintf("INSERT INTO %s (version, name) VALUES ($1, $2)", r.metadataTable),
+			migration.Version, migration.Name,
+		); err != nil {
+			return applied, errors.Wrapf(err, "failed to record migration %d as applied", migration.Version)
+		}
+
+		applied = append(applied, migration.Version)
+	}
+	return applied, nil
+}
+
+func (r *Runner) ensureMetadataTable(ctx context.Context) error {
+	_, err := r.exec.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+  version BIGINT NOT NULL PRIMARY KEY,
+  name TEXT NOT NULL,
+  applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+)`, r.metadataTable))
+	if err != nil {
+		return errors.Wrap(err, "failed to create migrations metadata table")
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package migrate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/VictorTrustyDev/simple-go-sql-builder/sqlb"
+	"github.com/VictorTrustyDev/simple-go-sql-builder/sqlb/sqlbtest"
+)
+
+type testUser struct {
+	Id    int64
+	Email string
+}
+
+var _ = sqlb.NewTableMetadata[testUser]("users").
+	AddColumns(
+		sqlb.NewColumnMetadata[testUser]("id").
+			PrimaryKey().
+			SqlType("BIGINT").
+			InsertSpec(func(u testUser) any {
+				return u.Id
+			}).
+			SelectSpec(func(u *testUser) sqlb.ResultColumnSelectSpec {
+				return sqlb.ResultColumnSelectSpec{
+					ToQueryArg: func() any {
+						return &u.Id
+					},
+				}
+			}),
+		sqlb.NewColumnMetadata[testUser]("email").
+			SqlType("TEXT").
+			InsertSpec(func(u testUser) any {
+				return u.Email
+			}).
+			SelectSpec(func(u *testUser) sqlb.ResultColumnSelectSpec {
+				return sqlb.ResultColumnSelectSpec{
+					ToQueryArg: func() any {
+						return &u.Email
+					},
+				}
+			}),
+	).Build(sqlb.TableMetadataBuildOption{
+	ExpectedPkColumns: []string{"id"},
+})
+
+func TestRunner_Up(t *testing.T) {
+	ctx := context.Background()
+
+	migrations := []Migration{
+		AddColumns[testUser](2, "email"),
+		CreateTable[testUser](1),
+		Sql(3, "index users email", "CREATE INDEX IF NOT EXISTS users_email_idx ON users (email)"),
+	}
+
+	t.Run("apply pending", func(t *testing.T) {
+		exec := sqlbtest.NewExecutor().
+			PrimeResult(0, 0).         // create metadata table
+			PrimeRows([]any{int64(1)}) // version 1 already applied
+		applied, err := NewRunner(exec, migrations...).Up(ctx)
+		require.NoError(t, err)
+		require.Equal(t, []int64{2, 3}, applied)
+
+		statements := exec.Statements()
+		require.Len(t, statements, 6)
+		require.Contains(t, statements[0].Sql, "CREATE TABLE IF NOT EXISTS schema_migrations")
+		require.Equal(t, "SELECT version FROM schema_migrations ORDER BY version", statements[1].Sql)
+		require.Equal(t, "ALTER TABLE users ADD COLUMN IF NOT EXISTS email TEXT", statements[2].Sql)
+		require.Equal(t, "INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", statements[3].Sql)
+		require.Equal(t, []any{int64(2), "add columns email to users"}, statements[3].Args)
+		require.Equal(t, "CREATE INDEX IF NOT EXISTS users_email_idx ON users (email)", statements[4].Sql)
+		require.Equal(t, []any{int64(3), "index users email"}, statements[5].Args)
+	})
+
+	t.Run("create table", func(t *testing.T) {
+		exec := sqlbtest.NewExecutor()
+		applied, err := NewRunner(exec, migrations[1]).WithMetadataTable("migrations").Up(ctx)
+		require.NoError(t, err)
+		require.Equal(t, []int64{1}, applied)
+
+		statements := exec.Statements()
+		require.Equal(t, "SELECT version FROM migrations ORDER BY version", statements[1].Sql)
+		require.Equal(t, `CREATE TABLE IF NOT EXISTS users (
+  id BIGINT NOT NULL,
+  email TEXT,
+  PRIMARY KEY (id)
+)`, statements[2].Sql)
+	})
+
+	t.Run("stop at failure", func(t *testing.T) {
+		exec := sqlbtest.NewExecutor().
+			PrimeResult(0, 0).
+			PrimeRows().
+			PrimeResult(0, 0).
+			PrimeResult(0, 0).
+			PrimeError(errors.New("column already exists"))
+		applied, err := NewRunner(exec, migrations...).Up(ctx)
+		require.ErrorContains(t, err, "failed to apply migration 2 (add columns email to users): column already exists")
+		require.Equal(t, []int64{1}, applied)
+		require.Len(t, exec.Statements(), 5)
+	})
+
+	t.Run("rows error", func(t *testing.T) {
+		exec := sqlbtest.NewExecutor().
+			PrimeResult(0, 0).
+			PrimeRowsError(errors.New("connection lost"), []any{int64(1)})
+		applied, err := NewRunner(exec, migrations...).Up(ctx)
+		require.ErrorContains(t, err, "failed to iterate applied versions: connection lost")
+		require.Empty(t, applied)
+		require.Len(t, exec.Statements(), 2, "no migration is applied")
+	})
+}
+
+func TestNewRunner(t *testing.T) {
+	require.PanicsWithValue(t, "duplicated migration version 1", func() {
+		NewRunner(sqlbtest.NewExecutor(), Sql(1, "a", "SELECT 1"), Sql(1, "b", "SELECT 1"))
+	})
+}
@@ -0,0 +1,105 @@
+package sqlb
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// BuildNamed builds the statement same as Build, but the placeholders are rendered as named parameters p1, p2,...
+// in the given style, and the arguments are returned as sql.NamedArg, for drivers and tools prefer named binding.
+//
+// The placeholders written directly in the tokens ($1, ? or @p1, depends on the dialect) are renamed too.
+func (b *SqlBuilder) BuildNamed(style NamedParamStyle) (stmt string, namedArgs []sql.NamedArg) {
+	var prefix string
+	switch style {
+	case NamedParamColon:
+		prefix = ":"
+	case NamedParamAt:
+		prefix = "@"
+	default:
+		panic("unknown named param style")
+	}
+
+	stmt, args := b.Build()
+
+	namedArgs = make([]sql.NamedArg, len(args))
+	for i, arg := range args {
+		namedArgs[i] = sql.Named(namedParamName(i+1), arg)
+	}
+
+	return renamePlaceholders(stmt, b.dialect, len(args), func(n int) string {
+		return prefix + namedParamName(n)
+	}), namedArgs
+}
+
+func namedParamName(n int) string {
+	return "p" + strconv.Itoa(n)
+}
+
+// renamePlaceholders renames the positional placeholders of the dialect, placeholder at position n is replaced by rename(n).
+// String literals are kept as is.
+func renamePlaceholders(stmt string, dialect Dialect, argsCount int, rename func(n int) string) string {
+	sb := strings.Builder{}
+	sb.Grow(len(stmt) + argsCount)
+
+	writeNamed := func(n int) {
+		if n < 1 || n > argsCount {
+			panic(fmt.Sprintf("placeholder no.%d is out of range, total %d args", n, argsCount))
+		}
+		sb.WriteString(rename(n))
+	}
+
+	// numberAt returns the number starts at position i, and the position after the number
+	numberAt := func(i int) (int, int) {
+		j := i
+		for j < len(stmt) && isDigit(stmt[j]) {
+			j++
+		}
+		if j == i {
+			return 0, i
+		}
+		n, _ := strconv.Atoi(stmt[i:j])
+		return n, j
+	}
+
+	questionMarks := 0
+	inLiteral := false
+	for i := 0; i < len(stmt); i++ {
+		c := stmt[i]
+		if c == '\'' {
+			inLiteral = !inLiteral
+		}
+		if inLiteral {
+			sb.WriteByte(c)
+			continue
+		}
+
+		switch {
+		case c == '?' && dialect.placeholder(1) == "?":
+			questionMarks++
+			writeNamed(questionMarks)
+			continue
+		case c == '$' && dialect == DialectPostgres:
+			if n, next := numberAt(i + 1); next > i+1 {
+				writeNamed(n)
+				i = next - 1
+				continue
+			}
+		case c == '@' && dialect == DialectSQLServer && strings.HasPrefix(stmt[i:], "@p"):
+			if n, next := numberAt(i + 2); next > i+2 {
+				writeNamed(n)
+				i = next - 1
+				continue
+			}
+		}
+		sb.WriteByte(c)
+	}
+
+	return sb.String()
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
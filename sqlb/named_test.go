@@ -0,0 +1,57 @@
+package sqlb
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSqlBuilder_BuildNamed(t *testing.T) {
+	t.Run("postgres", func(t *testing.T) {
+		table1 := UseTable[testStruct1]().Alias("t").Seal()
+		gotSql, gotArgs := Select(table1.Col("pk1")).From(table1).
+			Where(table1.Col("pk1"), "= $1 AND", table1.Col("cost"), "<> '$1'").
+			And(Eq(table1.Col("amount"), 3)).
+			Or(table1.Col("pk1"), "= $1").
+			Args("a").
+			WithFormat(FormatSingleLine).
+			BuildNamed(NamedParamColon)
+		require.Equal(t, `SELECT t.pk1 FROM table1 AS t WHERE t.pk1 = :p1 AND t.cost <> '$1' AND t.amount = :p2 OR t.pk1 = :p1`, gotSql)
+		require.Equal(t, []sql.NamedArg{sql.Named("p1", "a"), sql.Named("p2", 3)}, gotArgs)
+	})
+
+	t.Run("question mark placeholders", func(t *testing.T) {
+		table1 := UseTable[testStruct1]().Alias("t").Seal()
+		gotSql, gotArgs := Select(table1.Col("pk1")).From(table1).
+			Where(table1.Col("pk1"), "= ?").
+			And(Eq(table1.Col("amount"), 3)).
+			Args("a").
+			WithDialect(DialectMySQL).
+			WithFormat(FormatSingleLine).
+			BuildNamed(NamedParamAt)
+		require.Equal(t, `SELECT t.pk1 FROM table1 AS t WHERE t.pk1 = @p1 AND t.amount = @p2`, gotSql)
+		require.Equal(t, []sql.NamedArg{sql.Named("p1", "a"), sql.Named("p2", 3)}, gotArgs)
+	})
+
+	t.Run("insert", func(t *testing.T) {
+		table1 := UseTable[testStruct1]().Seal()
+		gotSql, gotArgs := InsertInto(table1, table1.Col("pk1"), table1.Col("pk2")).
+			Values(testStruct1{Pk1: "1", Pk2: 2}).
+			WithDialect(DialectSQLServer).
+			BuildNamed(NamedParamColon)
+		require.Equal(t, `INSERT INTO table1 (pk1, pk2)
+VALUES (:p1,:p2)`, gotSql)
+		require.Equal(t, []sql.NamedArg{sql.Named("p1", "1"), sql.Named("p2", 2)}, gotArgs)
+	})
+
+	t.Run("out of range", func(t *testing.T) {
+		table1 := UseTable[testStruct1]().Alias("t").Seal()
+		require.PanicsWithValue(t, "placeholder no.2 is out of range, total 1 args", func() {
+			Select(table1.Col("pk1")).From(table1).
+				Where(table1.Col("pk1"), "= $2").
+				Args("a").
+				BuildNamed(NamedParamColon)
+		})
+	})
+}
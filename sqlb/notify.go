@@ -0,0 +1,79 @@
+package sqlb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// ChangeNotification is the default payload of the change notifications, sent when no payload is provided to Notify.
+type ChangeNotification struct {
+	Table     string `json:"table"`
+	Operation string `json:"operation"`
+}
+
+// NotificationChannel returns the channel of the change notifications of table T, named as [table]_changes.
+func NotificationChannel[T any]() string {
+	return notificationChannel(GetTableMetadata[T]().Name())
+}
+
+func notificationChannel(tableName string) string {
+	return tableName + "_changes"
+}
+
+// Notify sends the notification (pg_notify) to the channel of the table, after successful Exec* (Postgres only).
+//
+// The payload is encoded as JSON, nil sends a ChangeNotification.
+// Inside a transaction, the notification is delivered to the listeners only when the transaction commits.
+// The failure of sending the notification after the successful execution is reported as NotifyError,
+// returned along with the result of the execution, since the statement is applied.
+func (b *SqlBuilder) Notify(payload any) *SqlBuilder {
+	b.mustTypeInsert()
+
+	if payload == nil {
+		payload = ChangeNotification{
+			Table:     b.insertIntoTable.genericTableMeta().Name(),
+			Operation: "INSERT",
+		}
+	}
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		panic(errors.Wrap(err, "failed to encode notification payload"))
+	}
+
+	b.notifyPayload = string(encoded)
+	return b
+}
+
+// mustNotifySupported panics if the notification is required but not supported by the dialect,
+// checked before the execution so the statement is not executed.
+func (b *SqlBuilder) mustNotifySupported() {
+	if b.notifyPayload != "" && b.dialect != DialectPostgres {
+		panic(fmt.Sprintf("NOTIFY is not supported by dialect %s", b.dialect))
+	}
+}
+
+// notify sends the notification to the channel of the table, if required, after the execution with the result.
+func (b *SqlBuilder) notify(ctx context.Context, exec Executor, result sql.Result) error {
+	if b.notifyPayload == "" {
+		return nil
+	}
+
+	channel := notificationChannel(b.insertIntoTable.genericTableMeta().Name())
+	if _, err := exec.ExecContext(ctx, "SELECT pg_notify($1, $2)", channel, b.notifyPayload); err != nil {
+		return NotifyError{Channel: channel, Result: result, Err: err}
+	}
+	return nil
+}
+
+// DecodeNotification decodes the JSON payload of the notification.
+func DecodeNotification[P any](payload string) (P, error) {
+	var p P
+	if err := json.Unmarshal([]byte(payload), &p); err != nil {
+		return p, errors.Wrap(err, "failed to decode notification payload")
+	}
+	return p, nil
+}
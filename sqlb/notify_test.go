@@ -0,0 +1,78 @@
+package sqlb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationChannel(t *testing.T) {
+	require.Equal(t, "products_changes", NotificationChannel[testDdlRow]())
+}
+
+func TestSqlBuilder_Notify(t *testing.T) {
+	newBuilder := func() *SqlBuilder {
+		return InsertInto(UseTable[testDdlRow]().Seal()).
+			Values(testDdlRow{Id: 1, Title: "book"})
+	}
+
+	t.Run("default payload", func(t *testing.T) {
+		b := newBuilder().Notify(nil)
+		require.Equal(t, `{"table":"products","operation":"INSERT"}`, b.notifyPayload)
+
+		exec := &recordingExecutor{}
+		_, err := b.ExecWithExecutor(context.Background(), exec)
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			"INSERT INTO products (id, title, note)\nVALUES ($1,$2,$3)",
+			"SELECT pg_notify($1, $2)",
+		}, exec.statements)
+	})
+
+	t.Run("custom payload", func(t *testing.T) {
+		type payload struct {
+			Id int64 `json:"id"`
+		}
+		b := newBuilder().Notify(payload{Id: 1})
+		require.Equal(t, `{"id":1}`, b.notifyPayload)
+
+		decoded, err := DecodeNotification[payload](b.notifyPayload)
+		require.NoError(t, err)
+		require.Equal(t, payload{Id: 1}, decoded)
+	})
+
+	t.Run("not notify when execution failed", func(t *testing.T) {
+		exec := &recordingExecutor{err: errors.New("duplicated key")}
+		_, err := newBuilder().Notify(nil).ExecWithExecutor(context.Background(), exec)
+		require.Error(t, err)
+		require.Len(t, exec.statements, 1)
+	})
+
+	t.Run("notify failure", func(t *testing.T) {
+		exec := &flakyExecutor{errs: []error{nil, errors.New("conn closed")}}
+		_, err := newBuilder().Notify(nil).ExecWithExecutor(context.Background(), exec)
+		var notifyErr NotifyError
+		require.ErrorAs(t, err, &notifyErr, "statement is executed, only the notification failed")
+		require.Equal(t, "products_changes", notifyErr.Channel)
+		require.EqualError(t, notifyErr.Err, "conn closed")
+		require.Len(t, exec.statements, 2)
+	})
+
+	t.Run("not supported by dialect", func(t *testing.T) {
+		exec := &recordingExecutor{}
+		require.Panics(t, func() {
+			_, _ = newBuilder().WithDialect(DialectMySQL).Notify(nil).ExecWithExecutor(context.Background(), exec)
+		})
+		require.Empty(t, exec.statements, "must not execute the statement")
+	})
+
+	t.Run("invalid payload", func(t *testing.T) {
+		require.Panics(t, func() {
+			newBuilder().Notify(func() {})
+		})
+		_, err := DecodeNotification[ChangeNotification]("not json")
+		require.ErrorContains(t, err, "failed to decode notification payload")
+	})
+}
@@ -0,0 +1,158 @@
+package sqlb
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// nullGroupingScanner scans the column grouped by ROLLUP, CUBE or GROUPING SETS,
+// which is NULL in the subtotal rows: leaves the destination as is and records the NULL.
+type nullGroupingScanner struct {
+	dest any
+	null bool
+}
+
+var _ sql.Scanner = (*nullGroupingScanner)(nil)
+
+func (s *nullGroupingScanner) Scan(src any) error {
+	if src == nil {
+		s.null = true
+		return nil
+	}
+	return assignScanned(s.dest, src)
+}
+
+// assignScanned stores the value scanned from the driver into the destination pointer,
+// following the conversions of database/sql (Rows.Scan): the sql.Scanner of the destination scans the value,
+// the numbers are converted via their text and range-checked, so 300 fails to be stored into int8 and 2.7 into int.
+func assignScanned(dest, src any) error {
+	if scanner, ok := dest.(sql.Scanner); ok {
+		return scanner.Scan(src)
+	}
+
+	dv := reflect.ValueOf(dest)
+	if dv.Kind() != reflect.Ptr || dv.IsNil() {
+		return errors.Errorf("destination must be a non-nil pointer, got %T", dest)
+	}
+	dv = dv.Elem()
+
+	switch d := dest.(type) {
+	case *[]byte:
+		if b, ok := src.([]byte); ok {
+			*d = append([]byte(nil), b...)
+			return nil
+		}
+	case *bool:
+		v, err := driver.Bool.ConvertValue(src)
+		if err != nil {
+			return errors.Wrapf(err, "failed to convert %T to bool", src)
+		}
+		*d = v.(bool)
+		return nil
+	case *any:
+		if b, ok := src.([]byte); ok {
+			src = append([]byte(nil), b...)
+		}
+		*d = src
+		return nil
+	}
+
+	sv := reflect.ValueOf(src)
+	if sv.Type().AssignableTo(dv.Type()) {
+		if b, ok := src.([]byte); ok {
+			src = append([]byte(nil), b...)
+			sv = reflect.ValueOf(src)
+		}
+		dv.Set(sv)
+		return nil
+	}
+	if dv.Kind() == sv.Kind() && sv.Type().ConvertibleTo(dv.Type()) {
+		dv.Set(sv.Convert(dv.Type()))
+		return nil
+	}
+
+	switch dv.Kind() {
+	case reflect.Ptr:
+		ptr := reflect.New(dv.Type().Elem())
+		if err := assignScanned(ptr.Interface(), src); err != nil {
+			return err
+		}
+		dv.Set(ptr)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return setNumberFromString(dv, scannedText(sv))
+	case reflect.String:
+		isBytes := sv.Kind() == reflect.Slice && sv.Type().Elem().Kind() == reflect.Uint8
+		if sv.Kind() == reflect.String || sv.Kind() == reflect.Bool || isNumberKind(sv.Kind()) || isBytes {
+			dv.SetString(scannedText(sv))
+			return nil
+		}
+	}
+
+	return errors.Errorf("unsupported scan, storing %T into %T", src, dest)
+}
+
+// scannedText returns the text of the scanned value, as database/sql converts the values to numbers and strings.
+func scannedText(sv reflect.Value) string {
+	switch sv.Kind() {
+	case reflect.String:
+		return sv.String()
+	case reflect.Slice:
+		if sv.Type().Elem().Kind() == reflect.Uint8 {
+			return string(sv.Bytes())
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(sv.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(sv.Uint(), 10)
+	case reflect.Float32:
+		return strconv.FormatFloat(sv.Float(), 'g', -1, 32)
+	case reflect.Float64:
+		return strconv.FormatFloat(sv.Float(), 'g', -1, 64)
+	case reflect.Bool:
+		return strconv.FormatBool(sv.Bool())
+	}
+	return fmt.Sprintf("%v", sv.Interface())
+}
+
+func isNumberKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+func setNumberFromString(dv reflect.Value, s string) error {
+	switch dv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err := strconv.ParseInt(s, 10, dv.Type().Bits())
+		if err != nil {
+			return errors.Wrapf(err, "failed to convert %q to %s", s, dv.Type())
+		}
+		dv.SetInt(v)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v, err := strconv.ParseUint(s, 10, dv.Type().Bits())
+		if err != nil {
+			return errors.Wrapf(err, "failed to convert %q to %s", s, dv.Type())
+		}
+		dv.SetUint(v)
+	default:
+		v, err := strconv.ParseFloat(s, dv.Type().Bits())
+		if err != nil {
+			return errors.Wrapf(err, "failed to convert %q to %s", s, dv.Type())
+		}
+		dv.SetFloat(v)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package sqlb
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ModuloPartitions splits the rows into n partitions by modulo of the integer column:
+// 'ABS(MOD([column], n)) = i' for i in [0, n).
+func ModuloPartitions(column GenericColumnToUse, n int) []Expr {
+	if n < 1 {
+		panic("number of partitions must be positive")
+	}
+	partitions := make([]Expr, n)
+	for i := range partitions {
+		partitions[i] = concatExpr("ABS(MOD(", column, fmt.Sprintf(", %d)) = %d", n, i))
+	}
+	return partitions
+}
+
+// RangePartitions splits the key range [min, max] of the integer column into n contiguous partitions:
+// '([column] >= lo AND [column] < hi)'.
+func RangePartitions(column GenericColumnToUse, min, max int64, n int) []Expr {
+	if n < 1 {
+		panic("number of partitions must be positive")
+	}
+	if min > max {
+		panic("min must not be greater than max")
+	}
+
+	size := (max - min + 1) / int64(n)
+	if (max-min+1)%int64(n) != 0 {
+		size++
+	}
+	if size < 1 {
+		size = 1
+	}
+
+	partitions := make([]Expr, 0, n)
+	for lo := min; lo <= max && len(partitions) < n; lo += size {
+		hi := lo + size
+		if hi > max || len(partitions) == n-1 {
+			hi = max + 1
+		}
+		partitions = append(partitions, concatExpr("(", Gte(column, lo), " AND ", Lt(column, hi), ")"))
+	}
+	return partitions
+}
+
+// ScanParallel runs the query of each partition concurrently, the builder of each partition is created by the factory,
+// eg: add the partition to the WHERE clause. The scanned rows are streamed to the callback, one call at a time.
+//
+// The first error cancels the context of the other partitions and is returned.
+func ScanParallel(ctx context.Context, exec Executor, partitions []Expr, builderFactory func(partition Expr) *SqlBuilder, fn func(rows *ScannedRows) error) error {
+	var mu sync.Mutex
+	return runParallel(ctx, len(partitions), func(ctx context.Context, i int) error {
+		rows, err := builderFactory(partitions[i]).QueryWithExecutor(ctx, exec)
+		if err != nil {
+			return errors.Wrapf(err, "failed to query partition no.%d", i+1)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		return fn(rows)
+	})
+}
+
+// QueryAllParallel runs the query of each partition concurrently like ScanParallel,
+// and merges the rows of table T in order of the partitions.
+//
+// The builder must select columns of exactly one table of type T and nothing else.
+func QueryAllParallel[T any](ctx context.Context, exec Executor, partitions []Expr, builderFactory func(partition Expr) *SqlBuilder) ([]T, error) {
+	results := make([][]T, len(partitions))
+	err := runParallel(ctx, len(partitions), func(ctx context.Context, i int) error {
+		b := builderFactory(partitions[i])
+		table := usingTableOfType[T](b)
+		rows, err := b.QueryWithExecutor(ctx, exec)
+		if err != nil {
+			return errors.Wrapf(err, "failed to query partition no.%d", i+1)
+		}
+		results[i] = table.ReadAllFromRows(rows)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var merged []T
+	for _, result := range results {
+		merged = append(merged, result...)
+	}
+	return merged, nil
+}
+
+// runParallel runs the function n times concurrently, returns the first error and cancels the others.
+// Panics are recovered and returned as error.
+func runParallel(ctx context.Context, n int, fn func(ctx context.Context, i int) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			err := func() (err error) {
+				defer func() {
+					if r := recover(); r != nil {
+						err = fmt.Errorf("panic in partition no.%d: %v", i+1, r)
+					}
+				}()
+				return fn(ctx, i)
+			}()
+			if err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}(i)
+	}
+	wg.Wait()
+	return firstErr
+}
@@ -0,0 +1,118 @@
+package sqlb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// rangeExecutor returns the products with id in [lo, hi) of the arguments, for the sake of the test.
+type rangeExecutor struct {
+	mu      sync.Mutex
+	queries int
+	failOn  int64
+}
+
+func (e *rangeExecutor) QueryContext(ctx context.Context, _ string, args ...any) (SqlRows, error) {
+	e.mu.Lock()
+	e.queries++
+	e.mu.Unlock()
+
+	lo, hi := args[0].(int64), args[1].(int64)
+	if lo == e.failOn {
+		return nil, errors.New("boom")
+	}
+	var rows [][]any
+	for id := lo; id < hi; id++ {
+		rows = append(rows, []any{id, fmt.Sprintf("product %d", id)})
+	}
+	return &valuesRows{rows: rows}, ctx.Err()
+}
+
+func (e *rangeExecutor) ExecContext(context.Context, string, ...any) (sql.Result, error) {
+	return nil, errors.New("not supported")
+}
+
+func TestPartitions(t *testing.T) {
+	products := UseTable[testDdlRow]().Seal()
+	render := func(partitions []Expr) []string {
+		var rendered []string
+		for _, partition := range partitions {
+			s, args := partition.render()
+			rendered = append(rendered, fmt.Sprintf("%s %v", s, args))
+		}
+		return rendered
+	}
+
+	require.Equal(t, []string{
+		"ABS(MOD(products.id, 3)) = 0 []",
+		"ABS(MOD(products.id, 3)) = 1 []",
+		"ABS(MOD(products.id, 3)) = 2 []",
+	}, render(ModuloPartitions(products.Col("id"), 3)))
+
+	require.Equal(t, []string{
+		"(products.id >= $1 AND products.id < $2) [1 5]",
+		"(products.id >= $1 AND products.id < $2) [5 9]",
+		"(products.id >= $1 AND products.id < $2) [9 11]",
+	}, render(RangePartitions(products.Col("id"), 1, 10, 3)))
+
+	require.Len(t, RangePartitions(products.Col("id"), 1, 2, 5), 2, "no empty partitions")
+
+	require.Panics(t, func() {
+		ModuloPartitions(products.Col("id"), 0)
+	})
+	require.Panics(t, func() {
+		RangePartitions(products.Col("id"), 10, 1, 2)
+	})
+}
+
+func TestQueryAllParallel(t *testing.T) {
+	factory := func(partition Expr) *SqlBuilder {
+		products := UseTable[testDdlRow]().Seal()
+		return Select(products.Columns("id", "title")...).From(products).Where(partition)
+	}
+	partitions := RangePartitions(UseTable[testDdlRow]().Seal().Col("id"), 1, 10, 3)
+
+	t.Run("merge results in order of partitions", func(t *testing.T) {
+		exec := &rangeExecutor{}
+		got, err := QueryAllParallel[testDdlRow](context.Background(), exec, partitions, factory)
+		require.NoError(t, err)
+		require.Equal(t, 3, exec.queries)
+		require.Len(t, got, 10)
+		for i, row := range got {
+			require.Equal(t, int64(i+1), row.Id)
+		}
+	})
+
+	t.Run("stream to callback", func(t *testing.T) {
+		var ids []int64
+		products := UseTable[testDdlRow]().Seal()
+		err := ScanParallel(context.Background(), &rangeExecutor{}, partitions, func(partition Expr) *SqlBuilder {
+			return Select(products.Columns("id", "title")...).From(products).Where(partition)
+		}, func(rows *ScannedRows) error {
+			for _, row := range products.ReadAllFromRows(rows) {
+				ids = append(ids, row.Id)
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+		require.Equal(t, []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, ids)
+	})
+
+	t.Run("first error is returned", func(t *testing.T) {
+		_, err := QueryAllParallel[testDdlRow](context.Background(), &rangeExecutor{failOn: 5}, partitions, factory)
+		require.ErrorContains(t, err, "failed to query partition no.2: boom")
+	})
+
+	t.Run("panic is returned as error", func(t *testing.T) {
+		_, err := QueryAllParallel[testStruct1](context.Background(), &rangeExecutor{}, partitions, factory)
+		require.ErrorContains(t, err, "no table of type")
+	})
+}
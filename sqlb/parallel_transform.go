@@ -0,0 +1,80 @@
+package sqlb
+
+import "sync"
+
+// ParallelTransform runs the OptionalTransform functions of the scanned rows by a pool of the given number of workers,
+// while the following rows are being scanned. Useful for the wide rows with expensive transforms, e.g. JSON decoding or decryption.
+//
+// The transforms must be independent of each other, each transform only writes the column of its own row.
+// The first failure is returned after the scanning, the remaining transforms are skipped.
+func (b *SqlBuilder) ParallelTransform(workers int) *SqlBuilder {
+	if workers < 1 {
+		panic("number of transform workers must be positive")
+	}
+	b.transformWorkers = workers
+	return b
+}
+
+// transformPool is the bounded pool of workers running the transforms,
+// submitting blocks when all workers are busy so the scanning does not run ahead unbounded.
+type transformPool struct {
+	jobs chan transformJob
+	wg   sync.WaitGroup
+	mu   sync.Mutex
+	err  error // err is the first failure
+}
+
+func newTransformPool(workers int) *transformPool {
+	p := &transformPool{
+		jobs: make(chan transformJob, workers),
+	}
+	p.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer p.wg.Done()
+			for job := range p.jobs {
+				if p.failed() {
+					continue // drain
+				}
+				if err := job.transform.transform(); err != nil {
+					p.fail(job.transform.fail(job.row, err))
+				}
+			}
+		}()
+	}
+	return p
+}
+
+// transformJob is the transform of the column of the row.
+type transformJob struct {
+	row       int
+	transform scannedTransform
+}
+
+func (p *transformPool) submit(row int, transform scannedTransform) {
+	if p.failed() {
+		return
+	}
+	p.jobs <- transformJob{row: row, transform: transform}
+}
+
+func (p *transformPool) failed() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.err != nil
+}
+
+func (p *transformPool) fail(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err == nil {
+		p.err = err
+	}
+}
+
+// wait waits for the submitted transforms and returns the first failure.
+func (p *transformPool) wait() error {
+	close(p.jobs)
+	p.wg.Wait()
+	return p.err
+}
@@ -0,0 +1,101 @@
+package sqlb
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testPayloadRow struct {
+	Id      int64
+	Raw     string
+	Decoded string
+}
+
+var (
+	payloadTransformsRunning    int32
+	payloadTransformsMaxRunning int32
+)
+
+var tableTestPayload = NewTableMetadata[testPayloadRow]("payloads").
+	AddColumns(
+		NewColumnMetadata[testPayloadRow]("id").
+			PrimaryKey().
+			InsertSpec(func(r testPayloadRow) any {
+				return r.Id
+			}).
+			SelectSpec(func(r *testPayloadRow) ResultColumnSelectSpec {
+				return ResultColumnSelectSpec{
+					ToQueryArg: func() any {
+						return &r.Id
+					},
+				}
+			}),
+		NewColumnMetadata[testPayloadRow]("payload").
+			InsertSpec(func(r testPayloadRow) any {
+				return r.Raw
+			}).
+			SelectSpec(func(r *testPayloadRow) ResultColumnSelectSpec {
+				return ResultColumnSelectSpec{
+					ToQueryArg: func() any {
+						return &r.Raw
+					},
+					OptionalTransform: func() error { // expensive decoding
+						running := atomic.AddInt32(&payloadTransformsRunning, 1)
+						defer atomic.AddInt32(&payloadTransformsRunning, -1)
+						for {
+							max := atomic.LoadInt32(&payloadTransformsMaxRunning)
+							if running <= max || atomic.CompareAndSwapInt32(&payloadTransformsMaxRunning, max, running) {
+								break
+							}
+						}
+
+						time.Sleep(5 * time.Millisecond)
+						r.Decoded = "decoded " + r.Raw
+						return nil
+					},
+				}
+			}),
+	).Build(TableMetadataBuildOption{
+	ExpectedPkColumns: []string{"id"},
+})
+
+func TestSqlBuilder_ParallelTransform(t *testing.T) {
+	t.Run("transforms run by the workers", func(t *testing.T) {
+		atomic.StoreInt32(&payloadTransformsMaxRunning, 0)
+
+		table := UseTable[testPayloadRow]().Seal()
+		values := make([][]any, 20)
+		for i := range values {
+			values[i] = []any{int64(i), "p"}
+		}
+
+		rows, err := Select(table.Columns()...).From(table).ParallelTransform(4).ScanRows(&valuesRows{rows: values})
+		require.NoError(t, err)
+		result := table.ReadAllFromRows(rows)
+		require.Len(t, result, 20)
+		for i, row := range result {
+			require.Equal(t, testPayloadRow{Id: int64(i), Raw: "p", Decoded: "decoded p"}, row)
+		}
+
+		maxRunning := atomic.LoadInt32(&payloadTransformsMaxRunning)
+		require.Greater(t, maxRunning, int32(1))
+		require.LessOrEqual(t, maxRunning, int32(4), "bounded by the workers")
+	})
+
+	t.Run("first failure is returned", func(t *testing.T) {
+		table := UseTable[testEnumRow]().Seal()
+		_, err := Select(table.Columns()...).From(table).ParallelTransform(2).ScanRows(&valuesRows{rows: [][]any{
+			{"1", "paid"},
+			{"2", "refunded"},
+			{"3", "pending"},
+		}})
+		require.ErrorContains(t, err, "failed to transform column")
+	})
+
+	require.PanicsWithValue(t, "number of transform workers must be positive", func() {
+		Select().ParallelTransform(0)
+	})
+}
@@ -0,0 +1,31 @@
+package sqlb
+
+// PartialRow is the row of a table read from a selection of a subset of its columns.
+// The fields of the columns not selected hold the zero values, Populated tells them apart.
+type PartialRow[T any] struct {
+	Row     T
+	columns map[string]struct{} // columns are the selected columns of the table, shared by the rows
+}
+
+// Populated returns true if the column was selected, meaning the field of the row holds the scanned value.
+func (r PartialRow[T]) Populated(columnName string) bool {
+	_, found := r.columns[wrapWithDoubleQuoteIfSqlKeyword(columnName)]
+	return found
+}
+
+// ReadPartialFromRow reads the table from the scanned rows, along with the selected columns of the table.
+func (t *TableToUse[T]) ReadPartialFromRow(scanner *ScannedRows) PartialRow[T] {
+	return PartialRow[T]{
+		Row:     t.ReadFromRow(scanner),
+		columns: scanner.selectedColumns[scanner.aliasOf(t)],
+	}
+}
+
+// ReadAllPartialFromRows reads all the table from the scanned rows, along with the selected columns of the table.
+func (t *TableToUse[T]) ReadAllPartialFromRows(scanner *ScannedRows) []PartialRow[T] {
+	result := make([]PartialRow[T], 0, len(scanner.rowsOfAliasToRow))
+	for scanner.Next() {
+		result = append(result, t.ReadPartialFromRow(scanner))
+	}
+	return result
+}
@@ -0,0 +1,94 @@
+package sqlb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testTagRow has the column named by a keyword, for the sake of the test.
+type testTagRow struct {
+	Id   int64
+	Name string
+}
+
+var tableTestTag = NewTableMetadata[testTagRow]("tags").
+	AddColumns(
+		NewColumnMetadata[testTagRow]("id").
+			PrimaryKey().
+			InsertSpec(func(r testTagRow) any {
+				return r.Id
+			}).
+			SelectSpec(func(r *testTagRow) ResultColumnSelectSpec {
+				return ResultColumnSelectSpec{
+					ToQueryArg: func() any {
+						return &r.Id
+					},
+				}
+			}),
+		NewColumnMetadata[testTagRow]("name").
+			InsertSpec(func(r testTagRow) any {
+				return r.Name
+			}).
+			SelectSpec(func(r *testTagRow) ResultColumnSelectSpec {
+				return ResultColumnSelectSpec{
+					ToQueryArg: func() any {
+						return &r.Name
+					},
+				}
+			}),
+	).Build(TableMetadataBuildOption{
+	ExpectedPkColumns: []string{"id"},
+})
+
+func TestTableToUse_ReadAllPartialFromRows(t *testing.T) {
+	table1 := UseTable[testStruct1]().Seal()
+	table2 := UseTable[testStruct2]().Alias("t2").Seal()
+
+	rows, err := Select(table1.Col("pk1"), table1.Col("amount"), table2.Col("pk3")).
+		From(table1).
+		Join(InnerJoin, table2, table1.Col("pk1"), table2.Col("pk1")).
+		ScanRows(&valuesRows{rows: [][]any{
+			{"a", 10, int64(1)},
+			{"b", 20, int64(2)},
+		}})
+	require.NoError(t, err)
+
+	require.True(t, rows.Next())
+	row1 := table1.ReadPartialFromRow(rows)
+	require.Equal(t, testStruct1{Pk1: "a", Amount: 10}, row1.Row)
+	require.True(t, row1.Populated("pk1"))
+	require.True(t, row1.Populated("amount"))
+	require.False(t, row1.Populated("pk2"))
+	require.False(t, row1.Populated("cost"))
+
+	row2 := table2.ReadPartialFromRow(rows)
+	require.Equal(t, testStruct2{Pk3: 1}, row2.Row)
+	require.True(t, row2.Populated("pk3"))
+	require.False(t, row2.Populated("pk1"))
+
+	t.Run("all rows", func(t *testing.T) {
+		rows, err := Select(table1.Col("pk1")).From(table1).ScanRows(&valuesRows{rows: [][]any{{"a"}, {"b"}}})
+		require.NoError(t, err)
+
+		result := table1.ReadAllPartialFromRows(rows)
+		require.Len(t, result, 2)
+		require.Equal(t, testStruct1{Pk1: "b"}, result[1].Row)
+		for _, row := range result {
+			require.True(t, row.Populated("pk1"))
+			require.False(t, row.Populated("amount"))
+		}
+	})
+
+	t.Run("keyword column", func(t *testing.T) {
+		tags := UseTable[testTagRow]().Seal()
+		rows, err := Select(tags.Col("name")).From(tags).ScanRows(&valuesRows{rows: [][]any{{"go"}}})
+		require.NoError(t, err)
+
+		require.True(t, rows.Next())
+		row := tags.ReadPartialFromRow(rows)
+		require.Equal(t, testTagRow{Name: "go"}, row.Row)
+		require.True(t, row.Populated("name"))
+		require.False(t, row.Populated("id"))
+	})
+}
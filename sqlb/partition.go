@@ -0,0 +1,91 @@
+package sqlb
+
+import (
+	"fmt"
+	"hash/fnv"
+	"time"
+)
+
+// PartitionResolver resolves the physical table name of the partition containing the key.
+type PartitionResolver interface {
+	// PartitionName returns name of the partition of the parent table, contains the key.
+	// Panic if the key is not supported.
+	PartitionName(parentTable string, key any) string
+}
+
+// RangePartitionResolver is a PartitionResolver of declarative range partitions by time,
+// provides the bounds to create the partitions.
+type RangePartitionResolver interface {
+	PartitionResolver
+
+	// PartitionBounds returns the bounds [from, to) of the partition containing the key.
+	PartitionBounds(key any) (from, to time.Time)
+}
+
+// MonthlyPartitionResolver resolves the partition by month of the time key, named as [parent]_YYYY_MM.
+type MonthlyPartitionResolver struct {
+	Location *time.Location // time zone the months are bounded by, default UTC
+}
+
+var _ RangePartitionResolver = MonthlyPartitionResolver{}
+
+func (r MonthlyPartitionResolver) PartitionName(parentTable string, key any) string {
+	from := r.monthStart(mustTimePartitionKey(key))
+	return fmt.Sprintf("%s_%04d_%02d", parentTable, from.Year(), from.Month())
+}
+
+func (r MonthlyPartitionResolver) PartitionBounds(key any) (from, to time.Time) {
+	from = r.monthStart(mustTimePartitionKey(key))
+	return from, from.AddDate(0, 1, 0)
+}
+
+// monthStart returns the start of the month contains the time, in the resolver's location.
+func (r MonthlyPartitionResolver) monthStart(t time.Time) time.Time {
+	loc := r.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+}
+
+// HashPartitionResolver resolves the partition by FNV-1a hash of the key (formatted via fmt), named as [parent]_p[N].
+//
+// The hash is computed by the application, so it is meant for application-managed partitions (plain tables),
+// it does not match the partition chosen by PostgreSQL for declarative hash partitions.
+type HashPartitionResolver struct {
+	Modulus uint32 // number of partitions
+}
+
+var _ PartitionResolver = HashPartitionResolver{}
+
+func (r HashPartitionResolver) PartitionName(parentTable string, key any) string {
+	if r.Modulus == 0 {
+		panic("modulus must be positive")
+	}
+	h := fnv.New32a()
+	_, _ = fmt.Fprint(h, key)
+	return fmt.Sprintf("%s_p%d", parentTable, h.Sum32()%r.Modulus)
+}
+
+func mustTimePartitionKey(key any) time.Time {
+	switch k := key.(type) {
+	case time.Time:
+		return k
+	case *time.Time:
+		if k != nil {
+			return *k
+		}
+	}
+	panic(fmt.Sprintf("partition key must be time.Time, got %T", key))
+}
+
+// UseTablePartitioned returns table to use, which is the partition containing the key,
+// resolved by the PartitionResolver of the table metadata. The alias is kept as the parent table name.
+func UseTablePartitioned[T any](key any) *TableToUse[T] {
+	t := UseTable[T]()
+	if t.metadata.partitionResolver == nil {
+		panic(fmt.Sprintf("no partition resolver for table %s", t.metadata.name))
+	}
+	return t.As(t.metadata.partitionResolver.PartitionName(t.metadata.name, key))
+}
@@ -0,0 +1,67 @@
+package sqlb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// partitionBoundLayout is the layout of the partition bounds, rendered as TIMESTAMPTZ literal.
+const partitionBoundLayout = "2006-01-02 15:04:05Z07:00"
+
+// CreatePartitionOf builds the DDL to create the partition of table T, which contains the key:
+//
+//	CREATE TABLE IF NOT EXISTS [partition] PARTITION OF [parent] FOR VALUES FROM ('[from]') TO ('[to]')
+//
+// The partition resolver of the table must be a RangePartitionResolver.
+func CreatePartitionOf[T any](key any) string {
+	metadata := GetTableMetadata[T]()
+	resolver := mustRangePartitionResolver(metadata)
+
+	from, to := resolver.PartitionBounds(key)
+	return fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s PARTITION OF %s FOR VALUES FROM (%s) TO (%s)",
+		resolver.PartitionName(metadata.name, key),
+		metadata.name,
+		quoteLiteral(from.Format(partitionBoundLayout)),
+		quoteLiteral(to.Format(partitionBoundLayout)),
+	)
+}
+
+// CreateUpcomingPartitions builds the DDL to create the partition of table T containing the given time,
+// and the following partitions, total count partitions.
+func CreateUpcomingPartitions[T any](since time.Time, count int) []string {
+	if count < 1 {
+		panic("count must be positive")
+	}
+	resolver := mustRangePartitionResolver(GetTableMetadata[T]())
+
+	statements := make([]string, count)
+	key := since
+	for i := range statements {
+		statements[i] = CreatePartitionOf[T](key)
+		_, key = resolver.PartitionBounds(key)
+	}
+	return statements
+}
+
+// EnsureUpcomingPartitions creates the partition of table T containing the given time and the following partitions
+// if not exists, total count partitions. Intended to be run periodically as a maintenance job.
+func EnsureUpcomingPartitions[T any](ctx context.Context, exec Executor, since time.Time, count int) error {
+	for _, stmt := range CreateUpcomingPartitions[T](since, count) {
+		if _, err := exec.ExecContext(ctx, stmt); err != nil {
+			return errors.Wrapf(err, "failed to create partition: %s", stmt)
+		}
+	}
+	return nil
+}
+
+func mustRangePartitionResolver[T any](metadata TableMetadata[T]) RangePartitionResolver {
+	resolver, ok := metadata.partitionResolver.(RangePartitionResolver)
+	if !ok {
+		panic(fmt.Sprintf("table %s does not have range partition resolver", metadata.name))
+	}
+	return resolver
+}
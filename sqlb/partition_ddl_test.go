@@ -0,0 +1,61 @@
+package sqlb
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingExecutor records the executed statements, for the sake of the test.
+type recordingExecutor struct {
+	statements []string
+	err        error
+}
+
+func (e *recordingExecutor) QueryContext(context.Context, string, ...any) (SqlRows, error) {
+	return nil, errors.New("not supported")
+}
+
+func (e *recordingExecutor) ExecContext(_ context.Context, query string, _ ...any) (sql.Result, error) {
+	e.statements = append(e.statements, query)
+	return nil, e.err
+}
+
+func TestCreatePartitionOf(t *testing.T) {
+	require.Equal(t,
+		`CREATE TABLE IF NOT EXISTS events_2024_12 PARTITION OF events FOR VALUES FROM ('2024-12-01 00:00:00Z') TO ('2025-01-01 00:00:00Z')`,
+		CreatePartitionOf[testEventRow](time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)),
+	)
+
+	require.Panics(t, func() {
+		CreatePartitionOf[testStruct1](time.Now())
+	}, "table is not partitioned")
+}
+
+func TestCreateUpcomingPartitions(t *testing.T) {
+	require.Equal(t, []string{
+		`CREATE TABLE IF NOT EXISTS events_2024_11 PARTITION OF events FOR VALUES FROM ('2024-11-01 00:00:00Z') TO ('2024-12-01 00:00:00Z')`,
+		`CREATE TABLE IF NOT EXISTS events_2024_12 PARTITION OF events FOR VALUES FROM ('2024-12-01 00:00:00Z') TO ('2025-01-01 00:00:00Z')`,
+		`CREATE TABLE IF NOT EXISTS events_2025_01 PARTITION OF events FOR VALUES FROM ('2025-01-01 00:00:00Z') TO ('2025-02-01 00:00:00Z')`,
+	}, CreateUpcomingPartitions[testEventRow](time.Date(2024, 11, 30, 0, 0, 0, 0, time.UTC), 3))
+
+	require.Panics(t, func() {
+		CreateUpcomingPartitions[testEventRow](time.Now(), 0)
+	})
+}
+
+func TestEnsureUpcomingPartitions(t *testing.T) {
+	exec := &recordingExecutor{}
+	err := EnsureUpcomingPartitions[testEventRow](context.Background(), exec, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), 2)
+	require.NoError(t, err)
+	require.Len(t, exec.statements, 2)
+
+	exec = &recordingExecutor{err: errors.New("permission denied")}
+	err = EnsureUpcomingPartitions[testEventRow](context.Background(), exec, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), 2)
+	require.ErrorContains(t, err, "permission denied")
+	require.Len(t, exec.statements, 1, "must stop at the first error")
+}
@@ -0,0 +1,86 @@
+package sqlb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testEventRow struct {
+	Id        int64
+	CreatedAt time.Time
+}
+
+var tableTestEvent = NewTableMetadata[testEventRow]("events").
+	AddColumns(
+		NewColumnMetadata[testEventRow]("id").
+			PrimaryKey().
+			InsertSpec(func(r testEventRow) any {
+				return r.Id
+			}).
+			SelectSpec(func(r *testEventRow) ResultColumnSelectSpec {
+				return ResultColumnSelectSpec{
+					ToQueryArg: func() any {
+						return &r.Id
+					},
+				}
+			}),
+		NewColumnMetadata[testEventRow]("created_at").
+			PrimaryKey().
+			InsertSpec(func(r testEventRow) any {
+				return r.CreatedAt
+			}).
+			SelectSpec(func(r *testEventRow) ResultColumnSelectSpec {
+				return ResultColumnSelectSpec{
+					ToQueryArg: func() any {
+						return &r.CreatedAt
+					},
+				}
+			}),
+	).Build(TableMetadataBuildOption{
+	ExpectedPkColumns: []string{"id", "created_at"},
+	PartitionResolver: MonthlyPartitionResolver{},
+})
+
+func TestMonthlyPartitionResolver(t *testing.T) {
+	createdAt := time.Date(2024, 2, 29, 23, 0, 0, 0, time.UTC)
+	require.Equal(t, "events_2024_02", MonthlyPartitionResolver{}.PartitionName("events", createdAt))
+	require.Equal(t, "events_2024_02", MonthlyPartitionResolver{}.PartitionName("events", &createdAt))
+
+	tz := time.FixedZone("UTC+7", 7*60*60)
+	require.Equal(t, "events_2024_03", MonthlyPartitionResolver{Location: tz}.PartitionName("events", createdAt))
+
+	require.Panics(t, func() {
+		MonthlyPartitionResolver{}.PartitionName("events", "2024-02")
+	})
+}
+
+func TestHashPartitionResolver(t *testing.T) {
+	resolver := HashPartitionResolver{Modulus: 8}
+	name := resolver.PartitionName("accounts", "tenant-1")
+	require.Regexp(t, `^accounts_p[0-7]$`, name)
+	require.Equal(t, name, resolver.PartitionName("accounts", "tenant-1"), "must be deterministic")
+
+	require.Panics(t, func() {
+		HashPartitionResolver{}.PartitionName("accounts", 1)
+	})
+}
+
+func TestUseTablePartitioned(t *testing.T) {
+	createdAt := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	events := UseTablePartitioned[testEventRow](createdAt).Seal()
+	sql, _ := Select(events.Col("id")).From(events).Build()
+	require.Equal(t, `SELECT events.id
+FROM events_2024_01 AS events
+`, sql)
+
+	sql, _ = InsertInto(events).Values(testEventRow{Id: 1, CreatedAt: createdAt}).Build()
+	require.Equal(t, `INSERT INTO events_2024_01 (id, created_at)
+VALUES ($1,$2)`, sql)
+
+	require.Panics(t, func() {
+		UseTablePartitioned[testStruct1](createdAt)
+	}, "table is not partitioned")
+}
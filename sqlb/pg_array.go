@@ -0,0 +1,213 @@
+package sqlb
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// StringArray is the Go value of Postgres TEXT[] column, can be used as argument and scan destination.
+type StringArray []string
+
+// Int64Array is the Go value of Postgres BIGINT[] (or INT[]) column, can be used as argument and scan destination.
+type Int64Array []int64
+
+var _ driver.Valuer = StringArray(nil)
+var _ sql.Scanner = (*StringArray)(nil)
+var _ driver.Valuer = Int64Array(nil)
+var _ sql.Scanner = (*Int64Array)(nil)
+
+var pgArrayElementEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// Value implements driver.Valuer, nil array is NULL.
+func (a StringArray) Value() (driver.Value, error) {
+	if a == nil {
+		return nil, nil
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString("{")
+	for i, element := range a {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		sb.WriteString(`"`)
+		sb.WriteString(pgArrayElementEscaper.Replace(element))
+		sb.WriteString(`"`)
+	}
+	sb.WriteString("}")
+	return sb.String(), nil
+}
+
+// Scan implements sql.Scanner, NULL is scanned as nil array.
+func (a *StringArray) Scan(src any) error {
+	elements, isNull, err := scanPgArray(src)
+	if err != nil {
+		return err
+	}
+	if isNull {
+		*a = nil
+		return nil
+	}
+
+	result := make(StringArray, len(elements))
+	for i, element := range elements {
+		if element == nil {
+			return errors.Errorf("NULL element at index %d is not supported", i)
+		}
+		result[i] = *element
+	}
+	*a = result
+	return nil
+}
+
+// Value implements driver.Valuer, nil array is NULL.
+func (a Int64Array) Value() (driver.Value, error) {
+	if a == nil {
+		return nil, nil
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString("{")
+	for i, element := range a {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		sb.WriteString(strconv.FormatInt(element, 10))
+	}
+	sb.WriteString("}")
+	return sb.String(), nil
+}
+
+// Scan implements sql.Scanner, NULL is scanned as nil array.
+func (a *Int64Array) Scan(src any) error {
+	elements, isNull, err := scanPgArray(src)
+	if err != nil {
+		return err
+	}
+	if isNull {
+		*a = nil
+		return nil
+	}
+
+	result := make(Int64Array, len(elements))
+	for i, element := range elements {
+		if element == nil {
+			return errors.Errorf("NULL element at index %d is not supported", i)
+		}
+		result[i], err = strconv.ParseInt(*element, 10, 64)
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse element at index %d", i)
+		}
+	}
+	*a = result
+	return nil
+}
+
+// arrayArg converts the slice into the corresponding array type, so it can be bound as argument.
+func arrayArg(values any) any {
+	switch v := values.(type) {
+	case []string:
+		return StringArray(v)
+	case []int64:
+		return Int64Array(v)
+	case []int:
+		result := make(Int64Array, len(v))
+		for i, e := range v {
+			result[i] = int64(e)
+		}
+		return result
+	case []int32:
+		result := make(Int64Array, len(v))
+		for i, e := range v {
+			result[i] = int64(e)
+		}
+		return result
+	default:
+		return values
+	}
+}
+
+func scanPgArray(src any) (elements []*string, isNull bool, err error) {
+	switch s := src.(type) {
+	case nil:
+		return nil, true, nil
+	case []byte:
+		elements, err = parsePgArray(string(s))
+	case string:
+		elements, err = parsePgArray(s)
+	default:
+		return nil, false, errors.Errorf("unsupported source type %T for array", src)
+	}
+	return elements, false, err
+}
+
+// parsePgArray parses one-dimensional Postgres array literal, e.g. {a,"b c",NULL}. Nil element represents NULL.
+func parsePgArray(s string) ([]*string, error) {
+	if len(s) < 2 || s[0] != '{' || s[len(s)-1] != '}' {
+		return nil, errors.Errorf("invalid array literal: %s", s)
+	}
+
+	body := s[1 : len(s)-1]
+	if body == "" {
+		return []*string{}, nil
+	}
+
+	var elements []*string
+	for i := 0; i <= len(body); {
+		if i < len(body) && body[i] == '{' {
+			return nil, errors.New("multi-dimensional array is not supported")
+		}
+
+		if i < len(body) && body[i] == '"' { // quoted element
+			sb := strings.Builder{}
+			i++
+			closed := false
+			for i < len(body) {
+				c := body[i]
+				if c == '\\' && i+1 < len(body) {
+					sb.WriteByte(body[i+1])
+					i += 2
+					continue
+				}
+				if c == '"' {
+					closed = true
+					i++
+					break
+				}
+				sb.WriteByte(c)
+				i++
+			}
+			if !closed {
+				return nil, errors.Errorf("unterminated quoted element in array literal: %s", s)
+			}
+			element := sb.String()
+			elements = append(elements, &element)
+		} else { // unquoted element
+			end := strings.IndexByte(body[i:], ',')
+			if end < 0 {
+				end = len(body) - i
+			}
+			element := strings.TrimSpace(body[i : i+end])
+			if strings.EqualFold(element, "NULL") {
+				elements = append(elements, nil)
+			} else {
+				elements = append(elements, &element)
+			}
+			i += end
+		}
+
+		if i >= len(body) {
+			break
+		}
+		if body[i] != ',' {
+			return nil, errors.Errorf("unexpected character %q in array literal: %s", body[i], s)
+		}
+		i++
+	}
+
+	return elements, nil
+}
@@ -0,0 +1,71 @@
+package sqlb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStringArray(t *testing.T) {
+	t.Run("value", func(t *testing.T) {
+		v, err := StringArray{"a", `b "c"`, `d\e`, ""}.Value()
+		require.NoError(t, err)
+		require.Equal(t, `{"a","b \"c\"","d\\e",""}`, v)
+
+		v, err = StringArray(nil).Value()
+		require.NoError(t, err)
+		require.Nil(t, v)
+
+		v, err = StringArray{}.Value()
+		require.NoError(t, err)
+		require.Equal(t, `{}`, v)
+	})
+
+	t.Run("scan", func(t *testing.T) {
+		var a StringArray
+		require.NoError(t, a.Scan([]byte(`{a,"b \"c\"","d\\e","",x y}`)))
+		require.Equal(t, StringArray{"a", `b "c"`, `d\e`, "", "x y"}, a)
+
+		require.NoError(t, a.Scan(`{}`))
+		require.Equal(t, StringArray{}, a)
+
+		require.NoError(t, a.Scan(nil))
+		require.Nil(t, a)
+
+		require.Error(t, a.Scan(`{a,NULL}`))
+		require.Error(t, a.Scan(`{{a},{b}}`))
+		require.Error(t, a.Scan(`{"a}`))
+		require.Error(t, a.Scan(`a,b`))
+		require.Error(t, a.Scan(1))
+	})
+}
+
+func TestInt64Array(t *testing.T) {
+	v, err := Int64Array{1, -2, 3}.Value()
+	require.NoError(t, err)
+	require.Equal(t, `{1,-2,3}`, v)
+
+	var a Int64Array
+	require.NoError(t, a.Scan(`{1,-2,3}`))
+	require.Equal(t, Int64Array{1, -2, 3}, a)
+
+	require.Error(t, a.Scan(`{1,a}`))
+}
+
+func TestColumnMetadataBuilder_arrays(t *testing.T) {
+	type row struct {
+		Tags []string
+		Ids  []int64
+	}
+
+	tags := NewColumnMetadata[row]("tags").StringArray(func(r *row) *[]string { return &r.Tags }).column
+	ids := NewColumnMetadata[row]("ids").Int64Array(func(r *row) *[]int64 { return &r.Ids }).column
+
+	require.Equal(t, StringArray{"a"}, tags.insertSpec(row{Tags: []string{"a"}}))
+	require.Equal(t, Int64Array{1}, ids.insertSpec(row{Ids: []int64{1}}))
+
+	var r row
+	require.NoError(t, tags.selectSpec(&r).ToQueryArg().(*StringArray).Scan(`{x,y}`))
+	require.NoError(t, ids.selectSpec(&r).ToQueryArg().(*Int64Array).Scan(`{7}`))
+	require.Equal(t, row{Tags: []string{"x", "y"}, Ids: []int64{7}}, r)
+}
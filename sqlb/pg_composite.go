@@ -0,0 +1,206 @@
+package sqlb
+
+import (
+	"database/sql"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// CompositeSelectSpec returns the select spec for Postgres composite column or ROW expression,
+// the attributes are mapped in order to the exported fields of the nested struct C.
+// Supported field types are string, bool, numbers, time.Time, sql.Scanner, nested struct (nested composite)
+// and pointers of them. NULL composite is scanned as zero value, NULL attribute as zero value or nil pointer.
+func CompositeSelectSpec[T any, C any](field func(*T) *C) ColumnSelectSpec[T] {
+	return func(v *T) ResultColumnSelectSpec {
+		var raw sql.NullString
+		return ResultColumnSelectSpec{
+			ToQueryArg: func() any {
+				return &raw
+			},
+			OptionalTransform: func() error {
+				var value C
+				if raw.Valid {
+					if err := ScanComposite(raw.String, &value); err != nil {
+						return err
+					}
+				}
+				*field(v) = value
+				return nil
+			},
+		}
+	}
+}
+
+// ScanComposite decodes the text representation of Postgres composite value, e.g. (1,"a b",), into the struct
+// pointed by dest. Can be used for the value of ROW expression selected via SelectExpr.
+func ScanComposite(src any, dest any) error {
+	dv := reflect.ValueOf(dest)
+	if dv.Kind() != reflect.Ptr || dv.IsNil() || dv.Elem().Kind() != reflect.Struct {
+		return errors.Errorf("destination must be a non-nil pointer to struct, got %T", dest)
+	}
+
+	var s string
+	switch v := src.(type) {
+	case nil:
+		dv.Elem().Set(reflect.Zero(dv.Elem().Type()))
+		return nil
+	case []byte:
+		s = string(v)
+	case string:
+		s = v
+	default:
+		return errors.Errorf("unsupported source type %T for composite", src)
+	}
+
+	return assignComposite(dv.Elem(), s)
+}
+
+func assignComposite(dv reflect.Value, s string) error {
+	attributes, err := parsePgComposite(s)
+	if err != nil {
+		return err
+	}
+
+	var fields []int
+	for i := 0; i < dv.NumField(); i++ {
+		if dv.Type().Field(i).IsExported() {
+			fields = append(fields, i)
+		}
+	}
+	if len(fields) != len(attributes) {
+		return errors.Errorf("composite value has %d attributes but %s has %d exported fields", len(attributes), dv.Type(), len(fields))
+	}
+
+	for i, attribute := range attributes {
+		if err := assignCompositeAttribute(dv.Field(fields[i]), attribute); err != nil {
+			return errors.Wrapf(err, "failed to assign attribute %d to field %s", i, dv.Type().Field(fields[i]).Name)
+		}
+	}
+	return nil
+}
+
+func assignCompositeAttribute(dv reflect.Value, attribute *string) error {
+	if attribute == nil {
+		dv.Set(reflect.Zero(dv.Type()))
+		return nil
+	}
+
+	if scanner, ok := dv.Addr().Interface().(sql.Scanner); ok {
+		return scanner.Scan(*attribute)
+	}
+
+	if dv.Kind() == reflect.Ptr {
+		ptr := reflect.New(dv.Type().Elem())
+		if err := assignCompositeAttribute(ptr.Elem(), attribute); err != nil {
+			return err
+		}
+		dv.Set(ptr)
+		return nil
+	}
+
+	s := *attribute
+	switch {
+	case dv.Type() == timeType:
+		t, err := parseRangeBound(s)
+		if err != nil {
+			return err
+		}
+		dv.Set(reflect.ValueOf(t))
+		return nil
+	case dv.Kind() == reflect.Struct:
+		return assignComposite(dv, s)
+	case dv.Kind() == reflect.String:
+		dv.SetString(s)
+		return nil
+	case dv.Kind() == reflect.Bool:
+		switch s {
+		case "t":
+			dv.SetBool(true)
+		case "f":
+			dv.SetBool(false)
+		default:
+			v, err := strconv.ParseBool(s)
+			if err != nil {
+				return errors.Wrapf(err, "failed to convert %q to bool", s)
+			}
+			dv.SetBool(v)
+		}
+		return nil
+	case isNumberKind(dv.Kind()):
+		return setNumberFromString(dv, s)
+	}
+
+	return errors.Errorf("unsupported field type %s for composite attribute", dv.Type())
+}
+
+// parsePgComposite parses Postgres composite literal, e.g. (1,"a b",,""). Nil attribute represents NULL,
+// which is the empty unquoted attribute, while "" is the empty string.
+func parsePgComposite(s string) ([]*string, error) {
+	if len(s) < 2 || s[0] != '(' || s[len(s)-1] != ')' {
+		return nil, errors.Errorf("invalid composite literal: %s", s)
+	}
+
+	body := s[1 : len(s)-1]
+	var attributes []*string
+	for i := 0; ; {
+		sb := strings.Builder{}
+		quoted := false
+		for i < len(body) && body[i] != ',' {
+			c := body[i]
+			switch {
+			case c == '"':
+				quoted = true
+				i++
+				closed := false
+				for i < len(body) {
+					c = body[i]
+					if c == '\\' && i+1 < len(body) {
+						sb.WriteByte(body[i+1])
+						i += 2
+						continue
+					}
+					if c == '"' {
+						if i+1 < len(body) && body[i+1] == '"' { // doubled quote
+							sb.WriteByte('"')
+							i += 2
+							continue
+						}
+						closed = true
+						i++
+						break
+					}
+					sb.WriteByte(c)
+					i++
+				}
+				if !closed {
+					return nil, errors.Errorf("unterminated quoted attribute in composite literal: %s", s)
+				}
+			case c == '\\' && i+1 < len(body):
+				sb.WriteByte(body[i+1])
+				i += 2
+			default:
+				sb.WriteByte(c)
+				i++
+			}
+		}
+
+		if !quoted && sb.Len() == 0 {
+			attributes = append(attributes, nil)
+		} else {
+			attribute := sb.String()
+			attributes = append(attributes, &attribute)
+		}
+
+		if i >= len(body) {
+			break
+		}
+		i++ // skip the comma
+	}
+	return attributes, nil
+}
@@ -0,0 +1,73 @@
+package sqlb
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePgComposite(t *testing.T) {
+	str := func(s string) *string { return &s }
+
+	attributes, err := parsePgComposite(`(1,"a b",,"",x\,y,"say ""hi""","c\\d")`)
+	require.NoError(t, err)
+	require.Equal(t, []*string{str("1"), str("a b"), nil, str(""), str("x,y"), str(`say "hi"`), str(`c\d`)}, attributes)
+
+	attributes, err = parsePgComposite(`(,)`)
+	require.NoError(t, err)
+	require.Equal(t, []*string{nil, nil}, attributes)
+
+	_, err = parsePgComposite(`(1,"a)`)
+	require.Error(t, err)
+	_, err = parsePgComposite(`1,2`)
+	require.Error(t, err)
+}
+
+func TestCompositeSelectSpec(t *testing.T) {
+	type money struct {
+		Amount   float64
+		Currency string
+	}
+	type address struct {
+		Street    string
+		Zip       *int
+		Verified  bool
+		CheckedAt time.Time
+		Price     money
+		note      string
+	}
+	type row struct {
+		Address address
+	}
+
+	selectSpec := CompositeSelectSpec[row](func(r *row) *address { return &r.Address })
+
+	var r row
+	rs := selectSpec(&r)
+	require.NoError(t, rs.ToQueryArg().(sql.Scanner).Scan([]byte(`("1 Main St",,t,"2024-01-02 03:04:05+00","(9.5,USD)")`)))
+	require.NoError(t, rs.OptionalTransform())
+	require.True(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC).Equal(r.Address.CheckedAt))
+	r.Address.CheckedAt = time.Time{}
+	require.Equal(t, address{
+		Street:   "1 Main St",
+		Verified: true,
+		Price:    money{Amount: 9.5, Currency: "USD"},
+	}, r.Address)
+
+	rs = selectSpec(&r)
+	require.NoError(t, rs.ToQueryArg().(sql.Scanner).Scan(nil))
+	require.NoError(t, rs.OptionalTransform())
+	require.Equal(t, address{}, r.Address)
+
+	rs = selectSpec(&r)
+	require.NoError(t, rs.ToQueryArg().(sql.Scanner).Scan(`(a,1)`))
+	require.ErrorContains(t, rs.OptionalTransform(), "2 attributes")
+
+	var m money
+	require.NoError(t, ScanComposite(`(12,EUR)`, &m))
+	require.Equal(t, money{Amount: 12, Currency: "EUR"}, m)
+	require.Error(t, ScanComposite(`(x,EUR)`, &m))
+	require.Error(t, ScanComposite(`(1,EUR)`, m))
+}
@@ -0,0 +1,147 @@
+package sqlb
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Hstore is the Go value of Postgres HSTORE column, can be used as argument and scan destination.
+type Hstore map[string]string
+
+var _ driver.Valuer = Hstore(nil)
+var _ sql.Scanner = (*Hstore)(nil)
+
+// Value implements driver.Valuer, nil map is NULL. Keys are sorted so the literal is deterministic.
+func (h Hstore) Value() (driver.Value, error) {
+	if h == nil {
+		return nil, nil
+	}
+
+	keys := make([]string, 0, len(h))
+	for key := range h {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	sb := strings.Builder{}
+	for i, key := range keys {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(`"`)
+		sb.WriteString(pgArrayElementEscaper.Replace(key))
+		sb.WriteString(`"=>"`)
+		sb.WriteString(pgArrayElementEscaper.Replace(h[key]))
+		sb.WriteString(`"`)
+	}
+	return sb.String(), nil
+}
+
+// Scan implements sql.Scanner, NULL is scanned as nil map.
+func (h *Hstore) Scan(src any) error {
+	var s string
+	switch v := src.(type) {
+	case nil:
+		*h = nil
+		return nil
+	case []byte:
+		s = string(v)
+	case string:
+		s = v
+	default:
+		return errors.Errorf("unsupported source type %T for hstore", src)
+	}
+
+	pairs, err := parsePgHstore(s)
+	if err != nil {
+		return err
+	}
+
+	result := make(Hstore, len(pairs))
+	for key, value := range pairs {
+		if value == nil {
+			return errors.Errorf("NULL value of key %q is not supported", key)
+		}
+		result[key] = *value
+	}
+	*h = result
+	return nil
+}
+
+// parsePgHstore parses Postgres hstore literal, e.g. "a"=>"1", "b"=>NULL. Nil value represents NULL.
+func parsePgHstore(s string) (map[string]*string, error) {
+	pairs := make(map[string]*string)
+
+	i := 0
+	skipSpaces := func() {
+		for i < len(s) && s[i] == ' ' {
+			i++
+		}
+	}
+	// readToken reads the quoted or unquoted token, returns whether it was quoted
+	readToken := func() (string, bool, error) {
+		sb := strings.Builder{}
+		if i < len(s) && s[i] == '"' {
+			i++
+			for i < len(s) {
+				c := s[i]
+				if c == '\\' && i+1 < len(s) {
+					sb.WriteByte(s[i+1])
+					i += 2
+					continue
+				}
+				if c == '"' {
+					i++
+					return sb.String(), true, nil
+				}
+				sb.WriteByte(c)
+				i++
+			}
+			return "", true, errors.Errorf("unterminated quoted token in hstore literal: %s", s)
+		}
+		for i < len(s) && s[i] != ',' && s[i] != ' ' && s[i] != '=' {
+			sb.WriteByte(s[i])
+			i++
+		}
+		if sb.Len() == 0 {
+			return "", false, errors.Errorf("invalid hstore literal: %s", s)
+		}
+		return sb.String(), false, nil
+	}
+
+	skipSpaces()
+	for i < len(s) {
+		key, _, err := readToken()
+		if err != nil {
+			return nil, err
+		}
+		skipSpaces()
+		if !strings.HasPrefix(s[i:], "=>") {
+			return nil, errors.Errorf("missing => after key %q in hstore literal: %s", key, s)
+		}
+		i += 2
+		skipSpaces()
+		value, quoted, err := readToken()
+		if err != nil {
+			return nil, err
+		}
+		if !quoted && strings.EqualFold(value, "NULL") {
+			pairs[key] = nil
+		} else {
+			pairs[key] = &value
+		}
+		skipSpaces()
+		if i < len(s) {
+			if s[i] != ',' {
+				return nil, errors.Errorf("invalid hstore literal: %s", s)
+			}
+			i++
+			skipSpaces()
+		}
+	}
+	return pairs, nil
+}
@@ -0,0 +1,69 @@
+package sqlb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHstore(t *testing.T) {
+	t.Run("value", func(t *testing.T) {
+		v, err := Hstore{"b": `x "y"`, "a": "1", `c\d`: ""}.Value()
+		require.NoError(t, err)
+		require.Equal(t, `"a"=>"1", "b"=>"x \"y\"", "c\\d"=>""`, v)
+
+		v, err = Hstore(nil).Value()
+		require.NoError(t, err)
+		require.Nil(t, v)
+
+		v, err = Hstore{}.Value()
+		require.NoError(t, err)
+		require.Equal(t, ``, v)
+	})
+
+	t.Run("scan", func(t *testing.T) {
+		var h Hstore
+		require.NoError(t, h.Scan([]byte(`"a"=>"1", "b"=>"x \"y\"", c=>d,"e f"=>"NULL"`)))
+		require.Equal(t, Hstore{"a": "1", "b": `x "y"`, "c": "d", "e f": "NULL"}, h)
+
+		require.NoError(t, h.Scan(``))
+		require.Equal(t, Hstore{}, h)
+
+		require.NoError(t, h.Scan(nil))
+		require.Nil(t, h)
+
+		require.Error(t, h.Scan(`"a"=>NULL`))
+		require.Error(t, h.Scan(`"a"=>"1`))
+		require.Error(t, h.Scan(`"a" "1"`))
+		require.Error(t, h.Scan(`"a"=>"1" "b"=>"2"`))
+		require.Error(t, h.Scan(1))
+	})
+}
+
+func TestColumnMetadataBuilder_maps(t *testing.T) {
+	type row struct {
+		Attributes map[string]string
+		Labels     map[string]string
+	}
+
+	hstore := NewColumnMetadata[row]("attributes").Hstore(func(r *row) *map[string]string { return &r.Attributes }).column
+	require.Equal(t, Hstore{"a": "1"}, hstore.insertSpec(row{Attributes: map[string]string{"a": "1"}}))
+
+	var r row
+	rs := hstore.selectSpec(&r)
+	require.NoError(t, rs.ToQueryArg().(*Hstore).Scan(`"k"=>"v"`))
+	require.Equal(t, map[string]string{"k": "v"}, r.Attributes)
+
+	jsonb := NewColumnMetadata[row]("labels").JsonbMap(func(r *row) *map[string]string { return &r.Labels }).column
+	require.Equal(t, jsonValue{value: map[string]string{"env": "dev"}}, jsonb.insertSpec(row{Labels: map[string]string{"env": "dev"}}))
+
+	rs = jsonb.selectSpec(&r)
+	*rs.ToQueryArg().(*[]byte) = []byte(`{"env":"prod"}`)
+	require.NoError(t, rs.OptionalTransform())
+	require.Equal(t, map[string]string{"env": "prod"}, r.Labels)
+
+	rs = jsonb.selectSpec(&r)
+	_ = rs.ToQueryArg()
+	require.NoError(t, rs.OptionalTransform())
+	require.Nil(t, r.Labels)
+}
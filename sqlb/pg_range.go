@@ -0,0 +1,150 @@
+package sqlb
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// TimeRange is the Go value of Postgres TSTZRANGE, TSRANGE or DATERANGE column,
+// can be used as argument and scan destination.
+//
+// Zero Lower/Upper means the bound is unbounded. NULL is scanned as zero value (unbounded on both sides).
+type TimeRange struct {
+	Lower          time.Time
+	Upper          time.Time
+	LowerInclusive bool
+	UpperInclusive bool
+	Empty          bool // Empty indicate the range contains no point, bounds are ignored
+}
+
+var _ driver.Valuer = TimeRange{}
+var _ sql.Scanner = (*TimeRange)(nil)
+
+// NewTimeRange returns the range [lower, upper), the canonical form of Postgres ranges.
+func NewTimeRange(lower, upper time.Time) TimeRange {
+	return TimeRange{
+		Lower:          lower,
+		Upper:          upper,
+		LowerInclusive: true,
+	}
+}
+
+// Contains reports whether the time is within the range.
+func (r TimeRange) Contains(t time.Time) bool {
+	if r.Empty {
+		return false
+	}
+	if !r.Lower.IsZero() {
+		if t.Before(r.Lower) || (!r.LowerInclusive && t.Equal(r.Lower)) {
+			return false
+		}
+	}
+	if !r.Upper.IsZero() {
+		if t.After(r.Upper) || (!r.UpperInclusive && t.Equal(r.Upper)) {
+			return false
+		}
+	}
+	return true
+}
+
+// Value implements driver.Valuer.
+func (r TimeRange) Value() (driver.Value, error) {
+	if r.Empty {
+		return "empty", nil
+	}
+
+	sb := strings.Builder{}
+	if r.LowerInclusive && !r.Lower.IsZero() {
+		sb.WriteString("[")
+	} else {
+		sb.WriteString("(")
+	}
+	if !r.Lower.IsZero() {
+		sb.WriteString(`"` + r.Lower.Format(time.RFC3339Nano) + `"`)
+	}
+	sb.WriteString(",")
+	if !r.Upper.IsZero() {
+		sb.WriteString(`"` + r.Upper.Format(time.RFC3339Nano) + `"`)
+	}
+	if r.UpperInclusive && !r.Upper.IsZero() {
+		sb.WriteString("]")
+	} else {
+		sb.WriteString(")")
+	}
+	return sb.String(), nil
+}
+
+// Scan implements sql.Scanner.
+func (r *TimeRange) Scan(src any) error {
+	var s string
+	switch v := src.(type) {
+	case nil:
+		*r = TimeRange{}
+		return nil
+	case []byte:
+		s = string(v)
+	case string:
+		s = v
+	default:
+		return errors.Errorf("unsupported source type %T for range", src)
+	}
+
+	s = strings.TrimSpace(s)
+	if strings.EqualFold(s, "empty") {
+		*r = TimeRange{Empty: true}
+		return nil
+	}
+	if len(s) < 3 || (s[0] != '[' && s[0] != '(') || (s[len(s)-1] != ']' && s[len(s)-1] != ')') {
+		return errors.Errorf("invalid range literal: %s", s)
+	}
+
+	bounds := strings.SplitN(s[1:len(s)-1], ",", 2)
+	if len(bounds) != 2 {
+		return errors.Errorf("invalid range literal: %s", s)
+	}
+
+	lower, err := parseRangeBound(bounds[0])
+	if err != nil {
+		return errors.Wrap(err, "failed to parse lower bound")
+	}
+	upper, err := parseRangeBound(bounds[1])
+	if err != nil {
+		return errors.Wrap(err, "failed to parse upper bound")
+	}
+
+	*r = TimeRange{
+		Lower:          lower,
+		Upper:          upper,
+		LowerInclusive: s[0] == '[',
+		UpperInclusive: s[len(s)-1] == ']',
+	}
+	return nil
+}
+
+var rangeBoundLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999-07",
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999-07:00:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+// parseRangeBound parses the bound of the range literal, empty or infinite bound is returned as zero time.
+func parseRangeBound(bound string) (time.Time, error) {
+	bound = strings.Trim(strings.TrimSpace(bound), `"`)
+	if bound == "" || bound == "infinity" || bound == "-infinity" {
+		return time.Time{}, nil
+	}
+
+	for _, layout := range rangeBoundLayouts {
+		if t, err := time.Parse(layout, bound); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.Errorf("unsupported time format: %s", bound)
+}
@@ -0,0 +1,63 @@
+package sqlb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeRange(t *testing.T) {
+	jan := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	feb := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("value", func(t *testing.T) {
+		v, err := NewTimeRange(jan, feb).Value()
+		require.NoError(t, err)
+		require.Equal(t, `["2024-01-01T00:00:00Z","2024-02-01T00:00:00Z")`, v)
+
+		v, err = TimeRange{Lower: jan, LowerInclusive: true}.Value()
+		require.NoError(t, err)
+		require.Equal(t, `["2024-01-01T00:00:00Z",)`, v)
+
+		v, err = TimeRange{Empty: true}.Value()
+		require.NoError(t, err)
+		require.Equal(t, `empty`, v)
+	})
+
+	t.Run("scan", func(t *testing.T) {
+		var r TimeRange
+		require.NoError(t, r.Scan([]byte(`["2024-01-01 00:00:00+00","2024-02-01 00:00:00+00")`)))
+		require.True(t, r.Lower.Equal(jan))
+		require.True(t, r.Upper.Equal(feb))
+		require.True(t, r.LowerInclusive)
+		require.False(t, r.UpperInclusive)
+
+		require.NoError(t, r.Scan(`[2024-01-01,2024-02-01)`))
+		require.Equal(t, NewTimeRange(jan, feb), r)
+
+		require.NoError(t, r.Scan(`(,"2024-02-01 07:00:00+07"]`))
+		require.True(t, r.Lower.IsZero())
+		require.True(t, r.Upper.Equal(feb))
+		require.True(t, r.UpperInclusive)
+
+		require.NoError(t, r.Scan(`empty`))
+		require.True(t, r.Empty)
+
+		require.NoError(t, r.Scan(nil))
+		require.Equal(t, TimeRange{}, r)
+
+		require.Error(t, r.Scan(`[a,b)`))
+		require.Error(t, r.Scan(`2024-01-01`))
+	})
+
+	t.Run("contains", func(t *testing.T) {
+		r := NewTimeRange(jan, feb)
+		require.True(t, r.Contains(jan))
+		require.True(t, r.Contains(jan.Add(time.Hour)))
+		require.False(t, r.Contains(feb))
+		require.False(t, r.Contains(jan.Add(-time.Hour)))
+		require.True(t, TimeRange{}.Contains(feb))
+		require.False(t, TimeRange{Empty: true}.Contains(feb))
+	})
+}
@@ -0,0 +1,42 @@
+package sqlb
+
+// PostGIS helpers, the spatial arguments are expression tokens: columns, expressions (e.g. MakePoint) or raw SQL strings.
+
+// sridWgs84 is the spatial reference id of WGS 84 (longitude/latitude), used by GPS.
+const sridWgs84 = 4326
+
+// MakePoint generates 'ST_SetSRID(ST_MakePoint($1, $2), 4326)', the WGS 84 point of the coordinates, bound as arguments.
+func MakePoint(longitude, latitude float64) Expr {
+	return concatExpr("ST_SetSRID(ST_MakePoint(", boundArg{value: longitude}, ", ", boundArg{value: latitude}, "), ", sridWgs84, ")")
+}
+
+// AsGeography generates '[expr]::geography', measures in meters on the spheroid.
+func AsGeography(expr any) Expr {
+	return concatExpr(expr, "::geography")
+}
+
+// AsGeometry generates '[expr]::geometry'.
+func AsGeometry(expr any) Expr {
+	return concatExpr(expr, "::geometry")
+}
+
+// StDWithin generates 'ST_DWithin([a], [b], $n)', checks if the geometries are within the distance,
+// which is in meters for geography and in SRID units for geometry.
+func StDWithin(a, b any, distance float64) Expr {
+	return functionExpr("ST_DWithin", a, b, boundArg{value: distance})
+}
+
+// StContains generates 'ST_Contains([a], [b])', checks if the geometry b lies inside the geometry a.
+func StContains(a, b any) Expr {
+	return functionExpr("ST_Contains", a, b)
+}
+
+// StIntersects generates 'ST_Intersects([a], [b])', checks if the geometries share any point.
+func StIntersects(a, b any) Expr {
+	return functionExpr("ST_Intersects", a, b)
+}
+
+// StDistance generates 'ST_Distance([a], [b])', the distance between the geometries.
+func StDistance(a, b any) Expr {
+	return functionExpr("ST_Distance", a, b)
+}
@@ -0,0 +1,303 @@
+package sqlb
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Eq generates '[left] = $n', the right side is bound as an argument unless it is a column or an expression.
+func Eq(left any, right any) Expr {
+	return compare(left, "=", right)
+}
+
+// NotEq generates '[left] <> $n'.
+func NotEq(left any, right any) Expr {
+	return compare(left, "<>", right)
+}
+
+// Lt generates '[left] < $n'.
+func Lt(left any, right any) Expr {
+	return compare(left, "<", right)
+}
+
+// Lte generates '[left] <= $n'.
+func Lte(left any, right any) Expr {
+	return compare(left, "<=", right)
+}
+
+// Gt generates '[left] > $n'.
+func Gt(left any, right any) Expr {
+	return compare(left, ">", right)
+}
+
+// Gte generates '[left] >= $n'.
+func Gte(left any, right any) Expr {
+	return compare(left, ">=", right)
+}
+
+// EqFold generates 'LOWER([left]) = LOWER($n)', case-insensitive equality.
+// When the left side is a column declared as CITEXT in metadata, it generates '[left] = $n' instead.
+func EqFold(left any, right any) Expr {
+	if column, ok := left.(GenericColumnToUse); ok && column.isCitext {
+		return compare(left, "=", right)
+	}
+	return concatExpr(functionExpr("LOWER", left), " = ", functionExpr("LOWER", valueToken(right)))
+}
+
+func compare(left any, operator string, right any) Expr {
+	return concatExpr(left, " "+operator+" ", valueToken(right))
+}
+
+// BetweenSymmetric generates '[expr] BETWEEN SYMMETRIC $n AND $m', the bounds are swapped when needed. Postgres only.
+func BetweenSymmetric(expr any, a any, b any) Expr {
+	return concatExpr(expr, " BETWEEN SYMMETRIC ", valueToken(a), " AND ", valueToken(b))
+}
+
+// WithinTimeRange generates '([expr] >= $n AND [expr] < $m)', the half-open time range [from, to).
+// Zero time means the bound is open: only '[expr] >= $n' or '[expr] < $m' is generated,
+// or 'TRUE' when both bounds are open.
+func WithinTimeRange(expr any, from, to time.Time) Expr {
+	switch {
+	case from.IsZero() && to.IsZero():
+		return concatExpr(true)
+	case to.IsZero():
+		return Gte(expr, from)
+	case from.IsZero():
+		return Lt(expr, to)
+	default:
+		return concatExpr("(", Gte(expr, from), " AND ", Lt(expr, to), ")")
+	}
+}
+
+// In generates '[left] IN ($1, $2,...)'. When no value provided, it generates 'FALSE'.
+func In(left any, values ...any) Expr {
+	if len(values) == 0 {
+		return concatExpr(false)
+	}
+
+	tokens := []any{left, " IN ("}
+	for i, value := range values {
+		if i > 0 {
+			tokens = append(tokens, ", ")
+		}
+		tokens = append(tokens, valueToken(value))
+	}
+	tokens = append(tokens, ")")
+	return concatExpr(tokens...)
+}
+
+// IsNull generates '[expr] IS NULL'.
+func IsNull(expr any) Expr {
+	return concatExpr(expr, " IS NULL")
+}
+
+// IsNotNull generates '[expr] IS NOT NULL'.
+func IsNotNull(expr any) Expr {
+	return concatExpr(expr, " IS NOT NULL")
+}
+
+// Like generates '[expr] LIKE $n', the pattern is bound as an argument.
+//
+// Use EscapeLikePattern to match user input literally, e.g. Like(col, "%"+EscapeLikePattern(input)+"%").
+func Like(expr any, pattern string) Expr {
+	return concatExpr(expr, " LIKE ", boundArg{value: pattern})
+}
+
+// ILike generates '[expr] ILIKE $n', case-insensitive version of Like.
+func ILike(expr any, pattern string) Expr {
+	return concatExpr(expr, " ILIKE ", boundArg{value: pattern})
+}
+
+// RegexMatch generates '[expr] ~ $n', the POSIX regular expression pattern is bound as an argument.
+//
+// The pattern is validated by the database when executing, Go regexp (RE2) rejects valid POSIX patterns, e.g. back-references.
+// Use TryRegexMatch to validate the patterns provided by the users beforehand.
+func RegexMatch(expr any, pattern string) Expr {
+	return concatExpr(expr, " ~ ", boundArg{value: pattern})
+}
+
+// RegexIMatch generates '[expr] ~* $n', case-insensitive version of RegexMatch.
+func RegexIMatch(expr any, pattern string) Expr {
+	return concatExpr(expr, " ~* ", boundArg{value: pattern})
+}
+
+// TryRegexMatch is RegexMatch validating the pattern against the POSIX regular expressions supported by Postgres,
+// for the patterns provided by the users. Returns ErrInvalidRegex if the pattern is invalid or uses Perl-only syntax.
+func TryRegexMatch(expr any, pattern string) (Expr, error) {
+	if err := validateRegex(pattern); err != nil {
+		return Expr{}, err
+	}
+	return RegexMatch(expr, pattern), nil
+}
+
+// TryRegexIMatch is RegexIMatch validating the pattern, see TryRegexMatch.
+func TryRegexIMatch(expr any, pattern string) (Expr, error) {
+	if err := validateRegex(pattern); err != nil {
+		return Expr{}, err
+	}
+	return RegexIMatch(expr, pattern), nil
+}
+
+// TrigramMatch generates '[expr] % $n', checks if the similarity is greater than pg_trgm.similarity_threshold,
+// can use the trigram index. Requires pg_trgm extension.
+func TrigramMatch(expr any, text string) Expr {
+	return concatExpr(expr, " % ", boundArg{value: text})
+}
+
+// Similar generates 'similarity([expr], $n) > $m', checks if the trigram similarity is greater than the threshold (0..1).
+// Requires pg_trgm extension.
+func Similar(expr any, text string, threshold float64) Expr {
+	if threshold < 0 || threshold > 1 {
+		panic(fmt.Sprintf("similarity threshold must be in range [0, 1], got %v", threshold))
+	}
+	return concatExpr(Similarity(expr, text), " > ", boundArg{value: threshold})
+}
+
+// Similarity generates 'similarity([expr], $n)', used to rank the results, e.g. OrderByExpr(Similarity(col, text), DESC).
+func Similarity(expr any, text string) Expr {
+	return functionExpr("similarity", expr, boundArg{value: text})
+}
+
+var likePatternEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// EscapeLikePattern escapes the LIKE wildcards '%', '_' and the default escape character '\'
+// so the input is treated literally in a LIKE/ILIKE pattern.
+func EscapeLikePattern(input string) string {
+	return likePatternEscaper.Replace(input)
+}
+
+// ArrayOverlaps generates '[array] && $n', checks if the array has any element in common with the values.
+// Slices of string and integers are bound as Postgres array.
+func ArrayOverlaps(array any, values any) Expr {
+	return concatExpr(array, " && ", boundArg{value: arrayArg(values)})
+}
+
+// ArrayContains generates '[array] @> $n', checks if the array contains all the values.
+// Slices of string and integers are bound as Postgres array.
+func ArrayContains(array any, values any) Expr {
+	return concatExpr(array, " @> ", boundArg{value: arrayArg(values)})
+}
+
+// ArrayHas generates '$n = ANY([array])', checks if the array contains the value.
+func ArrayHas(array any, value any) Expr {
+	return concatExpr(valueToken(value), " = ANY(", array, ")")
+}
+
+// EqAny generates '[expr] = ANY($n)', the values are bound as a single Postgres array argument,
+// an alternative to In which keeps the statement the same regardless the number of values.
+func EqAny(expr any, values any) Expr {
+	return concatExpr(expr, " = ANY(", boundArg{value: arrayArg(values)}, ")")
+}
+
+// HasKey generates '[expr] ? $n', checks if the HSTORE or JSONB contains the key. Postgres only.
+func HasKey(expr any, key string) Expr {
+	return concatExpr(expr, " ? ", boundArg{value: key})
+}
+
+// HasAnyKey generates '[expr] ?| $n', checks if the HSTORE or JSONB contains any of the keys. Postgres only.
+func HasAnyKey(expr any, keys ...string) Expr {
+	return concatExpr(expr, " ?| ", boundArg{value: StringArray(keys)})
+}
+
+// HasAllKeys generates '[expr] ?& $n', checks if the HSTORE or JSONB contains all the keys. Postgres only.
+func HasAllKeys(expr any, keys ...string) Expr {
+	return concatExpr(expr, " ?& ", boundArg{value: StringArray(keys)})
+}
+
+// HstoreContains generates '[expr] @> $n::HSTORE', checks if the HSTORE contains all the pairs.
+func HstoreContains(expr any, pairs map[string]string) Expr {
+	return concatExpr(expr, " @> ", boundArg{value: Hstore(pairs)}, "::HSTORE")
+}
+
+// JsonbContains generates '[expr] @> $n::JSONB', checks if the JSONB contains the value, which is bound as JSON.
+func JsonbContains(expr any, value any) Expr {
+	return concatExpr(expr, " @> ", boundArg{value: jsonValue{value: value}}, "::JSONB")
+}
+
+// RangeContains generates '[range] @> $n', checks if the range contains the element or the other range.
+func RangeContains(rangeExpr any, value any) Expr {
+	return concatExpr(rangeExpr, " @> ", valueToken(value))
+}
+
+// RangeContainsTime generates '[range] @> $n::TIMESTAMPTZ', checks if the time range contains the time.
+func RangeContainsTime(rangeExpr any, t time.Time) Expr {
+	return concatExpr(rangeExpr, " @> ", boundArg{value: t}, "::TIMESTAMPTZ")
+}
+
+// RangeContainedBy generates '[expr] <@ $n', checks if the element or the range is contained by the other range.
+func RangeContainedBy(expr any, rangeValue any) Expr {
+	return concatExpr(expr, " <@ ", valueToken(rangeValue))
+}
+
+// RangeOverlaps generates '[range] && $n', checks if the ranges have any point in common.
+func RangeOverlaps(rangeExpr any, other any) Expr {
+	return concatExpr(rangeExpr, " && ", valueToken(other))
+}
+
+// TupleIn generates '([col1], [col2]) IN (($1, $2), ($3, $4))', used to filter by composite keys.
+// When no row provided, it generates 'FALSE'.
+func TupleIn(columns []GenericColumnToUse, rows ...[]any) Expr {
+	if len(columns) == 0 {
+		panic("no columns provided for tuple")
+	}
+	if len(rows) == 0 {
+		return concatExpr(false)
+	}
+
+	tokens := tupleTokens(columns)
+	tokens = append(tokens, " IN (")
+	for i, row := range rows {
+		if len(row) != len(columns) {
+			panic(fmt.Sprintf("row no.%d has %d values, expected %d", i+1, len(row), len(columns)))
+		}
+		if i > 0 {
+			tokens = append(tokens, ", ")
+		}
+		tokens = append(tokens, tupleValueTokens(row)...)
+	}
+	tokens = append(tokens, ")")
+	return concatExpr(tokens...)
+}
+
+// TupleCompare generates '([col1], [col2]) [operator] ($1, $2)', used for keyset pagination.
+func TupleCompare(columns []GenericColumnToUse, operator string, values ...any) Expr {
+	if len(columns) == 0 {
+		panic("no columns provided for tuple")
+	}
+	if len(values) != len(columns) {
+		panic(fmt.Sprintf("provided %d values, expected %d", len(values), len(columns)))
+	}
+	switch operator {
+	case "=", "<>", "<", "<=", ">", ">=":
+	default:
+		panic(fmt.Sprintf("unsupported tuple comparison operator %s", operator))
+	}
+
+	tokens := tupleTokens(columns)
+	tokens = append(tokens, " "+operator+" ")
+	tokens = append(tokens, tupleValueTokens(values)...)
+	return concatExpr(tokens...)
+}
+
+func tupleTokens(columns []GenericColumnToUse) []any {
+	tokens := []any{"("}
+	for i, column := range columns {
+		if i > 0 {
+			tokens = append(tokens, ", ")
+		}
+		tokens = append(tokens, column)
+	}
+	return append(tokens, ")")
+}
+
+func tupleValueTokens(values []any) []any {
+	tokens := []any{"("}
+	for i, value := range values {
+		if i > 0 {
+			tokens = append(tokens, ", ")
+		}
+		tokens = append(tokens, valueToken(value))
+	}
+	return append(tokens, ")")
+}
@@ -0,0 +1,255 @@
+package sqlb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+//goland:noinspection SqlNoDataSourceInspection
+func TestPredicates(t *testing.T) {
+	table1 := UseTable[testStruct1]().Alias("t1").Seal()
+	table2 := UseTable[testStruct2]().Alias("t2").Seal()
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		expr     Expr
+		wantSql  string
+		wantArgs []any
+	}{
+		{
+			name:     "Eq value",
+			expr:     Eq(table1.Col("pk1"), "a"),
+			wantSql:  "t1.pk1 = $1",
+			wantArgs: []any{"a"},
+		},
+		{
+			name:    "Eq column",
+			expr:    Eq(table1.Col("pk1"), table2.Col("pk1")),
+			wantSql: "t1.pk1 = t2.pk1",
+		},
+		{
+			name:     "comparisons",
+			expr:     NewExpr(NotEq(table1.Col("pk1"), "a"), "AND", Lt(table1.Col("pk2"), 1), "OR", Lte(table1.Col("pk2"), 2), "OR", Gt(table1.Col("pk2"), 3), "OR", Gte(table1.Col("pk2"), 4)),
+			wantSql:  "t1.pk1 <> $1 AND t1.pk2 < $2 OR t1.pk2 <= $3 OR t1.pk2 > $4 OR t1.pk2 >= $5",
+			wantArgs: []any{"a", 1, 2, 3, 4},
+		},
+		{
+			name:     "In",
+			expr:     In(table1.Col("pk2"), 1, 2, 3),
+			wantSql:  "t1.pk2 IN ($1, $2, $3)",
+			wantArgs: []any{1, 2, 3},
+		},
+		{
+			name:    "In without values",
+			expr:    In(table1.Col("pk2")),
+			wantSql: "FALSE",
+		},
+		{
+			name:    "IsNull & IsNotNull",
+			expr:    NewExpr(IsNull(table1.Col("pk1")), "AND", IsNotNull(table1.Col("pk2"))),
+			wantSql: "t1.pk1 IS NULL AND t1.pk2 IS NOT NULL",
+		},
+		{
+			name:     "Like & ILike",
+			expr:     NewExpr(Like(table1.Col("pk1"), "a%"), "OR", ILike(table1.Col("pk1"), "%"+EscapeLikePattern("50%_off")+"%")),
+			wantSql:  "t1.pk1 LIKE $1 OR t1.pk1 ILIKE $2",
+			wantArgs: []any{"a%", `%50\%\_off%`},
+		},
+		{
+			name:     "ArrayOverlaps & ArrayContains",
+			expr:     NewExpr(ArrayOverlaps(table1.Col("pk1"), []string{"a"}), "AND", ArrayContains(table1.Col("pk1"), []int{1, 2})),
+			wantSql:  "t1.pk1 && $1 AND t1.pk1 @> $2",
+			wantArgs: []any{StringArray{"a"}, Int64Array{1, 2}},
+		},
+		{
+			name:     "ArrayHas & EqAny",
+			expr:     NewExpr(ArrayHas(table1.Col("pk1"), "a"), "AND", EqAny(table1.Col("pk2"), []int64{1, 2})),
+			wantSql:  "$1 = ANY(t1.pk1) AND t1.pk2 = ANY($2)",
+			wantArgs: []any{"a", Int64Array{1, 2}},
+		},
+		{
+			name:     "BetweenSymmetric",
+			expr:     BetweenSymmetric(table1.Col("amount"), 10, table1.Col("cost")),
+			wantSql:  "t1.amount BETWEEN SYMMETRIC $1 AND t1.cost",
+			wantArgs: []any{10},
+		},
+		{
+			name: "WithinTimeRange",
+			expr: NewExpr(
+				WithinTimeRange(table1.Col("pk1"), from, to), "AND",
+				WithinTimeRange(table1.Col("pk1"), from, time.Time{}), "AND",
+				WithinTimeRange(table1.Col("pk1"), time.Time{}, to), "AND",
+				WithinTimeRange(table1.Col("pk1"), time.Time{}, time.Time{}),
+			),
+			wantSql:  "(t1.pk1 >= $1 AND t1.pk1 < $2) AND t1.pk1 >= $3 AND t1.pk1 < $4 AND TRUE",
+			wantArgs: []any{from, to, from, to},
+		},
+		{
+			name:     "regex",
+			expr:     NewExpr(RegexMatch(table1.Col("pk1"), `^error: \d+`), "OR", RegexIMatch(table1.Col("pk2"), "timeout|refused")),
+			wantSql:  "t1.pk1 ~ $1 OR t1.pk2 ~* $2",
+			wantArgs: []any{`^error: \d+`, "timeout|refused"},
+		},
+		{
+			name:     "trigram",
+			expr:     NewExpr(TrigramMatch(table1.Col("pk1"), "jon"), "OR", Similar(table1.Col("pk2"), "smith", 0.4)),
+			wantSql:  "t1.pk1 % $1 OR similarity(t1.pk2, $2) > $3",
+			wantArgs: []any{"jon", "smith", 0.4},
+		},
+		{
+			name: "hstore & jsonb keys",
+			expr: NewExpr(
+				HasKey(table1.Col("pk1"), "color"), "AND",
+				HasAnyKey(table1.Col("pk1"), "a", "b"), "AND",
+				HasAllKeys(table1.Col("pk2"), "c"), "AND",
+				HstoreContains(table1.Col("pk1"), map[string]string{"color": "red"}), "AND",
+				JsonbContains(table1.Col("pk2"), map[string]string{"size": "L"}),
+			),
+			wantSql: "t1.pk1 ? $1 AND t1.pk1 ?| $2 AND t1.pk2 ?& $3 AND t1.pk1 @> $4::HSTORE AND t1.pk2 @> $5::JSONB",
+			wantArgs: []any{"color", StringArray{"a", "b"}, StringArray{"c"}, Hstore{"color": "red"},
+				jsonValue{value: map[string]string{"size": "L"}}},
+		},
+		{
+			name: "ranges",
+			expr: NewExpr(
+				RangeContainsTime(table1.Col("pk1"), time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)), "AND",
+				RangeContains(table1.Col("pk1"), NewTimeRange(time.Time{}, time.Time{})), "AND",
+				RangeContainedBy(table1.Col("pk1"), table2.Col("pk1")), "AND",
+				RangeOverlaps(table1.Col("pk1"), NewTimeRange(time.Time{}, time.Time{})),
+			),
+			wantSql:  "t1.pk1 @> $1::TIMESTAMPTZ AND t1.pk1 @> $2 AND t1.pk1 <@ t2.pk1 AND t1.pk1 && $3",
+			wantArgs: []any{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), TimeRange{LowerInclusive: true}, TimeRange{LowerInclusive: true}},
+		},
+		{
+			name:     "TupleIn",
+			expr:     TupleIn(table1.PrimaryKeyColumns(), []any{"a", 1}, []any{"b", 2}),
+			wantSql:  "(t1.pk1, t1.pk2) IN (($1, $2), ($3, $4))",
+			wantArgs: []any{"a", 1, "b", 2},
+		},
+		{
+			name:    "TupleIn without rows",
+			expr:    TupleIn(table1.PrimaryKeyColumns()),
+			wantSql: "FALSE",
+		},
+		{
+			name:     "TupleCompare",
+			expr:     TupleCompare(table1.PrimaryKeyColumns(), ">=", "a", 1),
+			wantSql:  "(t1.pk1, t1.pk2) >= ($1, $2)",
+			wantArgs: []any{"a", 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotSql, gotArgs := tt.expr.render()
+			require.Equal(t, tt.wantSql, gotSql)
+			require.Equal(t, tt.wantArgs, gotArgs)
+		})
+	}
+
+	t.Run("EscapeLikePattern", func(t *testing.T) {
+		require.Equal(t, "abc", EscapeLikePattern("abc"))
+		require.Equal(t, `100\%`, EscapeLikePattern("100%"))
+		require.Equal(t, `a\_b`, EscapeLikePattern("a_b"))
+		require.Equal(t, `c:\\dir`, EscapeLikePattern(`c:\dir`))
+	})
+
+	t.Run("invalid tuple", func(t *testing.T) {
+		require.Panics(t, func() {
+			TupleIn(table1.PrimaryKeyColumns(), []any{"a"})
+		})
+		require.Panics(t, func() {
+			TupleCompare(table1.PrimaryKeyColumns(), ">=", "a")
+		})
+		require.Panics(t, func() {
+			TupleCompare(table1.PrimaryKeyColumns(), "LIKE", "a", 1)
+		})
+	})
+}
+
+//goland:noinspection SqlNoDataSourceInspection
+func TestPredicates_placeholdersAllocation(t *testing.T) {
+	t.Run("SELECT, allocated after provided args", func(t *testing.T) {
+		table1 := UseTable[testStruct1]().Alias("t1").Seal()
+		b := Select(table1.Columns("pk1")...).
+			From(table1).
+			Where(table1.Col("amount"), "> $1").
+			And(TupleCompare(table1.PrimaryKeyColumns(), ">", "a", 1)).
+			Args(100).
+			Limit(10)
+
+		gotSql, gotArgs := b.Build()
+		require.Equal(t, `SELECT t1.pk1
+FROM table1 AS t1
+WHERE t1.amount > $1 AND (t1.pk1, t1.pk2) > ($2, $3)
+LIMIT 10
+`, gotSql)
+		require.Equal(t, []any{100, "a", 1}, gotArgs)
+
+		// build again must produce the same result
+		gotSql2, gotArgs2 := b.Build()
+		require.Equal(t, gotSql, gotSql2)
+		require.Equal(t, gotArgs, gotArgs2)
+	})
+
+	t.Run("INSERT, allocated after values", func(t *testing.T) {
+		table1 := UseTable[testStruct1]().Seal()
+		b := InsertInto(table1, table1.Col("pk1"), table1.Col("pk2")).
+			Values(testStruct1{Pk1: "a", Pk2: 1}).
+			OnConflict(table1.PrimaryKeyColumns()...).
+			DoUpdate(table1.Col("pk2").FromExcluded()).
+			Where(Lt(table1.Col("amount"), 100))
+
+		gotSql, gotArgs := b.Build()
+		require.Equal(t, `INSERT INTO table1 (pk1, pk2)
+VALUES ($1,$2)
+ON CONFLICT (pk1, pk2) DO UPDATE SET
+ pk2 = excluded.pk2
+WHERE table1.amount < $3`, gotSql)
+		require.Equal(t, []any{"a", 1, 100}, gotArgs)
+	})
+}
+
+type testCitextRow struct {
+	Id    int64
+	Email string
+}
+
+var tableTestCitext = NewTableMetadata[testCitextRow]("accounts").
+	AddColumns(
+		NewColumnMetadata[testCitextRow]("id").
+			PrimaryKey().
+			InsertSpec(func(r testCitextRow) any {
+				return r.Id
+			}),
+		NewColumnMetadata[testCitextRow]("email").
+			SqlType("citext").
+			InsertSpec(func(r testCitextRow) any {
+				return r.Email
+			}),
+	).
+	Build(TableMetadataBuildOption{
+		ExpectedPkColumns: []string{"id"},
+	})
+
+func TestEqFold(t *testing.T) {
+	table1 := UseTable[testStruct1]().Alias("t1").Seal()
+	accounts := UseTable[testCitextRow]().Alias("a").Seal()
+
+	gotSql, gotArgs := NewExpr(
+		EqFold(table1.Col("pk1"), "Jon@Example.com"), "AND",
+		EqFold(table1.Col("pk2"), table1.Col("pk1")), "AND",
+		EqFold(accounts.Col("email"), "Jon@Example.com"),
+	).render()
+	require.Equal(t, "LOWER(t1.pk1) = LOWER($1) AND LOWER(t1.pk2) = LOWER(t1.pk1) AND a.email = $2", gotSql)
+	require.Equal(t, []any{"Jon@Example.com", "Jon@Example.com"}, gotArgs)
+}
+
+func TestRegexMatch_posixPattern(t *testing.T) {
+	gotSql, gotArgs := RegexMatch("col", `^(a+)\1$`).render()
+	require.Equal(t, "col ~ $1", gotSql)
+	require.Equal(t, []any{`^(a+)\1$`}, gotArgs, "back-references are not supported by Go regexp, yet valid for the database")
+}
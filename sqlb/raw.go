@@ -0,0 +1,40 @@
+package sqlb
+
+import (
+	"strings"
+)
+
+// rawSql is a token of user-supplied SQL with its own arguments.
+type rawSql struct {
+	sql  string
+	args []any
+}
+
+// Raw creates an expression of the raw SQL with the bound arguments, eg: Raw("jsonb_path_exists(data, $1)", path).
+// It is the escape hatch for exotic SQL, usable as WHERE token, DO UPDATE token, or selected via SelectExpr.
+//
+// The placeholders are numbered from 1 in the dialect of the statement ($1, ? or @p1),
+// they are re-numbered to follow the arguments of the surrounding statement when building.
+func Raw(sql string, args ...any) Expr {
+	if strings.TrimSpace(sql) == "" {
+		panic("raw SQL cannot be empty")
+	}
+	return Expr{
+		tokens: []any{rawSql{sql: sql, args: args}},
+	}
+}
+
+// SelectExpr adds the expression to the SELECT statement with the alias, after the columns,
+// the scanned value is read by ScannedRows.GetAggregate.
+func (b *SqlBuilder) SelectExpr(alias string, expr Expr) *SqlBuilder {
+	return b.SelectAggregates(Aggregate{expr: expr}.As(alias))
+}
+
+// writeRawSql writes the raw SQL, the placeholders are shifted by the number of arguments already collected.
+func (c *buildContext) writeRawSql(raw rawSql) {
+	offset := len(c.args)
+	c.writeString(renamePlaceholders(raw.sql, c.dialect, len(raw.args), func(n int) string {
+		return c.placeholder(offset + n)
+	}))
+	c.args = append(c.args, raw.args...)
+}
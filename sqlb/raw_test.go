@@ -0,0 +1,55 @@
+package sqlb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRaw(t *testing.T) {
+	t.Run("where and select item", func(t *testing.T) {
+		table1 := UseTable[testStruct1]().Alias("t1").Seal()
+		gotSql, gotArgs := Select(table1.Col("pk1")).
+			SelectExpr("score", Raw("similarity(t1.pk1, $1)", "abc")).
+			From(table1).
+			Where(table1.Col("pk2"), "= $1").Args(2).
+			And(Raw("jsonb_path_exists(t1.data, $1) AND t1.amount > $2", "$.a", 10)).
+			And(Eq(table1.Col("amount"), 3)).
+			Build()
+		require.Equal(t, "SELECT t1.pk1, similarity(t1.pk1, $2) AS score\nFROM table1 AS t1\nWHERE t1.pk2 = $1 AND jsonb_path_exists(t1.data, $3) AND t1.amount > $4 AND t1.amount = $5\n", gotSql)
+		require.Equal(t, []any{2, "abc", "$.a", 10, 3}, gotArgs)
+	})
+
+	t.Run("do update", func(t *testing.T) {
+		table1 := UseTable[testStruct1]().Seal()
+		gotSql, gotArgs := InsertInto(table1, table1.Col("pk1"), table1.Col("pk2")).
+			Values(testStruct1{Pk1: "a", Pk2: 1}).
+			OnConflict(table1.Col("pk1"), table1.Col("pk2")).
+			DoUpdate(table1.Col("amount"), "=", Raw("LEAST(excluded.amount, $1)", 100)).
+			Build()
+		require.Equal(t, "INSERT INTO table1 (pk1, pk2)\nVALUES ($1,$2)\nON CONFLICT (pk1, pk2) DO UPDATE SET\n amount = LEAST(excluded.amount, $3)", gotSql)
+		require.Equal(t, []any{"a", 1, 100}, gotArgs)
+	})
+
+	t.Run("question mark placeholders, literal is kept", func(t *testing.T) {
+		table1 := UseTable[testStruct1]().Alias("t1").Seal()
+		gotSql, gotArgs := Select(table1.Col("pk1")).
+			From(table1).
+			Where(Eq(table1.Col("pk2"), 1)).
+			And(Raw("JSON_CONTAINS(t1.data, ?, '$.?')", `"x"`)).
+			WithDialect(DialectMySQL).
+			Build()
+		require.Equal(t, "SELECT t1.pk1\nFROM table1 AS t1\nWHERE t1.pk2 = ? AND JSON_CONTAINS(t1.data, ?, '$.?')\n", gotSql)
+		require.Equal(t, []any{1, `"x"`}, gotArgs)
+	})
+
+	t.Run("invalid usages", func(t *testing.T) {
+		table1 := UseTable[testStruct1]().Alias("t1").Seal()
+		require.Panics(t, func() {
+			Raw(" ")
+		})
+		require.Panics(t, func() {
+			Select(table1.Col("pk1")).From(table1).Where(Raw("t1.pk1 = $2", "a")).Build()
+		}, "placeholder out of range")
+	})
+}
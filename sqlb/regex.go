@@ -0,0 +1,199 @@
+package sqlb
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// regexMaxBound is the maximum of the bounds '{m,n}' of the quantifiers (RE_DUP_MAX of Postgres).
+const regexMaxBound = 255
+
+// regexEscapeLetters are the letters which can be escaped in the advanced regular expressions of Postgres:
+// character entry escapes, class-shorthand escapes and constraint escapes.
+const regexEscapeLetters = "abBcefnrtuUvxdsSwWDAmMyYZ"
+
+// regexEmbeddedOptions are the options of the embedded '(?options)' at the start of the pattern.
+const regexEmbeddedOptions = "bceimnpqstwx"
+
+// validateRegex validates the pattern against the subset of the POSIX regular expressions supported by Postgres (ARE):
+// balanced groups and bracket expressions, known escapes, back-references of the existing groups,
+// quantifiers with operands and valid bounds. Perl-only syntax, e.g. named groups or \p{...}, is rejected.
+func validateRegex(pattern string) error {
+	fail := func(pos int, format string, args ...any) error {
+		return errors.Wrapf(ErrInvalidRegex, "%s at position %d of %q", fmt.Sprintf(format, args...), pos, pattern)
+	}
+
+	i := 0
+	if strings.HasPrefix(pattern, "(?") && !strings.HasPrefix(pattern, "(?:") &&
+		!strings.HasPrefix(pattern, "(?=") && !strings.HasPrefix(pattern, "(?!") && !strings.HasPrefix(pattern, "(?<") {
+		end := strings.IndexByte(pattern, ')')
+		if end < 0 {
+			return fail(0, "unclosed embedded options")
+		}
+		for j := 2; j < end; j++ {
+			if !strings.ContainsRune(regexEmbeddedOptions, rune(pattern[j])) {
+				return fail(j, "unknown embedded option %q", pattern[j])
+			}
+		}
+		i = end + 1
+	}
+
+	var (
+		depth    int
+		groups   int
+		operand  bool // operand is true if the previous item can be quantified
+		quantity bool // quantity is true if the previous item is a quantifier
+	)
+	for i < len(pattern) {
+		c := pattern[i]
+		switch c {
+		case '(':
+			i++
+			if strings.HasPrefix(pattern[i:], "?") {
+				switch {
+				case strings.HasPrefix(pattern[i:], "?:"), strings.HasPrefix(pattern[i:], "?="), strings.HasPrefix(pattern[i:], "?!"):
+					i += 2
+				case strings.HasPrefix(pattern[i:], "?<="), strings.HasPrefix(pattern[i:], "?<!"):
+					i += 3
+				default:
+					return fail(i-1, "unsupported group")
+				}
+			} else {
+				groups++
+			}
+			depth++
+			operand, quantity = false, false
+			continue
+		case ')':
+			if depth == 0 {
+				return fail(i, "unbalanced parenthesis")
+			}
+			depth--
+			operand, quantity = true, false
+		case '|':
+			operand, quantity = false, false
+		case '*', '+', '?':
+			if c == '?' && quantity {
+				// non-greedy quantifier, e.g. '*?'
+				quantity = false
+				operand = false
+				break
+			}
+			if !operand {
+				return fail(i, "quantifier %q without operand", c)
+			}
+			operand, quantity = false, true
+		case '{':
+			end, ok, err := regexBound(pattern, i)
+			if err != nil {
+				return fail(i, "%v", err)
+			}
+			if !ok {
+				// '{' not followed by a digit is an ordinary character
+				operand, quantity = true, false
+				break
+			}
+			if !operand {
+				return fail(i, "quantifier %q without operand", c)
+			}
+			i = end
+			operand, quantity = false, true
+		case '[':
+			end, err := regexBracketEnd(pattern, i)
+			if err != nil {
+				return fail(i, "%v", err)
+			}
+			i = end
+			operand, quantity = true, false
+		case '\\':
+			if i+1 >= len(pattern) {
+				return fail(i, "trailing backslash")
+			}
+			next := pattern[i+1]
+			switch {
+			case next >= '1' && next <= '9':
+				if int(next-'0') > groups {
+					return fail(i, "back-reference to undefined group %c", next)
+				}
+			case next >= 'a' && next <= 'z' || next >= 'A' && next <= 'Z':
+				if !strings.ContainsRune(regexEscapeLetters, rune(next)) {
+					return fail(i, "unsupported escape \\%c", next)
+				}
+			}
+			i++
+			operand, quantity = true, false
+		case '^', '$':
+			operand, quantity = false, false
+		default:
+			operand, quantity = true, false
+		}
+		i++
+	}
+	if depth > 0 {
+		return fail(len(pattern), "unclosed parenthesis")
+	}
+	return nil
+}
+
+// regexBound parses the bound '{m}', '{m,}' or '{m,n}' starting at i, returns the position of '}'.
+// Returns false if '{' is not followed by a digit, which is an ordinary character.
+func regexBound(pattern string, i int) (int, bool, error) {
+	if i+1 >= len(pattern) || pattern[i+1] < '0' || pattern[i+1] > '9' {
+		return 0, false, nil
+	}
+	end := strings.IndexByte(pattern[i:], '}')
+	if end < 0 {
+		return 0, false, errors.New("unclosed bound")
+	}
+	end += i
+
+	bounds := strings.SplitN(pattern[i+1:end], ",", 2)
+	lower, err := strconv.Atoi(bounds[0])
+	if err != nil {
+		return 0, false, errors.Errorf("invalid bound %q", pattern[i:end+1])
+	}
+	upper := lower
+	if len(bounds) == 2 && bounds[1] != "" {
+		if upper, err = strconv.Atoi(bounds[1]); err != nil {
+			return 0, false, errors.Errorf("invalid bound %q", pattern[i:end+1])
+		}
+	}
+	if lower > upper || upper > regexMaxBound {
+		return 0, false, errors.Errorf("invalid bound %q", pattern[i:end+1])
+	}
+	return end, true, nil
+}
+
+// regexBracketEnd returns the position of ']' closing the bracket expression starting at i,
+// including the classes '[:alpha:]', collating elements '[.x.]' and equivalence classes '[=x=]'.
+func regexBracketEnd(pattern string, i int) (int, error) {
+	j := i + 1
+	if j < len(pattern) && pattern[j] == '^' {
+		j++
+	}
+	if j < len(pattern) && pattern[j] == ']' {
+		j++ // ']' first is an ordinary character
+	}
+	for j < len(pattern) {
+		switch pattern[j] {
+		case ']':
+			return j, nil
+		case '\\':
+			j++
+		case '[':
+			if j+1 < len(pattern) && strings.ContainsRune(":.=", rune(pattern[j+1])) {
+				closing := string(pattern[j+1]) + "]"
+				end := strings.Index(pattern[j+2:], closing)
+				if end < 0 {
+					return 0, errors.Errorf("unclosed %q", pattern[j:j+2])
+				}
+				j += 2 + end + 1
+			}
+		}
+		j++
+	}
+	return 0, errors.New("unclosed bracket expression")
+}
@@ -0,0 +1,64 @@
+package sqlb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_validateRegex(t *testing.T) {
+	for _, pattern := range []string{
+		`^error: \d+`,
+		`timeout|refused`,
+		`^(a+)\1$`,
+		`(?i)^abc`,
+		`(?:ab)+?c{2,3}`,
+		`x(?=y)(?!z)(?<=x)`,
+		`[[:alpha:]_][^]a-z]*`,
+		`\y\mword\M`,
+		`a{`,
+		`[\d\]]`,
+	} {
+		require.NoError(t, validateRegex(pattern), pattern)
+	}
+
+	for _, pattern := range []string{
+		`(a`,
+		`a)`,
+		`[abc`,
+		`[[:alpha:]`,
+		`*a`,
+		`a|+b`,
+		`a**`,
+		`a{3,1}`,
+		`a{256}`,
+		`a{2`,
+		`(a)\2`,
+		`\p{L}`,
+		`(?<name>a)`,
+		`(?P<name>a)`,
+		`a(?i)b`,
+		`(?z)a`,
+		`abc\`,
+	} {
+		require.ErrorIs(t, validateRegex(pattern), ErrInvalidRegex, pattern)
+	}
+}
+
+func TestTryRegexMatch(t *testing.T) {
+	expr, err := TryRegexMatch("col", `^(a+)\1$`)
+	require.NoError(t, err)
+	gotSql, gotArgs := expr.render()
+	require.Equal(t, "col ~ $1", gotSql)
+	require.Equal(t, []any{`^(a+)\1$`}, gotArgs)
+
+	expr, err = TryRegexIMatch("col", "timeout|refused")
+	require.NoError(t, err)
+	gotSql, _ = expr.render()
+	require.Equal(t, "col ~* $1", gotSql)
+
+	_, err = TryRegexMatch("col", `(?<name>a)`)
+	require.ErrorIs(t, err, ErrInvalidRegex)
+	_, err = TryRegexIMatch("col", `(a`)
+	require.ErrorIs(t, err, ErrInvalidRegex)
+}
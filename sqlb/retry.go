@@ -0,0 +1,175 @@
+package sqlb
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// RetryPolicy is the policy of re-executing the statement on transient errors, with exponential backoff.
+//
+// Retrying a statement executed inside a transaction is usually pointless,
+// since the transaction is aborted by most errors, retry the whole transaction instead.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts including the first one, default is 3.
+	MaxAttempts int
+	// InitialBackoff is the wait before the second attempt, doubled after each attempt, default is 50ms.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait between attempts, default is 1s.
+	MaxBackoff time.Duration
+	// Retryable returns true if the error of a query is worth retrying, default is IsTransientError.
+	Retryable func(err error) bool
+	// ExecRetryable returns true if the error of ExecWithExecutor is worth retrying, default is IsNotExecuted.
+	//
+	// By default only the errors proving the statement was never applied are retried, since the statement
+	// may have been applied before e.g. the connection was reset, and re-executing it would apply it twice.
+	// Set it explicitly (e.g. to IsTransientError) to opt in retrying the idempotent statements on any transient error.
+	ExecRetryable func(err error) bool
+}
+
+// Retry re-executes the statement on transient errors, following the policy.
+// ExecWithExecutor is only re-executed on the errors proving the statement was never applied unless
+// RetryPolicy.ExecRetryable is set explicitly, see IsNotExecuted.
+// When Timeout is set, the deadline is applied to each attempt.
+func (b *SqlBuilder) Retry(policy RetryPolicy) *SqlBuilder {
+	if policy.MaxAttempts < 0 || policy.InitialBackoff < 0 || policy.MaxBackoff < 0 {
+		panic("retry policy must not be negative")
+	}
+	if policy.MaxAttempts == 0 {
+		policy.MaxAttempts = 3
+	}
+	if policy.InitialBackoff == 0 {
+		policy.InitialBackoff = 50 * time.Millisecond
+	}
+	if policy.MaxBackoff == 0 {
+		policy.MaxBackoff = time.Second
+	}
+	if policy.Retryable == nil {
+		policy.Retryable = IsTransientError
+	}
+	if policy.ExecRetryable == nil {
+		policy.ExecRetryable = IsNotExecuted
+	}
+
+	b.retryPolicy = &policy
+	return b
+}
+
+// backoff returns the wait before the next attempt, attempt starts from 1.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.InitialBackoff
+	for i := 1; i < attempt && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	return d
+}
+
+// sqlStateError is implemented by the errors of the Postgres drivers (pgconn.PgError, pq.Error).
+type sqlStateError interface {
+	SQLState() string
+}
+
+const (
+	sqlStateAdminShutdown        = "57P01"
+	sqlStateDeadlockDetected     = "40P01"
+	sqlStateSerializationFailure = "40001"
+)
+
+// IsTransientError returns true if the error is any of IsConnectionReset, IsAdminShutdown or IsDeadlock.
+func IsTransientError(err error) bool {
+	return IsConnectionReset(err) || IsAdminShutdown(err) || IsDeadlock(err)
+}
+
+// IsConnectionReset returns true if the connection was reset or broken.
+func IsConnectionReset(err error) bool {
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// IsAdminShutdown returns true if the server terminated the connection (SQLSTATE 57P01).
+func IsAdminShutdown(err error) bool {
+	return hasSqlState(err, sqlStateAdminShutdown)
+}
+
+// IsDeadlock returns true if the statement was aborted due to deadlock or serialization failure
+// (SQLSTATE 40P01, 40001).
+func IsDeadlock(err error) bool {
+	return hasSqlState(err, sqlStateDeadlockDetected) || hasSqlState(err, sqlStateSerializationFailure)
+}
+
+// safeToRetryError is implemented by the errors of pgconn which occurred before sending any data to the server.
+type safeToRetryError interface {
+	SafeToRetry() bool
+}
+
+// IsNotExecuted returns true if the error proves the statement was never applied, so it is safe to re-execute:
+// failed to connect, driver.ErrBadConn (returned by the drivers only before sending the statement),
+// the errors of pgconn safe to retry, or the statement was rolled back due to deadlock or serialization failure.
+func IsNotExecuted(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	var safeErr safeToRetryError
+	if errors.As(err, &safeErr) && safeErr.SafeToRetry() {
+		return true
+	}
+	return errors.Is(err, driver.ErrBadConn) || IsDeadlock(err)
+}
+
+func hasSqlState(err error, code string) bool {
+	var stateErr sqlStateError
+	return errors.As(err, &stateErr) && stateErr.SQLState() == code
+}
+
+// execute runs the execution function of the statement, following the timeout and the retry policy of the builder.
+// The write statements are retried following RetryPolicy.ExecRetryable, the queries following RetryPolicy.Retryable.
+func (b *SqlBuilder) execute(ctx context.Context, exec Executor, stmt string, args []any, write bool, fn func(ctx context.Context) error) (err error) {
+	defer func(start time.Time) {
+		b.observe(ctx, start, err)
+		b.reportSlowQuery(ctx, exec, stmt, args, start, err)
+	}(time.Now())
+
+	attempt := func() error {
+		ctx, cancel, err := b.withTimeout(ctx, exec)
+		if err != nil {
+			return err
+		}
+		defer cancel()
+
+		return fn(ctx)
+	}
+
+	if b.retryPolicy == nil {
+		return attempt()
+	}
+
+	retryable := b.retryPolicy.Retryable
+	if write {
+		retryable = b.retryPolicy.ExecRetryable
+	}
+
+	for i := 1; ; i++ {
+		err := attempt()
+		if err == nil || i >= b.retryPolicy.MaxAttempts || !retryable(err) {
+			return err
+		}
+
+		timer := time.NewTimer(b.retryPolicy.backoff(i))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
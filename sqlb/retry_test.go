@@ -0,0 +1,171 @@
+package sqlb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type testSqlStateError string
+
+func (e testSqlStateError) Error() string {
+	return "sqlstate " + string(e)
+}
+
+func (e testSqlStateError) SQLState() string {
+	return string(e)
+}
+
+// flakyExecutor fails the first executions with the given errors, for the sake of the test.
+type flakyExecutor struct {
+	recordingExecutor
+	errs []error
+}
+
+func (e *flakyExecutor) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	_, _ = e.recordingExecutor.ExecContext(ctx, query, args...)
+	if len(e.statements) <= len(e.errs) {
+		return nil, e.errs[len(e.statements)-1]
+	}
+	return nil, nil
+}
+
+func TestIsTransientError(t *testing.T) {
+	require.True(t, IsConnectionReset(errors.Wrap(syscall.ECONNRESET, "read")))
+	require.True(t, IsConnectionReset(driver.ErrBadConn))
+	require.True(t, IsAdminShutdown(fmt.Errorf("wrapped: %w", testSqlStateError("57P01"))))
+	require.True(t, IsDeadlock(testSqlStateError("40P01")))
+	require.True(t, IsDeadlock(testSqlStateError("40001")))
+	require.True(t, IsTransientError(testSqlStateError("57P01")))
+	require.False(t, IsTransientError(testSqlStateError("23505")))
+	require.False(t, IsTransientError(errors.New("syntax error")))
+}
+
+func TestIsNotExecuted(t *testing.T) {
+	require.True(t, IsNotExecuted(&net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}))
+	require.True(t, IsNotExecuted(errors.Wrap(driver.ErrBadConn, "exec")))
+	require.True(t, IsNotExecuted(testSqlStateError("40001")))
+	require.True(t, IsNotExecuted(testSqlStateError("40P01")))
+	require.False(t, IsNotExecuted(&net.OpError{Op: "read", Err: syscall.ECONNRESET}))
+	require.False(t, IsNotExecuted(io.ErrUnexpectedEOF))
+	require.False(t, IsNotExecuted(testSqlStateError("57P01")))
+}
+
+func TestRetryPolicy_backoff(t *testing.T) {
+	p := RetryPolicy{
+		InitialBackoff: 10 * time.Millisecond,
+		MaxBackoff:     50 * time.Millisecond,
+	}
+	require.Equal(t, 10*time.Millisecond, p.backoff(1))
+	require.Equal(t, 20*time.Millisecond, p.backoff(2))
+	require.Equal(t, 40*time.Millisecond, p.backoff(3))
+	require.Equal(t, 50*time.Millisecond, p.backoff(4))
+	require.Equal(t, 50*time.Millisecond, p.backoff(100))
+}
+
+func TestSqlBuilder_Retry(t *testing.T) {
+	newBuilder := func() *SqlBuilder {
+		return InsertInto(UseTable[testDdlRow]().Seal()).
+			Values(testDdlRow{Id: 1, Title: "book"})
+	}
+
+	t.Run("retries transient errors", func(t *testing.T) {
+		exec := &flakyExecutor{
+			errs: []error{testSqlStateError("40P01"), driver.ErrBadConn},
+		}
+		_, err := newBuilder().
+			Retry(RetryPolicy{InitialBackoff: time.Millisecond}).
+			ExecWithExecutor(context.Background(), exec)
+		require.NoError(t, err)
+		require.Len(t, exec.statements, 3)
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		exec := &flakyExecutor{
+			errs: []error{driver.ErrBadConn, driver.ErrBadConn, driver.ErrBadConn},
+		}
+		_, err := newBuilder().
+			Retry(RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}).
+			ExecWithExecutor(context.Background(), exec)
+		require.ErrorIs(t, err, driver.ErrBadConn)
+		require.Len(t, exec.statements, 2)
+	})
+
+	t.Run("does not retry permanent errors", func(t *testing.T) {
+		exec := &flakyExecutor{
+			errs: []error{testSqlStateError("23505")},
+		}
+		_, err := newBuilder().
+			Retry(RetryPolicy{InitialBackoff: time.Millisecond}).
+			ExecWithExecutor(context.Background(), exec)
+		require.Error(t, err)
+		require.Len(t, exec.statements, 1)
+	})
+
+	t.Run("does not retry exec on errors after sending the statement by default", func(t *testing.T) {
+		exec := &flakyExecutor{
+			errs: []error{syscall.ECONNRESET},
+		}
+		_, err := newBuilder().
+			Retry(RetryPolicy{InitialBackoff: time.Millisecond}).
+			ExecWithExecutor(context.Background(), exec)
+		require.ErrorIs(t, err, syscall.ECONNRESET)
+		require.Len(t, exec.statements, 1)
+	})
+
+	t.Run("opt in retrying exec on transient errors", func(t *testing.T) {
+		exec := &flakyExecutor{
+			errs: []error{syscall.ECONNRESET, testSqlStateError("57P01")},
+		}
+		_, err := newBuilder().
+			Retry(RetryPolicy{InitialBackoff: time.Millisecond, ExecRetryable: IsTransientError}).
+			ExecWithExecutor(context.Background(), exec)
+		require.NoError(t, err)
+		require.Len(t, exec.statements, 3)
+	})
+
+	t.Run("custom predicate", func(t *testing.T) {
+		exec := &flakyExecutor{
+			errs: []error{testSqlStateError("23505")},
+		}
+		_, err := newBuilder().
+			Retry(RetryPolicy{
+				InitialBackoff: time.Millisecond,
+				ExecRetryable: func(err error) bool {
+					return true
+				},
+			}).
+			ExecWithExecutor(context.Background(), exec)
+		require.NoError(t, err)
+		require.Len(t, exec.statements, 2)
+	})
+
+	t.Run("stops waiting when context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		exec := &flakyExecutor{
+			errs: []error{driver.ErrBadConn},
+		}
+		_, err := newBuilder().
+			Retry(RetryPolicy{InitialBackoff: time.Hour}).
+			ExecWithExecutor(ctx, exec)
+		require.ErrorIs(t, err, driver.ErrBadConn)
+		require.Len(t, exec.statements, 1)
+	})
+
+	t.Run("invalid policy", func(t *testing.T) {
+		require.Panics(t, func() {
+			newBuilder().Retry(RetryPolicy{MaxAttempts: -1})
+		})
+	})
+}
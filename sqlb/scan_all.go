@@ -0,0 +1,142 @@
+package sqlb
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// ScanAll executes the SELECT statement of a single table and appends the records into dest,
+// a pointer to a slice of the table type, e.g. *[]Transaction or *[]*Transaction.
+// The records are appended while scanning, without buffering the rows into ScannedRows.
+func (b *SqlBuilder) ScanAll(ctx context.Context, exec Executor, dest any) error {
+	b.mustTypeSelect()
+	b.mustBasicSelect()
+	slice, pointer := b.mustScanAllDest(dest)
+	stmt, args, err := b.TryBuild()
+	if err != nil {
+		return err
+	}
+
+	from := slice.Len()
+	var scanned bool
+	value, err := b.query(ctx, exec, resultKindRecords, stmt, args, func(ctx context.Context) (any, error) {
+		scanned = true
+		slice.Set(slice.Slice(0, from)) // discard the records of the failed attempt, see Retry
+		rows, err := exec.QueryContext(ctx, stmt, args...)
+		if err != nil {
+			return nil, err
+		}
+		if err := b.scanAll(rows, slice, pointer); err != nil {
+			return nil, err
+		}
+		if b.cache == nil && b.singleflightGroup == nil {
+			return nil, nil
+		}
+		// the records are shared by the cache and the concurrent executions, detach them from dest
+		records := reflect.MakeSlice(reflect.SliceOf(slice.Type().Elem()), 0, slice.Len()-from)
+		records = reflect.AppendSlice(records, slice.Slice(from, slice.Len()))
+		return newScannedRecords(records, pointer), nil
+	})
+	if err != nil {
+		slice.Set(slice.Slice(0, from))
+		return err
+	}
+
+	if !scanned { // cached or executed concurrently by another caller
+		records := value.(reflect.Value)
+		for i := 0; i < records.Len(); i++ {
+			appendRecord(slice, records.Index(i).Interface(), pointer)
+		}
+	}
+	return nil
+}
+
+// scanAll scans the rows into the slice, the rows are closed after scanning.
+func (b *SqlBuilder) scanAll(rows SqlRows, slice reflect.Value, pointer bool) error {
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	if err := b.validate(); err != nil {
+		return err
+	}
+
+	scanner := b.newRowScanner()
+	tableRows := make([]row, 1)
+	var pending []func() any // records transformed in parallel, read after the transforms complete
+	for rows.Next() {
+		if _, _, err := scanner.scanTables(rows, tableRows); err != nil {
+			_ = scanner.wait()
+			return err
+		}
+		if scanner.pool != nil {
+			pending = append(pending, tableRows[0].valueFunc)
+			continue
+		}
+		appendRecord(slice, tableRows[0].valueFunc(), pointer)
+	}
+	if err := rows.Err(); err != nil {
+		_ = scanner.wait()
+		return errors.Wrap(err, "failed to iterate rows")
+	}
+	if err := scanner.wait(); err != nil {
+		return err
+	}
+
+	for _, valueFunc := range pending {
+		appendRecord(slice, valueFunc(), pointer)
+	}
+	return nil
+}
+
+// newScannedRecords returns the records as values of the table type, the pointers are dereferenced.
+func newScannedRecords(records reflect.Value, pointer bool) reflect.Value {
+	if !pointer {
+		return records
+	}
+	values := reflect.MakeSlice(reflect.SliceOf(records.Type().Elem().Elem()), records.Len(), records.Len())
+	for i := 0; i < records.Len(); i++ {
+		values.Index(i).Set(records.Index(i).Elem())
+	}
+	return values
+}
+
+// appendRecord appends the record to the slice, as a pointer to a copy if the slice holds pointers.
+func appendRecord(slice reflect.Value, record any, pointer bool) {
+	value := reflect.ValueOf(record)
+	if pointer {
+		ptr := reflect.New(value.Type())
+		ptr.Elem().Set(value)
+		value = ptr
+	}
+	slice.Set(reflect.Append(slice, value))
+}
+
+// mustScanAllDest returns the slice of dest and whether it holds pointers,
+// panics if the columns are not of a single table or dest is not a pointer to a slice of the table type.
+func (b *SqlBuilder) mustScanAllDest(dest any) (slice reflect.Value, pointer bool) {
+	if len(b.selectColumns) == 0 || len(b.selectAggregates) > 0 {
+		panic("ScanAll requires the columns of a single table")
+	}
+	table := b.selectColumns[0].table
+	for _, column := range b.selectColumns[1:] {
+		if b.aliasOf(column.table) != b.aliasOf(table) {
+			panic("ScanAll requires the columns of a single table")
+		}
+	}
+
+	recordType := reflect.TypeOf(table.genericTableMeta().newRow())
+	value := reflect.ValueOf(dest)
+	if value.Kind() == reflect.Ptr && !value.IsNil() && value.Elem().Kind() == reflect.Slice {
+		switch value.Elem().Type().Elem() {
+		case recordType:
+			return value.Elem(), false
+		case reflect.PtrTo(recordType):
+			return value.Elem(), true
+		}
+	}
+	panic(fmt.Sprintf("dest must be a pointer to a slice of %s or *%s, got %T", recordType, recordType, dest))
+}
@@ -0,0 +1,96 @@
+package sqlb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSqlBuilder_ScanAll(t *testing.T) {
+	products := UseTable[testDdlRow]().Seal()
+	ctx := context.Background()
+	newBuilder := func() *SqlBuilder {
+		return Select(products.Columns("id", "title")...).From(products)
+	}
+
+	t.Run("values", func(t *testing.T) {
+		exec := &fixedRowsExecutor{rows: [][]any{{int64(1), "a"}, {int64(2), "b"}}}
+		dest := []testDdlRow{{Id: 0}}
+		require.NoError(t, newBuilder().ScanAll(ctx, exec, &dest))
+		require.Equal(t, []testDdlRow{{Id: 0}, {Id: 1, Title: "a"}, {Id: 2, Title: "b"}}, dest, "appended to the existing records")
+	})
+
+	t.Run("pointers", func(t *testing.T) {
+		exec := &fixedRowsExecutor{rows: [][]any{{int64(1), "a"}, {int64(2), "b"}}}
+		var dest []*testDdlRow
+		require.NoError(t, newBuilder().ScanAll(ctx, exec, &dest))
+		require.Equal(t, []*testDdlRow{{Id: 1, Title: "a"}, {Id: 2, Title: "b"}}, dest)
+	})
+
+	t.Run("cached", func(t *testing.T) {
+		exec := &fixedRowsExecutor{rows: [][]any{{int64(1), "a"}}}
+		b := newBuilder().Cache(NewMemoryCache(time.Minute))
+
+		var first []*testDdlRow
+		require.NoError(t, b.ScanAll(ctx, exec, &first))
+		first[0].Title = "modified"
+
+		var second []*testDdlRow
+		require.NoError(t, b.ScanAll(ctx, exec, &second))
+		require.Equal(t, []*testDdlRow{{Id: 1, Title: "a"}}, second, "the cached records are detached from the destination")
+		require.Len(t, exec.queries, 1)
+	})
+
+	t.Run("cache shared with QueryWithExecutor", func(t *testing.T) {
+		exec := &fixedRowsExecutor{rows: [][]any{{int64(1), "a"}}}
+		cache := NewMemoryCache(time.Minute)
+
+		rows, err := newBuilder().Cache(cache).QueryWithExecutor(ctx, exec)
+		require.NoError(t, err)
+		require.True(t, rows.Next())
+
+		var dest []testDdlRow
+		require.NoError(t, newBuilder().Cache(cache).ScanAll(ctx, exec, &dest))
+		require.Equal(t, []testDdlRow{{Id: 1, Title: "a"}}, dest)
+
+		rows, err = newBuilder().Cache(cache).QueryWithExecutor(ctx, exec)
+		require.NoError(t, err)
+		require.True(t, rows.Next())
+		require.Len(t, exec.queries, 2, "the rows and the records are cached separately")
+	})
+
+	t.Run("parallel transform", func(t *testing.T) {
+		table := UseTable[testPayloadRow]().Seal()
+		var dest []testPayloadRow
+		exec := &fixedRowsExecutor{rows: [][]any{{int64(1), "p"}, {int64(2), "q"}}}
+		require.NoError(t, Select(table.Columns()...).From(table).ParallelTransform(2).ScanAll(ctx, exec, &dest))
+		require.Equal(t, []testPayloadRow{{Id: 1, Raw: "p", Decoded: "decoded p"}, {Id: 2, Raw: "q", Decoded: "decoded q"}}, dest)
+	})
+
+	t.Run("failed scan leaves dest unchanged", func(t *testing.T) {
+		table := UseTable[testEnumRow]().Seal()
+		dest := []testEnumRow{{Id: "0"}}
+		exec := &fixedRowsExecutor{rows: [][]any{{"1", "paid"}, {"2", "refunded"}}}
+		err := Select(table.Columns()...).From(table).ScanAll(ctx, exec, &dest)
+		require.ErrorContains(t, err, "failed to transform column")
+		require.Equal(t, []testEnumRow{{Id: "0"}}, dest)
+	})
+
+	t.Run("misuse", func(t *testing.T) {
+		table1 := UseTable[testStruct1]().Seal()
+		table2 := UseTable[testStruct2]().Seal()
+		exec := &fixedRowsExecutor{}
+
+		require.PanicsWithValue(t, "ScanAll requires the columns of a single table", func() {
+			_ = Select(table1.Col("pk1"), table2.Col("pk3")).From(table1, table2).ScanAll(ctx, exec, &[]testStruct1{})
+		})
+		require.PanicsWithValue(t, "dest must be a pointer to a slice of sqlb.testDdlRow or *sqlb.testDdlRow, got []sqlb.testDdlRow", func() {
+			_ = newBuilder().ScanAll(ctx, exec, []testDdlRow{})
+		})
+		require.PanicsWithValue(t, "dest must be a pointer to a slice of sqlb.testDdlRow or *sqlb.testDdlRow, got *[]sqlb.testStruct1", func() {
+			_ = newBuilder().ScanAll(ctx, exec, &[]testStruct1{})
+		})
+	})
+}
@@ -3,14 +3,19 @@ package sqlb
 import (
 	"context"
 	"database/sql"
+	"fmt"
 
 	"github.com/pkg/errors"
 )
 
 type ScannedRows struct {
-	rowsOfAliasToRow []map[string]*row
-	rowIdx           int
-	anyNext          bool
+	rowsOfAliasToRow    []map[string]*row
+	aggregatesOfRows    []map[string]any               // value of the selected aggregates by alias, of each row
+	nullGroupingsOfRows []map[string]struct{}          // [alias].[column] of the NULL grouping columns, of each row
+	selectedColumns     map[string]map[string]struct{} // selected columns of each table alias, shared by the rows
+	autoAliases         map[int64]string               // aliases chosen by the builder for the tables of UseTableAutoAlias
+	rowIdx              int
+	anyNext             bool
 }
 
 type row struct {
@@ -18,6 +23,26 @@ type row struct {
 	read      bool
 }
 
+// clone returns a copy of the scanned rows with the reading state reset, the row values are shared.
+func (sr *ScannedRows) clone() *ScannedRows {
+	cloned := &ScannedRows{
+		rowsOfAliasToRow:    make([]map[string]*row, len(sr.rowsOfAliasToRow)),
+		aggregatesOfRows:    sr.aggregatesOfRows,
+		nullGroupingsOfRows: sr.nullGroupingsOfRows,
+		selectedColumns:     sr.selectedColumns,
+		autoAliases:         sr.autoAliases,
+	}
+	for i, aliasToRow := range sr.rowsOfAliasToRow {
+		cloned.rowsOfAliasToRow[i] = make(map[string]*row, len(aliasToRow))
+		for alias, r := range aliasToRow {
+			cloned.rowsOfAliasToRow[i][alias] = &row{
+				valueFunc: r.valueFunc,
+			}
+		}
+	}
+	return cloned
+}
+
 func (sr *ScannedRows) Count() int {
 	return len(sr.rowsOfAliasToRow)
 }
@@ -50,100 +75,167 @@ func (sr *ScannedRows) GetTable(byAlias string) any {
 	return r.valueFunc()
 }
 
+// GetAggregate returns the scanned value of the selected aggregate, as returned by the driver.
+func (sr *ScannedRows) GetAggregate(alias string) any {
+	if !sr.anyNext {
+		panic("require calls Next() first")
+	}
+	value, found := sr.aggregatesOfRows[sr.rowIdx][alias]
+	if !found {
+		panic(fmt.Sprintf("aggregate %s is not selected", alias))
+	}
+	return value
+}
+
+// IsNullGrouping returns true if the column grouped by ROLLUP, CUBE or GROUPING SETS is NULL in the current row,
+// meaning the row is a subtotal of the column.
+func (sr *ScannedRows) IsNullGrouping(column GenericColumnToUse) bool {
+	if !sr.anyNext {
+		panic("require calls Next() first")
+	}
+	_, found := sr.nullGroupingsOfRows[sr.rowIdx][sr.aliasOf(column.table)+"."+column.name]
+	return found
+}
+
+// aliasOf returns the alias of the table in the builder scanned the rows, see SqlBuilder.aliasOf.
+func (sr *ScannedRows) aliasOf(table GenericTableToUse) string {
+	if alias, found := sr.autoAliases[table.uniqueIdentity()]; found {
+		return alias
+	}
+	return table.tableAlias()
+}
+
 var _ SqlRows = (*sql.Rows)(nil)
 
 func (b *SqlBuilder) Query(sqlDB *sql.DB) (*ScannedRows, error) {
-	b.mustTypeSelect()
-	b.mustBasicSelect()
-	stmt, args := b.Build()
-	return b.scanRows(sqlDB.Query(stmt, args...))
+	return b.QueryWithExecutor(context.Background(), WrapExecutor(sqlDB))
 }
 
 func (b *SqlBuilder) QueryWithContext(ctx context.Context, sqlTx *sql.Tx) (*ScannedRows, error) {
-	b.mustTypeSelect()
-	b.mustBasicSelect()
-	stmt, args := b.Build()
-	return b.scanRows(sqlTx.QueryContext(ctx, stmt, args...))
+	return b.QueryWithExecutor(ctx, WrapExecutor(sqlTx))
 }
 
-func (b *SqlBuilder) QueryExists(sqlDB *sql.DB) (exists bool, err error) {
-	b.mustSelectExists()
-	stmt, args := b.Build()
-	rows, err := sqlDB.Query(stmt, args...)
+// QueryWithExecutor executes the SELECT statement using the given executor and scans the result rows.
+func (b *SqlBuilder) QueryWithExecutor(ctx context.Context, exec Executor) (*ScannedRows, error) {
+	b.mustTypeSelect()
+	b.mustBasicSelect()
+	stmt, args, err := b.TryBuild()
 	if err != nil {
-		return false, err
-	}
-
-	defer func() {
-		_ = rows.Close()
-	}()
-
-	if !rows.Next() {
-		return false, errors.New("no rows returned")
+		return nil, err
 	}
 
-	err = rows.Scan(&exists)
+	value, err := b.query(ctx, exec, resultKindRows, stmt, args, func(ctx context.Context) (any, error) {
+		return b.scanRows(exec.QueryContext(ctx, stmt, args...))
+	})
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
-	return exists, nil
+	return value.(*ScannedRows), nil
+}
+
+func (b *SqlBuilder) QueryExists(sqlDB *sql.DB) (exists bool, err error) {
+	return b.QueryExistsWithExecutor(context.Background(), WrapExecutor(sqlDB))
 }
 
 func (b *SqlBuilder) QueryExistsWithContext(ctx context.Context, sqlTx *sql.Tx) (exists bool, err error) {
+	return b.QueryExistsWithExecutor(ctx, WrapExecutor(sqlTx))
+}
+
+// QueryExistsWithExecutor executes the SELECT EXISTS statement using the given executor.
+func (b *SqlBuilder) QueryExistsWithExecutor(ctx context.Context, exec Executor) (exists bool, err error) {
 	b.mustSelectExists()
-	stmt, args := b.Build()
-	rows, err := sqlTx.QueryContext(ctx, stmt, args...)
+	stmt, args, err := b.TryBuild()
 	if err != nil {
 		return false, err
 	}
 
-	defer func() {
-		_ = rows.Close()
-	}()
-
-	if !rows.Next() {
-		return false, errors.New("no rows returned")
-	}
-
-	err = rows.Scan(&exists)
+	value, err := b.query(ctx, exec, resultKindScalar, stmt, args, func(ctx context.Context) (any, error) {
+		var exists bool
+		err := queryScalar(ctx, exec, stmt, args, &exists)
+		return exists, err
+	})
 	if err != nil {
 		return false, err
 	}
 
-	return exists, nil
+	return value.(bool), nil
 }
 
-func (b *SqlBuilder) QueryCount(sqlDB *sql.DB) (count int, err error) {
+func (b *SqlBuilder) QueryCount(sqlDB *sql.DB) (count int64, err error) {
+	return b.QueryCountWithExecutor(context.Background(), WrapExecutor(sqlDB))
+}
+
+// QueryCountWithContext executes the SELECT COUNT statement using *sql.DB, *sql.Tx or *sql.Conn.
+func (b *SqlBuilder) QueryCountWithContext(ctx context.Context, std StdExecutor) (count int64, err error) {
+	return b.QueryCountWithExecutor(ctx, WrapExecutor(std))
+}
+
+// QueryCountWithExecutor executes the SELECT COUNT statement using the given executor.
+// The count is int64, the same as COUNT(*) of PostgreSQL (BIGINT).
+func (b *SqlBuilder) QueryCountWithExecutor(ctx context.Context, exec Executor) (count int64, err error) {
 	b.mustSelectCount()
-	stmt, args := b.Build()
-	rows, err := sqlDB.Query(stmt, args...)
+	stmt, args, err := b.TryBuild()
 	if err != nil {
 		return 0, err
 	}
 
-	defer func() {
-		_ = rows.Close()
-	}()
+	value, err := b.query(ctx, exec, resultKindScalar, stmt, args, func(ctx context.Context) (any, error) {
+		var count int64
+		err := queryScalar(ctx, exec, stmt, args, &count)
+		return count, err
+	})
+	if err != nil {
+		return 0, err
+	}
 
-	if !rows.Next() {
-		return 0, errors.New("no rows returned")
+	return value.(int64), nil
+}
+
+// query executes the query function, consults the cache and collapses the concurrent executions if configured.
+func (b *SqlBuilder) query(ctx context.Context, exec Executor, kind resultKind, stmt string, args []any, fn func(ctx context.Context) (any, error)) (any, error) {
+	run := func() (value any, err error) {
+		err = b.execute(ctx, exec, stmt, args, false, func(ctx context.Context) error {
+			value, err = fn(ctx)
+			return err
+		})
+		return value, err
 	}
 
-	err = rows.Scan(&count)
+	key, ok := queryFingerprint(kind, exec, stmt, args)
+	if !ok { // the result can not be keyed, neither cached nor collapsed
+		return run()
+	}
+	useCache := !inTransaction(exec)
+	if useCache {
+		if cached, found := b.cached(key); found {
+			return cached, nil
+		}
+	}
+
+	value, err := b.singleflight(key, func() (any, error) {
+		value, err := run()
+		if err != nil {
+			return nil, err
+		}
+
+		if useCache {
+			b.storeCache(key, value)
+		}
+		return value, nil
+	})
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
-	return count, nil
+	return value, nil
 }
 
-func (b *SqlBuilder) QueryCountWithContext(ctx context.Context, sqlTx *sql.Tx) (count int, err error) {
-	b.mustSelectCount()
-	stmt, args := b.Build()
-	rows, err := sqlTx.QueryContext(ctx, stmt, args...)
+// queryScalar executes the statement and scans the single value of the first row.
+func queryScalar(ctx context.Context, exec Executor, stmt string, args []any, dest any) error {
+	rows, err := exec.QueryContext(ctx, stmt, args...)
 	if err != nil {
-		return 0, err
+		return err
 	}
 
 	defer func() {
@@ -151,15 +243,19 @@ func (b *SqlBuilder) QueryCountWithContext(ctx context.Context, sqlTx *sql.Tx) (
 	}()
 
 	if !rows.Next() {
-		return 0, errors.New("no rows returned")
+		if err := rows.Err(); err != nil {
+			return errors.Wrap(err, "failed to iterate rows")
+		}
+		return errors.New("no rows returned")
 	}
 
-	err = rows.Scan(&count)
-	if err != nil {
-		return 0, err
-	}
+	return rows.Scan(dest)
+}
 
-	return count, nil
+// ScanRows scans the result rows of the SELECT statement which was executed elsewhere (eg: pgx batch), the rows are closed after scanning.
+func (b *SqlBuilder) ScanRows(rows SqlRows) (*ScannedRows, error) {
+	b.mustBasicSelect()
+	return b.scanRows(rows, nil)
 }
 
 func (b *SqlBuilder) scanRows(rows SqlRows, err error) (*ScannedRows, error) {
@@ -172,92 +268,303 @@ func (b *SqlBuilder) scanRows(rows SqlRows, err error) (*ScannedRows, error) {
 	}()
 
 	b.mustTypeSelect()
+	if err := b.validate(); err != nil { // the rows may be queried elsewhere, see ScanRows
+		return nil, err
+	}
 	sr := &ScannedRows{
-		rowsOfAliasToRow: make([]map[string]*row, 0),
+		rowsOfAliasToRow:    make([]map[string]*row, 0),
+		aggregatesOfRows:    make([]map[string]any, 0),
+		nullGroupingsOfRows: make([]map[string]struct{}, 0),
+		autoAliases:         b.autoAliases,
+	}
+
+	scanner := b.newRowScanner()
+	sr.selectedColumns = scanner.selectedColumns()
+	for rows.Next() {
+		if err := scanner.scan(rows, sr); err != nil {
+			_ = scanner.wait()
+			return nil, err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		_ = scanner.wait()
+		return nil, errors.Wrap(err, "failed to iterate rows")
+	}
+	if err := scanner.wait(); err != nil {
+		return nil, err
+	}
+
+	return sr, nil
+}
+
+// rowScanner scans the result rows using the layout of the selected columns computed once,
+// the scratch slices are reused by the rows so only the scanned values are allocated per row.
+type rowScanner struct {
+	tables        []scannedTable
+	nullGroupings []scannedNullGrouping // columns grouped by ROLLUP, CUBE or GROUPING SETS, NULL in the subtotal rows
+	aggregates    []Aggregate
+	columnsCount  int // columnsCount is the number of selected columns, the aggregates are scanned after the columns
+	maxRows       int // maxRows is the limit of the scanned rows, zero means no limit
+	scanned       int // scanned is the number of the scanned rows
+	// scratch
+	dest            []any
+	specs           []ResultColumnSelectSpec
+	transforms      []scannedTransform
+	groupings       []nullGroupingScanner
+	aggregateValues []any
+	pool            *transformPool // pool runs the transforms in parallel, nil means the transforms run while scanning
+}
+
+// scannedTable is the table of the selected columns.
+type scannedTable struct {
+	alias      string
+	columns    []string
+	selectSpec rowSelectSpec
+	positions  []int // positions are the scan positions of the columns of the table
+}
+
+type scannedNullGrouping struct {
+	position int
+	name     string // [alias].[column]
+}
+
+type scannedTransform struct {
+	position  int
+	table     string // table is the alias of the table of the column
+	column    string
+	transform func() error
+}
+
+// fail returns the failure of the transform of the row.
+func (t scannedTransform) fail(row int, err error) error {
+	return ScanError{Row: row, Table: t.table, Column: t.column, Err: err}
+}
+
+func (b *SqlBuilder) newRowScanner() *rowScanner {
+	s := &rowScanner{
+		aggregates:   b.selectAggregates,
+		columnsCount: len(b.selectColumns),
+		maxRows:      b.effectiveMaxRows(),
 	}
 
-	tablesByAlias := make(map[string]GenericTableToUse)
-	columnsByTableAlias := make(map[string][]string)
-	tableAliasToColumnToIndex := make(map[string]map[string]int)
+	tableIdxByAlias := make(map[string]int)
+	var metaOfTables []genericTableMetadata
 	for i, column := range b.selectColumns {
-		alias := column.table.tableAlias()
-		tablesByAlias[alias] = column.table
-		columnsByTableAlias[alias] = append(columnsByTableAlias[alias], column.name)
+		alias := b.aliasOf(column.table)
+		idx, found := tableIdxByAlias[alias]
+		if !found {
+			idx = len(s.tables)
+			tableIdxByAlias[alias] = idx
+			s.tables = append(s.tables, scannedTable{alias: alias})
+			metaOfTables = append(metaOfTables, column.table.genericTableMeta())
+		}
+		s.tables[idx].columns = append(s.tables[idx].columns, column.name)
+		s.tables[idx].positions = append(s.tables[idx].positions, i)
+
+		if b.isNullableGrouping(column) {
+			s.nullGroupings = append(s.nullGroupings, scannedNullGrouping{
+				position: i,
+				name:     b.nameWithAlias(column),
+			})
+		}
+	}
+	for i := range s.tables {
+		s.tables[i].selectSpec = metaOfTables[i].selectSpecOfColumns(s.tables[i].columns...)
+	}
+
+	s.dest = make([]any, len(b.selectColumns)+len(b.selectAggregates))
+	s.specs = make([]ResultColumnSelectSpec, 0, len(b.selectColumns))
+	s.transforms = make([]scannedTransform, 0, len(b.selectColumns))
+	s.groupings = make([]nullGroupingScanner, len(s.nullGroupings))
+	s.aggregateValues = make([]any, len(b.selectAggregates))
+	if b.transformWorkers > 0 {
+		s.pool = newTransformPool(b.transformWorkers)
+	}
+	return s
+}
 
-		if _, found := tableAliasToColumnToIndex[alias]; !found {
-			tableAliasToColumnToIndex[alias] = make(map[string]int)
+// selectedColumns returns the selected columns of each table alias.
+func (s *rowScanner) selectedColumns() map[string]map[string]struct{} {
+	selected := make(map[string]map[string]struct{}, len(s.tables))
+	for _, table := range s.tables {
+		columns := make(map[string]struct{}, len(table.columns))
+		for _, column := range table.columns {
+			columns[column] = struct{}{}
 		}
-		tableAliasToColumnToIndex[alias][column.name] = i
+		selected[table.alias] = columns
 	}
+	return selected
+}
 
-	for rows.Next() {
-		rowScanErr := func() (err error) {
-			aliasToRow := make(map[string]*row)
-			defer func() {
-				sr.rowsOfAliasToRow = append(sr.rowsOfAliasToRow, aliasToRow)
-			}()
-			//
-			columnsForScanning := make([]any, len(b.selectColumns))
-			optionalTransformFunctions := make([]func() error, 0, len(b.selectColumns))
-			defer func() {
-				if err == nil {
-					for _, transformFunc := range optionalTransformFunctions {
-						if transformFunc == nil {
-							continue
-						}
-						if transErr := transformFunc(); transErr != nil {
-							err = errors.Wrap(transErr, "failed to transform column")
-							return
-						}
-					}
-				}
-			}()
-
-			// construct columns for scanning and output
-			for _, table := range tablesByAlias {
-				tableAlias := table.tableAlias()
-				columns := columnsByTableAlias[tableAlias]
-				vf, specs := table.genericTableMeta().selectSpecOfColumns(columns...)
-				aliasToRow[tableAlias] = &row{
-					valueFunc: vf,
-				}
-
-				// register transform functions, order is not important
-				for _, spec := range specs {
-					optionalTransformFunctions = append(optionalTransformFunctions, spec.OptionalTransform)
-				}
-
-				// register columns for scanning, order is VERY important
-				for i, column := range columns {
-					spec := specs[i]
-					columnsForScanning[tableAliasToColumnToIndex[tableAlias][column]] = spec.ToQueryArg()
-				}
+// scan scans the current row into a new value of each table and appends it to the scanned rows.
+func (s *rowScanner) scan(rows SqlRows, sr *ScannedRows) error {
+	tableRows := make([]row, len(s.tables))
+	aggregates, nullGroupings, err := s.scanTables(rows, tableRows)
+	if err != nil {
+		return err
+	}
+
+	aliasToRow := make(map[string]*row, len(s.tables))
+	for i, table := range s.tables {
+		aliasToRow[table.alias] = &tableRows[i]
+	}
+	sr.rowsOfAliasToRow = append(sr.rowsOfAliasToRow, aliasToRow)
+	sr.aggregatesOfRows = append(sr.aggregatesOfRows, aggregates)
+	sr.nullGroupingsOfRows = append(sr.nullGroupingsOfRows, nullGroupings)
+	return nil
+}
+
+// scanTables scans the current row into a new value of each table, set to the rows in order of the tables.
+func (s *rowScanner) scanTables(rows SqlRows, tableRows []row) (aggregates map[string]any, nullGroupings map[string]struct{}, _ error) {
+	if s.maxRows > 0 && s.scanned == s.maxRows {
+		return nil, nil, ErrTooManyRows{Max: s.maxRows}
+	}
+	s.scanned++
+
+	if len(s.aggregates) > 0 {
+		aggregates = make(map[string]any, len(s.aggregates))
+	}
+
+	// construct columns for scanning and output, order of the destinations is VERY important
+	s.transforms = s.transforms[:0]
+	for i, table := range s.tables {
+		var valueFunc func() any
+		valueFunc, s.specs = table.selectSpec(s.specs[:0])
+		tableRows[i].valueFunc = valueFunc
+
+		for j, spec := range s.specs {
+			position := table.positions[j]
+			s.dest[position] = spec.ToQueryArg()
+			if spec.OptionalTransform != nil {
+				s.transforms = append(s.transforms, scannedTransform{
+					position:  position,
+					table:     table.alias,
+					column:    table.columns[j],
+					transform: spec.OptionalTransform,
+				})
 			}
+		}
+	}
+	for i, grouping := range s.nullGroupings {
+		s.groupings[i] = nullGroupingScanner{dest: s.dest[grouping.position]}
+		s.dest[grouping.position] = &s.groupings[i]
+	}
+	for i := range s.aggregateValues {
+		s.aggregateValues[i] = nil
+		s.dest[s.columnsCount+i] = &s.aggregateValues[i]
+	}
 
-			err = rows.Scan(columnsForScanning...)
-			if err != nil {
-				err = errors.Wrap(err, "failed to scan row")
-				return
+	if err := rows.Scan(s.dest...); err != nil {
+		return nil, nil, ScanError{Row: s.scanned - 1, Err: err}
+	}
+
+	for i, grouping := range s.nullGroupings {
+		if s.groupings[i].null {
+			if nullGroupings == nil {
+				nullGroupings = make(map[string]struct{})
 			}
+			nullGroupings[grouping.name] = struct{}{}
+		}
+	}
+	for i, aggregate := range s.aggregates {
+		aggregates[aggregate.alias] = s.aggregateValues[i]
+	}
 
-			return nil
-		}()
-		if rowScanErr != nil {
-			return nil, rowScanErr
+	// order of the transforms is not important
+	for _, t := range s.transforms {
+		if s.isNullGrouping(t.position) { // nothing to transform in the subtotal rows
+			continue
+		}
+		if s.pool != nil {
+			s.pool.submit(s.scanned-1, t)
+			continue
+		}
+		if err := t.transform(); err != nil {
+			return nil, nil, t.fail(s.scanned-1, err)
 		}
 	}
 
-	return sr, nil
+	return aggregates, nullGroupings, nil
+}
+
+// wait waits for the transforms running in parallel, returns the first failure.
+func (s *rowScanner) wait() error {
+	if s.pool == nil {
+		return nil
+	}
+	return s.pool.wait()
+}
+
+// isNullGrouping returns true if the column at the scan position is a NULL grouping of the current row.
+func (s *rowScanner) isNullGrouping(position int) bool {
+	for i, grouping := range s.nullGroupings {
+		if grouping.position == position {
+			return s.groupings[i].null
+		}
+	}
+	return false
 }
 
 func (b *SqlBuilder) Exec(sqlDB *sql.DB) (sql.Result, error) {
-	b.mustTypeInsert()
-	stmt, args := b.Build()
-	return sqlDB.Exec(stmt, args...)
+	return b.ExecWithExecutor(context.Background(), WrapExecutor(sqlDB))
 }
 
 func (b *SqlBuilder) ExecContext(ctx context.Context, sqlTx *sql.Tx) (sql.Result, error) {
+	return b.ExecWithExecutor(ctx, WrapExecutor(sqlTx))
+}
+
+// ExecWithExecutor executes the INSERT statement using the given executor.
+func (b *SqlBuilder) ExecWithExecutor(ctx context.Context, exec Executor) (sql.Result, error) {
 	b.mustTypeInsert()
-	stmt, args := b.Build()
-	return sqlTx.ExecContext(ctx, stmt, args...)
+	b.mustNotifySupported()
+	stmt, args, err := b.TryBuild()
+	if err != nil {
+		return nil, err
+	}
+
+	var result sql.Result
+	err = b.execute(ctx, exec, stmt, args, true, func(ctx context.Context) (err error) {
+		result, err = exec.ExecContext(ctx, stmt, args...)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	b.invalidateCache(exec)
+
+	if err := b.notify(ctx, exec, result); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// ErrUnexpectedRowsAffected is returned by ExecExpectOneRow when the number of affected rows is not 1.
+var ErrUnexpectedRowsAffected = errors.New("unexpected number of rows affected")
+
+// ExecRowsAffected executes the INSERT statement using the given executor, returns the number of affected rows.
+func (b *SqlBuilder) ExecRowsAffected(ctx context.Context, exec Executor) (int64, error) {
+	result, err := b.ExecWithExecutor(ctx, exec)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get rows affected")
+	}
+	return affected, nil
+}
+
+// ExecExpectOneRow executes the INSERT statement using the given executor,
+// returns ErrUnexpectedRowsAffected if the number of affected rows is not 1, eg: conflict ignored by DO NOTHING.
+func (b *SqlBuilder) ExecExpectOneRow(ctx context.Context, exec Executor) error {
+	affected, err := b.ExecRowsAffected(ctx, exec)
+	if err != nil {
+		return err
+	}
+	if affected != 1 {
+		return errors.Wrapf(ErrUnexpectedRowsAffected, "expected 1, got %d", affected)
+	}
+	return nil
 }
@@ -1,6 +1,8 @@
 package sqlb
 
 import (
+	"context"
+	"database/sql"
 	"testing"
 
 	"github.com/pkg/errors"
@@ -49,6 +51,10 @@ func (m *mockRowScanner) Close() error {
 	return nil
 }
 
+func (m *mockRowScanner) Err() error {
+	return nil
+}
+
 var _ SqlRows = (*mockRowScanner)(nil)
 
 func TestScannedRows(t *testing.T) {
@@ -139,3 +145,133 @@ func TestSqlBuilder_scanRows(t *testing.T) {
 		},
 	}, t2)
 }
+
+func TestSqlBuilder_scanRows_err(t *testing.T) {
+	table1 := UseTable[testStruct1]().Seal()
+	builder := Select(table1.Col("amount")).From(table1)
+
+	t.Run("iteration error is returned", func(t *testing.T) {
+		_, err := builder.ScanRows(&valuesRows{rows: [][]any{{1}}, err: sql.ErrConnDone})
+		require.True(t, errors.Is(err, sql.ErrConnDone))
+		require.EqualError(t, err, "failed to iterate rows: "+sql.ErrConnDone.Error())
+	})
+
+	t.Run("iteration error of scalar query", func(t *testing.T) {
+		exec := &fixedRowsExecutor{}
+		err := queryScalar(context.Background(), exec, "SELECT 1", nil, new(int))
+		require.EqualError(t, err, "no rows returned")
+
+		err = queryScalar(context.Background(), rowsExecutor{rows: &valuesRows{err: sql.ErrConnDone}}, "SELECT 1", nil, new(int))
+		require.True(t, errors.Is(err, sql.ErrConnDone))
+	})
+
+	t.Run("rows without Err are adapted", func(t *testing.T) {
+		rows, err := builder.ScanRows(AdaptSqlRows(rowsOnly{&valuesRows{rows: [][]any{{1}, {2}}}}))
+		require.NoError(t, err)
+		require.Equal(t, 2, rows.Count())
+
+		sqlRows := &valuesRows{}
+		require.Same(t, sqlRows, AdaptSqlRows(sqlRows), "rows implementing Err are returned as is")
+	})
+}
+
+func TestSqlBuilder_scanRows_errContext(t *testing.T) {
+	t.Run("scan", func(t *testing.T) {
+		table1 := UseTable[testStruct1]().Seal()
+		_, err := Select(table1.Col("amount")).From(table1).ScanRows(&valuesRows{rows: [][]any{{1}, {struct{}{}}}})
+		var scanErr ScanError
+		require.True(t, errors.As(err, &scanErr))
+		require.Equal(t, 1, scanErr.Row)
+		require.Empty(t, scanErr.Column)
+		require.ErrorContains(t, err, "failed to scan row 1: ")
+	})
+
+	table := UseTable[testEnumRow]().Alias("o").Seal()
+	values := [][]any{{"1", "paid"}, {"2", "paid"}, {"3", "refunded"}}
+	for name, builder := range map[string]*SqlBuilder{
+		"transform":          Select(table.Columns()...).From(table),
+		"parallel transform": Select(table.Columns()...).From(table).ParallelTransform(2),
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := builder.ScanRows(&valuesRows{rows: values})
+			var scanErr ScanError
+			require.True(t, errors.As(err, &scanErr))
+			require.Equal(t, ScanError{Row: 2, Table: "o", Column: "status", Err: scanErr.Err}, scanErr)
+			require.ErrorContains(t, err, `failed to transform column o.status of row 2: invalid value "refunded"`)
+		})
+	}
+}
+
+// rowsOnly hides Err of the rows, as the rows implemented before Err was required.
+type rowsOnly struct {
+	rows SqlRows
+}
+
+func (r rowsOnly) Next() bool {
+	return r.rows.Next()
+}
+
+func (r rowsOnly) Scan(dest ...any) error {
+	return r.rows.Scan(dest...)
+}
+
+func (r rowsOnly) Close() error {
+	return r.rows.Close()
+}
+
+// rowsExecutor returns the rows for any query.
+type rowsExecutor struct {
+	rows SqlRows
+}
+
+func (e rowsExecutor) QueryContext(context.Context, string, ...any) (SqlRows, error) {
+	return e.rows, nil
+}
+
+func (e rowsExecutor) ExecContext(context.Context, string, ...any) (sql.Result, error) {
+	return nil, errors.New("not supported")
+}
+
+// affectedExecutor returns the result with the number of affected rows, for the sake of the test.
+type affectedExecutor struct {
+	recordingExecutor
+	affected int64
+}
+
+func (e *affectedExecutor) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	if _, err := e.recordingExecutor.ExecContext(ctx, query, args...); err != nil {
+		return nil, err
+	}
+	return driverResult(e.affected), nil
+}
+
+// driverResult is the sql.Result with the number of affected rows.
+type driverResult int64
+
+func (r driverResult) LastInsertId() (int64, error) {
+	return 0, errors.New("not supported")
+}
+
+func (r driverResult) RowsAffected() (int64, error) {
+	return int64(r), nil
+}
+
+func TestSqlBuilder_ExecRowsAffected(t *testing.T) {
+	insert := func() *SqlBuilder {
+		products := UseTable[testDdlRow]().Seal()
+		return InsertInto(products).Values(testDdlRow{Id: 1, Title: "book"}).OnConflict(products.Col("id")).DoNothing()
+	}
+
+	affected, err := insert().ExecRowsAffected(context.Background(), &affectedExecutor{affected: 1})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), affected)
+
+	require.NoError(t, insert().ExecExpectOneRow(context.Background(), &affectedExecutor{affected: 1}))
+
+	err = insert().ExecExpectOneRow(context.Background(), &affectedExecutor{affected: 0})
+	require.ErrorIs(t, err, ErrUnexpectedRowsAffected)
+	require.ErrorContains(t, err, "expected 1, got 0")
+
+	_, err = insert().ExecRowsAffected(context.Background(), &affectedExecutor{recordingExecutor: recordingExecutor{err: errors.New("boom")}})
+	require.ErrorContains(t, err, "boom")
+}
@@ -0,0 +1,484 @@
+package sqlb
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var _ json.Marshaler = (*SqlBuilder)(nil)
+var _ json.Unmarshaler = (*SqlBuilder)(nil)
+
+// queryDefinitionVersion is the version of the JSON layout of the query definition.
+const queryDefinitionVersion = 1
+
+// queryDefinition is the JSON representation of the SELECT builder, see SqlBuilder.MarshalJSON.
+type queryDefinition struct {
+	Version    int               `json:"version"`
+	SelectType string            `json:"select_type"`
+	Format     string            `json:"format"`
+	Dialect    string            `json:"dialect"`
+	OmitAlias  bool              `json:"omit_redundant_alias,omitempty"`
+	Tables     []tableDefinition `json:"tables"`
+	Columns    []columnRef       `json:"columns,omitempty"`
+	From       []string          `json:"from"`
+	Joins      []joinDefinition  `json:"joins,omitempty"`
+	Where      []tokenDefinition `json:"where,omitempty"`
+	WhereArgs  []any             `json:"where_args,omitempty"`
+	Orders     []orderDefinition `json:"orders,omitempty"`
+	Offset     uint              `json:"offset,omitempty"`
+	Limit      uint              `json:"limit,omitempty"`
+}
+
+// tableDefinition is a using table, identified by the registered table name and the alias.
+type tableDefinition struct {
+	Table string `json:"table"`
+	Name  string `json:"name,omitempty"` // physical name when differs from the table name, eg: partition
+	Alias string `json:"alias"`
+}
+
+// columnRef refers a column of a using table, by the table alias.
+type columnRef struct {
+	Table string `json:"table"`
+	Name  string `json:"name"`
+}
+
+type joinDefinition struct {
+	Type  JoinType    `json:"type"`
+	Table string      `json:"table"`
+	On    []columnRef `json:"on"`
+}
+
+type orderDefinition struct {
+	Column columnRef `json:"column"`
+	Asc    bool      `json:"asc"`
+}
+
+// tokenDefinition is a WHERE token, exactly one of the fields is set.
+type tokenDefinition struct {
+	Text   *string           `json:"text,omitempty"`
+	Column *columnRef        `json:"column,omitempty"`
+	Expr   []tokenDefinition `json:"expr,omitempty"`
+	Arg    *json.RawMessage  `json:"arg,omitempty"`
+	Int    *int64            `json:"int,omitempty"`
+	Bool   *bool             `json:"bool,omitempty"`
+}
+
+// MarshalJSON serializes the SELECT builder, so the query definition can be stored (eg: saved filters, scheduled reports)
+// and rebuilt later by UnmarshalJSON.
+//
+// Only the statement is serialized, the execution options (Timeout, Retry, Cache,...) are not.
+// Arguments are serialized as JSON, values of driver.Valuer are converted first,
+// so the rebuilt arguments are the JSON-compatible types (string, int64, float64, bool, nil).
+func (b *SqlBuilder) MarshalJSON() ([]byte, error) {
+	if b._type != sqlBuilderTypeSelect {
+		return nil, errors.Errorf("serialization is only supported for %s, got %s", sqlBuilderTypeSelect, b._type)
+	}
+	if len(b.groupBy) > 0 || len(b.selectAggregates) > 0 {
+		return nil, errors.New("serialization of GROUP BY and aggregates is not supported")
+	}
+	for _, table := range b.selectFromTable {
+		if _, ok := table.(*ValuesRelation); ok {
+			return nil, errors.New("serialization of VALUES relation is not supported")
+		}
+	}
+	for _, j := range b.joinsOn {
+		if _, ok := j.joinOnTable.(*ValuesRelation); ok {
+			return nil, errors.New("serialization of VALUES relation is not supported")
+		}
+		if j.joinOnTokens.len() > 0 {
+			return nil, errors.New("serialization of JOIN ON condition is not supported")
+		}
+	}
+
+	for _, o := range b.orders {
+		if o.expr != nil {
+			return nil, errors.New("serialization of ORDER BY expression is not supported")
+		}
+	}
+
+	def := queryDefinition{
+		Version:    queryDefinitionVersion,
+		SelectType: string(b.selectType),
+		Format:     b.format.String(),
+		Dialect:    b.dialect.String(),
+		OmitAlias:  b.omitRedundantAlias,
+		Offset:     b.offset,
+		Limit:      b.limit,
+	}
+
+	tables := append([]GenericTableToUse{}, b.selectFromTable...)
+	for _, j := range b.joinsOn {
+		tables = append(tables, j.joinOnTable)
+	}
+	for _, column := range b.selectColumns {
+		tables = append(tables, column.table)
+	}
+	seen := make(map[string]struct{})
+	for _, table := range tables {
+		alias := b.aliasOf(table)
+		if _, found := seen[alias]; found {
+			continue
+		}
+		seen[alias] = struct{}{}
+
+		td := tableDefinition{
+			Table: table.genericTableMeta().Name(),
+			Alias: alias,
+		}
+		if table.tableName() != td.Table {
+			td.Name = table.tableName()
+		}
+		def.Tables = append(def.Tables, td)
+	}
+
+	for _, column := range b.selectColumns {
+		def.Columns = append(def.Columns, b.newColumnRef(column))
+	}
+	for _, table := range b.selectFromTable {
+		def.From = append(def.From, b.aliasOf(table))
+	}
+	for _, j := range b.joinsOn {
+		jd := joinDefinition{
+			Type:  j.joinType,
+			Table: b.aliasOf(j.joinOnTable),
+		}
+		for _, column := range j.joinOnColumns {
+			jd.On = append(jd.On, b.newColumnRef(column))
+		}
+		def.Joins = append(def.Joins, jd)
+	}
+
+	var err error
+	if def.Where, err = b.marshalTokens(b.tokens.list(b.whereTokens)); err != nil {
+		return nil, err
+	}
+	for i, arg := range b.whereArgs {
+		if def.WhereArgs, err = appendArg(def.WhereArgs, arg); err != nil {
+			return nil, errors.Wrapf(err, "failed to serialize where arg at index %d", i)
+		}
+	}
+
+	for _, o := range b.orders {
+		def.Orders = append(def.Orders, orderDefinition{
+			Column: b.newColumnRef(o.column),
+			Asc:    o.asc,
+		})
+	}
+
+	return json.Marshal(def)
+}
+
+// UnmarshalJSON rebuilds the SELECT builder serialized by MarshalJSON,
+// validates the tables and the columns against the current registered metadata.
+func (b *SqlBuilder) UnmarshalJSON(data []byte) (err error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var def queryDefinition
+	if err := decoder.Decode(&def); err != nil {
+		return errors.Wrap(err, "failed to decode query definition")
+	}
+	if def.Version != queryDefinitionVersion {
+		return errors.Errorf("unsupported query definition version %d", def.Version)
+	}
+
+	defer func() {
+		if r := recover(); r != nil { // the builder panics on invalid state
+			if e, ok := r.(error); ok {
+				err = errors.Wrap(e, "invalid query definition")
+			} else {
+				err = errors.Errorf("invalid query definition: %v", r)
+			}
+		}
+	}()
+
+	restored, err := def.rebuild()
+	if err != nil {
+		return err
+	}
+
+	*b = *restored
+	return nil
+}
+
+func (def queryDefinition) rebuild() (*SqlBuilder, error) {
+	tablesByAlias := make(map[string]GenericTableToUse, len(def.Tables))
+	for _, td := range def.Tables {
+		metadata, err := registeredTableByName(td.Table)
+		if err != nil {
+			return nil, err
+		}
+		name := td.Name
+		if name == "" {
+			name = td.Table
+		}
+		tablesByAlias[td.Alias] = metadata.useTable(name, td.Alias)
+	}
+
+	resolveTable := func(alias string) (GenericTableToUse, error) {
+		table, found := tablesByAlias[alias]
+		if !found {
+			return nil, errors.Errorf("unknown table alias %s", alias)
+		}
+		return table, nil
+	}
+	resolveColumn := func(ref columnRef) (GenericColumnToUse, error) {
+		table, err := resolveTable(ref.Table)
+		if err != nil {
+			return GenericColumnToUse{}, err
+		}
+		for _, column := range table.allColumns() {
+			if column.name == ref.Name {
+				return column, nil
+			}
+		}
+		return GenericColumnToUse{}, ErrUnknownColumn{Table: table.genericTableMeta().Name(), Column: ref.Name}
+	}
+	resolveColumns := func(refs []columnRef) ([]GenericColumnToUse, error) {
+		columns := make([]GenericColumnToUse, len(refs))
+		for i, ref := range refs {
+			column, err := resolveColumn(ref)
+			if err != nil {
+				return nil, err
+			}
+			columns[i] = column
+		}
+		return columns, nil
+	}
+
+	var b *SqlBuilder
+	switch selectType(def.SelectType) {
+	case selectTypeBasic:
+		columns, err := resolveColumns(def.Columns)
+		if err != nil {
+			return nil, err
+		}
+		b = Select(columns...)
+	case selectTypeExists:
+		b = SelectExists()
+	case selectTypeCount:
+		b = SelectCount()
+	default:
+		return nil, errors.Errorf("unknown select type %s", def.SelectType)
+	}
+
+	format, err := parseFormat(def.Format)
+	if err != nil {
+		return nil, err
+	}
+	dialect, err := parseDialect(def.Dialect)
+	if err != nil {
+		return nil, err
+	}
+	b.WithFormat(format).WithDialect(dialect)
+	if def.OmitAlias {
+		b.OmitRedundantAlias()
+	}
+
+	from := make([]GenericTableToUse, len(def.From))
+	for i, alias := range def.From {
+		if from[i], err = resolveTable(alias); err != nil {
+			return nil, err
+		}
+	}
+	b.From(from...)
+
+	for _, jd := range def.Joins {
+		table, err := resolveTable(jd.Table)
+		if err != nil {
+			return nil, err
+		}
+		on, err := resolveColumns(jd.On)
+		if err != nil {
+			return nil, err
+		}
+		b.Join(jd.Type, table, on...)
+	}
+
+	if len(def.Where) > 0 {
+		tokens, err := unmarshalTokens(def.Where, resolveColumn)
+		if err != nil {
+			return nil, err
+		}
+		b.Where(tokens...)
+		if len(def.WhereArgs) > 0 {
+			args := make([]any, len(def.WhereArgs))
+			for i, arg := range def.WhereArgs {
+				args[i] = fromJsonValue(arg)
+			}
+			b.Args(args...)
+		}
+	}
+
+	for i, od := range def.Orders {
+		column, err := resolveColumn(od.Column)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			b.OrderBy(column, OrderType(od.Asc))
+		} else {
+			b.ThenBy(column, OrderType(od.Asc))
+		}
+	}
+
+	if def.Offset > 0 {
+		b.Offset(def.Offset)
+	}
+	if def.Limit > 0 {
+		b.Limit(def.Limit)
+	}
+
+	return b, nil
+}
+
+func (b *SqlBuilder) newColumnRef(column GenericColumnToUse) columnRef {
+	return columnRef{
+		Table: b.aliasOf(column.table),
+		Name:  column.name,
+	}
+}
+
+func (b *SqlBuilder) marshalTokens(tokens []any) ([]tokenDefinition, error) {
+	defs := make([]tokenDefinition, 0, len(tokens))
+	for _, token := range tokens {
+		var def tokenDefinition
+		switch t := token.(type) {
+		case string:
+			def.Text = &t
+		case GenericColumnToUse:
+			ref := b.newColumnRef(t)
+			def.Column = &ref
+		case Expr:
+			nested, err := b.marshalTokens(t.tokens)
+			if err != nil {
+				return nil, err
+			}
+			def.Expr = nested
+		case boundArg:
+			args, err := appendArg(nil, t.value)
+			if err != nil {
+				return nil, err
+			}
+			raw, err := json.Marshal(args[0])
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to serialize argument")
+			}
+			msg := json.RawMessage(raw)
+			def.Arg = &msg
+		case int8, uint8, int16, uint16, int32, uint32, int64, uint64, int, uint:
+			v := reflect.ValueOf(t)
+			var i int64
+			if v.CanInt() {
+				i = v.Int()
+			} else {
+				i = int64(v.Uint())
+			}
+			def.Int = &i
+		case bool:
+			def.Bool = &t
+		default:
+			return nil, errors.Errorf("unsupported token type %T", t)
+		}
+		defs = append(defs, def)
+	}
+	return defs, nil
+}
+
+func unmarshalTokens(defs []tokenDefinition, resolveColumn func(columnRef) (GenericColumnToUse, error)) ([]any, error) {
+	tokens := make([]any, 0, len(defs))
+	for _, def := range defs {
+		switch {
+		case def.Text != nil:
+			tokens = append(tokens, *def.Text)
+		case def.Column != nil:
+			column, err := resolveColumn(*def.Column)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, column)
+		case def.Expr != nil:
+			nested, err := unmarshalTokens(def.Expr, resolveColumn)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, concatExpr(nested...))
+		case def.Arg != nil:
+			decoder := json.NewDecoder(bytes.NewReader(*def.Arg))
+			decoder.UseNumber()
+			var value any
+			if err := decoder.Decode(&value); err != nil {
+				return nil, errors.Wrap(err, "failed to decode argument")
+			}
+			tokens = append(tokens, boundArg{value: fromJsonValue(value)})
+		case def.Int != nil:
+			tokens = append(tokens, *def.Int)
+		case def.Bool != nil:
+			tokens = append(tokens, *def.Bool)
+		default:
+			return nil, errors.New("empty token")
+		}
+	}
+	return tokens, nil
+}
+
+// appendArg appends the argument in the JSON-compatible form, driver.Valuer is converted.
+func appendArg(args []any, arg any) ([]any, error) {
+	if valuer, ok := arg.(driver.Valuer); ok {
+		value, err := valuer.Value()
+		if err != nil {
+			return nil, err
+		}
+		arg = value
+	}
+	return append(args, arg), nil
+}
+
+// fromJsonValue converts the decoded JSON number to int64 or float64.
+func fromJsonValue(value any) any {
+	number, ok := value.(json.Number)
+	if !ok {
+		return value
+	}
+	if i, err := number.Int64(); err == nil {
+		return i
+	}
+	if f, err := number.Float64(); err == nil {
+		return f
+	}
+	return number.String()
+}
+
+func registeredTableByName(name string) (genericTableMetadata, error) {
+	mutexRegisterTable.Lock()
+	defer mutexRegisterTable.Unlock()
+
+	table, found := registeredTables[name]
+	if !found {
+		return nil, errors.Errorf("table %s is not registered", name)
+	}
+	return table.(genericTableMetadata), nil
+}
+
+func parseFormat(s string) (SqlFormat, error) {
+	for _, f := range []SqlFormat{FormatDefault, FormatSingleLine, FormatPretty} {
+		if strings.EqualFold(f.String(), s) {
+			return f, nil
+		}
+	}
+	return 0, errors.Errorf("unknown format %s", s)
+}
+
+func parseDialect(s string) (Dialect, error) {
+	for _, d := range []Dialect{DialectPostgres, DialectMySQL, DialectMySQL8, DialectSQLite, DialectSQLServer} {
+		if strings.EqualFold(d.String(), s) {
+			return d, nil
+		}
+	}
+	return 0, errors.Errorf("unknown dialect %s", s)
+}
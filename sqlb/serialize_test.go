@@ -0,0 +1,146 @@
+package sqlb
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSqlBuilder_MarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder func() *SqlBuilder
+	}{
+		{
+			name: "select with join, where, order and pagination",
+			builder: func() *SqlBuilder {
+				table1 := UseTable[testStruct1]().Alias("t1").Seal()
+				table2 := UseTable[testStruct2]().Alias("t2").Seal()
+				return Select(table1.Col("pk1"), table2.Col("pk3")).
+					From(table1).
+					Join(LeftJoin, table2, table1.Col("pk1"), table2.Col("pk1")).
+					Where(Eq(table1.Col("amount"), 10)).
+					And(table2.Col("pk2"), "IN ($1, $2)").Args(1, 2).
+					Or(In(table1.Col("cost"), "1 USD", "2 USD")).
+					OrderBy(table1.Col("pk1"), DESC).
+					ThenBy(table2.Col("pk3"), ASC).
+					Offset(10).
+					Limit(5).
+					WithFormat(FormatPretty)
+			},
+		},
+		{
+			name: "select exists",
+			builder: func() *SqlBuilder {
+				table1 := UseTable[testStruct1]().Alias("t1").Seal()
+				return SelectExists().From(table1).Where(table1.Col("pk2"), "=", 2, "AND", true)
+			},
+		},
+		{
+			name: "select without redundant alias",
+			builder: func() *SqlBuilder {
+				table1 := UseTable[testStruct1]().Seal()
+				return Select(table1.Col("pk1")).From(table1).OmitRedundantAlias()
+			},
+		},
+		{
+			name: "select count of partition",
+			builder: func() *SqlBuilder {
+				events := UseTable[testEventRow]().As("events_2024_01").Seal()
+				return SelectCount().From(events).WithDialect(DialectMySQL)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := tt.builder()
+			data, err := json.Marshal(original)
+			require.NoError(t, err)
+
+			restored := &SqlBuilder{}
+			require.NoError(t, json.Unmarshal(data, restored))
+
+			wantSql, wantArgs := original.Build()
+			gotSql, gotArgs := restored.Build()
+			require.Equal(t, wantSql, gotSql)
+			require.Len(t, gotArgs, len(wantArgs))
+			for i := range wantArgs {
+				require.EqualValues(t, wantArgs[i], gotArgs[i])
+			}
+		})
+	}
+}
+
+func TestSqlBuilder_UnmarshalJSON_validation(t *testing.T) {
+	table1 := UseTable[testStruct1]().Alias("t1").Seal()
+	data, err := json.Marshal(Select(table1.Col("pk1")).From(table1))
+	require.NoError(t, err)
+
+	var def map[string]any
+	require.NoError(t, json.Unmarshal(data, &def))
+
+	tests := []struct {
+		name    string
+		mutate  func(def map[string]any)
+		wantErr string
+	}{
+		{
+			name: "unknown table",
+			mutate: func(def map[string]any) {
+				def["tables"].([]any)[0].(map[string]any)["table"] = "dropped"
+			},
+			wantErr: "table dropped is not registered",
+		},
+		{
+			name: "unknown column",
+			mutate: func(def map[string]any) {
+				def["columns"].([]any)[0].(map[string]any)["name"] = "dropped"
+			},
+			wantErr: "column dropped does not exist in table table1",
+		},
+		{
+			name: "unsupported version",
+			mutate: func(def map[string]any) {
+				def["version"] = 99
+			},
+			wantErr: "unsupported query definition version 99",
+		},
+		{
+			name: "unknown select type",
+			mutate: func(def map[string]any) {
+				def["select_type"] = "???"
+			},
+			wantErr: "unknown select type ???",
+		},
+		{
+			name: "invalid state",
+			mutate: func(def map[string]any) {
+				def["joins"] = []any{map[string]any{
+					"type":  0,
+					"table": "t1",
+					"on": []any{
+						map[string]any{"table": "t1", "name": "pk1"},
+						map[string]any{"table": "t1", "name": "pk2"},
+					},
+				}}
+			},
+			wantErr: "invalid query definition: join on the same table at pair no.1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mutated := make(map[string]any)
+			reencoded, _ := json.Marshal(def)
+			require.NoError(t, json.Unmarshal(reencoded, &mutated))
+			tt.mutate(mutated)
+
+			data, err := json.Marshal(mutated)
+			require.NoError(t, err)
+			require.ErrorContains(t, json.Unmarshal(data, &SqlBuilder{}), tt.wantErr)
+		})
+	}
+
+	_, err = json.Marshal(InsertInto(table1).Values(testStruct1{}))
+	require.ErrorContains(t, err, "serialization is only supported for SELECT")
+}
@@ -0,0 +1,95 @@
+package sqlb
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// SingleflightGroup collapses the concurrent executions of the same query (fingerprint of the statement and the arguments)
+// into one database call, the result is shared by the callers.
+//
+// The group must be shared across the builders, eg: a package-level variable.
+type SingleflightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*singleflightCall
+}
+
+type singleflightCall struct {
+	wg    sync.WaitGroup
+	value any
+	err   error
+}
+
+// NewSingleflightGroup creates a group to collapse the concurrent executions of the same query.
+func NewSingleflightGroup() *SingleflightGroup {
+	return &SingleflightGroup{
+		calls: make(map[string]*singleflightCall),
+	}
+}
+
+// Singleflight collapses the concurrent executions of the same query using the group.
+//
+// The query is executed with the context of the first caller, the other callers share its result, including the error.
+// Only Query* are collapsed, Exec* are always executed.
+func (b *SqlBuilder) Singleflight(group *SingleflightGroup) *SqlBuilder {
+	if group == nil {
+		panic("group is nil")
+	}
+	b.singleflightGroup = group
+	return b
+}
+
+// do executes the function once for the concurrent calls of the same key,
+// the returned leader is true for the caller actually executed the function.
+// If the function panics, the panic is propagated to the leader while the other callers receive an error.
+func (g *SingleflightGroup) do(key string, fn func() (any, error)) (value any, err error, leader bool) {
+	g.mu.Lock()
+	if call, found := g.calls[key]; found {
+		g.mu.Unlock()
+		call.wg.Wait()
+		return call.value, call.err, false
+	}
+
+	call := &singleflightCall{}
+	call.wg.Add(1)
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	completed := false
+	defer func() {
+		r := recover()
+		if !completed { // panicked or exited, the other callers must not read a nil result as success
+			call.value, call.err = nil, errors.Errorf("query of the concurrent caller did not complete: %v", r)
+		}
+
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		call.wg.Done()
+
+		if r != nil {
+			panic(r)
+		}
+	}()
+
+	call.value, call.err = fn()
+	completed = true
+	return call.value, call.err, true
+}
+
+// singleflight executes the query function, collapsed by the group of the builder if any.
+func (b *SqlBuilder) singleflight(key string, fn func() (any, error)) (any, error) {
+	if b.singleflightGroup == nil {
+		return fn()
+	}
+
+	value, err, leader := b.singleflightGroup.do(key, fn)
+	if err != nil {
+		return nil, err
+	}
+	if sr, ok := value.(*ScannedRows); ok && !leader {
+		value = sr.clone()
+	}
+	return value, nil
+}
@@ -0,0 +1,124 @@
+package sqlb
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSingleflightGroup_do(t *testing.T) {
+	group := NewSingleflightGroup()
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	var leaderValue any
+	var leaderWg sync.WaitGroup
+	leaderWg.Add(1)
+	go func() {
+		defer leaderWg.Done()
+		leaderValue, _, _ = group.do("k", func() (any, error) {
+			close(started)
+			<-release
+			return 1, nil
+		})
+	}()
+	<-started
+
+	var followersWg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		followersWg.Add(1)
+		go func() {
+			defer followersWg.Done()
+			value, err, leader := group.do("k", func() (any, error) {
+				return nil, errors.New("must not be executed")
+			})
+			require.NoError(t, err)
+			require.False(t, leader)
+			require.Equal(t, 1, value)
+		}()
+	}
+
+	// give the followers time to join the call
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	followersWg.Wait()
+	leaderWg.Wait()
+	require.Equal(t, 1, leaderValue)
+
+	value, err, leader := group.do("k", func() (any, error) {
+		return 2, nil
+	})
+	require.NoError(t, err)
+	require.True(t, leader, "the key must be released after the call completed")
+	require.Equal(t, 2, value)
+
+	t.Run("leader panics", func(t *testing.T) {
+		release := make(chan struct{})
+		started := make(chan struct{})
+		var leaderPanic any
+		var leaderWg sync.WaitGroup
+		leaderWg.Add(1)
+		go func() {
+			defer leaderWg.Done()
+			defer func() {
+				leaderPanic = recover()
+			}()
+			_, _, _ = group.do("p", func() (any, error) {
+				close(started)
+				<-release
+				panic("boom")
+			})
+		}()
+		<-started
+
+		var followerErr error
+		var followerWg sync.WaitGroup
+		followerWg.Add(1)
+		go func() {
+			defer followerWg.Done()
+			_, followerErr, _ = group.do("p", func() (any, error) {
+				return nil, errors.New("must not be executed")
+			})
+		}()
+
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		followerWg.Wait()
+		leaderWg.Wait()
+
+		require.Equal(t, "boom", leaderPanic, "the panic is propagated to the leader")
+		require.EqualError(t, followerErr, "query of the concurrent caller did not complete: boom")
+	})
+}
+
+func TestSqlBuilder_Singleflight(t *testing.T) {
+	group := NewSingleflightGroup()
+	exec := &scalarExecutor{value: 3, delay: 100 * time.Millisecond}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			products := UseTable[testDdlRow]().Seal()
+			count, err := SelectCount().
+				From(products).
+				Singleflight(group).
+				QueryCountWithExecutor(context.Background(), exec)
+			require.NoError(t, err)
+			require.Equal(t, int64(3), count)
+		}()
+	}
+	wg.Wait()
+
+	require.Less(t, exec.queries.Load(), int32(10), "concurrent queries must be collapsed")
+
+	require.Panics(t, func() {
+		Select().Singleflight(nil)
+	})
+}
@@ -0,0 +1,87 @@
+package sqlb
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// SlowQueryHook receives the executions slower than the threshold, typically logs the report.
+type SlowQueryHook func(ctx context.Context, report SlowQueryReport)
+
+// SlowQueryReport is the slow execution of the statement, with the plan of the statement.
+type SlowQueryReport struct {
+	Sql      string
+	Args     []any
+	Duration time.Duration // Duration of the execution, including the retries
+	Err      error         // Err is the failure of the execution, nil on success
+	Plan     string        // Plan is the output of EXPLAIN, one line per row. Empty if failed to capture, see PlanErr
+	PlanErr  error         // PlanErr is the failure of capturing the plan
+}
+
+// SlowQuery reports the executions slower than the threshold to the hook,
+// with the plan captured by running EXPLAIN (ANALYZE off) of the statement with the same arguments. Postgres only.
+//
+// The plan is captured after the execution using the same executor, so it is not available when the transaction
+// of the executor is aborted. ANALYZE off means the statement is not executed again.
+func (b *SqlBuilder) SlowQuery(threshold time.Duration, hook SlowQueryHook) *SqlBuilder {
+	if threshold <= 0 {
+		panic("slow query threshold must be positive")
+	}
+	if hook == nil {
+		panic("slow query hook is nil")
+	}
+	b.slowQueryThreshold = threshold
+	b.slowQueryHook = hook
+	return b
+}
+
+// reportSlowQuery reports the execution started at the given time to the slow query hook, if it exceeded the threshold.
+func (b *SqlBuilder) reportSlowQuery(ctx context.Context, exec Executor, stmt string, args []any, start time.Time, err error) {
+	if b.slowQueryHook == nil {
+		return
+	}
+	duration := time.Since(start)
+	if duration < b.slowQueryThreshold {
+		return
+	}
+
+	report := SlowQueryReport{
+		Sql:      stmt,
+		Args:     args,
+		Duration: duration,
+		Err:      err,
+	}
+	report.Plan, report.PlanErr = b.explain(ctx, exec, stmt, args)
+	b.slowQueryHook(ctx, report)
+}
+
+// explain returns the plan of the statement, lines are joined by new line.
+func (b *SqlBuilder) explain(ctx context.Context, exec Executor, stmt string, args []any) (string, error) {
+	if b.dialect != DialectPostgres {
+		return "", errors.Errorf("EXPLAIN is not supported by dialect %s", b.dialect)
+	}
+
+	rows, err := exec.QueryContext(ctx, "EXPLAIN (ANALYZE off) "+stmt, args...)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to explain")
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var lines []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return "", errors.Wrap(err, "failed to scan the plan")
+		}
+		lines = append(lines, line)
+	}
+	if err := rows.Err(); err != nil {
+		return "", errors.Wrap(err, "failed to read the plan")
+	}
+	return strings.Join(lines, "\n"), nil
+}
@@ -0,0 +1,77 @@
+package sqlb
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// slowExecutor sleeps before executing the statements, answers EXPLAIN with the plan, for the sake of the test.
+type slowExecutor struct {
+	delay   time.Duration
+	plan    [][]any
+	queries []string
+}
+
+func (e *slowExecutor) QueryContext(_ context.Context, query string, _ ...any) (SqlRows, error) {
+	e.queries = append(e.queries, query)
+	if strings.HasPrefix(query, "EXPLAIN") {
+		return &valuesRows{rows: e.plan}, nil
+	}
+	time.Sleep(e.delay)
+	return &valuesRows{}, nil
+}
+
+func (e *slowExecutor) ExecContext(context.Context, string, ...any) (sql.Result, error) {
+	time.Sleep(e.delay)
+	return driverResult(1), nil
+}
+
+func TestSqlBuilder_SlowQuery(t *testing.T) {
+	table1 := UseTable[testStruct1]().Alias("t1").Seal()
+	newBuilder := func(reports *[]SlowQueryReport) *SqlBuilder {
+		return Select(table1.Col("pk1")).
+			From(table1).
+			Where(Eq(table1.Col("pk2"), 1)).
+			SlowQuery(10*time.Millisecond, func(_ context.Context, report SlowQueryReport) {
+				*reports = append(*reports, report)
+			})
+	}
+
+	t.Run("slow", func(t *testing.T) {
+		var reports []SlowQueryReport
+		exec := &slowExecutor{delay: 20 * time.Millisecond, plan: [][]any{{"Seq Scan on table1 t1"}, {"  Filter: (pk2 = $1)"}}}
+		_, err := newBuilder(&reports).QueryWithExecutor(context.Background(), exec)
+		require.NoError(t, err)
+
+		require.Len(t, reports, 1)
+		require.Equal(t, "SELECT t1.pk1\nFROM table1 AS t1\nWHERE t1.pk2 = $1\n", reports[0].Sql)
+		require.Equal(t, []any{1}, reports[0].Args)
+		require.GreaterOrEqual(t, reports[0].Duration, 20*time.Millisecond)
+		require.Equal(t, "Seq Scan on table1 t1\n  Filter: (pk2 = $1)", reports[0].Plan)
+		require.NoError(t, reports[0].PlanErr)
+		require.Equal(t, "EXPLAIN (ANALYZE off) "+reports[0].Sql, exec.queries[1])
+	})
+
+	t.Run("fast", func(t *testing.T) {
+		var reports []SlowQueryReport
+		exec := &slowExecutor{}
+		_, err := newBuilder(&reports).QueryWithExecutor(context.Background(), exec)
+		require.NoError(t, err)
+		require.Empty(t, reports)
+		require.Len(t, exec.queries, 1)
+	})
+
+	t.Run("dialect does not support EXPLAIN", func(t *testing.T) {
+		var reports []SlowQueryReport
+		exec := &slowExecutor{delay: 20 * time.Millisecond}
+		_, err := newBuilder(&reports).WithDialect(DialectSQLServer).QueryWithExecutor(context.Background(), exec)
+		require.NoError(t, err)
+		require.Len(t, reports, 1)
+		require.EqualError(t, reports[0].PlanErr, "EXPLAIN is not supported by dialect sqlserver")
+	})
+}
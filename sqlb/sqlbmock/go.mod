@@ -0,0 +1,19 @@
+module github.com/VictorTrustyDev/simple-go-sql-builder/sqlb/sqlbmock
+
+go 1.18.0
+
+require (
+	github.com/DATA-DOG/go-sqlmock v1.5.2
+	github.com/VictorTrustyDev/simple-go-sql-builder v0.0.0
+	github.com/pkg/errors v0.9.1
+	github.com/stretchr/testify v1.9.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/exp v0.0.0-20220426173459-3bcf042a4bf5 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/VictorTrustyDev/simple-go-sql-builder => ../..
@@ -0,0 +1,53 @@
+// Package sqlbmock integrates sqlb with go-sqlmock, converts the builders into the expectations
+// so the existing sqlmock-based suites can assert the statements generated by sqlb.
+package sqlbmock
+
+import (
+	"database/sql"
+	"database/sql/driver"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/pkg/errors"
+
+	"github.com/VictorTrustyDev/simple-go-sql-builder/sqlb"
+	"github.com/VictorTrustyDev/simple-go-sql-builder/sqlb/sqlbtest"
+)
+
+// QueryMatcher matches the statements ignoring whitespace differences and the numbering of positional placeholders,
+// see sqlbtest.NormalizeSQL. Provide via sqlmock.QueryMatcherOption, or use New.
+var QueryMatcher sqlmock.QueryMatcher = sqlmock.QueryMatcherFunc(func(expectedSQL, actualSQL string) error {
+	expected, actual := sqlbtest.NormalizeSQL(expectedSQL), sqlbtest.NormalizeSQL(actualSQL)
+	if expected != actual {
+		return errors.Errorf("actual sql: %q does not equal to expected %q", actual, expected)
+	}
+	return nil
+})
+
+// New creates the sqlmock database using QueryMatcher,
+// use sqlmock.New with sqlmock.QueryMatcherOption(QueryMatcher) when other options are needed.
+func New() (*sql.DB, sqlmock.Sqlmock, error) {
+	return sqlmock.New(sqlmock.QueryMatcherOption(QueryMatcher))
+}
+
+// ExpectBuilderQuery expects the SELECT statement of the builder with its arguments, returns the rows.
+// The mock must use QueryMatcher, see New.
+func ExpectBuilderQuery(mock sqlmock.Sqlmock, builder *sqlb.SqlBuilder, rows *sqlmock.Rows) *sqlmock.ExpectedQuery {
+	stmt, args := builder.Build()
+	return mock.ExpectQuery(stmt).WithArgs(toDriverArgs(args)...).WillReturnRows(rows)
+}
+
+// ExpectBuilderExec expects the INSERT statement of the builder with its arguments, returns the result,
+// e.g. sqlmock.NewResult(0, 1). The mock must use QueryMatcher, see New.
+func ExpectBuilderExec(mock sqlmock.Sqlmock, builder *sqlb.SqlBuilder, result driver.Result) *sqlmock.ExpectedExec {
+	stmt, args := builder.Build()
+	return mock.ExpectExec(stmt).WithArgs(toDriverArgs(args)...).WillReturnResult(result)
+}
+
+// toDriverArgs converts the arguments to the type WithArgs accepts.
+func toDriverArgs(args []any) []driver.Value {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg
+	}
+	return values
+}
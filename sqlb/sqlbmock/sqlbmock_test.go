@@ -0,0 +1,79 @@
+package sqlbmock
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/VictorTrustyDev/simple-go-sql-builder/sqlb"
+)
+
+type testAccount struct {
+	Id    int64
+	Owner string
+}
+
+var _ = sqlb.NewTableMetadata[testAccount]("accounts").
+	AddColumns(
+		sqlb.NewColumnMetadata[testAccount]("id").
+			PrimaryKey().
+			InsertSpec(func(a testAccount) any {
+				return a.Id
+			}).
+			SelectSpec(func(a *testAccount) sqlb.ResultColumnSelectSpec {
+				return sqlb.ResultColumnSelectSpec{
+					ToQueryArg: func() any {
+						return &a.Id
+					},
+				}
+			}),
+		sqlb.NewColumnMetadata[testAccount]("owner").
+			InsertSpec(func(a testAccount) any {
+				return a.Owner
+			}).
+			SelectSpec(func(a *testAccount) sqlb.ResultColumnSelectSpec {
+				return sqlb.ResultColumnSelectSpec{
+					ToQueryArg: func() any {
+						return &a.Owner
+					},
+				}
+			}),
+	).Build(sqlb.TableMetadataBuildOption{
+	ExpectedPkColumns: []string{"id"},
+})
+
+func TestExpectBuilder(t *testing.T) {
+	db, mock, err := New()
+	require.NoError(t, err)
+	defer func() {
+		_ = db.Close()
+	}()
+
+	accounts := sqlb.UseTable[testAccount]().Seal()
+	selectBuilder := func() *sqlb.SqlBuilder {
+		return sqlb.Select(accounts.Columns("id", "owner")...).From(accounts).Where(sqlb.Eq(accounts.Col("owner"), "alice"))
+	}
+	insertBuilder := func() *sqlb.SqlBuilder {
+		return sqlb.InsertInto(accounts).Values(testAccount{Id: 1, Owner: "bob"})
+	}
+
+	ExpectBuilderQuery(mock, selectBuilder(), sqlmock.NewRows([]string{"id", "owner"}).AddRow(int64(2), "alice"))
+	ExpectBuilderExec(mock, insertBuilder(), sqlmock.NewResult(0, 1))
+
+	rows, err := selectBuilder().QueryWithExecutor(context.Background(), sqlb.WrapExecutor(db))
+	require.NoError(t, err)
+	require.True(t, rows.Next())
+	require.Equal(t, testAccount{Id: 2, Owner: "alice"}, accounts.ReadFromRow(rows))
+
+	_, err = insertBuilder().ExecWithExecutor(context.Background(), sqlb.WrapExecutor(db))
+	require.NoError(t, err)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestQueryMatcher(t *testing.T) {
+	require.NoError(t, QueryMatcher.Match("SELECT a\nFROM t WHERE a = $1 AND b = $2", "SELECT a FROM t WHERE a = $3 AND b = $4"))
+	require.Error(t, QueryMatcher.Match("SELECT a FROM t", "SELECT b FROM t"))
+}
@@ -0,0 +1,73 @@
+// Package sqlbpgx integrates sqlb with pgx, queues the built statements into pgx.Batch
+// so they are executed in one round trip via the batch pipeline, and listens to the change notifications of the tables.
+package sqlbpgx
+
+import (
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/VictorTrustyDev/simple-go-sql-builder/sqlb"
+)
+
+// AppendToBatch queues the statement built by the builder into the batch.
+func AppendToBatch(batch *pgx.Batch, builder *sqlb.SqlBuilder) *pgx.QueuedQuery {
+	if batch == nil {
+		panic("batch is nil")
+	}
+	stmt, args := builder.Build()
+	return batch.Queue(stmt, args...)
+}
+
+// AppendAllToBatch queues all the statements of the sqlb.Batch into the pgx batch, each statement with its own arguments.
+func AppendAllToBatch(batch *pgx.Batch, statements *sqlb.Batch) []*pgx.QueuedQuery {
+	if batch == nil {
+		panic("batch is nil")
+	}
+	queued := make([]*pgx.QueuedQuery, 0, statements.Len())
+	for _, statement := range statements.Statements() {
+		queued = append(queued, batch.Queue(statement.Sql, statement.Args...))
+	}
+	return queued
+}
+
+// QueryInBatch queues the SELECT statement built by the builder into the batch,
+// fn receives the scanned rows when the batch results are read.
+func QueryInBatch(batch *pgx.Batch, builder *sqlb.SqlBuilder, fn func(rows *sqlb.ScannedRows) error) {
+	AppendToBatch(batch, builder).Query(func(rows pgx.Rows) error {
+		scanned, err := builder.ScanRows(sqlRows{rows: rows})
+		if err != nil {
+			return err
+		}
+		return fn(scanned)
+	})
+}
+
+// ExecInBatch queues the statement built by the builder into the batch,
+// fn receives the command tag when the batch results are read.
+func ExecInBatch(batch *pgx.Batch, builder *sqlb.SqlBuilder, fn func(ct pgconn.CommandTag) error) {
+	AppendToBatch(batch, builder).Exec(fn)
+}
+
+// sqlRows adapts pgx.Rows to sqlb.SqlRows.
+type sqlRows struct {
+	rows pgx.Rows
+}
+
+var _ sqlb.SqlRows = sqlRows{}
+
+func (r sqlRows) Next() bool {
+	return r.rows.Next()
+}
+
+func (r sqlRows) Scan(dest ...any) error {
+	return r.rows.Scan(dest...)
+}
+
+func (r sqlRows) Close() error {
+	r.rows.Close()
+	return r.rows.Err()
+}
+
+func (r sqlRows) Err() error {
+	return r.rows.Err()
+}
@@ -0,0 +1,113 @@
+package sqlbpgx
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/VictorTrustyDev/simple-go-sql-builder/sqlb"
+)
+
+type testItem struct {
+	Id    int64
+	Label string
+}
+
+var _ = sqlb.NewTableMetadata[testItem]("items").
+	AddColumns(
+		sqlb.NewColumnMetadata[testItem]("id").
+			PrimaryKey().
+			InsertSpec(func(i testItem) any {
+				return i.Id
+			}).
+			SelectSpec(func(i *testItem) sqlb.ResultColumnSelectSpec {
+				return sqlb.ResultColumnSelectSpec{
+					ToQueryArg: func() any {
+						return &i.Id
+					},
+				}
+			}),
+		sqlb.NewColumnMetadata[testItem]("label").
+			InsertSpec(func(i testItem) any {
+				return i.Label
+			}).
+			SelectSpec(func(i *testItem) sqlb.ResultColumnSelectSpec {
+				return sqlb.ResultColumnSelectSpec{
+					ToQueryArg: func() any {
+						return &i.Label
+					},
+				}
+			}),
+	).Build(sqlb.TableMetadataBuildOption{
+	ExpectedPkColumns: []string{"id"},
+})
+
+// fakeRows is a fake pgx.Rows returns the provided rows, for the sake of the test.
+type fakeRows struct {
+	pgx.Rows
+	rows   [][]any
+	idx    int
+	closed bool
+	err    error
+}
+
+func (r *fakeRows) Next() bool {
+	r.idx++
+	return r.idx <= len(r.rows)
+}
+
+func (r *fakeRows) Scan(dest ...any) error {
+	row := r.rows[r.idx-1]
+	*dest[0].(*int64) = row[0].(int64)
+	*dest[1].(*string) = row[1].(string)
+	return nil
+}
+
+func (r *fakeRows) Close() {
+	r.closed = true
+}
+
+func (r *fakeRows) Err() error {
+	return r.err
+}
+
+func TestAppendToBatch(t *testing.T) {
+	items := sqlb.UseTable[testItem]().Alias("i").Seal()
+
+	batch := &pgx.Batch{}
+	AppendToBatch(batch, sqlb.InsertInto(items).Values(testItem{Id: 1, Label: "a"}))
+	QueryInBatch(batch, sqlb.Select(items.Columns()...).From(items).Where(sqlb.Eq(items.Col("id"), int64(1))), func(*sqlb.ScannedRows) error {
+		return nil
+	})
+	AppendAllToBatch(batch, sqlb.NewBatch(
+		sqlb.InsertInto(items).Values(testItem{Id: 2, Label: "b"}),
+		sqlb.InsertInto(items).Values(testItem{Id: 3, Label: "c"}),
+	))
+
+	require.Equal(t, 4, batch.Len())
+	require.Equal(t, "INSERT INTO items (id, label)\nVALUES ($1,$2)", batch.QueuedQueries[0].SQL)
+	require.Equal(t, []any{int64(1), "a"}, batch.QueuedQueries[0].Arguments)
+	require.Equal(t, "SELECT i.id, i.label\nFROM items AS i\nWHERE i.id = $1\n", batch.QueuedQueries[1].SQL)
+	require.Equal(t, []any{int64(1)}, batch.QueuedQueries[1].Arguments)
+	require.Equal(t, []any{int64(3), "c"}, batch.QueuedQueries[3].Arguments)
+}
+
+func TestSqlRows(t *testing.T) {
+	items := sqlb.UseTable[testItem]().Alias("i").Seal()
+	builder := sqlb.Select(items.Columns()...).From(items)
+
+	rows := &fakeRows{
+		rows: [][]any{
+			{int64(1), "a"},
+			{int64(2), "b"},
+		},
+	}
+	scanned, err := builder.ScanRows(sqlRows{rows: rows})
+	require.NoError(t, err)
+	require.True(t, rows.closed)
+	require.Equal(t, []testItem{{Id: 1, Label: "a"}, {Id: 2, Label: "b"}}, items.ReadAllFromRows(scanned))
+
+	require.EqualError(t, sqlRows{rows: &fakeRows{err: errors.New("conn closed")}}.Close(), "conn closed")
+}
@@ -0,0 +1,23 @@
+module github.com/VictorTrustyDev/simple-go-sql-builder/sqlb/sqlbpgx
+
+go 1.18.0
+
+require (
+	github.com/VictorTrustyDev/simple-go-sql-builder v0.0.0
+	github.com/jackc/pgx/v5 v5.5.5
+	github.com/pkg/errors v0.9.1
+	github.com/stretchr/testify v1.9.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/jackc/pgpassfile v1.0.0 // indirect
+	github.com/jackc/pgservicefile v0.0.0-20221227161230-091c0ba34f0a // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/crypto v0.17.0 // indirect
+	golang.org/x/exp v0.0.0-20220426173459-3bcf042a4bf5 // indirect
+	golang.org/x/text v0.14.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/VictorTrustyDev/simple-go-sql-builder => ../..
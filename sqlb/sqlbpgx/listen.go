@@ -0,0 +1,50 @@
+package sqlbpgx
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+
+	"github.com/VictorTrustyDev/simple-go-sql-builder/sqlb"
+)
+
+// ListenTable listens to the change notifications of table T (see sqlb.NotificationChannel),
+// decodes the JSON payload into P and calls the handler, sent by sqlb.SqlBuilder.Notify.
+//
+// It blocks until the context is done or the handler returns error.
+// The connection is dedicated to listening during the call, acquire it from the pool and release after.
+func ListenTable[T any, P any](ctx context.Context, conn *pgx.Conn, handler func(payload P) error) error {
+	return Listen(ctx, conn, sqlb.NotificationChannel[T](), handler)
+}
+
+// Listen listens to the channel, decodes the JSON payload of the notifications into P and calls the handler.
+//
+// It blocks until the context is done or the handler returns error.
+func Listen[P any](ctx context.Context, conn *pgx.Conn, channel string, handler func(payload P) error) error {
+	if conn == nil {
+		panic("conn is nil")
+	}
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+		return errors.Wrapf(err, "failed to listen channel %s", channel)
+	}
+	defer func() {
+		_, _ = conn.Exec(context.Background(), "UNLISTEN "+pgx.Identifier{channel}.Sanitize())
+	}()
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return errors.Wrapf(err, "failed to wait for notification of channel %s", channel)
+		}
+
+		payload, err := sqlb.DecodeNotification[P](notification.Payload)
+		if err != nil {
+			return err
+		}
+		if err := handler(payload); err != nil {
+			return err
+		}
+	}
+}
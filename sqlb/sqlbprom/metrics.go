@@ -0,0 +1,69 @@
+// Package sqlbprom reports the executions of the sqlb statements to Prometheus,
+// counted and timed per operation, table and status.
+package sqlbprom
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/VictorTrustyDev/simple-go-sql-builder/sqlb"
+)
+
+const (
+	StatusSuccess = "success"
+	StatusFailure = "failure"
+)
+
+// Metrics implements sqlb.MetricsHook and prometheus.Collector, register it then provide to the builders via Metrics.
+type Metrics struct {
+	queries   *prometheus.CounterVec
+	durations *prometheus.HistogramVec
+}
+
+var _ sqlb.MetricsHook = (*Metrics)(nil)
+var _ prometheus.Collector = (*Metrics)(nil)
+
+// NewMetrics creates the metrics sqlb_queries_total and sqlb_query_duration_seconds under the namespace,
+// labeled by operation, table and status.
+func NewMetrics(namespace string) *Metrics {
+	labels := []string{"operation", "table", "status"}
+	return &Metrics{
+		queries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "sqlb",
+			Name:      "queries_total",
+			Help:      "Number of the executed statements.",
+		}, labels),
+		durations: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "sqlb",
+			Name:      "query_duration_seconds",
+			Help:      "Duration of the executed statements, including the retries.",
+			Buckets:   prometheus.DefBuckets,
+		}, labels),
+	}
+}
+
+// ObserveQuery implements sqlb.MetricsHook.
+func (m *Metrics) ObserveQuery(_ context.Context, observation sqlb.QueryObservation) {
+	status := StatusSuccess
+	if observation.Err != nil {
+		status = StatusFailure
+	}
+
+	m.queries.WithLabelValues(observation.Operation, observation.Table, status).Inc()
+	m.durations.WithLabelValues(observation.Operation, observation.Table, status).Observe(observation.Duration.Seconds())
+}
+
+// Describe implements prometheus.Collector.
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	m.queries.Describe(ch)
+	m.durations.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	m.queries.Collect(ch)
+	m.durations.Collect(ch)
+}
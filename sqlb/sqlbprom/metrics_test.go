@@ -0,0 +1,29 @@
+package sqlbprom
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/VictorTrustyDev/simple-go-sql-builder/sqlb"
+)
+
+func TestMetrics(t *testing.T) {
+	metrics := NewMetrics("app")
+	registry := prometheus.NewRegistry()
+	require.NoError(t, registry.Register(metrics))
+
+	ctx := context.Background()
+	metrics.ObserveQuery(ctx, sqlb.QueryObservation{Operation: "select", Table: "users", Duration: time.Millisecond})
+	metrics.ObserveQuery(ctx, sqlb.QueryObservation{Operation: "select", Table: "users", Duration: time.Millisecond})
+	metrics.ObserveQuery(ctx, sqlb.QueryObservation{Operation: "insert", Table: "users", Duration: time.Second, Err: errors.New("boom")})
+
+	require.Equal(t, 2.0, testutil.ToFloat64(metrics.queries.WithLabelValues("select", "users", StatusSuccess)))
+	require.Equal(t, 1.0, testutil.ToFloat64(metrics.queries.WithLabelValues("insert", "users", StatusFailure)))
+	require.Equal(t, 4, testutil.CollectAndCount(metrics))
+}
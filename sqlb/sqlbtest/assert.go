@@ -0,0 +1,112 @@
+package sqlbtest
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestingT is the subset of testing.TB required by the assertion helpers.
+type TestingT interface {
+	Errorf(format string, args ...any)
+}
+
+// AssertEqualSQL asserts that the two statements are equal, ignoring whitespace differences
+// and renumbering positional placeholders ($n) in order of appearance.
+func AssertEqualSQL(t TestingT, want, got string, msgAndArgs ...any) bool {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+	return assert.Equal(t, NormalizeSQL(want), NormalizeSQL(got), msgAndArgs...)
+}
+
+// NormalizeSQL returns the canonical form of the statement:
+//   - whitespaces are collapsed into a single space and trimmed.
+//   - whitespaces around parentheses, commas and semicolons are removed.
+//   - positional placeholders ($n) are renumbered in order of first appearance.
+//   - leading block comments are removed, e.g. the caller tags '/* file:N */' of SetCallerTagging.
+//
+// Quoted literals and identifiers are kept as is.
+func NormalizeSQL(stmt string) string {
+	stmt = trimLeadingComments(stmt)
+
+	sb := strings.Builder{}
+	sb.Grow(len(stmt))
+
+	renumbered := make(map[string]int)
+	isPunctuation := func(c byte) bool {
+		return c == '(' || c == ')' || c == ',' || c == ';'
+	}
+
+	var quote, last byte
+	pendingSpace := false
+	for i := 0; i < len(stmt); i++ {
+		c := stmt[i]
+
+		if quote != 0 {
+			sb.WriteByte(c)
+			last = c
+			if c == quote {
+				quote = 0
+			}
+			continue
+		}
+
+		switch c {
+		case ' ', '\t', '\n', '\r':
+			pendingSpace = true
+			continue
+		}
+
+		if pendingSpace && last != 0 && !isPunctuation(last) && !isPunctuation(c) {
+			sb.WriteByte(' ')
+		}
+		pendingSpace = false
+
+		if c == '$' && i+1 < len(stmt) && isDigit(stmt[i+1]) {
+			j := i + 1
+			for j < len(stmt) && isDigit(stmt[j]) {
+				j++
+			}
+			number := stmt[i+1 : j]
+			canonical, found := renumbered[number]
+			if !found {
+				canonical = len(renumbered) + 1
+				renumbered[number] = canonical
+			}
+			sb.WriteByte('$')
+			sb.WriteString(strconv.Itoa(canonical))
+			last = '0'
+			i = j - 1
+			continue
+		}
+
+		if c == '\'' || c == '"' {
+			quote = c
+		}
+		sb.WriteByte(c)
+		last = c
+	}
+
+	return sb.String()
+}
+
+// trimLeadingComments removes the block comments at the start of the statement.
+func trimLeadingComments(stmt string) string {
+	for {
+		trimmed := strings.TrimLeft(stmt, " \t\n\r")
+		if !strings.HasPrefix(trimmed, "/*") {
+			return stmt
+		}
+		end := strings.Index(trimmed, "*/")
+		if end < 0 {
+			return stmt
+		}
+		stmt = trimmed[end+2:]
+	}
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
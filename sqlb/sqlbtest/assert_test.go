@@ -0,0 +1,67 @@
+package sqlbtest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type recordingT struct {
+	errors []string
+}
+
+func (r *recordingT) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestNormalizeSQL(t *testing.T) {
+	tests := []struct {
+		stmt string
+		want string
+	}{
+		{
+			stmt: "SELECT a,  b\nFROM t\n",
+			want: "SELECT a,b FROM t",
+		},
+		{
+			stmt: "SELECT EXISTS( SELECT 1 FROM t WHERE a = $1 )",
+			want: "SELECT EXISTS(SELECT 1 FROM t WHERE a = $1)",
+		},
+		{
+			stmt: "VALUES ($3, $1), ($3,$2)",
+			want: "VALUES($1,$2),($1,$3)",
+		},
+		{
+			stmt: "WHERE a = '  $1  ' AND b = $10",
+			want: "WHERE a = '  $1  ' AND b = $1",
+		},
+		{
+			stmt: "/* orders/repository.go:42 */ SELECT a FROM t WHERE b = '/* c */'",
+			want: "SELECT a FROM t WHERE b = '/* c */'",
+		},
+		{
+			stmt: "/* a */\n/* b */ SELECT a FROM t",
+			want: "SELECT a FROM t",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.stmt, func(t *testing.T) {
+			require.Equal(t, tt.want, NormalizeSQL(tt.stmt))
+		})
+	}
+}
+
+func TestAssertEqualSQL(t *testing.T) {
+	t.Run("equal", func(t *testing.T) {
+		rt := &recordingT{}
+		require.True(t, AssertEqualSQL(rt, "SELECT a, b\nFROM t\nWHERE a = $2", "SELECT a,b FROM t WHERE a = $1"))
+		require.Empty(t, rt.errors)
+	})
+
+	t.Run("not equal", func(t *testing.T) {
+		rt := &recordingT{}
+		require.False(t, AssertEqualSQL(rt, "SELECT a FROM t", "SELECT b FROM t"))
+		require.Len(t, rt.errors, 1)
+	})
+}
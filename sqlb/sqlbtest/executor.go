@@ -0,0 +1,158 @@
+// Package sqlbtest provides helpers for unit testing code built on top of sqlb, without a real database.
+package sqlbtest
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/VictorTrustyDev/simple-go-sql-builder/sqlb"
+)
+
+// Statement is a statement received by the Executor.
+type Statement struct {
+	Sql  string
+	Args []any
+}
+
+// Executor is a fake sqlb.Executor which records the executed statements
+// and responds with the primed results, in order.
+//
+// When nothing was primed, queries return no rows and executions affect no rows.
+type Executor struct {
+	mu         sync.Mutex
+	statements []Statement
+	responses  []response
+}
+
+type response struct {
+	rows   *Rows
+	result sql.Result
+	err    error
+}
+
+var _ sqlb.Executor = (*Executor)(nil)
+
+// NewExecutor returns a new fake executor.
+func NewExecutor() *Executor {
+	return &Executor{}
+}
+
+// PrimeRows enqueues the rows to be returned by the next query.
+// Each row contains values of the selected columns, in order.
+func (e *Executor) PrimeRows(rows ...[]any) *Executor {
+	return e.prime(response{rows: NewRows(rows...)})
+}
+
+// PrimeRowsError enqueues the rows to be returned by the next query, which fail after the rows are iterated:
+// Next returns false and Err returns the error, e.g. the connection is lost while reading the result.
+func (e *Executor) PrimeRowsError(err error, rows ...[]any) *Executor {
+	if err == nil {
+		panic("error is nil")
+	}
+	return e.prime(response{rows: NewRows(rows...).WithErr(err)})
+}
+
+// PrimeResult enqueues the result to be returned by the next execution.
+func (e *Executor) PrimeResult(lastInsertId, rowsAffected int64) *Executor {
+	return e.prime(response{result: Result{
+		LastInsertIdValue: lastInsertId,
+		RowsAffectedValue: rowsAffected,
+	}})
+}
+
+// PrimeError enqueues the error to be returned by the next query or execution.
+func (e *Executor) PrimeError(err error) *Executor {
+	if err == nil {
+		panic("error is nil")
+	}
+	return e.prime(response{err: err})
+}
+
+func (e *Executor) prime(r response) *Executor {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.responses = append(e.responses, r)
+	return e
+}
+
+// Statements returns the recorded statements, in execution order.
+func (e *Executor) Statements() []Statement {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	clone := make([]Statement, len(e.statements))
+	copy(clone, e.statements)
+	return clone
+}
+
+// Reset clears the recorded statements and the primed responses.
+func (e *Executor) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.statements = nil
+	e.responses = nil
+}
+
+func (e *Executor) QueryContext(_ context.Context, query string, args ...any) (sqlb.SqlRows, error) {
+	r, found := e.record(query, args)
+	if !found {
+		return NewRows(), nil
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	if r.rows == nil {
+		return nil, errors.New("primed response is not rows")
+	}
+	return r.rows, nil
+}
+
+func (e *Executor) ExecContext(_ context.Context, query string, args ...any) (sql.Result, error) {
+	r, found := e.record(query, args)
+	if !found {
+		return Result{}, nil
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	if r.result == nil {
+		return nil, errors.New("primed response is not result")
+	}
+	return r.result, nil
+}
+
+// record records the statement and pops the next primed response.
+func (e *Executor) record(query string, args []any) (r response, found bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.statements = append(e.statements, Statement{
+		Sql:  query,
+		Args: args,
+	})
+
+	if len(e.responses) == 0 {
+		return response{}, false
+	}
+	r = e.responses[0]
+	e.responses = e.responses[1:]
+	return r, true
+}
+
+// Result is a fake sql.Result.
+type Result struct {
+	LastInsertIdValue int64
+	RowsAffectedValue int64
+}
+
+var _ sql.Result = Result{}
+
+func (r Result) LastInsertId() (int64, error) {
+	return r.LastInsertIdValue, nil
+}
+
+func (r Result) RowsAffected() (int64, error) {
+	return r.RowsAffectedValue, nil
+}
@@ -0,0 +1,150 @@
+package sqlbtest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/VictorTrustyDev/simple-go-sql-builder/sqlb"
+)
+
+type testAccount struct {
+	Id      int64
+	Owner   string
+	Balance int64
+}
+
+var _ = sqlb.NewTableMetadata[testAccount]("accounts").
+	AddColumns(
+		sqlb.NewColumnMetadata[testAccount]("id").
+			PrimaryKey().
+			InsertSpec(func(a testAccount) any {
+				return a.Id
+			}).
+			SelectSpec(func(a *testAccount) sqlb.ResultColumnSelectSpec {
+				return sqlb.ResultColumnSelectSpec{
+					ToQueryArg: func() any {
+						return &a.Id
+					},
+				}
+			}),
+		sqlb.NewColumnMetadata[testAccount]("owner").
+			InsertSpec(func(a testAccount) any {
+				return a.Owner
+			}).
+			SelectSpec(func(a *testAccount) sqlb.ResultColumnSelectSpec {
+				return sqlb.ResultColumnSelectSpec{
+					ToQueryArg: func() any {
+						return &a.Owner
+					},
+				}
+			}),
+		sqlb.NewColumnMetadata[testAccount]("balance").
+			InsertSpec(func(a testAccount) any {
+				return a.Balance
+			}).
+			SelectSpec(func(a *testAccount) sqlb.ResultColumnSelectSpec {
+				return sqlb.ResultColumnSelectSpec{
+					ToQueryArg: func() any {
+						return &a.Balance
+					},
+				}
+			}),
+	).Build(sqlb.TableMetadataBuildOption{
+	ExpectedPkColumns: []string{"id"},
+})
+
+func TestExecutor(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("query returns primed rows and records statement", func(t *testing.T) {
+		exec := NewExecutor().PrimeRows(
+			[]any{int64(1), "alice", int64(100)},
+			[]any{int64(2), []byte("bob"), 200},
+		)
+
+		accounts := sqlb.UseTable[testAccount]().Seal()
+		rows, err := sqlb.Select(accounts.Columns()...).
+			From(accounts).
+			Where(accounts.Col("balance"), "> $1").Args(50).
+			QueryWithExecutor(ctx, exec)
+		require.NoError(t, err)
+		require.Equal(t, []testAccount{
+			{Id: 1, Owner: "alice", Balance: 100},
+			{Id: 2, Owner: "bob", Balance: 200},
+		}, accounts.ReadAllFromRows(rows))
+
+		statements := exec.Statements()
+		require.Len(t, statements, 1)
+		require.Equal(t, "SELECT accounts.id, accounts.owner, accounts.balance\nFROM accounts AS accounts\nWHERE accounts.balance > $1\n", statements[0].Sql)
+		require.Equal(t, []any{50}, statements[0].Args)
+	})
+
+	t.Run("query without primed rows returns no rows", func(t *testing.T) {
+		exec := NewExecutor()
+
+		accounts := sqlb.UseTable[testAccount]().Seal()
+		rows, err := sqlb.Select(accounts.Columns()...).From(accounts).QueryWithExecutor(ctx, exec)
+		require.NoError(t, err)
+		require.Zero(t, rows.Count())
+	})
+
+	t.Run("count and exists", func(t *testing.T) {
+		exec := NewExecutor().PrimeRows([]any{int64(3)}).PrimeRows([]any{true})
+
+		accounts := sqlb.UseTable[testAccount]().Seal()
+		count, err := sqlb.SelectCount().From(accounts).QueryCountWithExecutor(ctx, exec)
+		require.NoError(t, err)
+		require.Equal(t, int64(3), count)
+
+		exists, err := sqlb.SelectExists().From(accounts).Where(sqlb.Eq(accounts.Col("owner"), "alice")).QueryExistsWithExecutor(ctx, exec)
+		require.NoError(t, err)
+		require.True(t, exists)
+
+		require.Len(t, exec.Statements(), 2)
+		require.Equal(t, []any{"alice"}, exec.Statements()[1].Args, "auto-allocated args must be passed to the executor")
+	})
+
+	t.Run("exec returns primed result", func(t *testing.T) {
+		exec := NewExecutor().PrimeResult(0, 2)
+
+		accounts := sqlb.UseTable[testAccount]().Seal()
+		result, err := sqlb.InsertInto(accounts).
+			Values(accounts.ValuesToAny([]testAccount{{Id: 1}, {Id: 2}})...).
+			ExecWithExecutor(ctx, exec)
+		require.NoError(t, err)
+
+		affected, err := result.RowsAffected()
+		require.NoError(t, err)
+		require.Equal(t, int64(2), affected)
+
+		statements := exec.Statements()
+		require.Len(t, statements, 1)
+		require.Equal(t, []any{int64(1), "", int64(0), int64(2), "", int64(0)}, statements[0].Args)
+	})
+
+	t.Run("primed error", func(t *testing.T) {
+		exec := NewExecutor().PrimeError(errors.New("boom"))
+
+		accounts := sqlb.UseTable[testAccount]().Seal()
+		_, err := sqlb.Select(accounts.Columns()...).From(accounts).QueryWithExecutor(ctx, exec)
+		require.EqualError(t, err, "boom")
+	})
+
+	t.Run("primed rows error", func(t *testing.T) {
+		exec := NewExecutor().PrimeRowsError(errors.New("connection lost"), []any{int64(1), "alice", int64(100)})
+
+		accounts := sqlb.UseTable[testAccount]().Seal()
+		_, err := sqlb.Select(accounts.Columns()...).From(accounts).QueryWithExecutor(ctx, exec)
+		require.EqualError(t, err, "failed to iterate rows: connection lost")
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		exec := NewExecutor().PrimeResult(0, 1)
+		_, _ = exec.ExecContext(ctx, "SELECT 1")
+		exec.Reset()
+		require.Empty(t, exec.Statements())
+	})
+}
@@ -0,0 +1,75 @@
+package sqlbtest
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/VictorTrustyDev/simple-go-sql-builder/sqlb"
+)
+
+// updateGolden rewrites the golden files instead of comparing, e.g. go test ./... -sqlbtest.update
+var updateGolden = flag.Bool("sqlbtest.update", false, "update the golden files of sqlbtest.Golden")
+
+// GoldenDir is the directory of the golden files, relative to the package of the test.
+var GoldenDir = "testdata"
+
+// GoldenT is the subset of testing.TB required by Golden.
+type GoldenT interface {
+	TestingT
+	Name() string
+	Fatalf(format string, args ...any)
+}
+
+// Golden renders the statement and the arguments of the builder, compares with the golden file named after the test,
+// e.g. testdata/TestRepository_List__by_status.golden for the sub-test "by status" of TestRepository_List.
+// Run the test with -sqlbtest.update to create or update the golden files, then review and check them in.
+//
+// One golden file per test, use sub-tests to lock in multiple statements.
+func Golden(t GoldenT, builder *sqlb.SqlBuilder) bool {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+
+	stmt, args := builder.Build()
+	got := renderGolden(stmt, args)
+	path := filepath.Join(GoldenDir, goldenFileName(t.Name()))
+
+	if *updateGolden {
+		if err := os.MkdirAll(GoldenDir, 0o755); err != nil {
+			t.Fatalf("failed to create golden directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("failed to write golden file: %v", err)
+		}
+		return true
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file, run with -sqlbtest.update to create it: %v", err)
+		return false
+	}
+	return assert.Equal(t, string(want), got, "generated SQL differs from golden file %s, run with -sqlbtest.update to update it", path)
+}
+
+// renderGolden renders the statement followed by the arguments, one per line with the type.
+func renderGolden(stmt string, args []any) string {
+	sb := strings.Builder{}
+	sb.WriteString("-- sql --\n")
+	sb.WriteString(strings.TrimRight(stmt, "\n"))
+	sb.WriteString("\n-- args --\n")
+	for i, arg := range args {
+		sb.WriteString(fmt.Sprintf("%d: %T %#v\n", i+1, arg, arg))
+	}
+	return sb.String()
+}
+
+// goldenFileName returns the file name of the test, sub-tests are separated by double underscores.
+func goldenFileName(testName string) string {
+	return strings.NewReplacer("/", "__", " ", "_", ":", "_").Replace(testName) + ".golden"
+}
@@ -0,0 +1,64 @@
+package sqlbtest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/VictorTrustyDev/simple-go-sql-builder/sqlb"
+)
+
+// goldenT records the failures of Golden, for the sake of the test.
+type goldenT struct {
+	recordingT
+	name  string
+	fatal []string
+}
+
+func (g *goldenT) Name() string {
+	return g.name
+}
+
+func (g *goldenT) Fatalf(format string, args ...any) {
+	g.fatal = append(g.fatal, fmt.Sprintf(format, args...))
+}
+
+func TestGolden(t *testing.T) {
+	table := sqlb.UseTable[testAccount]().Seal()
+	builder := sqlb.Select(table.Col("id")).From(table).Where(sqlb.Eq(table.Col("id"), int64(1)))
+
+	t.Run("match", func(t *testing.T) {
+		require.True(t, Golden(t, builder))
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		gt := &goldenT{name: "TestGolden/match"}
+		require.False(t, Golden(gt, sqlb.Select(table.Col("id")).From(table)))
+		require.Len(t, gt.errors, 1)
+		require.Contains(t, gt.errors[0], "generated SQL differs from golden file testdata/TestGolden__match.golden")
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		gt := &goldenT{name: "TestGolden/missing"}
+		require.False(t, Golden(gt, builder))
+		require.Len(t, gt.fatal, 1)
+		require.Contains(t, gt.fatal[0], "run with -sqlbtest.update to create it")
+	})
+
+	t.Run("update", func(t *testing.T) {
+		GoldenDir = t.TempDir()
+		*updateGolden = true
+		defer func() {
+			GoldenDir = "testdata"
+			*updateGolden = false
+		}()
+
+		require.True(t, Golden(t, builder))
+		written, err := os.ReadFile(filepath.Join(GoldenDir, "TestGolden__update.golden"))
+		require.NoError(t, err)
+		require.Equal(t, "-- sql --\nSELECT accounts.id\nFROM accounts AS accounts\nWHERE accounts.id = $1\n-- args --\n1: int64 1\n", string(written))
+	})
+}
@@ -0,0 +1,139 @@
+package sqlbtest
+
+import (
+	"database/sql"
+	"reflect"
+
+	"github.com/pkg/errors"
+
+	"github.com/VictorTrustyDev/simple-go-sql-builder/sqlb"
+)
+
+// Rows is a fake sqlb.SqlRows which returns the provided values.
+type Rows struct {
+	rows    [][]any
+	rowIdx  int
+	anyNext bool
+	closed  bool
+	err     error // err is returned by Err after the rows are iterated
+}
+
+var _ sqlb.SqlRows = (*Rows)(nil)
+
+// NewRows returns fake rows. Each row contains values of the selected columns, in order.
+func NewRows(rows ...[]any) *Rows {
+	return &Rows{
+		rows: rows,
+	}
+}
+
+func (r *Rows) Next() bool {
+	if r.closed {
+		return false
+	}
+	if r.anyNext {
+		r.rowIdx++
+	} else {
+		r.anyNext = true
+		r.rowIdx = 0
+	}
+	return r.rowIdx < len(r.rows)
+}
+
+func (r *Rows) Scan(dest ...any) error {
+	if !r.anyNext || r.rowIdx >= len(r.rows) {
+		return errors.New("no row to scan, require calls Next() first")
+	}
+
+	row := r.rows[r.rowIdx]
+	if len(row) != len(dest) {
+		return errors.Errorf("expected %d destination arguments in Scan, got %d", len(row), len(dest))
+	}
+
+	for i, d := range dest {
+		if err := assign(d, row[i]); err != nil {
+			return errors.Wrapf(err, "failed to scan column index %d", i)
+		}
+	}
+
+	return nil
+}
+
+func (r *Rows) Close() error {
+	r.closed = true
+	return nil
+}
+
+// Err returns the error provided by WithErr once all rows are iterated, simulating a failure during the iteration.
+func (r *Rows) Err() error {
+	if r.anyNext && r.rowIdx >= len(r.rows) {
+		return r.err
+	}
+	return nil
+}
+
+// WithErr makes the rows fail after the provided rows are iterated, Next returns false and Err returns the error.
+func (r *Rows) WithErr(err error) *Rows {
+	r.err = err
+	return r
+}
+
+// Closed returns true if the rows were closed.
+func (r *Rows) Closed() bool {
+	return r.closed
+}
+
+// assign copies the value into the destination pointer, converting when possible.
+func assign(dest, value any) error {
+	if scanner, ok := dest.(sql.Scanner); ok {
+		return scanner.Scan(value)
+	}
+
+	dv := reflect.ValueOf(dest)
+	if dv.Kind() != reflect.Ptr || dv.IsNil() {
+		return errors.Errorf("destination must be a non-nil pointer, got %T", dest)
+	}
+	dv = dv.Elem()
+
+	if value == nil {
+		switch dv.Kind() {
+		case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map:
+			dv.Set(reflect.Zero(dv.Type()))
+			return nil
+		default:
+			return errors.Errorf("cannot assign NULL to %s", dv.Type())
+		}
+	}
+
+	vv := reflect.ValueOf(value)
+	if dv.Kind() == reflect.Ptr {
+		ptr := reflect.New(dv.Type().Elem())
+		if err := assign(ptr.Interface(), value); err != nil {
+			return err
+		}
+		dv.Set(ptr)
+		return nil
+	}
+	if vv.Type().AssignableTo(dv.Type()) {
+		dv.Set(vv)
+		return nil
+	}
+	if vv.Type().ConvertibleTo(dv.Type()) && vv.Kind() != reflect.String && dv.Kind() != reflect.String {
+		dv.Set(vv.Convert(dv.Type()))
+		return nil
+	}
+	if b, ok := value.([]byte); ok && dv.Kind() == reflect.String {
+		dv.SetString(string(b))
+		return nil
+	}
+	if s, ok := value.(string); ok && dv.Kind() == reflect.Slice && dv.Type().Elem().Kind() == reflect.Uint8 {
+		dv.SetBytes([]byte(s))
+		return nil
+	}
+	if vv.Kind() == reflect.String && dv.Kind() == reflect.String {
+		dv.SetString(vv.String())
+		return nil
+	}
+
+	return errors.Errorf("cannot assign %T to %s", value, dv.Type())
+}
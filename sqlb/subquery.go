@@ -0,0 +1,51 @@
+package sqlb
+
+import (
+	"fmt"
+	"strings"
+)
+
+// subquery is a token represents a SELECT statement embedded in another statement.
+type subquery struct {
+	b *SqlBuilder
+}
+
+// Exists generates 'EXISTS (SELECT 1 FROM ...)' of the SELECT EXISTS builder, usable in WHERE of another builder.
+//
+// The subquery can be correlated by referencing columns of the outer query in its WHERE,
+// the tables of the subquery must use aliases different from the ones of the outer query.
+// Arguments of the subquery are re-numbered to follow the arguments of the outer query.
+func Exists(sub *SqlBuilder) Expr {
+	mustExistsSubquery(sub)
+	return concatExpr("EXISTS (", subquery{b: sub}, ")")
+}
+
+// NotExists generates 'NOT EXISTS (SELECT 1 FROM ...)', see Exists.
+func NotExists(sub *SqlBuilder) Expr {
+	mustExistsSubquery(sub)
+	return concatExpr("NOT EXISTS (", subquery{b: sub}, ")")
+}
+
+func mustExistsSubquery(sub *SqlBuilder) {
+	if sub == nil {
+		panic("subquery is nil")
+	}
+	sub.mustSelectExists()
+}
+
+// writeSubquery writes the SELECT statement of the subquery in a single line,
+// the placeholders of the subquery are shifted by the number of arguments already collected.
+func (c *buildContext) writeSubquery(sub *SqlBuilder, clause string) {
+	if sub.dialect != c.dialect {
+		panic(fmt.Sprintf("subquery in %s must use the same dialect %s, got %s", clause, c.dialect, sub.dialect))
+	}
+
+	sb := strings.Builder{}
+	args := sub.writeSelect(&sb, c)
+
+	offset := len(c.args)
+	c.writeString(renamePlaceholders(normalizeSqlSpaces(sb.String()), c.dialect, len(args), func(n int) string {
+		return c.placeholder(offset + n)
+	}))
+	c.args = append(c.args, args...)
+}
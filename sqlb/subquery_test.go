@@ -0,0 +1,123 @@
+package sqlb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectExists_Join(t *testing.T) {
+	t.Run("exists with join", func(t *testing.T) {
+		table1 := UseTable[testStruct1]().Alias("t1").Seal()
+		table2 := UseTable[testStruct2]().Alias("t2").Seal()
+		gotSql, gotArgs := SelectExists().
+			From(table1).
+			Join(InnerJoin, table2, table1.Col("pk1"), table2.Col("pk1")).
+			Where(Eq(table2.Col("pk3"), "x")).
+			Build()
+		require.Equal(t, "SELECT EXISTS(SELECT 1 FROM table1 AS t1\nINNER JOIN table2 AS t2 ON t1.pk1 = t2.pk1\nWHERE t2.pk3 = $1\n)", gotSql)
+		require.Equal(t, []any{"x"}, gotArgs)
+	})
+
+	t.Run("exists with self join", func(t *testing.T) {
+		parent := UseTable[testStruct1]().Alias("p").Seal()
+		child := UseTable[testStruct1]().Alias("c").Seal()
+		gotSql, _ := SelectExists().
+			From(parent).
+			Join(InnerJoin, child, parent.Col("pk1"), child.Col("pk1")).
+			Build()
+		require.Equal(t, "SELECT EXISTS(SELECT 1 FROM table1 AS p\nINNER JOIN table1 AS c ON p.pk1 = c.pk1\n)", gotSql)
+	})
+}
+
+func TestExists(t *testing.T) {
+	t.Run("correlated subquery, args are re-numbered", func(t *testing.T) {
+		table1 := UseTable[testStruct1]().Alias("t1").Seal()
+		table2 := UseTable[testStruct2]().Alias("t2").Seal()
+
+		sub := SelectExists().
+			From(table2).
+			Where(table2.Col("pk1"), "=", table1.Col("pk1")).
+			And(table2.Col("pk3"), "= $1").Args("x").
+			And(Gt(table2.Col("pk2"), 3))
+		gotSql, gotArgs := Select(table1.Col("pk1")).
+			From(table1).
+			Where(table1.Col("pk2"), "= $1").Args(5).
+			And(NotExists(sub)).
+			And(Eq(table1.Col("amount"), 10)).
+			Build()
+		require.Equal(t, "SELECT t1.pk1\nFROM table1 AS t1\nWHERE t1.pk2 = $1 AND NOT EXISTS (SELECT 1 FROM table2 AS t2 WHERE t2.pk1 = t1.pk1 AND t2.pk3 = $2 AND t2.pk2 > $3) AND t1.amount = $4\n", gotSql)
+		require.Equal(t, []any{5, "x", 3, 10}, gotArgs)
+	})
+
+	t.Run("question mark placeholders", func(t *testing.T) {
+		table1 := UseTable[testStruct1]().Alias("t1").Seal()
+		table2 := UseTable[testStruct2]().Alias("t2").Seal()
+
+		sub := SelectExists().From(table2).Where(Eq(table2.Col("pk1"), "a")).WithDialect(DialectMySQL)
+		gotSql, gotArgs := Select(table1.Col("pk1")).
+			From(table1).
+			Where(Exists(sub)).
+			WithDialect(DialectMySQL).
+			Build()
+		require.Equal(t, "SELECT t1.pk1\nFROM table1 AS t1\nWHERE EXISTS (SELECT 1 FROM table2 AS t2 WHERE t2.pk1 = ?)\n", gotSql)
+		require.Equal(t, []any{"a"}, gotArgs)
+	})
+
+	t.Run("invalid usages", func(t *testing.T) {
+		table1 := UseTable[testStruct1]().Alias("t1").Seal()
+		table2 := UseTable[testStruct2]().Alias("t2").Seal()
+
+		require.Panics(t, func() {
+			Exists(Select(table2.Col("pk1")).From(table2))
+		}, "only SELECT EXISTS builder is supported")
+		require.Panics(t, func() {
+			sub := SelectExists().From(table2).WithDialect(DialectMySQL)
+			Select(table1.Col("pk1")).From(table1).Where(Exists(sub)).Build()
+		}, "dialect must be the same")
+	})
+}
+
+func TestSqlBuilder_WhereNotExists(t *testing.T) {
+	products := UseTable[testDdlRow]().Seal()
+	existing := UseTable[testDdlRow]().Alias("e").Seal()
+	newSub := func() *SqlBuilder {
+		return SelectExists().From(existing).Where(Eq(existing.Col("title"), "book"))
+	}
+
+	t.Run("postgres", func(t *testing.T) {
+		gotSql, gotArgs := InsertInto(products, products.Col("id"), products.Col("title")).
+			Values(testDdlRow{Id: 1, Title: "book"}).
+			WhereNotExists(newSub()).
+			Returning(products.Col("id")).
+			Build()
+		require.Equal(t, "INSERT INTO products (id, title)\nSELECT $1::BIGINT,$2::TEXT\nWHERE NOT EXISTS (SELECT 1 FROM products AS e WHERE e.title = $3)\nRETURNING id", gotSql)
+		require.Equal(t, []any{int64(1), "book", "book"}, gotArgs)
+	})
+
+	t.Run("mysql", func(t *testing.T) {
+		gotSql, gotArgs := InsertInto(products, products.Col("id"), products.Col("title")).
+			Values(testDdlRow{Id: 1, Title: "book"}).
+			WhereNotExists(newSub().WithDialect(DialectMySQL)).
+			WithDialect(DialectMySQL).
+			Build()
+		require.Equal(t, "INSERT INTO products (id, title)\nSELECT ?,? FROM DUAL\nWHERE NOT EXISTS (SELECT 1 FROM products AS e WHERE e.title = ?)", gotSql)
+		require.Equal(t, []any{int64(1), "book", "book"}, gotArgs)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		require.PanicsWithValue(t, "INSERT WHERE NOT EXISTS requires exactly one row, got 2", func() {
+			InsertInto(products).Values(testDdlRow{}, testDdlRow{}).WhereNotExists(newSub())
+		})
+		require.Panics(t, func() {
+			InsertInto(products).Values(testDdlRow{}).WhereNotExists(Select(existing.Col("id")).From(existing))
+		})
+		require.Panics(t, func() {
+			InsertInto(products).Values(testDdlRow{}).WhereNotExists(newSub()).OnConflict(products.Col("id"))
+		})
+		require.PanicsWithValue(t, "column pk1 has no SQL type, required to cast the value of INSERT WHERE NOT EXISTS", func() {
+			table1 := UseTable[testStruct1]().Seal()
+			InsertInto(table1, table1.Col("pk1")).Values(testStruct1{}).WhereNotExists(newSub())
+		})
+	})
+}
@@ -17,11 +17,39 @@ var (
 )
 
 type TableMetadata[T any] struct {
-	name          string
-	columns       []ColumnMetadata[T]
-	columnsByName map[string]ColumnMetadata[T]
+	name              string
+	columns           []ColumnMetadata[T]
+	columnsByName     map[string]ColumnMetadata[T]
+	partitionResolver PartitionResolver // optional
+	uniques           []UniqueConstraint
+	checks            []CheckConstraint
+	foreignKeys       []ForeignKey
+	insertSpecs       *insertSpecCache // insertSpecs caches the insert specs by the columns, shared by the copies
+	materializedView  bool             // materializedView indicates the table is a materialized view, read-only
 }
 
+// insertSpecCache caches the insert specs of the tables by the inserted columns, so they are built once per table.
+type insertSpecCache struct {
+	mu    sync.RWMutex
+	specs map[string]insertRecordSpec
+}
+
+// rowSelectSpec creates a new row and appends the select specs of the columns bound to the row to specs,
+// in order of the columns. valueFunc returns the row.
+type rowSelectSpec func(specs []ResultColumnSelectSpec) (valueFunc func() any, _ []ResultColumnSelectSpec)
+
+// insertRecordSpec appends the insert values of the columns of the record to values, in order of the columns.
+type insertRecordSpec func(record any, values []any) []any
+
+// ForeignKey is the reference from the column to the column of another table, declared via TableMetadataBuilder.ForeignKey.
+type ForeignKey struct {
+	Column           string
+	ReferencedTable  string
+	ReferencedColumn string
+}
+
+// GetTableMetadata returns the metadata of the table registered first for type T.
+// Use GetTableMetadataByName for the other tables registered for the same type.
 func GetTableMetadata[T any]() TableMetadata[T] {
 	typeName := getStructTypeName(new(T))
 	if name, found := registeredTableTypeToName[typeName]; found {
@@ -30,6 +58,20 @@ func GetTableMetadata[T any]() TableMetadata[T] {
 	panic(fmt.Sprintf("table for type %s is not registered", typeName))
 }
 
+// GetTableMetadataByName returns the metadata of the table registered by name for type T,
+// eg: the history table variant shares the struct type with the live table.
+func GetTableMetadataByName[T any](name string) TableMetadata[T] {
+	registered, found := registeredTables[name]
+	if !found {
+		panic(fmt.Sprintf("table %s is not registered", name))
+	}
+	metadata, ok := registered.(TableMetadata[T])
+	if !ok {
+		panic(fmt.Sprintf("table %s is not registered for type %s", name, getStructTypeName(new(T))))
+	}
+	return metadata
+}
+
 func GetRegisteredTablesName() []string {
 	return maps.Keys(registeredTables)
 }
@@ -38,6 +80,37 @@ func (t TableMetadata[T]) Name() string {
 	return t.name
 }
 
+// IsMaterializedView returns true if the table is a materialized view, see TableMetadataBuildOption.MaterializedView.
+func (t TableMetadata[T]) IsMaterializedView() bool {
+	return t.materializedView
+}
+
+// PartitionResolver returns the partition resolver of the table, nil if the table is not partitioned.
+func (t TableMetadata[T]) PartitionResolver() PartitionResolver {
+	return t.partitionResolver
+}
+
+// UniqueConstraints returns the declared unique constraints of the table.
+func (t TableMetadata[T]) UniqueConstraints() []UniqueConstraint {
+	clone := make([]UniqueConstraint, len(t.uniques))
+	copy(clone, t.uniques)
+	return clone
+}
+
+// CheckConstraints returns the declared check constraints of the table.
+func (t TableMetadata[T]) CheckConstraints() []CheckConstraint {
+	clone := make([]CheckConstraint, len(t.checks))
+	copy(clone, t.checks)
+	return clone
+}
+
+// ForeignKeys returns the declared foreign keys of the table.
+func (t TableMetadata[T]) ForeignKeys() []ForeignKey {
+	clone := make([]ForeignKey, len(t.foreignKeys))
+	copy(clone, t.foreignKeys)
+	return clone
+}
+
 func (t TableMetadata[T]) Columns() []ColumnMetadata[T] {
 	clone := make([]ColumnMetadata[T], len(t.columns))
 	copy(clone, t.columns)
@@ -62,11 +135,23 @@ func (t TableMetadata[T]) ColumnsName() []string {
 	return names
 }
 
+// MustGetColumnByName returns the column by name, panics ErrUnknownColumn if not found.
 func (t TableMetadata[T]) MustGetColumnByName(name string) ColumnMetadata[T] {
 	if col, found := t.columnsByName[wrapWithDoubleQuoteIfSqlKeyword(name)]; found {
 		return col
 	}
-	panic(fmt.Sprintf("column with name %s not found", name))
+	panic(ErrUnknownColumn{Table: t.name, Column: name})
+}
+
+// insertableColumnsName returns name of the columns can be inserted, the generated and read-only columns are excluded.
+func (t TableMetadata[T]) insertableColumnsName() []string {
+	var names []string
+	for _, col := range t.columns {
+		if !col.generated && !col.readOnly {
+			names = append(names, col.name)
+		}
+	}
+	return names
 }
 
 // NewRow returns new struct of type T
@@ -75,8 +160,11 @@ func (t TableMetadata[T]) NewRow() T {
 }
 
 type TableMetadataBuilder[T any] struct {
-	name    string
-	columns []*ColumnMetadataBuilder[T]
+	name        string
+	columns     []*ColumnMetadataBuilder[T]
+	uniques     []UniqueConstraint
+	checks      []CheckConstraint
+	foreignKeys []ForeignKey
 }
 
 func NewTableMetadata[T any](name string) *TableMetadataBuilder[T] {
@@ -96,8 +184,64 @@ func (b *TableMetadataBuilder[T]) AddColumns(columns ...*ColumnMetadataBuilder[T
 	return b
 }
 
+// AddColumnGroup adds the columns of the groups.
+func (b *TableMetadataBuilder[T]) AddColumnGroup(groups ...ColumnGroup[T]) *TableMetadataBuilder[T] {
+	for _, group := range groups {
+		b.AddColumns(group.builders()...)
+	}
+	return b
+}
+
+// Unique declares the unique constraint of the columns, emitted by the DDL generation.
+// Once any unique constraint is declared, the ON CONFLICT target must match the primary key or one of them.
+func (b *TableMetadataBuilder[T]) Unique(name string, columns ...string) *TableMetadataBuilder[T] {
+	if name == "" {
+		panic("name cannot be empty")
+	}
+	if len(columns) == 0 {
+		panic("unique constraint must have at least one column")
+	}
+	b.uniques = append(b.uniques, UniqueConstraint{
+		Name:    name,
+		Columns: wrapManyWithDoubleQuoteIfSqlKeyword(columns...),
+	})
+	return b
+}
+
+// Check declares the check constraint of the expression, emitted by the DDL generation, eg: Check("positive_amount", "amount > 0").
+func (b *TableMetadataBuilder[T]) Check(name string, expression string) *TableMetadataBuilder[T] {
+	if name == "" {
+		panic("name cannot be empty")
+	}
+	if strings.TrimSpace(expression) == "" {
+		panic("expression cannot be empty")
+	}
+	b.checks = append(b.checks, CheckConstraint{
+		Name:       name,
+		Expression: strings.TrimSpace(expression),
+	})
+	return b
+}
+
+// ForeignKey declares the column references the column of another table, eg: ForeignKey("order_id", "orders", "id").
+// Used to insert the parent and the child rows together, see InsertWithChildren.
+// Not emitted by the DDL generation, which does not order the tables by dependencies.
+func (b *TableMetadataBuilder[T]) ForeignKey(column string, referencedTable string, referencedColumn string) *TableMetadataBuilder[T] {
+	if column == "" || referencedTable == "" || referencedColumn == "" {
+		panic("column, referenced table and referenced column cannot be empty")
+	}
+	b.foreignKeys = append(b.foreignKeys, ForeignKey{
+		Column:           wrapWithDoubleQuoteIfSqlKeyword(column),
+		ReferencedTable:  referencedTable,
+		ReferencedColumn: wrapWithDoubleQuoteIfSqlKeyword(referencedColumn),
+	})
+	return b
+}
+
 type TableMetadataBuildOption struct {
-	ExpectedPkColumns []string // used to double-check the primary key columns
+	ExpectedPkColumns []string          // used to double-check the primary key columns
+	PartitionResolver PartitionResolver // optional, resolves the partition to use, see UseTablePartitioned
+	MaterializedView  bool              // the table is a materialized view, read-only, see RefreshMaterializedView
 }
 
 func (b *TableMetadataBuilder[T]) Build(opt TableMetadataBuildOption) TableMetadata[T] {
@@ -107,14 +251,15 @@ func (b *TableMetadataBuilder[T]) Build(opt TableMetadataBuildOption) TableMetad
 	columns := make([]ColumnMetadata[T], len(b.columns))
 	columnsByName := make(map[string]ColumnMetadata[T])
 	pkColumnsName := make([]string, 0)
-	for i, col := range b.columns {
-		columns[i] = col.column
-		if _, found := columnsByName[col.column.name]; found {
-			panic(fmt.Sprintf("column with name %s is already added", col.column.name))
+	for i, cb := range b.columns {
+		col := cb.build()
+		columns[i] = col
+		if _, found := columnsByName[col.name]; found {
+			panic(fmt.Sprintf("column with name %s is already added", col.name))
 		}
-		columnsByName[col.column.name] = col.column
-		if col.column.isPk {
-			pkColumnsName = append(pkColumnsName, col.column.name)
+		columnsByName[col.name] = col
+		if col.isPk {
+			pkColumnsName = append(pkColumnsName, col.name)
 		}
 	}
 
@@ -125,27 +270,81 @@ func (b *TableMetadataBuilder[T]) Build(opt TableMetadataBuildOption) TableMetad
 		panic(fmt.Sprintf("expected primary keys [%s] for table %s, but got [%s]", strings.Join(opt.ExpectedPkColumns, ", "), b.name, strings.Join(pkColumnsName, ", ")))
 	}
 
+	constraintNames := make(map[string]struct{})
+	for _, unique := range b.uniques {
+		for _, column := range unique.Columns {
+			if _, found := columnsByName[column]; !found {
+				panic(fmt.Sprintf("column %s of unique constraint %s not found", column, unique.Name))
+			}
+		}
+		if _, found := constraintNames[unique.Name]; found {
+			panic(fmt.Sprintf("constraint with name %s is already added", unique.Name))
+		}
+		constraintNames[unique.Name] = struct{}{}
+	}
+	for _, foreignKey := range b.foreignKeys {
+		if _, found := columnsByName[foreignKey.Column]; !found {
+			panic(fmt.Sprintf("column %s of foreign key not found", foreignKey.Column))
+		}
+		// the referenced table registered later is validated by InsertWithChildren
+		if !hasReferencedColumn(b.name, columnsByName, foreignKey) {
+			panic(fmt.Sprintf("referenced column %s of foreign key not found in table %s", foreignKey.ReferencedColumn, foreignKey.ReferencedTable))
+		}
+	}
+	for _, check := range b.checks {
+		if _, found := constraintNames[check.Name]; found {
+			panic(fmt.Sprintf("constraint with name %s is already added", check.Name))
+		}
+		constraintNames[check.Name] = struct{}{}
+	}
+
 	tableMetadata := TableMetadata[T]{
-		name:          b.name,
-		columns:       columns,
-		columnsByName: columnsByName,
+		name:              b.name,
+		columns:           columns,
+		columnsByName:     columnsByName,
+		partitionResolver: opt.PartitionResolver,
+		uniques:           b.uniques,
+		checks:            b.checks,
+		foreignKeys:       b.foreignKeys,
+		insertSpecs:       &insertSpecCache{specs: make(map[string]insertRecordSpec)},
+		materializedView:  opt.MaterializedView,
 	}
 
 	{ // register table
 		typeName := getStructTypeName(new(T))
 
-		if _, found := registeredTableTypeToName[typeName]; found { // prevent duplicate registration
-			panic(fmt.Sprintf("table for type %s is already registered", typeName))
+		if _, found := registeredTables[b.name]; found { // prevent duplicate registration
+			panic(fmt.Sprintf("table %s is already registered", b.name))
 		}
 
-		//
-		registeredTableTypeToName[typeName] = b.name
+		// the first registration is the default table of the type, the others are accessed by name
+		if _, found := registeredTableTypeToName[typeName]; !found {
+			registeredTableTypeToName[typeName] = b.name
+		}
 		registeredTables[b.name] = tableMetadata
 	}
 
 	return tableMetadata
 }
 
+// hasReferencedColumn returns false if the referenced table is the table itself or registered, and has no referenced column.
+func hasReferencedColumn[T any](tableName string, columnsByName map[string]ColumnMetadata[T], foreignKey ForeignKey) bool {
+	if foreignKey.ReferencedTable == tableName {
+		_, found := columnsByName[foreignKey.ReferencedColumn]
+		return found
+	}
+	registered, found := registeredTables[foreignKey.ReferencedTable]
+	if !found {
+		return true
+	}
+	for _, column := range registered.(genericTableMetadata).schema().Columns {
+		if column.Name == foreignKey.ReferencedColumn {
+			return true
+		}
+	}
+	return false
+}
+
 func getStructTypeName(v any) string {
 	if t := reflect.TypeOf(v); t.Kind() == reflect.Ptr {
 		return t.Elem().Name()
@@ -157,8 +356,13 @@ func getStructTypeName(v any) string {
 type genericTableMetadata interface {
 	Name() string
 	typeName() string
-	selectSpecOfColumns(columnsName ...string) (valueFunc func() any, specs []ResultColumnSelectSpec)
-	insertSpecOfColumns(columnsName ...string) []func(any) any
+	isMaterializedView() bool
+	newRow() any
+	selectSpecOfColumns(columnsName ...string) rowSelectSpec
+	insertSpecOfColumns(columnsName ...string) insertRecordSpec
+	validateColumns(row any, columnsName ...string) error
+	schema() TableSchema
+	useTable(name, alias string) GenericTableToUse
 }
 
 func (t TableMetadata[T]) asGeneric() genericTableMetadata {
@@ -167,53 +371,123 @@ func (t TableMetadata[T]) asGeneric() genericTableMetadata {
 
 var _ genericTableMetadata = TableMetadata[any]{}
 
+func (t TableMetadata[T]) isMaterializedView() bool {
+	return t.materializedView
+}
+
 func (t TableMetadata[T]) newRow() any {
 	return t.NewRow()
 }
 
+func (t TableMetadata[T]) schema() TableSchema {
+	return t.Schema()
+}
+
+// useTable returns the sealed table to use, with the physical name and the alias.
+func (t TableMetadata[T]) useTable(name, alias string) GenericTableToUse {
+	use := newTableToUse(t)
+	if name != t.name {
+		use.As(name)
+	}
+	if alias != t.name {
+		use.Alias(alias)
+	}
+	return use.Seal()
+}
+
 func (t TableMetadata[T]) typeName() string {
 	return getStructTypeName(new(T))
 }
 
-func (t TableMetadata[T]) selectSpecOfColumns(columnsName ...string) (func() any, []ResultColumnSelectSpec) {
+// selectSpecOfColumns returns the select spec of the given columns, all columns if not provided.
+// The columns are resolved once, the spec is called per scanned row.
+func (t TableMetadata[T]) selectSpecOfColumns(columnsName ...string) rowSelectSpec {
 	if len(columnsName) == 0 {
 		columnsName = t.ColumnsName()
 	}
 
-	row := t.NewRow()
-
-	columns := make([]ResultColumnSelectSpec, len(columnsName))
+	selectSpecs := make([]ColumnSelectSpec[T], len(columnsName))
 	for i, name := range columnsName {
 		name := wrapWithDoubleQuoteIfSqlKeyword(name)
-		_, selectSpec := t.MustGetColumnByName(name).SelectSpec()
-		columns[i] = selectSpec(&row)
+		_, selectSpecs[i] = t.MustGetColumnByName(name).SelectSpec()
+	}
+
+	return func(specs []ResultColumnSelectSpec) (func() any, []ResultColumnSelectSpec) {
+		row := new(T)
+		for _, selectSpec := range selectSpecs {
+			specs = append(specs, selectSpec(row))
+		}
+		return func() any {
+			return *row
+		}, specs
+	}
+}
+
+// insertSpecOfColumns returns the insert spec of the given columns, all insertable columns if not provided.
+// The spec is built once per columns and reused by the following builds.
+func (t TableMetadata[T]) insertSpecOfColumns(columnsName ...string) insertRecordSpec {
+	if t.insertSpecs == nil {
+		return t.newInsertRecordSpec(columnsName)
+	}
+
+	key := strings.Join(columnsName, ",")
+	t.insertSpecs.mu.RLock()
+	spec, found := t.insertSpecs.specs[key]
+	t.insertSpecs.mu.RUnlock()
+	if found {
+		return spec
 	}
 
-	return func() any {
-		return row
-	}, columns
+	spec = t.newInsertRecordSpec(columnsName)
+	t.insertSpecs.mu.Lock()
+	t.insertSpecs.specs[key] = spec
+	t.insertSpecs.mu.Unlock()
+	return spec
 }
 
-func (t TableMetadata[T]) insertSpecOfColumns(columnsName ...string) []func(any) any {
+func (t TableMetadata[T]) newInsertRecordSpec(columnsName []string) insertRecordSpec {
 	if len(columnsName) == 0 {
-		columnsName = t.ColumnsName()
+		columnsName = t.insertableColumnsName()
 	}
 
-	result := make([]func(any) any, len(columnsName))
+	specs := make([]ColumnInsertSpec[T], len(columnsName))
 	for i, name := range columnsName {
 		name := wrapWithDoubleQuoteIfSqlKeyword(name)
-		_, insertSpec := t.MustGetColumnByName(name).InsertSpec()
-		result[i] = func(a any) any {
-			return insertSpec(a.(T))
+		_, specs[i] = t.MustGetColumnByName(name).InsertSpec()
+	}
+
+	return func(record any, values []any) []any {
+		row := record.(T)
+		for _, spec := range specs {
+			values = append(values, spec(row))
 		}
+		return values
 	}
+}
 
-	return result
+// validateColumns validates the record using validator of the given columns, all columns if not provided.
+func (t TableMetadata[T]) validateColumns(row any, columnsName ...string) error {
+	if len(columnsName) == 0 {
+		columnsName = t.ColumnsName()
+	}
+
+	for _, name := range columnsName {
+		column := t.MustGetColumnByName(name)
+		if column.validator == nil {
+			continue
+		}
+		if err := column.validator(row.(T)); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 // Contains SQL keywords that need to be double-quoted.
-// Can be added via AddSqlKeyword
-var sqlKeywords map[string]struct{}
+// Can be added via AddSqlKeyword. Initialized as a variable rather than in init,
+// so the table metadata declared at package level within this package sees them.
+var sqlKeywords = newSqlKeywords()
 
 // AddSqlKeyword adds a SQL keyword to be double-quoted when used as table or column name.
 func AddSqlKeyword(keyword string) {
@@ -235,14 +509,15 @@ func wrapManyWithDoubleQuoteIfSqlKeyword(name ...string) []string {
 	return result
 }
 
-func init() {
-	sqlKeywords = map[string]struct{}{}
+func newSqlKeywords() map[string]struct{} {
+	keywords := map[string]struct{}{}
 
 	predefinedList := []string{
 		"count", "index", "name", "type", "types", "from", "to", "order", "value", "state", "time", "left", "right", "day", "local",
 	}
 
 	for _, kw := range predefinedList {
-		sqlKeywords[kw] = struct{}{}
+		keywords[kw] = struct{}{}
 	}
+	return keywords
 }
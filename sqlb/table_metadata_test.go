@@ -99,7 +99,7 @@ func TestGenericTableMetadata(t *testing.T) {
 
 	t.Run("can set to struct value (generic)", func(t *testing.T) {
 		gtm := tableTest1.asGeneric()
-		kts, selectSpecs := gtm.selectSpecOfColumns("amount", "cost")
+		kts, selectSpecs := gtm.selectSpecOfColumns("amount", "cost")(nil)
 		require.Len(t, selectSpecs, 2)
 
 		selectAmount := selectSpecs[0]
@@ -107,6 +107,19 @@ func TestGenericTableMetadata(t *testing.T) {
 
 		test(t, selectAmount, selectCost, kts)
 	})
+
+	t.Run("insert spec is cached", func(t *testing.T) {
+		gtm := tableTest1.asGeneric()
+		spec := gtm.insertSpecOfColumns("pk1", "amount")
+		require.Equal(t, []any{"a", 10}, spec(testStruct1{Pk1: "a", Amount: 10}, nil))
+
+		values := make([]any, 0, 2)
+		require.Equal(t, []any{"b", 20}, spec(testStruct1{Pk1: "b", Amount: 20}, values))
+
+		cached, found := tableTest1.insertSpecs.specs["pk1,amount"]
+		require.True(t, found, "shared by the copies of the metadata")
+		require.Equal(t, []any{"c", 30}, cached(testStruct1{Pk1: "c", Amount: 30}, nil))
+	})
 }
 
 type Money struct {
@@ -265,3 +278,68 @@ var tableTest2 = NewTableMetadata[testStruct2]("table2").
 	).Build(TableMetadataBuildOption{
 	ExpectedPkColumns: []string{"pk1", "pk2", "pk3"},
 })
+
+// tableTestDdlHistory is the history variant of the products table, registered for the same type with an extra column.
+var tableTestDdlHistory = NewTableMetadata[testDdlRow]("products_history").
+	AddColumns(
+		NewColumnMetadata[testDdlRow]("id").
+			SqlType("BIGINT").
+			InsertSpec(func(r testDdlRow) any {
+				return r.Id
+			}).
+			SelectSpec(func(r *testDdlRow) ResultColumnSelectSpec {
+				return ResultColumnSelectSpec{
+					ToQueryArg: func() any {
+						return &r.Id
+					},
+				}
+			}),
+		NewColumnMetadata[testDdlRow]("title").
+			SqlType("TEXT").
+			InsertSpec(func(r testDdlRow) any {
+				return r.Title
+			}).
+			SelectSpec(func(r *testDdlRow) ResultColumnSelectSpec {
+				return ResultColumnSelectSpec{
+					ToQueryArg: func() any {
+						return &r.Title
+					},
+				}
+			}),
+		NewColumnMetadata[testDdlRow]("operation").
+			SqlType("TEXT").
+			InsertSpec(func(testDdlRow) any {
+				return "UPDATE"
+			}).
+			SelectSpec(func(*testDdlRow) ResultColumnSelectSpec {
+				return ResultColumnSelectSpec{
+					ToQueryArg: func() any {
+						return new(string)
+					},
+				}
+			}),
+	).Build(TableMetadataBuildOption{})
+
+func TestGetTableMetadataByName(t *testing.T) {
+	require.Equal(t, "products", GetTableMetadata[testDdlRow]().Name(), "first registration is the default")
+	require.Equal(t, tableTestDdlHistory.ColumnsName(), GetTableMetadataByName[testDdlRow]("products_history").ColumnsName())
+	require.Equal(t, tableTestDdl.ColumnsName(), GetTableMetadataByName[testDdlRow]("products").ColumnsName())
+
+	history := UseTableByName[testDdlRow]("products_history").Alias("h").Seal()
+	gotSql, gotArgs := InsertInto(history).Values(testDdlRow{Id: 1, Title: "book"}).Build()
+	require.Equal(t, "INSERT INTO products_history (id, title, operation)\nVALUES ($1,$2,$3)", gotSql)
+	require.Equal(t, []any{int64(1), "book", "UPDATE"}, gotArgs)
+
+	gotSql, _ = Select(history.Col("operation")).From(history).Build()
+	require.Equal(t, "SELECT h.operation\nFROM products_history AS h\n", gotSql)
+
+	require.Panics(t, func() {
+		GetTableMetadataByName[testDdlRow]("unknown")
+	})
+	require.Panics(t, func() {
+		GetTableMetadataByName[testStruct1]("products_history")
+	}, "registered for another type")
+	require.Panics(t, func() {
+		NewTableMetadata[testStruct1]("products_history").Build(TableMetadataBuildOption{})
+	}, "table name already registered")
+}
@@ -0,0 +1,62 @@
+package sqlb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// TempTable is a temporary table with the structure of table T, created within a transaction
+// and dropped at the end of it (PostgreSQL).
+//
+// Typical usage for huge IN-lists: create the temp table, bulk-insert the keys via InsertInto(temp.Use()),
+// then join it in the subsequent selects within the same transaction.
+type TempTable[T any] struct {
+	metadata TableMetadata[T]
+	name     string
+}
+
+// CreateTemp creates a temporary table with the structure of the table, within the transaction.
+// All columns must have the data type provided via ColumnMetadataBuilder.SqlType.
+func (t TableMetadata[T]) CreateTemp(ctx context.Context, tx *sql.Tx) (*TempTable[T], error) {
+	return t.CreateTempWithExecutor(ctx, WrapExecutor(tx))
+}
+
+// CreateTempWithExecutor is the same as CreateTemp, the executor must be bound to a transaction,
+// otherwise the temporary table is dropped immediately.
+func (t TableMetadata[T]) CreateTempWithExecutor(ctx context.Context, exec Executor) (*TempTable[T], error) {
+	temp := &TempTable[T]{
+		metadata: t,
+		name:     tempTableName(t.name),
+	}
+	if _, err := exec.ExecContext(ctx, t.Schema().CreateTempTableStatement(temp.name)); err != nil {
+		return nil, errors.Wrapf(err, "failed to create temporary table of %s", t.name)
+	}
+	return temp, nil
+}
+
+// Name returns the name of the temporary table.
+func (t *TempTable[T]) Name() string {
+	return t.name
+}
+
+// Use returns the temporary table to use, not sealed so the alias can be set.
+// Each call returns a new instance, one per builder is recommended.
+func (t *TempTable[T]) Use() *TableToUse[T] {
+	return newTableToUse(t.metadata).As(t.name)
+}
+
+// tempTableName returns unique name of temporary table for the table, eg: tmp_orders_1a2b3c4d.
+func tempTableName(tableName string) string {
+	sanitized := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' {
+			return r
+		}
+		return '_'
+	}, tableName)
+	return fmt.Sprintf("tmp_%s_%08x", sanitized, rand.Uint32())
+}
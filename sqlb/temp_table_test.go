@@ -0,0 +1,53 @@
+package sqlb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTableMetadata_CreateTemp(t *testing.T) {
+	t.Run("create, insert into and join the temporary table", func(t *testing.T) {
+		exec := &recordingExecutor{}
+		temp, err := tableTestDdl.CreateTempWithExecutor(context.Background(), exec)
+		require.NoError(t, err)
+		require.Regexp(t, `^tmp_products_[0-9a-f]{8}$`, temp.Name())
+		require.Equal(t, []string{`CREATE TEMPORARY TABLE ` + temp.Name() + ` (
+  id BIGINT NOT NULL,
+  title TEXT NOT NULL,
+  note TEXT,
+  PRIMARY KEY (id)
+) ON COMMIT DROP`}, exec.statements)
+
+		insertTemp := temp.Use().Seal()
+		gotSql, gotArgs := InsertInto(insertTemp, insertTemp.Col("id"), insertTemp.Col("title")).
+			Values(testDdlRow{Id: 1, Title: "a"}, testDdlRow{Id: 2, Title: "b"}).
+			Build()
+		require.Equal(t, "INSERT INTO "+temp.Name()+" (id, title)\nVALUES ($1,$2),($3,$4)", gotSql)
+		require.Equal(t, []any{int64(1), "a", int64(2), "b"}, gotArgs)
+
+		products := UseTable[testDdlRow]().Alias("p").Seal()
+		keys := temp.Use().Alias("k").Seal()
+		gotSql, _ = Select(products.Col("title")).
+			From(products).
+			Join(InnerJoin, keys, products.Col("id"), keys.Col("id")).
+			Build()
+		require.Equal(t, "SELECT p.title\nFROM products AS p\nINNER JOIN "+temp.Name()+" AS k ON p.id = k.id\n", gotSql)
+	})
+
+	t.Run("unique name per temporary table", func(t *testing.T) {
+		exec := &recordingExecutor{}
+		temp1, err := tableTestDdl.CreateTempWithExecutor(context.Background(), exec)
+		require.NoError(t, err)
+		temp2, err := tableTestDdl.CreateTempWithExecutor(context.Background(), exec)
+		require.NoError(t, err)
+		require.NotEqual(t, temp1.Name(), temp2.Name())
+	})
+
+	t.Run("failed to create", func(t *testing.T) {
+		_, err := tableTestDdl.CreateTempWithExecutor(context.Background(), &recordingExecutor{err: errors.New("boom")})
+		require.ErrorContains(t, err, "failed to create temporary table of products: boom")
+	})
+}
@@ -0,0 +1,64 @@
+package sqlb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// TimeoutOption is the option of the per-query timeout.
+type TimeoutOption struct {
+	// StatementTimeout prefixes the execution with SET LOCAL statement_timeout (Postgres only),
+	// so the server kills the statement even if the client does not cancel it.
+	//
+	// SET LOCAL only takes effect inside a transaction, the executor must be bound to a transaction
+	// (eg: sqlb.WrapExecutor(tx) or a TxExecutor), otherwise the execution fails with ErrNotInTransaction.
+	// The setting lasts until the end of the transaction, so it also applies to the following statements
+	// of the transaction which do not set their own timeout.
+	StatementTimeout bool
+}
+
+// Timeout sets the deadline of the execution of the statement, the execution context is wrapped with the deadline.
+func (b *SqlBuilder) Timeout(d time.Duration, opt ...TimeoutOption) *SqlBuilder {
+	if d <= 0 {
+		panic("timeout must be positive")
+	}
+	if len(opt) > 1 {
+		panic("only one option is allowed")
+	}
+
+	b.timeout = d
+	b.statementTimeout = len(opt) == 1 && opt[0].StatementTimeout
+	return b
+}
+
+// withTimeout wraps the context with the deadline and sets the statement_timeout if required.
+// The returned cancel function must be called after the execution, including reading the rows.
+func (b *SqlBuilder) withTimeout(ctx context.Context, exec Executor) (context.Context, context.CancelFunc, error) {
+	if b.timeout == 0 {
+		return ctx, func() {}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+
+	if b.statementTimeout {
+		if b.dialect != DialectPostgres {
+			cancel()
+			panic(fmt.Sprintf("statement_timeout is not supported by dialect %s", b.dialect))
+		}
+		if !inTransaction(exec) {
+			cancel()
+			return nil, nil, errors.Wrap(ErrNotInTransaction, "SET LOCAL statement_timeout")
+		}
+
+		stmt := fmt.Sprintf("SET LOCAL statement_timeout = %d", b.timeout.Milliseconds())
+		if _, err := exec.ExecContext(ctx, stmt); err != nil {
+			cancel()
+			return nil, nil, errors.Wrap(err, "failed to set statement_timeout")
+		}
+	}
+
+	return ctx, cancel, nil
+}
@@ -0,0 +1,108 @@
+package sqlb
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// deadlineExecutor records the executed statements and the remaining time before the deadline, for the sake of the test.
+type deadlineExecutor struct {
+	recordingExecutor
+	remaining []time.Duration
+	inTx      bool
+}
+
+func (e *deadlineExecutor) InTransaction() bool {
+	return e.inTx
+}
+
+func (e *deadlineExecutor) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	var remaining time.Duration
+	if deadline, ok := ctx.Deadline(); ok {
+		remaining = time.Until(deadline)
+	}
+	e.remaining = append(e.remaining, remaining)
+	return e.recordingExecutor.ExecContext(ctx, query, args...)
+}
+
+func TestSqlBuilder_Timeout(t *testing.T) {
+	t.Run("wraps the context with deadline", func(t *testing.T) {
+		exec := &deadlineExecutor{}
+		_, err := InsertInto(UseTable[testDdlRow]().Seal()).
+			Values(testDdlRow{Id: 1, Title: "book"}).
+			Timeout(time.Minute).
+			ExecWithExecutor(context.Background(), exec)
+		require.NoError(t, err)
+		require.Len(t, exec.statements, 1)
+		require.Greater(t, exec.remaining[0], time.Duration(0))
+		require.LessOrEqual(t, exec.remaining[0], time.Minute)
+	})
+
+	t.Run("no deadline by default", func(t *testing.T) {
+		exec := &deadlineExecutor{}
+		_, err := InsertInto(UseTable[testDdlRow]().Seal()).
+			Values(testDdlRow{Id: 1, Title: "book"}).
+			ExecWithExecutor(context.Background(), exec)
+		require.NoError(t, err)
+		require.Equal(t, time.Duration(0), exec.remaining[0])
+	})
+
+	t.Run("statement timeout", func(t *testing.T) {
+		exec := &deadlineExecutor{inTx: true}
+		_, err := InsertInto(UseTable[testDdlRow]().Seal()).
+			Values(testDdlRow{Id: 1, Title: "book"}).
+			Timeout(1500*time.Millisecond, TimeoutOption{StatementTimeout: true}).
+			ExecWithExecutor(context.Background(), exec)
+		require.NoError(t, err)
+		require.Len(t, exec.statements, 2)
+		require.Equal(t, "SET LOCAL statement_timeout = 1500", exec.statements[0])
+		require.Greater(t, exec.remaining[0], time.Duration(0))
+	})
+
+	t.Run("failed to set statement timeout", func(t *testing.T) {
+		exec := &deadlineExecutor{
+			recordingExecutor: recordingExecutor{err: errors.New("conn closed")},
+			inTx:              true,
+		}
+		_, err := InsertInto(UseTable[testDdlRow]().Seal()).
+			Values(testDdlRow{Id: 1, Title: "book"}).
+			Timeout(time.Second, TimeoutOption{StatementTimeout: true}).
+			ExecWithExecutor(context.Background(), exec)
+		require.ErrorContains(t, err, "failed to set statement_timeout")
+		require.Len(t, exec.statements, 1, "must not execute the statement")
+	})
+
+	t.Run("statement timeout requires a transaction", func(t *testing.T) {
+		exec := &deadlineExecutor{}
+		_, err := InsertInto(UseTable[testDdlRow]().Seal()).
+			Values(testDdlRow{Id: 1, Title: "book"}).
+			Timeout(time.Second, TimeoutOption{StatementTimeout: true}).
+			ExecWithExecutor(context.Background(), exec)
+		require.ErrorIs(t, err, ErrNotInTransaction)
+		require.Empty(t, exec.statements, "must not execute any statement")
+	})
+
+	t.Run("statement timeout is not supported by dialect", func(t *testing.T) {
+		require.Panics(t, func() {
+			_, _ = InsertInto(UseTable[testDdlRow]().Seal()).
+				Values(testDdlRow{Id: 1, Title: "book"}).
+				WithDialect(DialectMySQL).
+				Timeout(time.Second, TimeoutOption{StatementTimeout: true}).
+				ExecWithExecutor(context.Background(), &deadlineExecutor{})
+		})
+	})
+
+	t.Run("invalid timeout", func(t *testing.T) {
+		require.Panics(t, func() {
+			Select().Timeout(0)
+		})
+		require.Panics(t, func() {
+			Select().Timeout(time.Second, TimeoutOption{}, TimeoutOption{})
+		})
+	})
+}
@@ -0,0 +1,49 @@
+package sqlb
+
+// tokenArenaFirstChunk is the capacity of the storage allocated on the first token, it fits the short clauses,
+// e.g. DO UPDATE with its WHERE, in a single allocation no larger than the per-clause slices it replaces.
+const tokenArenaFirstChunk = 8
+
+// tokenList is the span of the arena tokens of a clause.
+type tokenList struct {
+	offset int32
+	length int32
+}
+
+func (l tokenList) len() int {
+	return int(l.length)
+}
+
+// tokenArena is the per-builder storage of the tokens of every clause (WHERE, HAVING, JOIN ON, ON CONFLICT DO UPDATE),
+// the clauses are spans of a single slice instead of one growing []any per clause.
+// The tokens are kept boxed as provided, they are already boxed by the variadic clause methods.
+type tokenArena struct {
+	tokens []any
+}
+
+// append stores the user provided tokens and returns the list extended with them.
+// Only the last list can be extended, the clause order enforced by the builder guarantees it.
+func (a *tokenArena) append(list tokenList, tokens ...any) tokenList {
+	if a.tokens == nil {
+		size := tokenArenaFirstChunk
+		if len(tokens) > size {
+			size = len(tokens)
+		}
+		a.tokens = make([]any, 0, size)
+	}
+	if list.length == 0 {
+		list.offset = int32(len(a.tokens))
+	} else if int(list.offset+list.length) != len(a.tokens) {
+		panic("only the tokens of the last clause can be extended")
+	}
+
+	a.tokens = append(a.tokens, tokens...)
+	list.length += int32(len(tokens))
+	return list
+}
+
+// list returns the tokens of the list, as they were provided.
+func (a *tokenArena) list(list tokenList) []any {
+	end := list.offset + list.length
+	return a.tokens[list.offset:end:end]
+}
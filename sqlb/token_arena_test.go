@@ -0,0 +1,41 @@
+package sqlb
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenArena(t *testing.T) {
+	table1 := UseTable[testStruct1]().Alias("t1").Seal()
+	amount := table1.Col("amount")
+	expr := Gt(amount, 1)
+
+	var arena tokenArena
+	where := arena.append(tokenList{}, table1.Col("pk1"), "= $1")
+	where = arena.append(where, "AND", amount, "> 10")
+	having := arena.append(tokenList{}, expr)
+
+	require.Equal(t, []any{table1.Col("pk1"), "= $1", "AND", amount, "> 10"}, arena.list(where))
+	require.Equal(t, []any{expr}, arena.list(having))
+	require.Equal(t, 5, where.len())
+	require.Equal(t, tokenArenaFirstChunk, cap(arena.tokens), "the clauses share the first chunk")
+
+	// growing beyond the first chunk keeps the previous tokens
+	list := tokenList{}
+	for i := 0; i < 40; i++ {
+		list = arena.append(list, "OR", amount)
+	}
+	require.Equal(t, 80, list.len())
+	require.Equal(t, []any{"OR", amount}, arena.list(list)[78:])
+	require.Equal(t, []any{expr}, arena.list(having))
+
+	require.PanicsWithValue(t, "only the tokens of the last clause can be extended", func() {
+		arena.append(where, "OR", amount)
+	})
+
+	sb := &strings.Builder{}
+	newBuildContext(sb, nil, DialectPostgres).writeArenaTokens(&arena, where, "WHERE")
+	require.Equal(t, " t1.pk1 = $1 AND t1.amount > 10", sb.String())
+}
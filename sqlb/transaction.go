@@ -0,0 +1,84 @@
+package sqlb
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// InTransaction runs the function inside a transaction of the database,
+// commits if the function returns nil, rolls back otherwise (including panic).
+//
+// The executor passed to the function is bound to the transaction,
+// so the transaction-level advisory locks (AdvisoryXactLock) obtained by the function are released when it completes.
+// The cache entries invalidated by the executions of the function (see Cache) are removed after the commit.
+func InTransaction(ctx context.Context, db *sql.DB, opts *sql.TxOptions, fn func(exec Executor) error) (err error) {
+	tx, err := db.BeginTx(ctx, opts)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			_ = tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	exec := &txExecutor{stdExecutor: stdExecutor{std: tx}}
+	if err = fn(exec); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "failed to commit transaction")
+	}
+	exec.committed()
+	return nil
+}
+
+// InTransactionWithLock runs the function inside a transaction like InTransaction,
+// after obtaining the transaction-level advisory lock of the key, so the function is mutually exclusive across instances.
+func InTransactionWithLock(ctx context.Context, db *sql.DB, opts *sql.TxOptions, key int64, fn func(exec Executor) error) error {
+	return InTransaction(ctx, db, opts, func(exec Executor) error {
+		if err := AdvisoryXactLock(ctx, exec, key); err != nil {
+			return err
+		}
+		return fn(exec)
+	})
+}
+
+var _ TxExecutor = (*txExecutor)(nil)
+
+// txExecutor is the executor of the transaction of InTransaction,
+// collecting the functions to run after the transaction is committed.
+type txExecutor struct {
+	stdExecutor
+	mu          sync.Mutex
+	afterCommit []func()
+}
+
+func (e *txExecutor) InTransaction() bool {
+	return true
+}
+
+// onCommit runs the function after the transaction is committed, the function is discarded if the transaction is rolled back.
+func (e *txExecutor) onCommit(fn func()) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.afterCommit = append(e.afterCommit, fn)
+}
+
+func (e *txExecutor) committed() {
+	e.mu.Lock()
+	afterCommit := e.afterCommit
+	e.afterCommit = nil
+	e.mu.Unlock()
+
+	for _, fn := range afterCommit {
+		fn()
+	}
+}
@@ -0,0 +1,82 @@
+package sqlb
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInTransaction(t *testing.T) {
+	newDB := func(t *testing.T) (*sql.DB, *txConnector) {
+		connector := &txConnector{}
+		db := sql.OpenDB(connector)
+		t.Cleanup(func() {
+			_ = db.Close()
+		})
+		return db, connector
+	}
+
+	t.Run("commits", func(t *testing.T) {
+		db, connector := newDB(t)
+		err := InTransaction(context.Background(), db, nil, func(exec Executor) error {
+			require.True(t, inTransaction(exec))
+			_, err := exec.ExecContext(context.Background(), "DELETE FROM products")
+			return err
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"BEGIN", "DELETE FROM products", "COMMIT"}, connector.events)
+	})
+
+	t.Run("rolls back on error", func(t *testing.T) {
+		db, connector := newDB(t)
+		err := InTransaction(context.Background(), db, nil, func(exec Executor) error {
+			_, _ = exec.ExecContext(context.Background(), "DELETE FROM products")
+			return errors.New("failed")
+		})
+		require.EqualError(t, err, "failed")
+		require.Equal(t, []string{"BEGIN", "DELETE FROM products", "ROLLBACK"}, connector.events)
+	})
+
+	t.Run("rolls back on panic", func(t *testing.T) {
+		db, connector := newDB(t)
+		require.PanicsWithValue(t, "boom", func() {
+			_ = InTransaction(context.Background(), db, nil, func(exec Executor) error {
+				_, _ = exec.ExecContext(context.Background(), "DELETE FROM products")
+				panic("boom")
+			})
+		})
+		require.Equal(t, []string{"BEGIN", "DELETE FROM products", "ROLLBACK"}, connector.events)
+	})
+
+	t.Run("with lock", func(t *testing.T) {
+		db, connector := newDB(t)
+		err := InTransactionWithLock(context.Background(), db, nil, 42, func(exec Executor) error {
+			_, err := exec.ExecContext(context.Background(), "DELETE FROM products")
+			return err
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"BEGIN", "SELECT pg_advisory_xact_lock($1)", "DELETE FROM products", "COMMIT"}, connector.events)
+	})
+
+	t.Run("with lock rolls back on error", func(t *testing.T) {
+		db, connector := newDB(t)
+		err := InTransactionWithLock(context.Background(), db, nil, 42, func(exec Executor) error {
+			return errors.New("failed")
+		})
+		require.EqualError(t, err, "failed")
+		require.Equal(t, []string{"BEGIN", "SELECT pg_advisory_xact_lock($1)", "ROLLBACK"}, connector.events)
+	})
+
+	t.Run("with lock rolls back on panic", func(t *testing.T) {
+		db, connector := newDB(t)
+		require.Panics(t, func() {
+			_ = InTransactionWithLock(context.Background(), db, nil, 42, func(exec Executor) error {
+				panic("boom")
+			})
+		})
+		require.Equal(t, []string{"BEGIN", "SELECT pg_advisory_xact_lock($1)", "ROLLBACK"}, connector.events)
+	})
+}
@@ -1,5 +1,7 @@
 package sqlb
 
+import "fmt"
+
 type sqlBuilderType string
 
 const (
@@ -25,16 +27,20 @@ const (
 	previousIsSelectFrom    previousAddedBuilderAction = "SELECT FROM"
 	previousIsSelectJoin    previousAddedBuilderAction = "SELECT JOIN"
 	previousIsSelectWhere   previousAddedBuilderAction = "SELECT WHERE"
+	previousIsSelectGroupBy previousAddedBuilderAction = "SELECT GROUP BY"
+	previousIsSelectHaving  previousAddedBuilderAction = "SELECT HAVING"
 	previousIsSelectOrderBy previousAddedBuilderAction = "SELECT ORDER BY"
 	previousIsSelectOffset  previousAddedBuilderAction = "SELECT OFFSET"
 	previousIsSelectLimit   previousAddedBuilderAction = "SELECT LIMIT"
 	// INSERT
 	previousIsInsertInto                        previousAddedBuilderAction = "INSERT INTO"
 	previousIsInsertIntoValues                  previousAddedBuilderAction = "INSERT VALUES"
+	previousIsInsertIntoWhereNotExists          previousAddedBuilderAction = "INSERT WHERE NOT EXISTS"
 	previousIsInsertIntoOnConflict              previousAddedBuilderAction = "INSERT ON CONFLICT"
 	previousIsInsertIntoOnConflictDoUpdate      previousAddedBuilderAction = "INSERT ON CONFLICT DO UPDATE"
 	previousIsInsertIntoOnConflictDoUpdateWhere previousAddedBuilderAction = "INSERT ON CONFLICT DO UPDATE WHERE"
 	previousIsInsertIntoOnConflictDoNoThing     previousAddedBuilderAction = "INSERT ON CONFLICT DO NOTHING"
+	previousIsInsertIntoReturning               previousAddedBuilderAction = "INSERT RETURNING"
 	//
 )
 
@@ -48,10 +54,24 @@ const (
 	RightJoin
 )
 
+func (j JoinType) String() string {
+	switch j {
+	case InnerJoin:
+		return "INNER JOIN"
+	case LeftJoin:
+		return "LEFT JOIN"
+	case RightJoin:
+		return "RIGHT JOIN"
+	default:
+		return fmt.Sprintf("JoinType(%d)", uint8(j))
+	}
+}
+
 type joinOn struct {
 	joinType      JoinType
 	joinOnTable   GenericTableToUse
 	joinOnColumns []GenericColumnToUse
+	joinOnTokens  tokenList // additional conditions, AND-ed after the key pairs
 }
 
 // OrderType is used to specify the order of the results
@@ -62,15 +82,117 @@ const (
 	DESC OrderType = false
 )
 
+func (o OrderType) String() string {
+	if o == ASC {
+		return "ASC"
+	}
+	return "DESC"
+}
+
+// SqlFormat controls the layout of the generated statement
+type SqlFormat uint8
+
+const (
+	// FormatDefault renders one clause per line, as is.
+	FormatDefault SqlFormat = iota
+	// FormatSingleLine renders the whole statement in one line with normalized spacing.
+	FormatSingleLine
+	// FormatPretty renders one clause per line with normalized spacing, continuation lines are indented.
+	FormatPretty
+)
+
+func (f SqlFormat) String() string {
+	switch f {
+	case FormatDefault:
+		return "default"
+	case FormatSingleLine:
+		return "single line"
+	case FormatPretty:
+		return "pretty"
+	default:
+		return fmt.Sprintf("SqlFormat(%d)", uint8(f))
+	}
+}
+
+// Dialect is the SQL dialect of the generated statement
+type Dialect uint8
+
+const (
+	// DialectPostgres renders PostgreSQL statements, the default.
+	DialectPostgres Dialect = iota
+	// DialectMySQL renders MySQL (5.7+, MariaDB) statements, upsert via ON DUPLICATE KEY UPDATE [column] = VALUES([column]).
+	DialectMySQL
+	// DialectMySQL8 renders MySQL 8.0.19+ statements, upsert via the row alias: VALUES (...) AS excluded ON DUPLICATE KEY UPDATE.
+	DialectMySQL8
+	// DialectSQLite renders SQLite (3.35+) statements.
+	DialectSQLite
+	// DialectSQLServer renders SQL Server statements: @p1 placeholders, bracket-quoted identifiers, OFFSET/FETCH pagination.
+	DialectSQLServer
+)
+
+func (d Dialect) String() string {
+	switch d {
+	case DialectPostgres:
+		return "postgres"
+	case DialectMySQL:
+		return "mysql"
+	case DialectMySQL8:
+		return "mysql8"
+	case DialectSQLite:
+		return "sqlite"
+	case DialectSQLServer:
+		return "sqlserver"
+	default:
+		return fmt.Sprintf("Dialect(%d)", uint8(d))
+	}
+}
+
+// NamedParamStyle is the style of the named parameters rendered by BuildNamed
+type NamedParamStyle uint8
+
+const (
+	// NamedParamColon renders :p1, :p2,... eg: Oracle, sqlx named queries.
+	NamedParamColon NamedParamStyle = iota
+	// NamedParamAt renders @p1, @p2,... eg: SQL Server.
+	NamedParamAt
+)
+
 type orderBy struct {
 	column GenericColumnToUse
+	expr   *Expr // when provided, ordering by the expression instead of the column
 	asc    bool
 }
 
+// SqlRows is the result rows of the query, implemented by *sql.Rows.
+// Err returns the error encountered during the iteration, it is checked after Next returns false.
 type SqlRows interface {
 	Next() bool
 	Scan(dest ...any) error
 	Close() error
+	Err() error
+}
+
+// SqlRowsWithoutErr is SqlRows without Err, use AdaptSqlRows to adapt the implementations written before Err was required.
+type SqlRowsWithoutErr interface {
+	Next() bool
+	Scan(dest ...any) error
+	Close() error
+}
+
+// AdaptSqlRows adapts the rows without Err to SqlRows, Err of the adapted rows always returns nil.
+func AdaptSqlRows(rows SqlRowsWithoutErr) SqlRows {
+	if sqlRows, ok := rows.(SqlRows); ok {
+		return sqlRows
+	}
+	return rowsWithoutErr{SqlRowsWithoutErr: rows}
+}
+
+type rowsWithoutErr struct {
+	SqlRowsWithoutErr
+}
+
+func (rowsWithoutErr) Err() error {
+	return nil
 }
 
 type Pagination struct {
@@ -6,22 +6,35 @@ import (
 )
 
 type GenericColumnToUse struct {
-	name  string
-	isPk  bool
-	table GenericTableToUse
+	name        string
+	isPk        bool
+	sqlType     string
+	isCitext    bool // declared as CITEXT, compared case-insensitively by the database
+	isGenerated bool // computed by the database, cannot be inserted or updated
+	isReadOnly  bool // maintained by triggers or the database, cannot be inserted or updated
+	table       GenericTableToUse
 }
 
 func newGenericColumnToUse[T any](column ColumnMetadata[T], table GenericTableToUse) GenericColumnToUse {
 	return GenericColumnToUse{
-		name:  column.Name(),
-		isPk:  column.isPk,
-		table: table,
+		name:        column.Name(),
+		isPk:        column.isPk,
+		sqlType:     column.sqlType,
+		isCitext:    strings.EqualFold(column.sqlType, "CITEXT"),
+		isGenerated: column.generated,
+		isReadOnly:  column.readOnly,
+		table:       table,
 	}
 }
 
-// nameWithAlias returns [alias].[column]
-func (c GenericColumnToUse) nameWithAlias() string {
-	return c.table.tableAlias() + "." + c.name
+// mustWritable panics if the column is generated or read-only, the action is used in the panic message.
+func (c GenericColumnToUse) mustWritable(action string) {
+	if c.isGenerated {
+		panic(fmt.Sprintf("column %s is generated, cannot be %s", c.name, action))
+	}
+	if c.isReadOnly {
+		panic(fmt.Sprintf("column %s is read-only, cannot be %s", c.name, action))
+	}
 }
 
 // NameOnly returns [column]
@@ -64,16 +77,43 @@ func (c GenericColumnToUse) Least() string {
 	return c.name + " = LEAST(" + c.NameWithTableName() + ", " + c.Excluded() + ")"
 }
 
+// AddExcluded generates statement '[column] = [table].[column] + excluded.[column]', used in ON CONFLICT DO UPDATE of counters
+func (c GenericColumnToUse) AddExcluded() string {
+	return c.name + " = " + c.NameWithTableName() + " + " + c.Excluded()
+}
+
+// Increment generates statement '[column] = [table].[column] + $n', the delta is bound as an argument,
+// used in ON CONFLICT DO UPDATE of counters
+func (c GenericColumnToUse) Increment(delta any) Expr {
+	return concatExpr(c.name+" = "+c.NameWithTableName()+" + ", boundArg{value: delta})
+}
+
 // GinStringArrayContains generates statement '[column] @> ARRAY[$1]::TEXT[]'
+//
+// Deprecated: the placeholder number breaks when the conditions are reordered, use StringArrayContains.
 func (c GenericColumnToUse) GinStringArrayContains(argumentNumber int) string {
 	return fmt.Sprintf(`%s @> ARRAY[$%d]::TEXT[]`, c.name, argumentNumber)
 }
 
 // Gin2DimensionalByteArrayContains generates statement '[column] @> ARRAY[$1]::BYTEA[]'
+//
+// Deprecated: the placeholder number breaks when the conditions are reordered, use ByteArrayContains.
 func (c GenericColumnToUse) Gin2DimensionalByteArrayContains(argumentNumber int) string {
 	return fmt.Sprintf(`%s @> ARRAY[$%d]::BYTEA[]`, c.name, argumentNumber)
 }
 
+// StringArrayContains generates statement '[column] @> ARRAY[$n]::TEXT[]', the value is bound as an argument.
+// Can be served by GIN index of the TEXT[] column.
+func (c GenericColumnToUse) StringArrayContains(value string) Expr {
+	return concatExpr(c, " @> ARRAY[", boundArg{value: value}, "]::TEXT[]")
+}
+
+// ByteArrayContains generates statement '[column] @> ARRAY[$n]::BYTEA[]', the value is bound as an argument.
+// Can be served by GIN index of the BYTEA[] column.
+func (c GenericColumnToUse) ByteArrayContains(value []byte) Expr {
+	return concatExpr(c, " @> ARRAY[", boundArg{value: value}, "]::BYTEA[]")
+}
+
 // InNumbers generates statement '[column] IN (1,2,3)'
 func (c GenericColumnToUse) InNumbers(numbers ...int) string {
 	var sb strings.Builder
@@ -88,3 +128,28 @@ func (c GenericColumnToUse) InNumbers(numbers ...int) string {
 	sb.WriteString(")")
 	return sb.String()
 }
+
+// JsonGet generates statement '[column] -> [key]', returns the JSON object field as JSONB, the key is quoted
+func (c GenericColumnToUse) JsonGet(key string) string {
+	return fmt.Sprintf(`%s -> %s`, c.name, quoteLiteral(key))
+}
+
+// JsonGetText generates statement '[column] ->> [key]', returns the JSON object field as TEXT, the key is quoted
+func (c GenericColumnToUse) JsonGetText(key string) string {
+	return fmt.Sprintf(`%s ->> %s`, c.name, quoteLiteral(key))
+}
+
+// JsonContains generates statement '[column] @> $1::JSONB'
+func (c GenericColumnToUse) JsonContains(argumentNumber int) string {
+	return fmt.Sprintf(`%s @> $%d::JSONB`, c.name, argumentNumber)
+}
+
+// JsonPathExists generates statement '[column] @? [path]', checks if the JSON path returns any item, the path is quoted
+func (c GenericColumnToUse) JsonPathExists(path string) string {
+	return fmt.Sprintf(`%s @? %s`, c.name, quoteLiteral(path))
+}
+
+// quoteLiteral wraps the value with single quotes, escaping the single quotes inside.
+func quoteLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
@@ -0,0 +1,30 @@
+package sqlb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenericColumnToUse_Json(t *testing.T) {
+	col := UseTable[testStruct1]().Seal().Col("cost")
+
+	require.Equal(t, `cost -> 'currency'`, col.JsonGet("currency"))
+	require.Equal(t, `cost ->> 'currency'`, col.JsonGetText("currency"))
+	require.Equal(t, `cost ->> 'it''s'`, col.JsonGetText("it's"))
+	require.Equal(t, `cost @> $2::JSONB`, col.JsonContains(2))
+	require.Equal(t, `cost @? '$.tags[*] ? (@ == "a")'`, col.JsonPathExists(`$.tags[*] ? (@ == "a")`))
+}
+
+func TestGenericColumnToUse_ArrayContains(t *testing.T) {
+	table1 := UseTable[testStruct1]().Alias("t1").Seal()
+
+	gotSql, gotArgs := Select(table1.Col("pk1")).
+		From(table1).
+		Where(Eq(table1.Col("amount"), 1)).
+		And(table1.Col("pk1").StringArrayContains("a")).
+		And(table1.Col("pk2").ByteArrayContains([]byte{1})).
+		Build()
+	require.Equal(t, "SELECT t1.pk1\nFROM table1 AS t1\nWHERE t1.amount = $1 AND t1.pk1 @> ARRAY[$2]::TEXT[] AND t1.pk2 @> ARRAY[$3]::BYTEA[]\n", gotSql)
+	require.Equal(t, []any{1, "a", []byte{1}}, gotArgs)
+}
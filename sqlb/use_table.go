@@ -22,12 +22,32 @@ type TableToUse[T any] struct {
 	metadata TableMetadata[T]
 	name     string
 	alias    string // alias is the alias for the table
+	auto     bool   // auto indicates the alias is chosen by each builder registering the table, see UseTableAutoAlias
 }
 
-// UseTable returns table to use.
+// UseTable returns table to use, the table registered first for type T.
 func UseTable[T any]() *TableToUse[T] {
-	metadata := GetTableMetadata[T]()
+	return newTableToUse(GetTableMetadata[T]())
+}
+
+// UseTableByName returns table to use, the table registered by name for type T, see GetTableMetadataByName.
+func UseTableByName[T any](name string) *TableToUse[T] {
+	return newTableToUse(GetTableMetadataByName[T](name))
+}
 
+// UseTableAutoAlias returns table to use, the table registered first for type T,
+// the alias is chosen by the builder: the table name, or the table name suffixed by _2, _3,... if already used.
+// Useful for generated code and dynamic joins of the same table multiple times without inventing the aliases.
+//
+// The alias is chosen by each builder when the table is registered (Select, From, Join,...) for the first time,
+// the table itself is not modified so it can be shared by the builders, see ScannedRows for reading the rows.
+func UseTableAutoAlias[T any]() *TableToUse[T] {
+	t := UseTable[T]()
+	t.auto = true
+	return t
+}
+
+func newTableToUse[T any](metadata TableMetadata[T]) *TableToUse[T] {
 	return &TableToUse[T]{
 		uid:      rand.Int64(),
 		sealed:   false,
@@ -60,6 +80,7 @@ func (t *TableToUse[T]) Alias(alias string) *TableToUse[T] {
 	}
 
 	t.alias = alias
+	t.auto = false
 	return t
 }
 
@@ -159,7 +180,7 @@ func (t *TableToUse[T]) Col(column string) GenericColumnToUse {
 
 // ReadFromRow reads the table from the scanned rows.
 func (t *TableToUse[T]) ReadFromRow(scanner *ScannedRows) T {
-	return scanner.GetTable(t.alias).(T)
+	return scanner.GetTable(scanner.aliasOf(t)).(T)
 }
 
 // ReadAllFromRows reads all the table from the scanned rows.
@@ -195,6 +216,11 @@ func (t *TableToUse[T]) tableAlias() string {
 	return t.alias
 }
 
+// autoAliased returns true if the alias is chosen by the builder, see UseTableAutoAlias.
+func (t *TableToUse[T]) autoAliased() bool {
+	return t.auto
+}
+
 func (t TableToUse[T]) genericTableMeta() genericTableMetadata {
 	return t.metadata.asGeneric()
 }
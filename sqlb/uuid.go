@@ -0,0 +1,79 @@
+package sqlb
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// UuidVersion is the version of UUID to be generated on the client side.
+type UuidVersion uint8
+
+const (
+	UuidV4 UuidVersion = 4 // random
+	UuidV7 UuidVersion = 7 // time-ordered, better for index locality
+)
+
+// UuidOption is the option for the UUID primary key column preset.
+type UuidOption struct {
+	Version UuidVersion // version of the UUID to be generated when the field is empty, default v4
+
+	// ServerSide emits gen_random_uuid() instead of generating the UUID on the client side when the field is empty.
+	ServerSide bool
+}
+
+// NewUuidV4 returns a new random UUID (version 4) in the canonical form.
+func NewUuidV4() string {
+	var u [16]byte
+	mustReadRandom(u[:])
+	return formatUuid(u, UuidV4)
+}
+
+// NewUuidV7 returns a new time-ordered UUID (version 7) in the canonical form.
+func NewUuidV7() string {
+	var u [16]byte
+	mustReadRandom(u[6:])
+
+	var ms [8]byte
+	binary.BigEndian.PutUint64(ms[:], uint64(time.Now().UnixMilli()))
+	copy(u[:6], ms[2:])
+
+	return formatUuid(u, UuidV7)
+}
+
+func newUuid(version UuidVersion) string {
+	switch version {
+	case 0, UuidV4:
+		return NewUuidV4()
+	case UuidV7:
+		return NewUuidV7()
+	default:
+		panic(fmt.Sprintf("unsupported UUID version %d", version))
+	}
+}
+
+// formatUuid sets the version & variant bits then formats the UUID as xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx.
+func formatUuid(u [16]byte, version UuidVersion) string {
+	u[6] = (u[6] & 0x0f) | byte(version)<<4
+	u[8] = (u[8] & 0x3f) | 0x80
+
+	var buf [36]byte
+	hex.Encode(buf[0:8], u[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], u[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], u[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], u[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], u[10:])
+	return string(buf[:])
+}
+
+func mustReadRandom(b []byte) {
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("failed to read random bytes: %v", err))
+	}
+}
@@ -0,0 +1,29 @@
+package sqlb
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewUuid(t *testing.T) {
+	pattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-([47])[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+	t.Run("v4", func(t *testing.T) {
+		u := NewUuidV4()
+		require.Regexp(t, pattern, u)
+		require.Equal(t, "4", pattern.FindStringSubmatch(u)[1])
+		require.NotEqual(t, u, NewUuidV4())
+	})
+
+	t.Run("v7", func(t *testing.T) {
+		u1 := NewUuidV7()
+		require.Regexp(t, pattern, u1)
+		require.Equal(t, "7", pattern.FindStringSubmatch(u1)[1])
+
+		u2 := NewUuidV7()
+		require.NotEqual(t, u1, u2)
+		require.LessOrEqual(t, u1[:13], u2[:13], "timestamp prefix must be ordered")
+	})
+}
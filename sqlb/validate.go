@@ -0,0 +1,249 @@
+package sqlb
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Validate checks the builder then the rendered statement for structural issues: unbalanced parentheses,
+// trailing operators, duplicate aliases and placeholder gaps, catching the builder bugs before the database does.
+// Raw SQL tokens are checked as well, since they are part of the rendered statement.
+func (b *SqlBuilder) Validate() error {
+	stmt, args, err := b.TryBuild()
+	if err != nil {
+		return err
+	}
+	return validateStatement(stmt, len(args), b.dialect)
+}
+
+// sqlToken is a lexical token of the statement, quoted literals, identifiers and comments are skipped.
+type sqlToken struct {
+	text string
+	pos  int
+}
+
+// trailingOperators are the operators require the right operand.
+var trailingOperators = map[string]struct{}{
+	"AND": {}, "OR": {}, "NOT": {}, "IN": {}, "IS": {}, "LIKE": {}, "ILIKE": {}, "BETWEEN": {},
+	"=": {}, "<>": {}, "!=": {}, "<": {}, ">": {}, "<=": {}, ">=": {}, "+": {}, "-": {}, "/": {}, "||": {}, ",": {},
+}
+
+// clauseKeywords start a new clause, an operator right before them is missing the operand.
+var clauseKeywords = map[string]struct{}{
+	"FROM": {}, "WHERE": {}, "GROUP": {}, "HAVING": {}, "ORDER": {}, "LIMIT": {}, "OFFSET": {}, "FETCH": {},
+	"JOIN": {}, "ON": {}, "VALUES": {}, "RETURNING": {}, "UNION": {},
+}
+
+// conditionKeywords start a condition, a conjunction right after them is missing the left operand.
+var conditionKeywords = map[string]struct{}{
+	"WHERE": {}, "ON": {}, "HAVING": {}, "(": {},
+}
+
+// validateStatement checks the rendered statement, see Validate.
+func validateStatement(stmt string, argsCount int, dialect Dialect) error {
+	tokens, err := tokenizeSql(stmt)
+	if err != nil {
+		return err
+	}
+
+	// parentheses & aliases, the aliases are scoped by the parentheses,
+	// the table aliases (FROM, JOIN) and the column aliases are distinct namespaces
+	depth := 0
+	aliasScopes := []*aliasScope{newAliasScope()}
+	for i, token := range tokens {
+		switch token.text {
+		case "(":
+			depth++
+			aliasScopes = append(aliasScopes, newAliasScope())
+		case ")":
+			depth--
+			if depth < 0 {
+				return errors.Errorf("unbalanced parentheses: unexpected ) at position %d", token.pos)
+			}
+			aliasScopes = aliasScopes[:len(aliasScopes)-1]
+		case "FROM", "JOIN":
+			aliasScopes[depth].inTables = true
+		case "AS":
+			if i+1 >= len(tokens) || !isSqlWord(tokens[i+1].text) {
+				continue
+			}
+			if !aliasScopes[depth].add(strings.ToLower(tokens[i+1].text)) {
+				return errors.Errorf("duplicate alias %s at position %d", tokens[i+1].text, tokens[i+1].pos)
+			}
+		default:
+			if _, isClause := clauseKeywords[token.text]; isClause || token.text == "SELECT" {
+				aliasScopes[depth].inTables = false
+			}
+		}
+	}
+	if depth != 0 {
+		return errors.Errorf("unbalanced parentheses: %d not closed", depth)
+	}
+
+	// operators
+	for i, token := range tokens {
+		if _, isOperator := trailingOperators[token.text]; isOperator {
+			if i+1 >= len(tokens) {
+				return errors.Errorf("trailing operator %s at the end of the statement", token.text)
+			}
+			next := tokens[i+1].text
+			if _, isClause := clauseKeywords[next]; isClause || next == ")" {
+				return errors.Errorf("trailing operator %s before %s at position %d", token.text, next, token.pos)
+			}
+		}
+		if _, isCondition := conditionKeywords[token.text]; isCondition && i+1 < len(tokens) {
+			if next := tokens[i+1].text; next == "AND" || next == "OR" {
+				return errors.Errorf("leading operator %s after %s at position %d", next, token.text, tokens[i+1].pos)
+			}
+		}
+	}
+
+	return validatePlaceholders(tokens, argsCount, dialect)
+}
+
+// aliasScope is the aliases declared within a level of parentheses.
+type aliasScope struct {
+	tables   map[string]struct{}
+	columns  map[string]struct{}
+	inTables bool // within FROM or JOIN, the aliases are of the tables
+}
+
+func newAliasScope() *aliasScope {
+	return &aliasScope{
+		tables:  map[string]struct{}{},
+		columns: map[string]struct{}{},
+	}
+}
+
+// add declares the alias in the namespace of the current clause, returns false if it is already declared.
+func (s *aliasScope) add(alias string) bool {
+	aliases := s.columns
+	if s.inTables {
+		aliases = s.tables
+	}
+	if _, found := aliases[alias]; found {
+		return false
+	}
+	aliases[alias] = struct{}{}
+	return true
+}
+
+// validatePlaceholders checks the placeholders reference all the arguments, without gaps.
+func validatePlaceholders(tokens []sqlToken, argsCount int, dialect Dialect) error {
+	if dialect.placeholder(1) == "?" {
+		count := 0
+		for _, token := range tokens {
+			if token.text == "?" {
+				count++
+			}
+		}
+		if count != argsCount {
+			return errors.Errorf("%d placeholders for %d args", count, argsCount)
+		}
+		return nil
+	}
+
+	prefix := strings.ToUpper(strings.TrimSuffix(dialect.placeholder(1), "1")) // the words are upper-cased
+	referenced := make([]bool, argsCount+1)
+	for _, token := range tokens {
+		if !strings.HasPrefix(token.text, prefix) {
+			continue
+		}
+		n, err := strconv.Atoi(token.text[len(prefix):])
+		if err != nil {
+			continue
+		}
+		if n < 1 || n > argsCount {
+			return errors.Errorf("placeholder %s is out of range, total %d args", token.text, argsCount)
+		}
+		referenced[n] = true
+	}
+	for n := 1; n <= argsCount; n++ {
+		if !referenced[n] {
+			return errors.Errorf("placeholder %s%d is missing, total %d args", prefix, n, argsCount)
+		}
+	}
+	return nil
+}
+
+// tokenizeSql splits the statement into words (upper-cased keywords), placeholders, numbers, punctuations and operators.
+func tokenizeSql(stmt string) ([]sqlToken, error) {
+	var tokens []sqlToken
+	isWordChar := func(c byte) bool {
+		return c == '_' || c == '.' || c == '$' || c == '@' || isDigit(c) || (c|0x20 >= 'a' && c|0x20 <= 'z') || c >= 0x80
+	}
+	isOperatorChar := func(c byte) bool {
+		return strings.IndexByte("=<>!+-*/|%&^~#?:", c) >= 0
+	}
+
+	for i := 0; i < len(stmt); {
+		c := stmt[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '-' && strings.HasPrefix(stmt[i:], "--"):
+			end := strings.IndexByte(stmt[i:], '\n')
+			if end < 0 {
+				return tokens, nil
+			}
+			i += end + 1
+		case c == '/' && strings.HasPrefix(stmt[i:], "/*"):
+			end := strings.Index(stmt[i+2:], "*/")
+			if end < 0 {
+				return nil, errors.Errorf("unterminated comment at position %d", i)
+			}
+			i += end + 4
+		case c == '\'' || c == '"' || c == '`':
+			j := i + 1
+			for {
+				end := strings.IndexByte(stmt[j:], c)
+				if end < 0 {
+					return nil, errors.Errorf("unterminated quote %c at position %d", c, i)
+				}
+				j += end + 1
+				if j < len(stmt) && stmt[j] == c { // doubled quote
+					j++
+					continue
+				}
+				break
+			}
+			tokens = append(tokens, sqlToken{text: stmt[i:j], pos: i})
+			i = j
+		case c == '(' || c == ')' || c == ',' || c == ';':
+			tokens = append(tokens, sqlToken{text: string(c), pos: i})
+			i++
+		case isWordChar(c):
+			j := i
+			for j < len(stmt) && isWordChar(stmt[j]) {
+				j++
+			}
+			tokens = append(tokens, sqlToken{text: strings.ToUpper(stmt[i:j]), pos: i})
+			i = j
+		case isOperatorChar(c):
+			j := i
+			for j < len(stmt) && isOperatorChar(stmt[j]) && !strings.HasPrefix(stmt[j:], "--") && !strings.HasPrefix(stmt[j:], "/*") {
+				j++
+			}
+			if j == i { // comment starts right after
+				j++
+			}
+			tokens = append(tokens, sqlToken{text: stmt[i:j], pos: i})
+			i = j
+		default:
+			tokens = append(tokens, sqlToken{text: string(c), pos: i})
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+// isSqlWord returns true if the token is a word or a quoted identifier, e.g. keyword, identifier.
+func isSqlWord(text string) bool {
+	if text == "" {
+		return false
+	}
+	c := text[0]
+	return c == '_' || c == '"' || c == '`' || (c|0x20 >= 'a' && c|0x20 <= 'z') || c >= 0x80
+}
@@ -0,0 +1,160 @@
+package sqlb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSqlBuilder_Validate(t *testing.T) {
+	table1 := UseTable[testStruct1]().Alias("t1").Seal()
+	table2 := UseTable[testStruct2]().Alias("t2").Seal()
+
+	valid := map[string]*SqlBuilder{
+		"select with join and subquery": Select(table1.Col("pk1"), table2.Col("pk3")).
+			From(table1).
+			Join(LeftJoin, table2, table1.Col("pk1"), table2.Col("pk1")).
+			AndOn(Gt(table2.Col("amount"), 1)).
+			Where(Eq(table1.Col("pk2"), "a")).
+			And(Exists(SelectExists().From(UseTable[testStruct1]().Alias("s1").Seal()).Where(Eq(table1.Col("amount"), 2)))),
+		"aggregate aliased as the table": Select(table1.Col("pk1")).
+			SelectAggregates(Sum(table1.Col("amount")).As("t1")).
+			From(table1).
+			GroupBy(table1.Col("pk1")),
+		"select exists":        SelectExists().From(table1).Where(table1.Col("pk1"), "= $1").Args("a"),
+		"select in MySQL":      Select(table1.Col("pk1")).From(table1).Where(In(table1.Col("pk1"), "a", "b")).WithDialect(DialectMySQL),
+		"select in SQL Server": Select(table1.Col("pk1")).From(table1).Where(Eq(table1.Col("pk1"), "a")).Limit(1).WithDialect(DialectSQLServer),
+		"insert on conflict": InsertInto(table1, table1.Col("pk1"), table1.Col("amount")).
+			Values(testStruct1{Pk1: "a"}, testStruct1{Pk1: "b"}).
+			OnConflict(table1.Col("pk1")).
+			DoUpdate(table1.Col("amount").AddExcluded()).
+			Returning(table1.Col("pk1")),
+	}
+	for name, b := range valid {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, b.Validate())
+		})
+	}
+
+	t.Run("invalid raw tokens", func(t *testing.T) {
+		err := Select(table1.Col("pk1")).From(table1).Where(table1.Col("pk1"), "= $1 AND").Args("a").Validate()
+		require.EqualError(t, err, "trailing operator AND at the end of the statement")
+	})
+
+	t.Run("builder error", func(t *testing.T) {
+		err := Select(table1.Col("pk1")).From(table1, UseTable[testStruct2]().Alias("t1").Seal()).Validate()
+		require.ErrorIs(t, err, ErrAliasConflict)
+	})
+}
+
+func TestValidateStatement(t *testing.T) {
+	tests := []struct {
+		name      string
+		stmt      string
+		argsCount int
+		dialect   Dialect
+		wantErr   string
+	}{
+		{
+			name:      "valid",
+			stmt:      "/* repo.go:1 */ SELECT a AS \"x\", CAST(b AS TEXT) FROM t AS t WHERE a = $1 AND b = ')(' -- $9\n",
+			argsCount: 1,
+		},
+		{
+			name:    "not closed",
+			stmt:    "SELECT COUNT(1 FROM t",
+			wantErr: "unbalanced parentheses: 1 not closed",
+		},
+		{
+			name:    "unexpected closing",
+			stmt:    "SELECT a) FROM t",
+			wantErr: "unbalanced parentheses: unexpected ) at position 8",
+		},
+		{
+			name:    "unterminated quote",
+			stmt:    "SELECT 'a FROM t",
+			wantErr: "unterminated quote ' at position 7",
+		},
+		{
+			name:    "trailing operator before clause",
+			stmt:    "SELECT a, FROM t",
+			wantErr: "trailing operator , before FROM at position 8",
+		},
+		{
+			name:    "trailing operator before closing",
+			stmt:    "SELECT a FROM t WHERE (a = 1 OR)",
+			wantErr: "trailing operator OR before ) at position 29",
+		},
+		{
+			name:    "leading operator",
+			stmt:    "SELECT a FROM t WHERE AND a = 1",
+			wantErr: "leading operator AND after WHERE at position 22",
+		},
+		{
+			name:    "duplicate alias",
+			stmt:    "SELECT 1 FROM t1 AS a, t2 AS A",
+			wantErr: "duplicate alias A at position 29",
+		},
+		{
+			name:    "duplicate column alias",
+			stmt:    "SELECT a AS x, b AS X FROM t AS t",
+			wantErr: "duplicate alias X at position 20",
+		},
+		{
+			name:    "duplicate joined table alias",
+			stmt:    "SELECT 1 FROM t1 AS a JOIN t2 AS a ON a.id = a.id",
+			wantErr: "duplicate alias A at position 33",
+		},
+		{
+			name: "column alias same as table alias",
+			stmt: "SELECT table1.pk1, SUM(table1.amount) AS table1 FROM table1 AS table1",
+		},
+		{
+			name:    "same alias in subquery",
+			stmt:    "SELECT 1 FROM t1 AS a WHERE EXISTS (SELECT 1 FROM t2 AS a)",
+			wantErr: "",
+		},
+		{
+			name:      "placeholder gap",
+			stmt:      "SELECT a FROM t WHERE a = $1 AND b = $3",
+			argsCount: 3,
+			wantErr:   "placeholder $2 is missing, total 3 args",
+		},
+		{
+			name:      "placeholder out of range",
+			stmt:      "SELECT a FROM t WHERE a = $2",
+			argsCount: 1,
+			wantErr:   "placeholder $2 is out of range, total 1 args",
+		},
+		{
+			name:      "question marks",
+			stmt:      "SELECT a FROM t WHERE a = ? AND b = '?'",
+			argsCount: 2,
+			dialect:   DialectMySQL,
+			wantErr:   "1 placeholders for 2 args",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateStatement(tt.stmt, tt.argsCount, tt.dialect)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func FuzzValidateStatement(f *testing.F) {
+	f.Add("SELECT a FROM t AS t WHERE a = $1", 1)
+	f.Add("INSERT INTO t (a) VALUES (?)", 1)
+	f.Add("SELECT '' /* */ -- ", 0)
+	f.Fuzz(func(t *testing.T, stmt string, argsCount int) {
+		if argsCount < 0 || argsCount > 100 {
+			t.Skip()
+		}
+		_ = validateStatement(stmt, argsCount, DialectPostgres) // must not panic
+		_ = validateStatement(stmt, argsCount, DialectMySQL)
+	})
+}
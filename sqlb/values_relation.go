@@ -0,0 +1,200 @@
+package sqlb
+
+import (
+	"fmt"
+	"math/rand/v2"
+)
+
+// ValuesRelation is an in-memory list of rows usable as a table in FROM and JOIN:
+// '(VALUES ($1::[type1], $2::[type2]), ($3, $4)) AS [alias] ([col1], [col2])'.
+//
+// Eg: anti-join the list against a table without creating temp tables,
+// the rows of the relation can not be scanned, select the columns of the tables instead.
+type ValuesRelation struct {
+	uid     int64
+	rows    [][]any
+	alias   string
+	columns []ValuesColumn
+}
+
+// ValuesColumn is the column of the VALUES relation, see Column.
+type ValuesColumn struct {
+	name    string
+	sqlType string
+}
+
+// Column declares the column of the VALUES relation with its SQL type, eg: Column("id", "BIGINT").
+// The values of the first row are cast to the types, since the database infers the types of the columns from them,
+// eg: the placeholders would be inferred as TEXT by Postgres and fail to compare with the columns of other types.
+func Column(name, sqlType string) ValuesColumn {
+	if name == "" {
+		panic("column name cannot be empty")
+	}
+	if sqlType == "" {
+		panic(fmt.Sprintf("column %s must have the SQL type", name))
+	}
+	return ValuesColumn{name: name, sqlType: sqlType}
+}
+
+var _ GenericTableToUse = (*ValuesRelation)(nil)
+
+// Values creates the VALUES relation of the rows, the relation must be named via As before use.
+// Values are bound as arguments, unless they are columns or expressions.
+func Values(rows ...[]any) *ValuesRelation {
+	if len(rows) == 0 {
+		panic("VALUES must have at least one row")
+	}
+	return &ValuesRelation{
+		uid:  rand.Int64(),
+		rows: rows,
+	}
+}
+
+// As names the relation and its columns, each row must have value for every column.
+//
+// Eg: Values([]any{1}, []any{2}).As("v", sqlb.Column("id", "BIGINT")).
+func (v *ValuesRelation) As(alias string, columns ...ValuesColumn) *ValuesRelation {
+	if v.alias != "" {
+		panic("alias already set")
+	}
+	if alias == "" {
+		panic("alias cannot be empty")
+	}
+	if len(columns) == 0 {
+		panic("VALUES must have at least one column")
+	}
+	for i, row := range v.rows {
+		if len(row) != len(columns) {
+			panic(fmt.Sprintf("row no.%d has %d values, expected %d", i+1, len(row), len(columns)))
+		}
+	}
+
+	v.alias = alias
+	v.columns = columns
+	return v
+}
+
+// Col returns column of the relation by name.
+func (v *ValuesRelation) Col(column string) GenericColumnToUse {
+	v.mustSealed()
+	for _, c := range v.columns {
+		if c.name == column {
+			return GenericColumnToUse{
+				name:  c.name,
+				table: v,
+			}
+		}
+	}
+	panic(fmt.Sprintf("column %s does not exist in VALUES %s", column, v.alias))
+}
+
+func (v *ValuesRelation) uniqueIdentity() int64 {
+	return v.uid
+}
+
+// tableName returns the alias, the relation is rendered by writeTableReference.
+func (v *ValuesRelation) tableName() string {
+	return v.alias
+}
+
+func (v *ValuesRelation) tableAlias() string {
+	return v.alias
+}
+
+func (v *ValuesRelation) genericTableMeta() genericTableMetadata {
+	return valuesRelationMetadata{relation: v}
+}
+
+func (v *ValuesRelation) allColumns() []GenericColumnToUse {
+	columns := make([]GenericColumnToUse, len(v.columns))
+	for i, c := range v.columns {
+		columns[i] = v.Col(c.name)
+	}
+	return columns
+}
+
+func (v *ValuesRelation) mustSealed() {
+	if v.alias == "" {
+		panic("VALUES must be named via As")
+	}
+}
+
+// writeValuesRelation writes '(VALUES ($1::[type1], $2::[type2]), ($3, $4)) AS [alias] ([col1], [col2])',
+// the values of the first row are cast to the types of the columns.
+func (c *buildContext) writeValuesRelation(v *ValuesRelation) {
+	if c.dialect.isMySQL() || c.dialect == DialectSQLite {
+		panic(ErrUnsupportedByDialect{Feature: "VALUES relation", Dialect: c.dialect})
+	}
+
+	c.writeString("(VALUES ")
+	for i, row := range v.rows {
+		if i > 0 {
+			c.writeString(", ")
+		}
+		c.writeString("(")
+		for j, value := range row {
+			if j > 0 {
+				c.writeString(", ")
+			}
+			if i > 0 {
+				c.writeToken(valueToken(value), "VALUES")
+			} else {
+				c.writeCast(valueToken(value), v.columns[j].sqlType, "VALUES")
+			}
+		}
+		c.writeString(")")
+	}
+	c.writeString(") AS ")
+	c.writeString(v.alias)
+	c.writeString(" (")
+	for i, column := range v.columns {
+		if i > 0 {
+			c.writeString(", ")
+		}
+		c.writeString(column.name)
+	}
+	c.writeString(")")
+}
+
+// valuesRelationMetadata is the metadata of the VALUES relation, which is not registered.
+type valuesRelationMetadata struct {
+	relation *ValuesRelation
+}
+
+var _ genericTableMetadata = valuesRelationMetadata{}
+
+func (m valuesRelationMetadata) Name() string {
+	return m.relation.alias
+}
+
+func (m valuesRelationMetadata) typeName() string {
+	return ""
+}
+
+func (m valuesRelationMetadata) isMaterializedView() bool {
+	return false
+}
+
+func (m valuesRelationMetadata) newRow() any {
+	panic("scanning columns of VALUES relation is not supported")
+}
+
+func (m valuesRelationMetadata) selectSpecOfColumns(...string) rowSelectSpec {
+	panic("scanning columns of VALUES relation is not supported")
+}
+
+func (m valuesRelationMetadata) insertSpecOfColumns(...string) insertRecordSpec {
+	panic("inserting into VALUES relation is not supported")
+}
+
+func (m valuesRelationMetadata) validateColumns(any, ...string) error {
+	return nil
+}
+
+func (m valuesRelationMetadata) schema() TableSchema {
+	panic("VALUES relation has no schema")
+}
+
+func (m valuesRelationMetadata) useTable(string, string) GenericTableToUse {
+	panic("VALUES relation is not registered")
+}
@@ -0,0 +1,72 @@
+package sqlb
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValues(t *testing.T) {
+	t.Run("anti-join the list against a table", func(t *testing.T) {
+		table1 := UseTable[testStruct1]().Alias("t1").Seal()
+		v := Values([]any{"a", 1}, []any{"b", 2}).As("v", Column("pk1", "TEXT"), Column("pk2", "INT"))
+
+		gotSql, gotArgs := Select(v.Col("pk1"), v.Col("pk2")).
+			From(v).
+			Join(LeftJoin, table1, v.Col("pk1"), table1.Col("pk1")).
+			Where(IsNull(table1.Col("pk1"))).
+			Build()
+		require.Equal(t, "SELECT v.pk1, v.pk2\nFROM (VALUES ($1::TEXT, $2::INT), ($3, $4)) AS v (pk1, pk2)\nLEFT JOIN table1 AS t1 ON v.pk1 = t1.pk1\nWHERE t1.pk1 IS NULL\n", gotSql)
+		require.Equal(t, []any{"a", 1, "b", 2}, gotArgs)
+	})
+
+	t.Run("join the list, values are allocated after where args", func(t *testing.T) {
+		table1 := UseTable[testStruct1]().Alias("t1").Seal()
+		v := Values([]any{"a"}).As("v", Column("pk1", "TEXT"))
+
+		gotSql, gotArgs := Select(table1.Col("pk1")).
+			From(table1).
+			Join(InnerJoin, v, table1.Col("pk1"), v.Col("pk1")).
+			Where(table1.Col("pk2"), "= $1").Args(9).
+			Build()
+		require.Equal(t, "SELECT t1.pk1\nFROM table1 AS t1\nINNER JOIN (VALUES ($2::TEXT)) AS v (pk1) ON t1.pk1 = v.pk1\nWHERE t1.pk2 = $1\n", gotSql)
+		require.Equal(t, []any{9, "a"}, gotArgs)
+	})
+
+	t.Run("cast of other dialects", func(t *testing.T) {
+		v := Values([]any{1}, []any{2}).As("v", Column("id", "BIGINT"))
+
+		gotSql, _ := Select(v.Col("id")).From(v).WithDialect(DialectSQLServer).Build()
+		require.Equal(t, "SELECT v.id\nFROM (VALUES (CAST(@p1 AS BIGINT)), (@p2)) AS v (id)\n", gotSql)
+	})
+
+	t.Run("invalid usages", func(t *testing.T) {
+		require.Panics(t, func() {
+			Values()
+		})
+		require.Panics(t, func() {
+			Values([]any{"a", 1}).As("v", Column("pk1", "TEXT"))
+		}, "row must have value for every column")
+		require.Panics(t, func() {
+			Values([]any{"a"}).As("v", Column("pk1", "TEXT")).Col("pk2")
+		})
+		require.Panics(t, func() {
+			Values([]any{"a"}).As("v", Column("pk1", ""))
+		}, "column must have the SQL type")
+		require.Panics(t, func() {
+			v := Values([]any{"a"})
+			Select().From(v).Build()
+		}, "must be named")
+		require.Panics(t, func() {
+			v := Values([]any{"a"}).As("v", Column("pk1", "TEXT"))
+			Select(v.Col("pk1")).From(v).WithDialect(DialectMySQL).Build()
+		})
+	})
+
+	t.Run("serialization is not supported", func(t *testing.T) {
+		v := Values([]any{"a"}).As("v", Column("pk1", "TEXT"))
+		_, err := json.Marshal(Select(v.Col("pk1")).From(v))
+		require.Error(t, err)
+	})
+}